@@ -1,8 +1,29 @@
 package aliyunpan
 
-import "github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
+import (
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
+)
 
 type (
+	// AlbumOperator 个人相簿接口，WebPanClient 和 OpenPanClient 都实现了该接口
+	AlbumOperator interface {
+		// AlbumListGetAll 获取所有相簿列表
+		AlbumListGetAll(param *AlbumListParam) (AlbumList, *apierror.ApiError)
+		// AlbumList 获取一页相簿列表
+		AlbumList(param *AlbumListParam) (*AlbumListResult, *apierror.ApiError)
+		AlbumCreate(param *AlbumCreateParam) (*AlbumEntity, *apierror.ApiError)
+		AlbumEdit(param *AlbumEditParam) (*AlbumEntity, *apierror.ApiError)
+		AlbumDelete(param *AlbumDeleteParam) (bool, *apierror.ApiError)
+		AlbumGet(param *AlbumGetParam) (*AlbumEntity, *apierror.ApiError)
+		// AlbumListFileGetAll 获取相簿下的所有文件
+		AlbumListFileGetAll(param *AlbumListFileParam) (FileList, *apierror.ApiError)
+		// AlbumListFile 获取相簿下的一页文件
+		AlbumListFile(param *AlbumListFileParam) (*FileListResult, *apierror.ApiError)
+		AlbumAddFile(param *AlbumFileParam) (*FileList, *apierror.ApiError)
+		AlbumDeleteFile(param *AlbumFileParam) (bool, *apierror.ApiError)
+	}
+
 	// AlbumEntity 相薄实体
 	AlbumEntity struct {
 		Owner       string `json:"owner"`
@@ -19,6 +40,66 @@ type (
 
 	ShareAlbumList []*AlbumEntity
 
+	// AlbumList 相簿列表
+	AlbumList []*AlbumEntity
+
+	// AlbumListParam 获取相簿列表参数
+	AlbumListParam struct {
+		// OrderBy 排序字段，created_at、updated_at、file_count，默认 created_at
+		OrderBy AlbumOrderBy `json:"order_by"`
+		// OrderDirection 排序方向，ASC 升序，DESC 降序，默认 ASC
+		OrderDirection AlbumOrderDirection `json:"order_direction"`
+		// Limit 返回数量，默认100
+		Limit int `json:"limit"`
+		// Marker 分页标记
+		Marker string `json:"marker"`
+	}
+	AlbumOrderBy        string
+	AlbumOrderDirection string
+
+	// AlbumListResult 获取相簿列表返回值
+	AlbumListResult struct {
+		Items AlbumList `json:"items"`
+		// NextMarker 不为空，说明还有下一页
+		NextMarker string `json:"next_marker"`
+	}
+
+	// AlbumCreateParam 相簿创建参数
+	AlbumCreateParam struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	// AlbumEditParam 相簿编辑参数
+	AlbumEditParam struct {
+		AlbumId     string `json:"album_id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	// AlbumDeleteParam 相簿删除参数
+	AlbumDeleteParam struct {
+		AlbumId string `json:"album_id"`
+	}
+	// AlbumGetParam 相簿查询参数
+	AlbumGetParam struct {
+		AlbumId string `json:"album_id"`
+	}
+
+	// AlbumListFileParam 获取相簿文件列表参数
+	AlbumListFileParam struct {
+		AlbumId string `json:"album_id"`
+		// Limit 返回文件数量，默认100
+		Limit int `json:"limit"`
+		// Marker 分页标记
+		Marker string `json:"marker"`
+		// ImageThumbnailWidth 生成的图片缩略图宽度，默认480px
+		ImageThumbnailWidth int `json:"image_thumbnail_width"`
+	}
+	// AlbumFileParam 相簿增加或者删除文件参数
+	AlbumFileParam struct {
+		AlbumId       string                 `json:"album_id"`
+		DriveFileList []FileBatchActionParam `json:"drive_file_list"`
+	}
+
 	// ShareAlbumListFileParam 获取共享相册文件列表参数
 	ShareAlbumListFileParam struct {
 		// AlbumId 共享相册唯一ID
@@ -64,9 +145,31 @@ type (
 	}
 )
 
+const (
+	AlbumOrderByCreatedAt AlbumOrderBy = "created_at"
+	AlbumOrderByUpdatedAt AlbumOrderBy = "updated_at"
+	AlbumOrderByFileCount AlbumOrderBy = "file_count"
+
+	// AlbumOrderDirectionDesc 降序
+	AlbumOrderDirectionDesc AlbumOrderDirection = "DESC"
+	// AlbumOrderDirectionAsc 升序
+	AlbumOrderDirectionAsc AlbumOrderDirection = "ASC"
+)
+
 func (a *AlbumEntity) CreatedAtStr() string {
 	return apiutil.UnixTime2LocalFormat(a.CreatedAt)
 }
 func (a *AlbumEntity) UpdatedAtStr() string {
 	return apiutil.UnixTime2LocalFormat(a.UpdatedAt)
 }
+
+// AddFileItem 增加一个要加入或者移出相簿的文件
+func (a *AlbumFileParam) AddFileItem(driveId, fileId string) {
+	if a.DriveFileList == nil {
+		a.DriveFileList = []FileBatchActionParam{}
+	}
+	a.DriveFileList = append(a.DriveFileList, FileBatchActionParam{
+		DriveId: driveId,
+		FileId:  fileId,
+	})
+}
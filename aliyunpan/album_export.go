@@ -0,0 +1,420 @@
+package aliyunpan
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+const (
+	// LivePhotoSplit 实况图片保存为图片和视频两个文件，默认值
+	LivePhotoSplit LivePhotoMode = "split"
+	// LivePhotoLivp 实况图片保存为原始的 .livp 文件
+	LivePhotoLivp LivePhotoMode = "livp"
+
+	// LivePhotoStillHeic 实况图片的静态图片优先使用 heic 格式，默认值
+	LivePhotoStillHeic LivePhotoStillFormat = "heic"
+	// LivePhotoStillJpeg 实况图片的静态图片优先使用 jpeg 格式
+	LivePhotoStillJpeg LivePhotoStillFormat = "jpeg"
+
+	// AlbumExportStateFileName 导出目录下记录已导出文件的状态文件，用于增量导出
+	AlbumExportStateFileName = ".aliyunpan_album_export.json"
+)
+
+type (
+	// LivePhotoMode 实况图片的保存方式
+	LivePhotoMode string
+	// LivePhotoStillFormat 实况图片中静态图片的格式
+	LivePhotoStillFormat string
+
+	// AlbumExportParam 相簿导出参数
+	AlbumExportParam struct {
+		// LocalDir 本地保存的文件夹，不存在会自动创建
+		LocalDir string
+		// LivePhotoMode 实况图片的保存方式，为空默认 LivePhotoSplit
+		LivePhotoMode LivePhotoMode
+		// LivePhotoStill 实况图片同时有 heic 和 jpeg 时优先保存的格式，为空默认 LivePhotoStillHeic
+		LivePhotoStill LivePhotoStillFormat
+	}
+
+	// AlbumExportItem 单个相簿文件的导出结果
+	AlbumExportItem struct {
+		File *FileEntity
+		// LocalPaths 保存到本地的文件路径，实况图片拆分后有两个文件
+		LocalPaths []string
+		// Skipped 之前已经导出过并且没有变化，本次跳过
+		Skipped bool
+		Err     *apierror.ApiError
+	}
+
+	// AlbumExportFunc 每导出一个文件回调一次
+	AlbumExportFunc func(item *AlbumExportItem)
+
+	// AlbumExportOperator 相簿导出需要的网盘操作，个人相簿和共享相簿分别实现
+	AlbumExportOperator interface {
+		// AlbumFileList 获取相簿中的所有文件
+		AlbumFileList() (FileList, *apierror.ApiError)
+		// AlbumFileUrl 获取相簿文件的下载地址，实况图片可以通过 StreamsUrl 返回图片和视频的下载地址
+		AlbumFileUrl(file *FileEntity) (*ShareAlbumGetFileUrlResult, *apierror.ApiError)
+		// OpenUrl 打开下载地址
+		OpenUrl(url string) (io.ReadCloser, error)
+	}
+
+	// albumExportRecord 状态文件中记录的已导出文件
+	albumExportRecord struct {
+		Size          int64         `json:"size"`
+		ContentHash   string        `json:"content_hash"`
+		UpdatedAt     string        `json:"updated_at"`
+		LivePhotoMode LivePhotoMode `json:"live_photo_mode,omitempty"`
+		// Names 相对 LocalDir 的文件名
+		Names []string `json:"names"`
+	}
+
+	albumExporter struct {
+		op    AlbumExportOperator
+		param AlbumExportParam
+		state map[string]*albumExportRecord
+		// taken 已被占用的本地文件名，小写
+		taken map[string]bool
+	}
+)
+
+// ExportAlbum 把相簿中的所有文件下载到本地文件夹，文件的修改时间设置为相簿中文件的创建时间。
+// 实况图片按照 LivePhotoMode 拆分为图片和视频，没有单独的下载流时解压 .livp 文件。
+// 导出记录保存在 AlbumExportStateFileName 中，再次导出时跳过没有变化的文件。返回每个文件的导出结果
+func ExportAlbum(op AlbumExportOperator, param *AlbumExportParam, handler AlbumExportFunc) ([]*AlbumExportItem, *apierror.ApiError) {
+	if param == nil || param.LocalDir == "" {
+		return nil, apierror.NewFailedApiError("本地文件夹不能为空")
+	}
+	e := &albumExporter{
+		op:    op,
+		param: *param,
+		state: map[string]*albumExportRecord{},
+		taken: map[string]bool{},
+	}
+	if e.param.LivePhotoMode == "" {
+		e.param.LivePhotoMode = LivePhotoSplit
+	}
+	if e.param.LivePhotoStill == "" {
+		e.param.LivePhotoStill = LivePhotoStillHeic
+	}
+	if err := os.MkdirAll(e.param.LocalDir, 0755); err != nil {
+		return nil, apierror.NewApiErrorWithError(err)
+	}
+	if err := e.loadState(); err != nil {
+		return nil, apierror.NewApiErrorWithError(err)
+	}
+
+	fileList, err := op.AlbumFileList()
+	if err != nil {
+		return nil, err
+	}
+	items := []*AlbumExportItem{}
+	for _, f := range fileList {
+		if f == nil || f.IsFolder() {
+			continue
+		}
+		item := e.export(f)
+		items = append(items, item)
+		if handler != nil {
+			handler(item)
+		}
+	}
+	return items, nil
+}
+
+func (e *albumExporter) statePath() string {
+	return filepath.Join(e.param.LocalDir, AlbumExportStateFileName)
+}
+
+func (e *albumExporter) loadState() error {
+	data, err := ioutil.ReadFile(e.statePath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &e.state); err != nil {
+		return err
+	}
+	for _, r := range e.state {
+		for _, name := range r.Names {
+			e.taken[strings.ToLower(name)] = true
+		}
+	}
+	return nil
+}
+
+func (e *albumExporter) saveState() error {
+	data, err := json.MarshalIndent(e.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(e.statePath(), data, 0644)
+}
+
+func (e *albumExporter) export(f *FileEntity) *AlbumExportItem {
+	item := &AlbumExportItem{File: f}
+	isLivePhoto := f.IsAlbumLivePhotoFile()
+	mode := LivePhotoMode("")
+	if isLivePhoto {
+		mode = e.param.LivePhotoMode
+	}
+
+	// 增量导出，记录一致并且本地文件都在则跳过
+	old := e.state[f.FileId]
+	if old != nil && old.Size == f.FileSize && old.ContentHash == f.ContentHash && old.UpdatedAt == f.UpdatedAt && old.LivePhotoMode == mode && e.allExist(old.Names) {
+		item.Skipped = true
+		item.LocalPaths = e.localPaths(old.Names)
+		return item
+	}
+	if old != nil {
+		// 文件有变化，删除之前导出的文件后重新导出
+		for _, name := range old.Names {
+			os.Remove(filepath.Join(e.param.LocalDir, name))
+			delete(e.taken, strings.ToLower(name))
+		}
+		delete(e.state, f.FileId)
+	}
+
+	urlResult, err := e.op.AlbumFileUrl(f)
+	if err != nil {
+		item.Err = err
+		return item
+	}
+	var names []string
+	var e1 error
+	if isLivePhoto && mode == LivePhotoSplit {
+		names, e1 = e.exportLivePhoto(f, urlResult)
+	} else {
+		name := e.reserveName(f.FileName, nil)
+		names = []string{name}
+		e1 = e.download(urlResult.Url, name)
+	}
+	if e1 != nil {
+		item.Err = apierror.NewApiErrorWithError(e1)
+		return item
+	}
+
+	// 文件时间使用相簿中的时间
+	if t, ok := parseFileTime(f.CreatedAt); ok {
+		for _, p := range e.localPaths(names) {
+			os.Chtimes(p, t, t)
+		}
+	}
+	item.LocalPaths = e.localPaths(names)
+	e.state[f.FileId] = &albumExportRecord{
+		Size:          f.FileSize,
+		ContentHash:   f.ContentHash,
+		UpdatedAt:     f.UpdatedAt,
+		LivePhotoMode: mode,
+		Names:         names,
+	}
+	if e1 := e.saveState(); e1 != nil {
+		item.Err = apierror.NewApiErrorWithError(e1)
+	}
+	return item
+}
+
+// exportLivePhoto 把实况图片保存为图片和视频，优先使用单独的下载流，没有则下载 .livp 文件并解压
+func (e *albumExporter) exportLivePhoto(f *FileEntity, urlResult *ShareAlbumGetFileUrlResult) ([]string, error) {
+	base := strings.TrimSuffix(f.FileName, path.Ext(f.FileName))
+	// 基础文件名加上所有可能的后缀名都可用，保证图片和视频的文件名一致
+	base = e.reserveName(base, []string{".heic", ".heif", ".jpg", ".jpeg", ".mov"})
+	delete(e.taken, strings.ToLower(base))
+
+	if s := urlResult.StreamsUrl; s != nil && (s.Heic != "" || s.Jpeg != "") {
+		stillUrl, stillExt := s.Heic, ".heic"
+		if s.Heic == "" || (s.Jpeg != "" && e.param.LivePhotoStill == LivePhotoStillJpeg) {
+			stillUrl, stillExt = s.Jpeg, ".jpg"
+		}
+		names := []string{e.reserveName(base+stillExt, nil)}
+		if err := e.download(stillUrl, names[0]); err != nil {
+			return nil, err
+		}
+		if s.Mov != "" {
+			names = append(names, e.reserveName(base+".mov", nil))
+			if err := e.download(s.Mov, names[1]); err != nil {
+				// 删除已经保存的图片，避免重新导出时图片文件名被占用
+				e.discard(names)
+				return nil, err
+			}
+		}
+		return names, nil
+	}
+
+	// 下载 .livp 文件到临时文件并解压
+	tmpName := e.reserveName(f.FileName+".tmp", nil)
+	defer delete(e.taken, strings.ToLower(tmpName))
+	tmpPath := filepath.Join(e.param.LocalDir, tmpName)
+	defer os.Remove(tmpPath)
+	if err := e.download(urlResult.Url, tmpName); err != nil {
+		return nil, err
+	}
+	return e.unpackLivp(tmpPath, base)
+}
+
+// unpackLivp 解压 .livp 文件，.livp 是包含一张图片和一个 .mov 视频的 zip 文件
+func (e *albumExporter) unpackLivp(livpPath, base string) ([]string, error) {
+	r, err := zip.OpenReader(livpPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid livp file: %w", err)
+	}
+	defer r.Close()
+
+	var still, video *zip.File
+	for _, zf := range r.File {
+		switch strings.ToLower(path.Ext(zf.Name)) {
+		case ".heic", ".heif":
+			if still == nil || e.param.LivePhotoStill == LivePhotoStillHeic {
+				still = zf
+			}
+		case ".jpg", ".jpeg":
+			if still == nil || e.param.LivePhotoStill == LivePhotoStillJpeg {
+				still = zf
+			}
+		case ".mov":
+			video = zf
+		}
+	}
+	if still == nil {
+		return nil, fmt.Errorf("no image found in livp file")
+	}
+	names := []string{}
+	for _, zf := range []*zip.File{still, video} {
+		if zf == nil {
+			continue
+		}
+		name := e.reserveName(base+strings.ToLower(path.Ext(zf.Name)), nil)
+		names = append(names, name)
+		rc, err := zf.Open()
+		if err != nil {
+			e.discard(names)
+			return nil, err
+		}
+		err = e.writeFile(name, rc)
+		rc.Close()
+		if err != nil {
+			e.discard(names)
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+// discard 删除导出失败时已经保存的文件，并释放占用的文件名
+func (e *albumExporter) discard(names []string) {
+	for _, name := range names {
+		os.Remove(filepath.Join(e.param.LocalDir, name))
+		delete(e.taken, strings.ToLower(name))
+	}
+}
+
+// reserveName 获取一个本地不存在并且没有被其他相簿文件使用的文件名，companions 是同时需要可用的其他后缀名
+func (e *albumExporter) reserveName(name string, companions []string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_").Replace(name)
+	exists := func(n string) bool {
+		candidates := []string{n}
+		for _, ext := range companions {
+			candidates = append(candidates, n+ext)
+		}
+		for _, c := range candidates {
+			if e.taken[strings.ToLower(c)] {
+				return true
+			}
+			if _, err := os.Stat(filepath.Join(e.param.LocalDir, c)); err == nil {
+				return true
+			}
+		}
+		return false
+	}
+	if len(companions) > 0 {
+		// 没有后缀名的基础文件名，避免 UniqueFileName 把文件名中的点当作后缀
+		if exists(name) {
+			for i := 1; ; i++ {
+				n := fmt.Sprintf("%s(%d)", name, i)
+				if !exists(n) {
+					name = n
+					break
+				}
+			}
+		}
+	} else {
+		name = UniqueFileName(name, exists)
+	}
+	e.taken[strings.ToLower(name)] = true
+	return name
+}
+
+func (e *albumExporter) allExist(names []string) bool {
+	for _, p := range e.localPaths(names) {
+		if _, err := os.Stat(p); err != nil {
+			return false
+		}
+	}
+	return len(names) > 0
+}
+
+func (e *albumExporter) localPaths(names []string) []string {
+	paths := []string{}
+	for _, name := range names {
+		paths = append(paths, filepath.Join(e.param.LocalDir, name))
+	}
+	return paths
+}
+
+// download 下载文件到 LocalDir 下的 name，下载完成前写入临时文件
+func (e *albumExporter) download(url, name string) error {
+	if url == "" {
+		return fmt.Errorf("empty download url: %s", name)
+	}
+	body, err := e.op.OpenUrl(url)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return e.writeFile(name, body)
+}
+
+func (e *albumExporter) writeFile(name string, r io.Reader) error {
+	target := filepath.Join(e.param.LocalDir, name)
+	tmp := target + ".part"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, target)
+}
+
+// parseFileTime 解析文件时间，支持本地时间格式和 RFC3339 格式
+func parseFileTime(s string) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", s, time.Local); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
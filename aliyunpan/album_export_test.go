@@ -0,0 +1,213 @@
+package aliyunpan
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+// albumExportStub 内存中的相簿，contents 的 key 为下载地址，opened 记录打开的下载地址
+type albumExportStub struct {
+	files    FileList
+	contents map[string][]byte
+	// streams 实况图片单独的下载流，key 为文件ID
+	streams map[string]*ShareAlbumFileStreamUrlItem
+	opened  []string
+}
+
+func newAlbumExportStub() *albumExportStub {
+	return &albumExportStub{
+		files:    FileList{},
+		contents: map[string][]byte{},
+		streams:  map[string]*ShareAlbumFileStreamUrlItem{},
+	}
+}
+
+// add 添加相簿文件，下载地址为 url/文件ID
+func (s *albumExportStub) add(fileId, name, content string) *FileEntity {
+	f := &FileEntity{
+		DriveId:     "d",
+		AlbumId:     "album",
+		FileId:      fileId,
+		FileName:    name,
+		FileType:    "file",
+		FileSize:    int64(len(content)),
+		ContentHash: "H" + fileId,
+		CreatedAt:   "2023-01-01 08:00:00",
+		UpdatedAt:   "2023-01-01 08:00:00",
+	}
+	s.files = append(s.files, f)
+	s.contents["url/"+fileId] = []byte(content)
+	return f
+}
+
+func (s *albumExportStub) AlbumFileList() (FileList, *apierror.ApiError) {
+	return s.files, nil
+}
+
+func (s *albumExportStub) AlbumFileUrl(file *FileEntity) (*ShareAlbumGetFileUrlResult, *apierror.ApiError) {
+	return &ShareAlbumGetFileUrlResult{Url: "url/" + file.FileId, StreamsUrl: s.streams[file.FileId]}, nil
+}
+
+func (s *albumExportStub) OpenUrl(url string) (io.ReadCloser, error) {
+	s.opened = append(s.opened, url)
+	data, ok := s.contents[url]
+	if !ok {
+		return nil, fmt.Errorf("not found: %s", url)
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func stubLivp(t *testing.T, files map[string]string) string {
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		require.Nil(t, err)
+		f.Write([]byte(content))
+	}
+	require.Nil(t, w.Close())
+	return buf.String()
+}
+
+func readExported(t *testing.T, dir, name string) string {
+	data, err := ioutil.ReadFile(filepath.Join(dir, name))
+	require.Nil(t, err, name)
+	return string(data)
+}
+
+func TestExportAlbumIncremental(t *testing.T) {
+	s := newAlbumExportStub()
+	a := s.add("a", "a.jpg", "aaa")
+	s.add("b", "b.jpg", "bbb")
+	dir := t.TempDir()
+
+	items, err := ExportAlbum(s, &AlbumExportParam{LocalDir: dir}, nil)
+	require.Nil(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "aaa", readExported(t, dir, "a.jpg"))
+	assert.FileExists(t, filepath.Join(dir, AlbumExportStateFileName))
+	info, e := os.Stat(filepath.Join(dir, "a.jpg"))
+	require.Nil(t, e)
+	created, _ := parseFileTime(a.CreatedAt)
+	assert.True(t, created.Equal(info.ModTime()))
+
+	// 没有变化的文件跳过，有变化的文件重新导出
+	s.opened = nil
+	a.UpdatedAt = "2023-02-01 08:00:00"
+	s.contents["url/a"] = []byte("aaa2")
+	items, err = ExportAlbum(s, &AlbumExportParam{LocalDir: dir}, nil)
+	require.Nil(t, err)
+	assert.False(t, items[0].Skipped)
+	assert.True(t, items[1].Skipped)
+	assert.Equal(t, []string{"url/a"}, s.opened)
+	assert.Equal(t, "aaa2", readExported(t, dir, "a.jpg"))
+	assert.NoFileExists(t, filepath.Join(dir, "a(1).jpg"))
+
+	// 本地文件被删除时重新导出
+	s.opened = nil
+	require.Nil(t, os.Remove(filepath.Join(dir, "b.jpg")))
+	_, err = ExportAlbum(s, &AlbumExportParam{LocalDir: dir}, nil)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"url/b"}, s.opened)
+	assert.Equal(t, "bbb", readExported(t, dir, "b.jpg"))
+}
+
+func TestExportAlbumNameCollision(t *testing.T) {
+	s := newAlbumExportStub()
+	s.add("a1", "a.jpg", "1")
+	s.add("a2", "A.jpg", "2")
+	s.add("a3", "x/a.jpg", "3")
+	dir := t.TempDir()
+	// 本地已有的文件不会被覆盖
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "x_a.jpg"), []byte("local"), 0644))
+
+	items, err := ExportAlbum(s, &AlbumExportParam{LocalDir: dir}, nil)
+	require.Nil(t, err)
+	require.Len(t, items, 3)
+	assert.Equal(t, []string{filepath.Join(dir, "a.jpg")}, items[0].LocalPaths)
+	assert.Equal(t, []string{filepath.Join(dir, "A(1).jpg")}, items[1].LocalPaths)
+	assert.Equal(t, []string{filepath.Join(dir, "x_a(1).jpg")}, items[2].LocalPaths)
+	assert.Equal(t, "local", readExported(t, dir, "x_a.jpg"))
+
+	// 再次导出时使用状态文件中记录的文件名
+	items, err = ExportAlbum(s, &AlbumExportParam{LocalDir: dir}, nil)
+	require.Nil(t, err)
+	for _, item := range items {
+		assert.True(t, item.Skipped)
+	}
+	assert.Equal(t, []string{filepath.Join(dir, "A(1).jpg")}, items[1].LocalPaths)
+}
+
+func TestExportAlbumLivePhoto(t *testing.T) {
+	s := newAlbumExportStub()
+	s.add("l1", "live.livp", stubLivp(t, map[string]string{"IMG.HEIC": "heic", "IMG.JPG": "jpg", "IMG.MOV": "mov"}))
+	s.add("l2", "stream.livp", "")
+	s.streams["l2"] = &ShareAlbumFileStreamUrlItem{Jpeg: "stream/jpeg", Mov: "stream/mov"}
+	s.contents["stream/jpeg"] = []byte("stream-jpeg")
+	s.contents["stream/mov"] = []byte("stream-mov")
+	dir := t.TempDir()
+
+	// 没有下载流时解压 .livp，同时有 heic 和 jpeg 时按照 LivePhotoStill 选择
+	items, err := ExportAlbum(s, &AlbumExportParam{LocalDir: dir, LivePhotoStill: LivePhotoStillJpeg}, nil)
+	require.Nil(t, err)
+	require.Len(t, items, 2)
+	require.Nil(t, items[0].Err)
+	assert.Equal(t, []string{filepath.Join(dir, "live.jpg"), filepath.Join(dir, "live.mov")}, items[0].LocalPaths)
+	assert.Equal(t, "jpg", readExported(t, dir, "live.jpg"))
+	assert.Equal(t, "mov", readExported(t, dir, "live.mov"))
+	assert.NoFileExists(t, filepath.Join(dir, "live.livp.tmp"))
+	require.Nil(t, items[1].Err)
+	assert.Equal(t, "stream-jpeg", readExported(t, dir, "stream.jpg"))
+	assert.Equal(t, "stream-mov", readExported(t, dir, "stream.mov"))
+
+	// 切换保存方式后重新导出为原始的 .livp 文件，之前拆分的文件被删除
+	items, err = ExportAlbum(s, &AlbumExportParam{LocalDir: dir, LivePhotoMode: LivePhotoLivp}, nil)
+	require.Nil(t, err)
+	assert.False(t, items[0].Skipped)
+	assert.Equal(t, []string{filepath.Join(dir, "live.livp")}, items[0].LocalPaths)
+	assert.NoFileExists(t, filepath.Join(dir, "live.jpg"))
+	assert.NoFileExists(t, filepath.Join(dir, "live.mov"))
+
+	// 无效的 .livp 文件只影响该文件
+	s.contents["url/l1"] = []byte("not a zip")
+	s.files[0].UpdatedAt = "2023-02-01 08:00:00"
+	items, err = ExportAlbum(s, &AlbumExportParam{LocalDir: dir}, nil)
+	require.Nil(t, err)
+	assert.NotNil(t, items[0].Err)
+	assert.Nil(t, items[1].Err)
+}
+
+func TestExportAlbumLivePhotoPartialFailure(t *testing.T) {
+	s := newAlbumExportStub()
+	s.add("l1", "stream.livp", "")
+	s.streams["l1"] = &ShareAlbumFileStreamUrlItem{Jpeg: "stream/jpeg", Mov: "stream/mov"}
+	s.contents["stream/jpeg"] = []byte("stream-jpeg")
+	dir := t.TempDir()
+
+	// 视频下载失败时删除已经保存的图片
+	items, err := ExportAlbum(s, &AlbumExportParam{LocalDir: dir}, nil)
+	require.Nil(t, err)
+	require.Len(t, items, 1)
+	assert.NotNil(t, items[0].Err)
+	assert.NoFileExists(t, filepath.Join(dir, "stream.jpg"))
+
+	// 重新导出时使用原来的文件名
+	s.contents["stream/mov"] = []byte("stream-mov")
+	items, err = ExportAlbum(s, &AlbumExportParam{LocalDir: dir}, nil)
+	require.Nil(t, err)
+	require.Nil(t, items[0].Err)
+	assert.Equal(t, []string{filepath.Join(dir, "stream.jpg"), filepath.Join(dir, "stream.mov")}, items[0].LocalPaths)
+	assert.Equal(t, "stream-jpeg", readExported(t, dir, "stream.jpg"))
+	assert.Equal(t, "stream-mov", readExported(t, dir, "stream.mov"))
+	assert.NoFileExists(t, filepath.Join(dir, "stream(1).jpg"))
+}
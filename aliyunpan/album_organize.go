@@ -0,0 +1,294 @@
+package aliyunpan
+
+import (
+	"path"
+	"strings"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+const (
+	// AlbumRuleByMonth 按照文件时间的月份归类到相簿
+	AlbumRuleByMonth AlbumRuleType = "month"
+	// AlbumRuleByFolder 按照文件所在的文件夹名称归类到相簿
+	AlbumRuleByFolder AlbumRuleType = "folder"
+
+	// AlbumTimeCreated 使用文件的创建时间，默认值
+	AlbumTimeCreated AlbumTimeSource = "created"
+	// AlbumTimeUpdated 使用文件的修改时间
+	AlbumTimeUpdated AlbumTimeSource = "updated"
+
+	// DefaultAlbumMonthLayout 按月归类时默认的相簿名称格式
+	DefaultAlbumMonthLayout = "2006-01"
+	// DefaultAlbumOrganizeBatchSize 每次加入相簿的默认文件数量
+	DefaultAlbumOrganizeBatchSize = 100
+)
+
+type (
+	// AlbumRuleType 相簿归类规则类型
+	AlbumRuleType string
+	// AlbumTimeSource 按月归类时使用的文件时间
+	AlbumTimeSource string
+
+	// AlbumOrganizeRule 相簿归类规则，相簿名称为 Prefix 加上月份或者文件夹名称
+	AlbumOrganizeRule struct {
+		Type AlbumRuleType
+		// Prefix 相簿名称前缀，例如：手机备份-
+		Prefix string
+		// MonthLayout 按月归类时月份的时间格式，为空默认 DefaultAlbumMonthLayout
+		MonthLayout string
+		// TimeSource 按月归类时使用的文件时间，为空默认 AlbumTimeCreated
+		TimeSource AlbumTimeSource
+		// Categories 参与归类的文件分类，为空默认 image 和 video
+		Categories []string
+	}
+
+	// AlbumOrganizeParam 相簿自动归类参数
+	AlbumOrganizeParam struct {
+		DriveId string
+		// Path 需要归类的网盘文件夹，会递归处理子文件夹
+		Path string
+		// Rules 归类规则，一个文件可以按照多个规则加入多个相簿
+		Rules []*AlbumOrganizeRule
+		// BatchSize 每次加入相簿的文件数量，为空默认 DefaultAlbumOrganizeBatchSize
+		BatchSize int
+	}
+
+	// AlbumOrganizeAlbum 单个相簿的归类结果
+	AlbumOrganizeAlbum struct {
+		Album *AlbumEntity
+		// Created 相簿是否是本次新建的
+		Created bool
+		// AddedCount 本次加入相簿的文件数量
+		AddedCount int
+		// SkippedCount 已经在相簿中的文件数量
+		SkippedCount int
+	}
+
+	// AlbumOrganizeResult 相簿自动归类结果
+	AlbumOrganizeResult struct {
+		// ScannedCount 扫描的文件数量
+		ScannedCount int
+		// MatchedCount 满足任意规则的文件数量
+		MatchedCount int
+		// Albums 涉及到的相簿，按照首次匹配的顺序排列
+		Albums []*AlbumOrganizeAlbum
+	}
+
+	// AlbumOrganizeOperator 相簿自动归类需要的网盘操作
+	AlbumOrganizeOperator interface {
+		FileInfoByPath(driveId string, pathStr string) (*FileEntity, *apierror.ApiError)
+		FileListGetAll(param *FileListParam, delayMilliseconds int) (FileList, *apierror.ApiError)
+		// ListAlbums 获取所有相簿
+		ListAlbums() (AlbumList, *apierror.ApiError)
+		// CreateAlbum 创建相簿
+		CreateAlbum(name string) (*AlbumEntity, *apierror.ApiError)
+		// ListAlbumFiles 获取相簿中的所有文件
+		ListAlbumFiles(albumId string) (FileList, *apierror.ApiError)
+		// AddAlbumFiles 把文件加入相簿，每次调用的文件数量不超过 BatchSize
+		AddAlbumFiles(albumId string, files FileList) *apierror.ApiError
+	}
+
+	albumOrganizer struct {
+		op       AlbumOrganizeOperator
+		param    AlbumOrganizeParam
+		result   *AlbumOrganizeResult
+		existing map[string]*AlbumEntity
+		albums   map[string]*albumOrganizeState
+	}
+
+	albumOrganizeState struct {
+		result *AlbumOrganizeAlbum
+		// files 相簿中已有的文件，key 为 driveId/fileId
+		files   map[string]bool
+		pending FileList
+	}
+)
+
+// OrganizeAlbums 按照规则把网盘文件夹中的图片和视频归类到相簿中，相簿不存在时自动创建。
+// 已经在相簿中的文件会被跳过，同一个文件夹可以重复执行，适合定时任务。
+// 任意一个相簿操作失败时立即返回错误，已经加入相簿的文件不会回滚
+func OrganizeAlbums(op AlbumOrganizeOperator, param *AlbumOrganizeParam) (*AlbumOrganizeResult, *apierror.ApiError) {
+	if param == nil || param.DriveId == "" || len(param.Rules) == 0 {
+		return nil, apierror.NewFailedApiError("参数不能为空")
+	}
+	for _, rule := range param.Rules {
+		if rule == nil || (rule.Type != AlbumRuleByMonth && rule.Type != AlbumRuleByFolder) {
+			return nil, apierror.NewFailedApiError("不支持的相簿归类规则")
+		}
+	}
+	o := &albumOrganizer{
+		op:       op,
+		param:    *param,
+		result:   &AlbumOrganizeResult{Albums: []*AlbumOrganizeAlbum{}},
+		existing: map[string]*AlbumEntity{},
+		albums:   map[string]*albumOrganizeState{},
+	}
+	if o.param.BatchSize <= 0 {
+		o.param.BatchSize = DefaultAlbumOrganizeBatchSize
+	}
+	o.param.Path = path.Clean("/" + o.param.Path)
+
+	albumList, err := op.ListAlbums()
+	if err != nil {
+		return nil, err
+	}
+	for _, album := range albumList {
+		if _, ok := o.existing[album.Name]; !ok {
+			o.existing[album.Name] = album
+		}
+	}
+
+	root, err := op.FileInfoByPath(o.param.DriveId, o.param.Path)
+	if err != nil {
+		return nil, err
+	}
+	if !root.IsFolder() {
+		return nil, apierror.NewFailedApiError("不是文件夹：" + o.param.Path)
+	}
+	if err := o.walk(root.FileId, path.Base(o.param.Path)); err != nil {
+		return nil, err
+	}
+	for _, album := range o.result.Albums {
+		if err := o.flush(o.albums[album.Album.Name], 1); err != nil {
+			return nil, err
+		}
+	}
+	return o.result, nil
+}
+
+func (o *albumOrganizer) walk(folderId, folderName string) *apierror.ApiError {
+	fileList, err := o.op.FileListGetAll(&FileListParam{
+		DriveId:      o.param.DriveId,
+		ParentFileId: folderId,
+	}, 0)
+	if err != nil {
+		return err
+	}
+	for _, f := range fileList {
+		if f.IsFolder() {
+			if err := o.walk(f.FileId, f.FileName); err != nil {
+				return err
+			}
+			continue
+		}
+		o.result.ScannedCount++
+		matched := false
+		for _, rule := range o.param.Rules {
+			name, ok := rule.albumName(f, folderName)
+			if !ok {
+				continue
+			}
+			matched = true
+			if err := o.add(name, f); err != nil {
+				return err
+			}
+		}
+		if matched {
+			o.result.MatchedCount++
+		}
+	}
+	return nil
+}
+
+// add 把文件加入相簿的待添加列表，满一批时加入相簿
+func (o *albumOrganizer) add(albumName string, f *FileEntity) *apierror.ApiError {
+	state, err := o.album(albumName)
+	if err != nil {
+		return err
+	}
+	key := f.DriveId + "/" + f.FileId
+	if state.files[key] {
+		state.result.SkippedCount++
+		return nil
+	}
+	state.files[key] = true
+	state.pending = append(state.pending, f)
+	return o.flush(state, o.param.BatchSize)
+}
+
+// album 获取相簿的归类状态，相簿不存在时创建
+func (o *albumOrganizer) album(name string) (*albumOrganizeState, *apierror.ApiError) {
+	if state, ok := o.albums[name]; ok {
+		return state, nil
+	}
+	state := &albumOrganizeState{
+		result:  &AlbumOrganizeAlbum{},
+		files:   map[string]bool{},
+		pending: FileList{},
+	}
+	if album, ok := o.existing[name]; ok {
+		state.result.Album = album
+		fileList, err := o.op.ListAlbumFiles(album.AlbumId)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fileList {
+			state.files[f.DriveId+"/"+f.FileId] = true
+		}
+	} else {
+		album, err := o.op.CreateAlbum(name)
+		if err != nil {
+			return nil, err
+		}
+		state.result.Album = album
+		state.result.Created = true
+	}
+	o.albums[name] = state
+	o.result.Albums = append(o.result.Albums, state.result)
+	return state, nil
+}
+
+// flush 待添加的文件数量达到 minCount 时加入相簿
+func (o *albumOrganizer) flush(state *albumOrganizeState, minCount int) *apierror.ApiError {
+	if len(state.pending) == 0 || len(state.pending) < minCount {
+		return nil
+	}
+	if err := o.op.AddAlbumFiles(state.result.Album.AlbumId, state.pending); err != nil {
+		return err
+	}
+	state.result.AddedCount += len(state.pending)
+	state.pending = FileList{}
+	return nil
+}
+
+// albumName 按照规则计算文件所属的相簿名称，文件不满足规则时返回 false
+func (rule *AlbumOrganizeRule) albumName(f *FileEntity, folderName string) (string, bool) {
+	categories := rule.Categories
+	if len(categories) == 0 {
+		categories = []string{"image", "video"}
+	}
+	matched := false
+	for _, c := range categories {
+		if strings.EqualFold(c, f.Category) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return "", false
+	}
+
+	switch rule.Type {
+	case AlbumRuleByMonth:
+		timeStr := f.CreatedAt
+		if rule.TimeSource == AlbumTimeUpdated {
+			timeStr = f.UpdatedAt
+		}
+		t, ok := parseFileTime(timeStr)
+		if !ok {
+			return "", false
+		}
+		layout := rule.MonthLayout
+		if layout == "" {
+			layout = DefaultAlbumMonthLayout
+		}
+		return rule.Prefix + t.Format(layout), true
+	case AlbumRuleByFolder:
+		if folderName == "" || folderName == "/" {
+			return "", false
+		}
+		return rule.Prefix + folderName, true
+	}
+	return "", false
+}
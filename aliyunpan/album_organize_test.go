@@ -0,0 +1,159 @@
+package aliyunpan
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+// albumOrganizeStub 在 stubDrive 上保存相簿，addCalls 记录每次加入相簿的文件数量
+type albumOrganizeStub struct {
+	*stubDrive
+	albums     AlbumList
+	albumFiles map[string]FileList
+	addCalls   []int
+	// failAdd 加入该相簿时返回错误
+	failAdd string
+}
+
+func newAlbumOrganizeStub() *albumOrganizeStub {
+	return &albumOrganizeStub{stubDrive: newStubDrive(), albums: AlbumList{}, albumFiles: map[string]FileList{}}
+}
+
+// addMedia 添加图片或者视频文件，创建时间为 createdAt
+func (s *albumOrganizeStub) addMedia(fileId, parentFileId, name, category, createdAt string) *FileEntity {
+	f := s.add(fileId, parentFileId, name, 10, "H"+fileId, "")
+	f.Category = category
+	f.CreatedAt = createdAt
+	return f
+}
+
+func (s *albumOrganizeStub) ListAlbums() (AlbumList, *apierror.ApiError) {
+	return s.albums, nil
+}
+
+func (s *albumOrganizeStub) CreateAlbum(name string) (*AlbumEntity, *apierror.ApiError) {
+	album := &AlbumEntity{AlbumId: "album" + strconv.Itoa(len(s.albums)+1), Name: name}
+	s.albums = append(s.albums, album)
+	return album, nil
+}
+
+func (s *albumOrganizeStub) ListAlbumFiles(albumId string) (FileList, *apierror.ApiError) {
+	return s.albumFiles[albumId], nil
+}
+
+func (s *albumOrganizeStub) AddAlbumFiles(albumId string, files FileList) *apierror.ApiError {
+	if albumId == s.failAdd {
+		return apierror.NewFailedApiError("add failed")
+	}
+	s.addCalls = append(s.addCalls, len(files))
+	s.albumFiles[albumId] = append(s.albumFiles[albumId], files...)
+	return nil
+}
+
+func TestOrganizeAlbumsByMonth(t *testing.T) {
+	s := newAlbumOrganizeStub()
+	s.add("camera", DefaultRootParentFileId, "Camera", 0, "", "")
+	s.addMedia("a", "camera", "a.jpg", "image", "2023-01-05 10:00:00")
+	s.addMedia("b", "camera", "b.mp4", "video", "2023-01-20 10:00:00")
+	s.addMedia("c", "camera", "c.jpg", "image", "2023-02-01 10:00:00")
+	s.addMedia("d", "camera", "d.txt", "doc", "2023-02-01 10:00:00")
+	s.addMedia("e", "camera", "e.jpg", "image", "")
+
+	r, err := OrganizeAlbums(s, &AlbumOrganizeParam{
+		DriveId: "d",
+		Path:    "/Camera",
+		Rules:   []*AlbumOrganizeRule{{Type: AlbumRuleByMonth, Prefix: "备份-"}},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 5, r.ScannedCount)
+	// 文档不在默认分类中，没有时间的文件无法按月归类
+	assert.Equal(t, 3, r.MatchedCount)
+	require.Len(t, r.Albums, 2)
+	assert.Equal(t, "备份-2023-01", r.Albums[0].Album.Name)
+	assert.Equal(t, 2, r.Albums[0].AddedCount)
+	assert.Equal(t, "备份-2023-02", r.Albums[1].Album.Name)
+	assert.Equal(t, 1, r.Albums[1].AddedCount)
+	assert.True(t, r.Albums[0].Created)
+}
+
+func TestOrganizeAlbumsExistingAlbum(t *testing.T) {
+	s := newAlbumOrganizeStub()
+	s.add("camera", DefaultRootParentFileId, "Camera", 0, "", "")
+	s.add("sub", "camera", "trip", 0, "", "")
+	a := s.addMedia("a", "camera", "a.jpg", "image", "")
+	s.addMedia("b", "sub", "b.jpg", "image", "")
+	s.albums = AlbumList{{AlbumId: "x", Name: "Camera"}}
+	s.albumFiles["x"] = FileList{a}
+
+	r, err := OrganizeAlbums(s, &AlbumOrganizeParam{
+		DriveId: "d",
+		Path:    "Camera/",
+		Rules:   []*AlbumOrganizeRule{{Type: AlbumRuleByFolder}},
+	})
+	require.Nil(t, err)
+	require.Len(t, r.Albums, 2)
+	// 已有的相簿不会重新创建，已经在相簿中的文件被跳过
+	assert.Equal(t, "x", r.Albums[0].Album.AlbumId)
+	assert.False(t, r.Albums[0].Created)
+	assert.Equal(t, 1, r.Albums[0].SkippedCount)
+	assert.Equal(t, 0, r.Albums[0].AddedCount)
+	// 子文件夹按照子文件夹的名称归类
+	assert.Equal(t, "trip", r.Albums[1].Album.Name)
+	assert.True(t, r.Albums[1].Created)
+	assert.Equal(t, 1, r.Albums[1].AddedCount)
+	assert.Len(t, s.albums, 2)
+	assert.Len(t, s.albumFiles["x"], 1)
+}
+
+func TestOrganizeAlbumsBatchSize(t *testing.T) {
+	s := newAlbumOrganizeStub()
+	s.add("camera", DefaultRootParentFileId, "Camera", 0, "", "")
+	for i := 0; i < 5; i++ {
+		id := strconv.Itoa(i)
+		s.addMedia(id, "camera", id+".jpg", "image", "")
+	}
+
+	r, err := OrganizeAlbums(s, &AlbumOrganizeParam{
+		DriveId:   "d",
+		Path:      "/Camera",
+		Rules:     []*AlbumOrganizeRule{{Type: AlbumRuleByFolder}},
+		BatchSize: 2,
+	})
+	require.Nil(t, err)
+	require.Len(t, r.Albums, 1)
+	assert.Equal(t, 5, r.Albums[0].AddedCount)
+	assert.Equal(t, []int{2, 2, 1}, s.addCalls)
+}
+
+func TestOrganizeAlbumsError(t *testing.T) {
+	s := newAlbumOrganizeStub()
+	s.add("camera", DefaultRootParentFileId, "Camera", 0, "", "")
+	s.addMedia("a", "camera", "a.jpg", "image", "")
+
+	_, err := OrganizeAlbums(s, &AlbumOrganizeParam{DriveId: "d", Path: "/Camera"})
+	assert.NotNil(t, err)
+	_, err = OrganizeAlbums(s, &AlbumOrganizeParam{
+		DriveId: "d",
+		Path:    "/Camera",
+		Rules:   []*AlbumOrganizeRule{{Type: "year"}},
+	})
+	assert.NotNil(t, err)
+	_, err = OrganizeAlbums(s, &AlbumOrganizeParam{
+		DriveId: "d",
+		Path:    "/Camera/a.jpg",
+		Rules:   []*AlbumOrganizeRule{{Type: AlbumRuleByFolder}},
+	})
+	assert.NotNil(t, err)
+
+	s.failAdd = "album1"
+	_, err = OrganizeAlbums(s, &AlbumOrganizeParam{
+		DriveId: "d",
+		Path:    "/Camera",
+		Rules:   []*AlbumOrganizeRule{{Type: AlbumRuleByFolder}},
+	})
+	assert.NotNil(t, err)
+}
@@ -16,6 +16,7 @@ package apierror
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -91,19 +92,32 @@ const (
 	ApiCodeUploadIdNotFound ApiCode = 35
 	// ApiCodeUploadPayloadTooLarge 上传文件大小超过限制
 	ApiCodeUploadPayloadTooLarge ApiCode = 36
+	// ApiCodePlanTargetChanged 预演计划中的操作目标在执行前已经发生变化
+	ApiCodePlanTargetChanged ApiCode = 37
 )
 
 type ApiCode int
 
 type ApiError struct {
+	// Code 本地统一的错误码
 	Code ApiCode
-	Err  string
+	// Err 错误信息
+	Err string
+	// HttpStatus 服务器响应的HTTP状态码，0代表未知
+	HttpStatus int
+	// ServerCode 阿里云盘服务器返回的原始错误码，例如：NotFound.File、AlreadyExist.File
+	ServerCode string
+	// RequestId 阿里云盘服务器返回的请求ID（x-request-id），用于向官方反馈问题
+	RequestId string
+
+	// cause 底层错误，例如网络错误
+	cause error
 }
 
 func NewApiError(code ApiCode, err string) *ApiError {
 	return &ApiError{
-		code,
-		err,
+		Code: code,
+		Err:  err,
 	}
 }
 
@@ -111,10 +125,32 @@ func NewApiErrorWithError(err error) *ApiError {
 	if err == nil {
 		return NewApiError(ApiCodeOk, "")
 	} else {
+		var apiErr *ApiError
+		if errors.As(err, &apiErr) {
+			return apiErr
+		}
 		if IsNetErr(err) {
-			return NewApiError(ApiCodeNetError, err.Error())
+			return NewApiError(ApiCodeNetError, err.Error()).WithCause(err)
 		}
-		return NewApiError(ApiCodeFailed, err.Error())
+		return NewApiError(ApiCodeFailed, err.Error()).WithCause(err)
+	}
+}
+
+// NewServerApiError 根据服务器返回的原始错误码创建错误，网页端和OpenAPI使用同一套错误码映射
+func NewServerApiError(httpStatus int, serverCode, message, requestId string) *ApiError {
+	code := ApiCodeFailed
+	if m, ok := serverCodeMapping[serverCode]; ok {
+		code = m.code
+		if m.message != "" {
+			message = m.message
+		}
+	}
+	return &ApiError{
+		Code:       code,
+		Err:        message,
+		HttpStatus: httpStatus,
+		ServerCode: serverCode,
+		RequestId:  requestId,
 	}
 }
 
@@ -126,6 +162,63 @@ func NewFailedApiError(err string) *ApiError {
 	return NewApiError(ApiCodeFailed, err)
 }
 
+// NewFailedApiErrorWithCause 创建 ApiCodeFailed 错误并保留底层错误。网页端接口的请求错误一直使用 ApiCodeFailed，
+// 网络错误不改变错误码，可以通过 errors.Is(err, ErrNetwork) 判断
+func NewFailedApiErrorWithCause(err error) *ApiError {
+	if err == nil {
+		return NewApiError(ApiCodeOk, "")
+	}
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return NewApiError(ApiCodeFailed, err.Error()).WithCause(err)
+}
+
+// WithCause 设置底层错误
+func (a *ApiError) WithCause(err error) *ApiError {
+	a.cause = err
+	return a
+}
+
+// WithHttpStatus 设置HTTP状态码
+func (a *ApiError) WithHttpStatus(httpStatus int) *ApiError {
+	a.HttpStatus = httpStatus
+	return a
+}
+
+// WithRequestId 设置请求ID
+func (a *ApiError) WithRequestId(requestId string) *ApiError {
+	a.RequestId = requestId
+	return a
+}
+
+// Unwrap 返回底层错误，支持 errors.Is / errors.As
+func (a *ApiError) Unwrap() error {
+	if a == nil {
+		return nil
+	}
+	return a.cause
+}
+
+// Is 支持 errors.Is(err, apierror.ErrNotFound) 这样的判断，错误码一致即认为匹配
+func (a *ApiError) Is(target error) bool {
+	t, ok := target.(*ApiError)
+	if !ok || a == nil || t == nil {
+		return false
+	}
+	if codes, ok := sentinelCodes[t]; ok {
+		for _, c := range codes {
+			if c == a.Code {
+				return true
+			}
+		}
+		// 错误码不是 ApiCodeNetError 但是底层错误是网络错误
+		return t == ErrNetwork && a.cause != nil && IsNetErr(a.cause)
+	}
+	return a == t
+}
+
 func (a *ApiError) SetErr(code ApiCode, err string) {
 	a.Code = code
 	a.Err = err
@@ -145,6 +238,15 @@ func (a *ApiError) ErrCode() ApiCode {
 func (a *ApiError) String() string {
 	sb := &strings.Builder{}
 	fmt.Fprintf(sb, "Code=%d, Err=%s", a.Code, a.Err)
+	if a.HttpStatus != 0 {
+		fmt.Fprintf(sb, ", HttpStatus=%d", a.HttpStatus)
+	}
+	if a.ServerCode != "" {
+		fmt.Fprintf(sb, ", ServerCode=%s", a.ServerCode)
+	}
+	if a.RequestId != "" {
+		fmt.Fprintf(sb, ", RequestId=%s", a.RequestId)
+	}
 	return sb.String()
 }
 
@@ -152,43 +254,12 @@ func (a *ApiError) String() string {
 func ParseCommonApiError(data []byte) *ApiError {
 	if string(data) == "Bad Gateway" {
 		// 	HTTP/1.1 502 Bad Gateway
-		return NewApiError(ApiCodeBadGateway, "网关错误，你的请求可能被临时限流了")
+		return NewApiError(ApiCodeBadGateway, "网关错误，你的请求可能被临时限流了").WithHttpStatus(502)
 	}
 	errResp := &ErrorResp{}
 	if err := json.Unmarshal(data, errResp); err == nil {
 		if errResp.ErrorCode != "" {
-			if "AccessTokenInvalid" == errResp.ErrorCode {
-				return NewApiError(ApiCodeTokenExpiredCode, errResp.GetErrorMsg())
-			} else if "NotFound.File" == errResp.ErrorCode || "NotFound.FileId" == errResp.ErrorCode {
-				return NewApiError(ApiCodeFileNotFoundCode, errResp.GetErrorMsg())
-			} else if "AlreadyExist.File" == errResp.ErrorCode {
-				return NewApiError(ApiCodeFileAlreadyExisted, errResp.GetErrorMsg())
-			} else if "BadRequest" == errResp.ErrorCode {
-				return NewApiError(ApiCodeFailed, errResp.GetErrorMsg())
-			} else if "InvalidParameter.RefreshToken" == errResp.ErrorCode {
-				return NewApiError(ApiCodeRefreshTokenExpiredCode, errResp.GetErrorMsg())
-			} else if "FileShareNotAllowed" == errResp.ErrorCode {
-				return NewApiError(ApiCodeFileShareNotAllowed, errResp.GetErrorMsg())
-			} else if "InvalidRapidProof" == errResp.ErrorCode {
-				return NewApiError(ApiCodeInvalidRapidProof, errResp.GetErrorMsg())
-			} else if "NotFound.View" == errResp.ErrorCode {
-				return NewApiError(ApiCodeNotFoundView, errResp.GetErrorMsg())
-			} else if "BadRequest" == errResp.ErrorCode {
-				return NewApiError(ApiCodeBadRequest, errResp.GetErrorMsg())
-			} else if "InvalidResource.FileTypeFolder" == errResp.ErrorCode {
-				return NewApiError(ApiCodeInvalidResource, errResp.GetErrorMsg())
-			} else if "NotFound.VideoPreviewInfo" == errResp.ErrorCode {
-				return NewApiError(ApiCodeVideoPreviewInfoNotFound, errResp.GetErrorMsg())
-			} else if "FeatureTemporaryDisabled" == errResp.ErrorCode {
-				return NewApiError(ApiCodeFeatureTemporaryDisabled, errResp.GetErrorMsg())
-			} else if "ForbiddenFileInTheRecycleBin" == errResp.ErrorCode {
-				return NewApiError(ApiCodeForbiddenFileInTheRecycleBin, errResp.GetErrorMsg())
-			} else if "UserDeviceOffline" == errResp.ErrorCode {
-				return NewApiError(ApiCodeUserDeviceOffline, "你账号已超出最大登录设备数量，请先下线一台设备，然后重启本应用，才可以继续使用")
-			} else if "DeviceSessionSignatureInvalid" == errResp.ErrorCode {
-				return NewApiError(ApiCodeDeviceSessionSignatureInvalid, "签名过期，需要更新签名密钥")
-			}
-			return NewFailedApiError(errResp.GetErrorMsg())
+			return NewServerApiError(0, errResp.ErrorCode, errResp.GetErrorMsg(), errResp.RequestId)
 		}
 	}
 	return nil
@@ -200,15 +271,23 @@ func ParseCommonResponseApiError(resp *http.Response) ([]byte, *ApiError) {
 		return nil, nil
 	}
 
+	requestId := resp.Header.Get("x-request-id")
 	switch resp.StatusCode {
 	case 502:
-		return nil, NewApiError(ApiCodeBadGateway, "网关错误，可能是请求的参数有误")
+		return nil, NewApiError(ApiCodeBadGateway, "网关错误，可能是请求的参数有误").WithHttpStatus(resp.StatusCode).WithRequestId(requestId)
 	case 429:
-		return nil, NewApiError(ApiCodeTooManyRequests, "请求太频繁，已被阿里云盘临时限流")
+		return nil, NewApiError(ApiCodeTooManyRequests, "请求太频繁，已被阿里云盘临时限流").WithHttpStatus(resp.StatusCode).WithRequestId(requestId)
 	}
 	data, e := ioutil.ReadAll(resp.Body)
 	if e != nil {
-		return nil, NewFailedApiError(e.Error())
+		return nil, NewApiErrorWithError(e)
+	}
+	apiErr := ParseCommonApiError(data)
+	if apiErr != nil {
+		apiErr.HttpStatus = resp.StatusCode
+		if apiErr.RequestId == "" {
+			apiErr.RequestId = requestId
+		}
 	}
-	return data, ParseCommonApiError(data)
+	return data, apiErr
 }
@@ -1,7 +1,10 @@
 package apierror
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"syscall"
 	"testing"
 )
 
@@ -12,3 +15,39 @@ func TestError(t *testing.T) {
 	e := ParseCommonApiError([]byte(ers))
 	fmt.Println(e)
 }
+
+func TestErrorIs(t *testing.T) {
+	ers := "{\"code\":\"NotFound.File\",\"message\":\"file not found\",\"requestId\":\"0bc13b0116660546641984077e4b93\"}"
+	e := ParseCommonApiError([]byte(ers))
+	if !errors.Is(e, ErrNotFound) || errors.Is(e, ErrAlreadyExists) {
+		t.Fatal("errors.Is mismatch")
+	}
+	if e.ServerCode != "NotFound.File" || e.RequestId != "0bc13b0116660546641984077e4b93" {
+		t.Fatal("server code or request id lost")
+	}
+
+	cause := &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}
+	wrapped := fmt.Errorf("list file: %w", NewApiErrorWithError(cause))
+	var apiErr *ApiError
+	if !errors.As(wrapped, &apiErr) || apiErr.Code != ApiCodeNetError {
+		t.Fatal("errors.As failed")
+	}
+	if !errors.Is(wrapped, ErrNetwork) || !errors.Is(wrapped, syscall.ECONNREFUSED) {
+		t.Fatal("cause lost")
+	}
+}
+
+func TestFailedApiErrorWithCause(t *testing.T) {
+	e := NewFailedApiErrorWithCause(&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED})
+	if e.Code != ApiCodeFailed || !errors.Is(e, ErrNetwork) || !errors.Is(e, syscall.ECONNREFUSED) {
+		t.Fatal("network cause lost")
+	}
+	e = NewFailedApiErrorWithCause(errors.New("invalid json"))
+	if e.Code != ApiCodeFailed || errors.Is(e, ErrNetwork) {
+		t.Fatal("not a network error")
+	}
+	notFound := NewApiError(ApiCodeFileNotFoundCode, "not found")
+	if NewFailedApiErrorWithCause(notFound) != notFound {
+		t.Fatal("api error should be returned as is")
+	}
+}
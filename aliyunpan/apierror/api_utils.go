@@ -1,6 +1,7 @@
 package apierror
 
 import (
+	"errors"
 	"net"
 	"net/url"
 	"os"
@@ -13,6 +14,11 @@ func IsNetErr(err error) bool {
 	if !b {
 		b = underlyingErrorIs(err, syscall.ECONNABORTED)
 	}
+	if !b {
+		// 超时、DNS解析失败等
+		var netErr net.Error
+		b = errors.As(err, &netErr)
+	}
 	return b
 }
 
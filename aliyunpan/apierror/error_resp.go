@@ -21,6 +21,7 @@ type ErrorResp struct {
 	ErrorCode       string `json:"code"`
 	ErrorMsg        string `json:"message"`
 	ErrorDisplayMsg string `json:"display_message"`
+	RequestId       string `json:"requestId"`
 }
 
 type ErrorXmlResp struct {
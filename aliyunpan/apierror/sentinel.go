@@ -0,0 +1,74 @@
+package apierror
+
+var (
+	// ErrNotFound 文件或者资源不存在
+	ErrNotFound = &ApiError{Code: ApiCodeFileNotFoundCode, Err: "not found"}
+	// ErrAlreadyExists 文件已存在
+	ErrAlreadyExists = &ApiError{Code: ApiCodeFileAlreadyExisted, Err: "already exists"}
+	// ErrTokenExpired Token已过期或者无效
+	ErrTokenExpired = &ApiError{Code: ApiCodeTokenExpiredCode, Err: "token expired"}
+	// ErrRefreshTokenExpired RefreshToken已过期，需要重新登录
+	ErrRefreshTokenExpired = &ApiError{Code: ApiCodeRefreshTokenExpiredCode, Err: "refresh token expired"}
+	// ErrPermissionDenied 没有权限
+	ErrPermissionDenied = &ApiError{Code: ApiCodePermissionDenied, Err: "permission denied"}
+	// ErrThrottled 请求被限流，包括429和502网关错误
+	ErrThrottled = &ApiError{Code: ApiCodeTooManyRequests, Err: "throttled"}
+	// ErrNetwork 网络错误
+	ErrNetwork = &ApiError{Code: ApiCodeNetError, Err: "network error"}
+	// ErrBadRequest 请求非法
+	ErrBadRequest = &ApiError{Code: ApiCodeBadRequest, Err: "bad request"}
+	// ErrFeatureDisabled 功能维护中
+	ErrFeatureDisabled = &ApiError{Code: ApiCodeFeatureTemporaryDisabled, Err: "feature temporary disabled"}
+	// ErrUploadExpired 上传任务已经失效
+	ErrUploadExpired = &ApiError{Code: ApiCodeUploadIdNotFound, Err: "upload id not found"}
+	// ErrPayloadTooLarge 上传文件大小超过限制
+	ErrPayloadTooLarge = &ApiError{Code: ApiCodeUploadPayloadTooLarge, Err: "payload too large"}
+	// ErrPlanTargetChanged 预演计划中的操作目标已经发生变化
+	ErrPlanTargetChanged = &ApiError{Code: ApiCodePlanTargetChanged, Err: "plan target changed"}
+
+	// sentinelCodes 哨兵错误可以匹配的错误码
+	sentinelCodes = map[*ApiError][]ApiCode{
+		ErrNotFound:            {ApiCodeFileNotFoundCode, ApiCodeNotFoundView, ApiCodeVideoPreviewInfoNotFound},
+		ErrAlreadyExists:       {ApiCodeFileAlreadyExisted},
+		ErrTokenExpired:        {ApiCodeTokenExpiredCode, ApiCodeAccessTokenInvalid},
+		ErrRefreshTokenExpired: {ApiCodeRefreshTokenExpiredCode},
+		ErrPermissionDenied:    {ApiCodePermissionDenied, ApiCodeUserNotAllowedAccessDrive, ApiCodeForbidden, ApiCodeFileShareNotAllowed},
+		ErrThrottled:           {ApiCodeTooManyRequests, ApiCodeBadGateway},
+		ErrNetwork:             {ApiCodeNetError},
+		ErrBadRequest:          {ApiCodeBadRequest, ApiCodeInvalidResource},
+		ErrFeatureDisabled:     {ApiCodeFeatureTemporaryDisabled},
+		ErrUploadExpired:       {ApiCodeUploadIdNotFound},
+		ErrPayloadTooLarge:     {ApiCodeUploadPayloadTooLarge},
+		ErrPlanTargetChanged:   {ApiCodePlanTargetChanged},
+	}
+
+	// serverCodeMapping 阿里云盘服务器错误码到本地错误码的映射，网页端接口和OpenAPI共用
+	serverCodeMapping = map[string]struct {
+		code ApiCode
+		// message 替换服务器返回的错误信息，为空则使用服务器的错误信息
+		message string
+	}{
+		"AccessTokenInvalid":             {code: ApiCodeTokenExpiredCode},
+		"AccessTokenExpired":             {code: ApiCodeTokenExpiredCode},
+		"RefreshTokenExpired":            {code: ApiCodeRefreshTokenExpiredCode},
+		"InvalidParameter.RefreshToken":  {code: ApiCodeRefreshTokenExpiredCode},
+		"NotFound.File":                  {code: ApiCodeFileNotFoundCode},
+		"NotFound.FileId":                {code: ApiCodeFileNotFoundCode},
+		"NotFound.UploadId":              {code: ApiCodeUploadIdNotFound},
+		"NotFound.View":                  {code: ApiCodeNotFoundView},
+		"NotFound.VideoPreviewInfo":      {code: ApiCodeVideoPreviewInfoNotFound},
+		"AlreadyExist.File":              {code: ApiCodeFileAlreadyExisted},
+		"BadRequest":                     {code: ApiCodeFailed},
+		"FileShareNotAllowed":            {code: ApiCodeFileShareNotAllowed},
+		"InvalidRapidProof":              {code: ApiCodeInvalidRapidProof},
+		"InvalidResource.FileTypeFolder": {code: ApiCodeInvalidResource},
+		"FeatureTemporaryDisabled":       {code: ApiCodeFeatureTemporaryDisabled},
+		"ForbiddenFileInTheRecycleBin":   {code: ApiCodeForbiddenFileInTheRecycleBin},
+		"PermissionDenied":               {code: ApiCodePermissionDenied},
+		"UserNotAllowedAccessDrive":      {code: ApiCodeUserNotAllowedAccessDrive},
+		"TooManyRequests":                {code: ApiCodeTooManyRequests},
+		"Payload Too Large":              {code: ApiCodeUploadPayloadTooLarge},
+		"UserDeviceOffline":              {code: ApiCodeUserDeviceOffline, message: "你账号已超出最大登录设备数量，请先下线一台设备，然后重启本应用，才可以继续使用"},
+		"DeviceSessionSignatureInvalid":  {code: ApiCodeDeviceSessionSignatureInvalid, message: "签名过期，需要更新签名密钥"},
+	}
+)
@@ -0,0 +1,325 @@
+package apitransport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+type (
+	// Cassette 录制的请求、响应记录，用于在没有账号的情况下回放接口响应，编写确定性的测试
+	Cassette struct {
+		Interactions []*Interaction `json:"interactions"`
+
+		mutex sync.Mutex
+		used  map[*Interaction]bool
+	}
+
+	// Interaction 一次请求和对应的响应，已经脱敏
+	Interaction struct {
+		Request  CassetteRequest  `json:"request"`
+		Response CassetteResponse `json:"response"`
+	}
+
+	// CassetteRequest 录制的请求，不保存请求头
+	CassetteRequest struct {
+		Method string `json:"method"`
+		URL    string `json:"url"`
+		// Body JSON格式的请求体
+		Body json.RawMessage `json:"body,omitempty"`
+		// BodyText 非JSON格式的请求体
+		BodyText string `json:"body_text,omitempty"`
+	}
+
+	// CassetteResponse 录制的响应
+	CassetteResponse struct {
+		StatusCode int               `json:"status_code"`
+		Headers    map[string]string `json:"headers,omitempty"`
+		// Body JSON格式的响应体
+		Body json.RawMessage `json:"body,omitempty"`
+		// BodyText 非JSON格式的响应体
+		BodyText string `json:"body_text,omitempty"`
+	}
+
+	// recordingTransport 录制请求和响应
+	recordingTransport struct {
+		base     http.RoundTripper
+		cassette *Cassette
+	}
+
+	// replayTransport 回放录制的响应
+	replayTransport struct {
+		cassette *Cassette
+	}
+)
+
+var (
+	// sensitiveJsonKeys 需要脱敏的JSON字段名称（不区分大小写、忽略下划线，包含即匹配）。
+	// creator_id、last_modifier_id、相簿的 owner 都是用户ID
+	sensitiveJsonKeys = []string{"token", "signature", "userid", "password", "secret", "phone", "email", "avatar", "nickname", "username",
+		"creatorid", "modifierid", "owner"}
+
+	// keptResponseHeaders 录制时保留的响应头
+	keptResponseHeaders = []string{"Content-Type", "x-request-id", "x-retry-after"}
+)
+
+// NewCassette 创建空的录制记录
+func NewCassette() *Cassette {
+	return &Cassette{}
+}
+
+// LoadCassette 从文件加载录制记录
+func LoadCassette(filePath string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cassette{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("parse cassette %s: %w", filePath, err)
+	}
+	return c, nil
+}
+
+// Save 保存录制记录到文件
+func (c *Cassette) Save(filePath string) error {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	c.mutex.Lock()
+	err := encoder.Encode(c)
+	c.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filePath, buf.Bytes(), os.FileMode(0644))
+}
+
+func (c *Cassette) add(i *Interaction) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.Interactions = append(c.Interactions, i)
+}
+
+// match 查找匹配的记录，优先使用还没有回放过的记录
+func (c *Cassette) match(method, urlPath string, body []byte) *Interaction {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.used == nil {
+		c.used = map[*Interaction]bool{}
+	}
+	var found *Interaction
+	for _, i := range c.Interactions {
+		if !strings.EqualFold(i.Request.Method, method) {
+			continue
+		}
+		if u, err := url.Parse(i.Request.URL); err != nil || u.Path != urlPath {
+			continue
+		}
+		if !bodyEqual(i.Request.Body, i.Request.BodyText, body) {
+			continue
+		}
+		if !c.used[i] {
+			c.used[i] = true
+			return i
+		}
+		if found == nil {
+			found = i
+		}
+	}
+	return found
+}
+
+// NewRecordingTransport 返回录制传输层，请求会经过 base 发送，脱敏后的请求和响应保存在 cassette 中
+func NewRecordingTransport(base http.RoundTripper, cassette *Cassette) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &recordingTransport{
+		base:     base,
+		cassette: cassette,
+	}
+}
+
+// NewReplayTransport 返回回放传输层，按照请求方法、路径、请求体匹配录制的响应，不会访问网络
+func NewReplayTransport(cassette *Cassette) http.RoundTripper {
+	return &replayTransport{
+		cassette: cassette,
+	}
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	i := &Interaction{
+		Request: CassetteRequest{
+			Method: req.Method,
+			URL:    RedactURL(req.URL),
+		},
+		Response: CassetteResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    map[string]string{},
+		},
+	}
+	i.Request.Body, i.Request.BodyText = redactBody(reqBody)
+	i.Response.Body, i.Response.BodyText = redactBody(respBody)
+	for _, h := range keptResponseHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			i.Response.Headers[h] = v
+		}
+	}
+	t.cassette.add(i)
+	return resp, nil
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	i := t.cassette.match(req.Method, req.URL.Path, reqBody)
+	if i == nil {
+		return nil, fmt.Errorf("cassette: no recorded interaction for %s %s", req.Method, req.URL.Path)
+	}
+
+	body := []byte(i.Response.BodyText)
+	if len(i.Response.Body) > 0 {
+		buf := &bytes.Buffer{}
+		if err := json.Compact(buf, i.Response.Body); err != nil {
+			return nil, err
+		}
+		body = buf.Bytes()
+	}
+	header := http.Header{}
+	for k, v := range i.Response.Headers {
+		header.Set(k, v)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+		StatusCode:    i.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// readRequestBody 读取请求体，并且恢复请求体以便继续发送
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	data, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}
+	return data, nil
+}
+
+// redactBody 脱敏请求体或者响应体，JSON格式的返回 RawMessage，其他格式返回文本
+func redactBody(data []byte) (json.RawMessage, string) {
+	if len(data) == 0 {
+		return nil, ""
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, string(data)
+	}
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(redactJsonValue(v)); err != nil {
+		return nil, string(data)
+	}
+	return bytes.TrimSpace(buf.Bytes()), ""
+}
+
+func redactJsonValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			if isSensitiveJsonKey(k) {
+				switch item.(type) {
+				case string:
+					value[k] = RedactedValue
+					continue
+				case float64:
+					// 数字类型的ID替换成0，保持字段类型不变，回放时仍然可以解析
+					value[k] = 0
+					continue
+				}
+			}
+			value[k] = redactJsonValue(item)
+		}
+		return value
+	case []interface{}:
+		for k, item := range value {
+			value[k] = redactJsonValue(item)
+		}
+		return value
+	case string:
+		// 下载、上传、缩略图等链接中的签名
+		if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+			return RedactURLString(value)
+		}
+	}
+	return v
+}
+
+func isSensitiveJsonKey(key string) bool {
+	key = strings.ToLower(strings.ReplaceAll(key, "_", ""))
+	for _, k := range sensitiveJsonKeys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyEqual 比较录制的请求体和实际的请求体，JSON格式的请求体先脱敏再比较内容，忽略字段顺序
+func bodyEqual(recorded json.RawMessage, recordedText string, body []byte) bool {
+	actual, actualText := redactBody(body)
+	if len(recorded) == 0 || len(actual) == 0 {
+		return len(recorded) == 0 && len(actual) == 0 && recordedText == actualText
+	}
+	var a, b interface{}
+	if json.Unmarshal(recorded, &a) != nil || json.Unmarshal(actual, &b) != nil {
+		return false
+	}
+	return reflect.DeepEqual(a, b)
+}
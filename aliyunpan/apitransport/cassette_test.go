@@ -0,0 +1,68 @@
+package apitransport
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("x-request-id", "req-1")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Write([]byte(`{"access_token":"at-secret","user_id":"u-secret","owner_user_id":987654321,"nick_name":"tom","items":[{"creator_id":"c-secret","last_modifier_id":"m-secret","url":"https://oss.example.com/a.jpg?x-oss-signature=sig-secret&di=1","size":3}]}`))
+	}))
+	defer server.Close()
+
+	cassette := NewCassette()
+	client := &http.Client{Transport: NewRecordingTransport(http.DefaultTransport, cassette)}
+	req, _ := http.NewRequest("POST", server.URL+"/v2/user/get?sign=s-secret", strings.NewReader(`{"refresh_token":"rt-secret","drive_id":"1"}`))
+	req.Header.Set("authorization", "Bearer at-secret")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "at-secret") {
+		t.Fatal("recording must not change the live response")
+	}
+
+	cassetteFile := filepath.Join(t.TempDir(), "cassette.json")
+	if err := cassette.Save(cassetteFile); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadFile(cassetteFile)
+	for _, secret := range []string{"at-secret", "rt-secret", "u-secret", "s-secret", "sig-secret", "tom", "c-secret", "m-secret", "session=secret", "987654321"} {
+		if strings.Contains(string(data), secret) {
+			t.Fatalf("cassette leaks %s: %s", secret, data)
+		}
+	}
+
+	loaded, err := LoadCassette(cassetteFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay := &http.Client{Transport: NewReplayTransport(loaded)}
+	// 不同的 token 和字段顺序也能匹配
+	req, _ = http.NewRequest("POST", "https://api.example.com/v2/user/get", strings.NewReader(`{"drive_id":"1","refresh_token":"other"}`))
+	resp, err = replay.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != 200 || resp.Header.Get("x-request-id") != "req-1" || !strings.Contains(string(body), `"size":3`) ||
+		!strings.Contains(string(body), `"owner_user_id":0`) {
+		t.Fatalf("unexpected replay response: %d %s", resp.StatusCode, body)
+	}
+
+	req, _ = http.NewRequest("POST", "https://api.example.com/v2/user/get", strings.NewReader(`{"drive_id":"2"}`))
+	if _, err = replay.Do(req); err == nil {
+		t.Fatal("body mismatch should not be replayed")
+	}
+}
@@ -0,0 +1,222 @@
+package apitransport
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/tickstep/library-go/requester"
+)
+
+type (
+	// ClientOptions 客户端的 http 配置，用于 NewWebPanClient、NewOpenPanClient、openapi.NewAliPanClient。
+	//
+	// 超时时间的优先级：SetTimeout > WithTimeout > WithHTTPClient 的 Timeout > 默认30秒，后设置的覆盖先设置的。
+	// SetTimeout 只作用于接口请求，数据传输（上传分片、下载文件）使用 WithTransferTimeout 设置的超时时间。
+	// 接口请求的超时时间包含失败重试的时间，重试总时间由 aliyunpan.RetryPolicy 的 MaxElapsed 限制
+	ClientOptions struct {
+		// Transport 基础传输层，用于自定义代理、TLS根证书、连接池大小，或者录制回放等测试用的传输层。
+		// 重试、限速、观测等传输层会包装在它的外面
+		Transport http.RoundTripper
+		// HTTPClient 自定义的 http.Client，会使用它的 Transport、Jar、CheckRedirect、Timeout，但不会修改它本身
+		HTTPClient *http.Client
+		// Proxy 代理地址，支持 http://、https://、socks5://。自定义的传输层必须是 *http.Transport，
+		// 地址无效或者传输层不支持代理时创建客户端会 panic，不会退回直连，可以先调用 Validate 检查
+		Proxy string
+		// Timeout 接口请求的超时时间
+		Timeout time.Duration
+		// TransferTimeout 数据传输请求的超时时间，0代表使用默认值
+		TransferTimeout time.Duration
+		// Governor 请求限速配置，nil代表使用 DefaultGovernorConfig（不限速）
+		Governor *GovernorConfig
+
+		mutex sync.Mutex
+		// base 同一个配置创建的所有 http 客户端共用的基础传输层
+		base http.RoundTripper
+	}
+
+	// ClientOption 客户端配置选项
+	ClientOption func(options *ClientOptions)
+)
+
+// WithTransport 设置基础传输层
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(options *ClientOptions) {
+		options.Transport = transport
+	}
+}
+
+// WithHTTPClient 使用自定义的 http.Client，WithTransport 设置的传输层优先
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(options *ClientOptions) {
+		options.HTTPClient = client
+	}
+}
+
+// WithProxy 设置代理地址，例如 http://127.0.0.1:8080、socks5://127.0.0.1:1080，空字符串代表不使用代理。地址无效时 panic
+func WithProxy(proxyAddr string) ClientOption {
+	if proxyAddr != "" {
+		if _, err := parseProxy(proxyAddr); err != nil {
+			panic(err)
+		}
+	}
+	return func(options *ClientOptions) {
+		options.Proxy = proxyAddr
+	}
+}
+
+// WithTimeout 设置接口请求的超时时间
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(options *ClientOptions) {
+		options.Timeout = timeout
+	}
+}
+
+// WithTransferTimeout 设置数据传输请求的超时时间
+func WithTransferTimeout(timeout time.Duration) ClientOption {
+	return func(options *ClientOptions) {
+		options.TransferTimeout = timeout
+	}
+}
+
+// WithGovernorConfig 设置请求限速配置，例如 WithGovernorConfig(MetadataRateGovernorConfig(10))
+func WithGovernorConfig(config GovernorConfig) ClientOption {
+	return func(options *ClientOptions) {
+		options.Governor = &config
+	}
+}
+
+// NewClientOptions 合并配置选项
+func NewClientOptions(opts ...ClientOption) *ClientOptions {
+	options := &ClientOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(options)
+		}
+	}
+	return options
+}
+
+// Validate 检查配置，代理地址无效或者自定义的传输层不是 *http.Transport 时返回错误
+func (o *ClientOptions) Validate() error {
+	if o == nil || o.Proxy == "" {
+		return nil
+	}
+	if _, err := parseProxy(o.Proxy); err != nil {
+		return err
+	}
+	if base := o.customTransport(); base != nil {
+		if _, ok := base.(*http.Transport); !ok {
+			return fmt.Errorf("proxy is not supported by custom transport %T", base)
+		}
+	}
+	return nil
+}
+
+// NewGovernor 按照配置创建请求限速器
+func (o *ClientOptions) NewGovernor() *Governor {
+	if o == nil || o.Governor == nil {
+		return NewGovernor(DefaultGovernorConfig())
+	}
+	return NewGovernor(*o.Governor)
+}
+
+// NewHTTPClient 按照配置创建接口请求使用的 requester.HTTPClient
+func (o *ClientOptions) NewHTTPClient() *requester.HTTPClient {
+	return o.newHTTPClient(o.Timeout)
+}
+
+// NewTransferHTTPClient 按照配置创建数据传输使用的 requester.HTTPClient。
+// 同一个 ClientOptions 创建的接口请求和数据传输客户端共用同一个基础传输层（连接池），超时时间和外层的限速、重试等传输层各自独立
+func (o *ClientOptions) NewTransferHTTPClient() *requester.HTTPClient {
+	return o.newHTTPClient(o.TransferTimeout)
+}
+
+func (o *ClientOptions) newHTTPClient(timeout time.Duration) *requester.HTTPClient {
+	client := requester.NewHTTPClient()
+	// 触发内部传输层的初始化，之后替换 Transport 不会再被覆盖
+	client.SetKeepAlive(true)
+
+	if o != nil {
+		if c := o.HTTPClient; c != nil {
+			if c.Jar != nil {
+				client.Client.Jar = c.Jar
+			}
+			client.Client.CheckRedirect = c.CheckRedirect
+			if c.Timeout > 0 {
+				client.Client.Timeout = c.Timeout
+			}
+		}
+		client.Client.Transport = o.baseTransport(client.Client.Transport)
+	}
+	if timeout > 0 {
+		client.Client.Timeout = timeout
+	}
+	return client
+}
+
+// baseTransport 第一次调用时按照配置创建基础传输层，之后都返回同一个，defaultTransport 为 requester 默认创建的传输层。
+// 代理配置无效时 panic
+func (o *ClientOptions) baseTransport(defaultTransport http.RoundTripper) http.RoundTripper {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.base != nil {
+		return o.base
+	}
+	if err := o.Validate(); err != nil {
+		panic(err)
+	}
+	base, custom := defaultTransport, false
+	if c := o.customTransport(); c != nil {
+		base, custom = c, true
+	}
+	if o.Proxy != "" {
+		t, ok := base.(*http.Transport)
+		if !ok {
+			panic(fmt.Errorf("proxy is not supported by transport %T", base))
+		}
+		base = applyProxy(t, o.Proxy, custom)
+	}
+	o.base = base
+	return base
+}
+
+// customTransport 调用方设置的传输层，WithTransport 优先于 http.Client 的 Transport，没有设置返回nil
+func (o *ClientOptions) customTransport() http.RoundTripper {
+	if o.Transport != nil {
+		return o.Transport
+	}
+	if c := o.HTTPClient; c != nil && c.Transport != nil {
+		return c.Transport
+	}
+	return nil
+}
+
+// parseProxy 解析代理地址
+func parseProxy(proxyAddr string) (*url.URL, error) {
+	u, err := url.Parse(proxyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy address %q: %w", proxyAddr, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy address %q: unsupported scheme", proxyAddr)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy address %q: missing host", proxyAddr)
+	}
+	return u, nil
+}
+
+// applyProxy 设置代理。自定义的 *http.Transport 会被复制一份，不会修改调用方的对象
+func applyProxy(t *http.Transport, proxyAddr string, custom bool) http.RoundTripper {
+	u, _ := parseProxy(proxyAddr)
+	if custom {
+		t = t.Clone()
+	}
+	t.Proxy = http.ProxyURL(u)
+	return t
+}
@@ -0,0 +1,85 @@
+package apitransport
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClientOptionsSharedTransport(t *testing.T) {
+	options := NewClientOptions(WithProxy("http://127.0.0.1:8080"))
+	api := options.NewHTTPClient()
+	transfer := options.NewTransferHTTPClient()
+	tr, ok := api.Client.Transport.(*http.Transport)
+	require.True(t, ok)
+	// 同一个配置创建的客户端共用连接池
+	assert.Same(t, tr, transfer.Client.Transport)
+	require.NotNil(t, tr.Proxy)
+	u, err := tr.Proxy(&http.Request{})
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:8080", u.Host)
+
+	// 不同的配置互不影响
+	other := NewClientOptions().NewHTTPClient()
+	assert.NotSame(t, tr, other.Client.Transport)
+}
+
+func TestClientOptionsCustomTransport(t *testing.T) {
+	custom := &http.Transport{}
+	options := NewClientOptions(WithTransport(custom), WithProxy("socks5://127.0.0.1:1080"))
+	api := options.NewHTTPClient()
+	transfer := options.NewTransferHTTPClient()
+	// 设置代理时复制调用方的传输层，不修改原对象
+	assert.Nil(t, custom.Proxy)
+	assert.NotSame(t, custom, api.Client.Transport)
+	assert.Same(t, api.Client.Transport, transfer.Client.Transport)
+}
+
+func TestClientOptionsInvalidProxy(t *testing.T) {
+	// 无效的代理地址在设置时就失败，不会退回直连
+	for _, addr := range []string{"127.0.0.1:8080", "ftp://127.0.0.1:21", "http://", "http://[::1"} {
+		assert.Panics(t, func() { WithProxy(addr) }, addr)
+		options := &ClientOptions{Proxy: addr}
+		assert.Error(t, options.Validate(), addr)
+		assert.Panics(t, func() { options.NewHTTPClient() }, addr)
+	}
+	assert.NotPanics(t, func() { NewClientOptions(WithProxy("")).NewHTTPClient() })
+
+	// 不是 *http.Transport 的传输层无法设置代理，创建客户端失败
+	fn := roundTripFunc(func(req *http.Request) (*http.Response, error) { return nil, nil })
+	options := NewClientOptions(WithTransport(fn), WithProxy("http://127.0.0.1:8080"))
+	assert.Error(t, options.Validate())
+	assert.Panics(t, func() { options.NewHTTPClient() })
+	options = NewClientOptions(WithHTTPClient(&http.Client{Transport: fn}), WithProxy("socks5://127.0.0.1:1080"))
+	assert.Error(t, options.Validate())
+	assert.Panics(t, func() { options.NewTransferHTTPClient() })
+	assert.NoError(t, NewClientOptions(WithTransport(&http.Transport{}), WithProxy("http://127.0.0.1:8080")).Validate())
+}
+
+func TestClientOptionsHTTPClient(t *testing.T) {
+	jar, _ := cookiejar.New(nil)
+	hc := &http.Client{Transport: &http.Transport{}, Jar: jar, Timeout: 5 * time.Second}
+	options := NewClientOptions(WithHTTPClient(hc), WithTransferTimeout(time.Minute))
+	api := options.NewHTTPClient()
+	assert.Same(t, hc.Transport, api.Client.Transport)
+	assert.Equal(t, jar, api.Client.Jar)
+	assert.Equal(t, 5*time.Second, api.Client.Timeout)
+	assert.Equal(t, time.Minute, options.NewTransferHTTPClient().Client.Timeout)
+
+	// WithTransport 优先于 http.Client 的 Transport，WithTimeout 优先于 http.Client 的 Timeout
+	custom := &http.Transport{}
+	options = NewClientOptions(WithHTTPClient(hc), WithTransport(custom), WithTimeout(time.Second))
+	api = options.NewHTTPClient()
+	assert.Same(t, custom, api.Client.Transport)
+	assert.Equal(t, time.Second, api.Client.Timeout)
+}
@@ -0,0 +1,436 @@
+package apitransport
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// RequestClassMetadata 元数据请求，例如文件列表、文件信息、创建文件夹等接口调用
+	RequestClassMetadata RequestClass = iota
+	// RequestClassTransfer 数据传输请求，例如上传分片、下载文件内容
+	RequestClassTransfer
+)
+
+type (
+	// RequestClass 请求类别，不同类别使用独立的令牌桶
+	RequestClass int
+
+	// RequestClassifier 判断请求的类别
+	RequestClassifier func(req *http.Request) RequestClass
+
+	// BucketConfig 令牌桶配置
+	BucketConfig struct {
+		// Rate 每秒允许的请求数，小于等于0代表不限速
+		Rate float64
+		// Burst 令牌桶容量，即允许的突发请求数
+		Burst int
+		// MaxConcurrent 最大并发请求数，小于等于0代表不限制
+		MaxConcurrent int
+	}
+
+	// GovernorConfig 请求限速配置
+	GovernorConfig struct {
+		// Metadata 元数据请求配置
+		Metadata BucketConfig
+		// Transfer 数据传输请求配置
+		Transfer BucketConfig
+		// BackoffFactor 收到限流（429）后速率乘以的系数，取值 0~1
+		BackoffFactor float64
+		// MinRateFactor 速率下降的下限，相对于配置的 Rate
+		MinRateFactor float64
+		// RecoverInterval 没有再被限流时，每隔多久恢复一次速率
+		RecoverInterval time.Duration
+		// RecoverStep 每次恢复的速率比例
+		RecoverStep float64
+	}
+
+	// ClassStats 单个类别的请求统计
+	ClassStats struct {
+		// Requests 请求总数
+		Requests int64
+		// Throttled 被服务器限流的次数
+		Throttled int64
+		// Delayed 因为限速而等待的请求数
+		Delayed int64
+		// WaitTime 因为限速累计等待的时间
+		WaitTime time.Duration
+		// InFlight 正在进行的请求数
+		InFlight int64
+		// Rate 当前实际生效的速率（请求数/秒），0代表不限速
+		Rate float64
+	}
+
+	// GovernorStats 请求统计
+	GovernorStats struct {
+		Metadata ClassStats
+		Transfer ClassStats
+	}
+
+	// Governor 客户端请求限速器，所有的接口调用都经过同一个限速器。
+	// 元数据请求和数据传输请求分别使用独立的令牌桶，收到429限流后自动降低速率，之后再逐步恢复
+	Governor struct {
+		mutex    sync.RWMutex
+		config   GovernorConfig
+		buckets  [2]*bucket
+		classify RequestClassifier
+	}
+
+	bucket struct {
+		// 原子操作的计数器放在最前面，保证32位平台上的内存对齐
+		requests  int64
+		throttled int64
+		delayed   int64
+		waitTime  int64
+		inFlight  int64
+
+		mutex        sync.Mutex
+		config       BucketConfig
+		factor       float64
+		tokens       float64
+		last         time.Time
+		lastAdjust   time.Time
+		lastThrottle time.Time
+		sem          chan struct{}
+	}
+
+	// governorTransport 经过限速器的传输层
+	governorTransport struct {
+		base     http.RoundTripper
+		governor *Governor
+		// class 固定的请求类别，小于0则使用分类函数判断
+		class RequestClass
+	}
+
+	// releaseBody 响应体关闭时释放并发名额
+	releaseBody struct {
+		io.ReadCloser
+		once    sync.Once
+		release func()
+	}
+)
+
+// DefaultGovernorConfig 默认限速配置，元数据请求和数据传输都不限速，只统计请求。
+// 令牌桶不限速时收到429也不会自动降速，需要限速时使用 MetadataRateGovernorConfig 或者自定义配置，
+// 在创建客户端时通过 WithGovernorConfig 传入，或者之后调用 Governor.SetConfig 修改
+func DefaultGovernorConfig() GovernorConfig {
+	return GovernorConfig{
+		Metadata:        BucketConfig{},
+		Transfer:        BucketConfig{},
+		BackoffFactor:   0.5,
+		MinRateFactor:   0.1,
+		RecoverInterval: 10 * time.Second,
+		RecoverStep:     0.1,
+	}
+}
+
+// MetadataRateGovernorConfig 元数据请求每秒最多 rate 个的限速配置，收到429后自动降速，数据传输不限速。
+// 批量操作大量文件时建议使用，例如每秒10个可以避免触发服务器风控
+func MetadataRateGovernorConfig(rate float64) GovernorConfig {
+	config := DefaultGovernorConfig()
+	burst := int(rate)
+	if burst < 1 {
+		burst = 1
+	}
+	config.Metadata = BucketConfig{
+		Rate:  rate,
+		Burst: burst,
+	}
+	return config
+}
+
+// DefaultRequestClassifier 默认的请求分类：PUT上传数据以及带 Range 的下载请求属于数据传输，其他都是元数据请求
+func DefaultRequestClassifier(req *http.Request) RequestClass {
+	if req.Method == http.MethodPut || req.Header.Get("Range") != "" {
+		return RequestClassTransfer
+	}
+	return RequestClassMetadata
+}
+
+// NewGovernor 创建限速器
+func NewGovernor(config GovernorConfig) *Governor {
+	g := &Governor{
+		classify: DefaultRequestClassifier,
+	}
+	g.buckets[RequestClassMetadata] = newBucket(config.Metadata)
+	g.buckets[RequestClassTransfer] = newBucket(config.Transfer)
+	g.config = config
+	return g
+}
+
+// SetConfig 运行中修改限速配置，已经降低的速率会被重置
+func (g *Governor) SetConfig(config GovernorConfig) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.config = config
+	g.buckets[RequestClassMetadata].setConfig(config.Metadata)
+	g.buckets[RequestClassTransfer].setConfig(config.Transfer)
+}
+
+// Config 获取限速配置
+func (g *Governor) Config() GovernorConfig {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return g.config
+}
+
+// SetClassifier 设置请求分类函数
+func (g *Governor) SetClassifier(classify RequestClassifier) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if classify == nil {
+		classify = DefaultRequestClassifier
+	}
+	g.classify = classify
+}
+
+// Classify 判断请求的类别
+func (g *Governor) Classify(req *http.Request) RequestClass {
+	g.mutex.RLock()
+	classify := g.classify
+	g.mutex.RUnlock()
+	return classify(req)
+}
+
+// Acquire 获取一个请求名额，必要时等待。请求完成后需要调用返回的 release 函数
+func (g *Governor) Acquire(ctx context.Context, class RequestClass) (release func(), err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	b := g.bucket(class)
+	atomic.AddInt64(&b.requests, 1)
+
+	if wait := b.reserve(time.Now(), g.Config()); wait > 0 {
+		atomic.AddInt64(&b.delayed, 1)
+		atomic.AddInt64(&b.waitTime, int64(wait))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	b.mutex.Lock()
+	sem := b.sem
+	b.mutex.Unlock()
+	if sem != nil {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case sem <- struct{}{}:
+		}
+	}
+	atomic.AddInt64(&b.inFlight, 1)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			atomic.AddInt64(&b.inFlight, -1)
+			if sem != nil {
+				<-sem
+			}
+		})
+	}, nil
+}
+
+// Observe 记录请求的响应状态，收到429等限流响应后降低速率
+func (g *Governor) Observe(class RequestClass, statusCode int) {
+	if statusCode != http.StatusTooManyRequests && statusCode != 509 {
+		return
+	}
+	b := g.bucket(class)
+	atomic.AddInt64(&b.throttled, 1)
+	b.throttle(time.Now(), g.Config())
+}
+
+// Stats 获取请求统计
+func (g *Governor) Stats() GovernorStats {
+	return GovernorStats{
+		Metadata: g.bucket(RequestClassMetadata).stats(g.Config()),
+		Transfer: g.bucket(RequestClassTransfer).stats(g.Config()),
+	}
+}
+
+// Transport 返回经过限速器的传输层
+func (g *Governor) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &governorTransport{
+		base:     base,
+		governor: g,
+		class:    -1,
+	}
+}
+
+// TransferTransport 返回经过限速器的传输层，所有请求都按照数据传输请求处理
+func (g *Governor) TransferTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &governorTransport{
+		base:     base,
+		governor: g,
+		class:    RequestClassTransfer,
+	}
+}
+
+// Do 让调用方自己发起的请求（例如上传、下载的回调函数）也经过限速器，返回的响应体需要关闭
+func (g *Governor) Do(class RequestClass, fn func() (*http.Response, error)) (*http.Response, error) {
+	release, err := g.Acquire(context.Background(), class)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := fn()
+	if resp == nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+	g.Observe(class, resp.StatusCode)
+	// 调用方在 Do 返回后才读取响应体，关闭响应体时才释放并发名额
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, err
+}
+
+func (g *Governor) bucket(class RequestClass) *bucket {
+	if class == RequestClassTransfer {
+		return g.buckets[RequestClassTransfer]
+	}
+	return g.buckets[RequestClassMetadata]
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *governorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	class := t.class
+	if class < 0 {
+		class = t.governor.Classify(req)
+	}
+	release, err := t.governor.Acquire(req.Context(), class)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp == nil {
+		release()
+		return resp, err
+	}
+	t.governor.Observe(class, resp.StatusCode)
+	// 响应体读取完毕关闭后才释放并发名额
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+func (r *releaseBody) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
+
+func newBucket(config BucketConfig) *bucket {
+	b := &bucket{}
+	b.setConfig(config)
+	return b
+}
+
+func (b *bucket) setConfig(config BucketConfig) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.config = config
+	b.factor = 1
+	b.tokens = float64(b.burst())
+	b.last = time.Now()
+	b.sem = nil
+	if config.MaxConcurrent > 0 {
+		b.sem = make(chan struct{}, config.MaxConcurrent)
+	}
+}
+
+func (b *bucket) burst() int {
+	if b.config.Burst < 1 {
+		return 1
+	}
+	return b.config.Burst
+}
+
+// recover 长时间没有被限流则逐步恢复速率，调用时需要持有锁
+func (b *bucket) recover(now time.Time, config GovernorConfig) {
+	if b.factor >= 1 || config.RecoverInterval <= 0 || config.RecoverStep <= 0 {
+		return
+	}
+	for b.factor < 1 && now.Sub(b.lastAdjust) >= config.RecoverInterval {
+		b.factor += config.RecoverStep
+		b.lastAdjust = b.lastAdjust.Add(config.RecoverInterval)
+	}
+	if b.factor > 1 {
+		b.factor = 1
+	}
+}
+
+// reserve 预留一个令牌，返回需要等待的时间
+func (b *bucket) reserve(now time.Time, config GovernorConfig) time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.config.Rate <= 0 {
+		return 0
+	}
+	b.recover(now, config)
+	rate := b.config.Rate * b.factor
+	b.tokens += now.Sub(b.last).Seconds() * rate
+	if b.tokens > float64(b.burst()) {
+		b.tokens = float64(b.burst())
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / rate * float64(time.Second))
+}
+
+// throttle 被服务器限流，降低速率。同一秒内的多次限流只降低一次
+func (b *bucket) throttle(now time.Time, config GovernorConfig) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.config.Rate <= 0 || now.Sub(b.lastThrottle) < time.Second {
+		return
+	}
+	b.lastThrottle = now
+	b.lastAdjust = now
+	factor := config.BackoffFactor
+	if factor <= 0 || factor >= 1 {
+		factor = 0.5
+	}
+	minFactor := config.MinRateFactor
+	if minFactor <= 0 {
+		minFactor = 0.05
+	}
+	b.factor *= factor
+	if b.factor < minFactor {
+		b.factor = minFactor
+	}
+	// 清空令牌，避免降速后仍然突发请求
+	if b.tokens > 0 {
+		b.tokens = 0
+	}
+}
+
+func (b *bucket) stats(config GovernorConfig) ClassStats {
+	b.mutex.Lock()
+	b.recover(time.Now(), config)
+	rate := b.config.Rate * b.factor
+	b.mutex.Unlock()
+	return ClassStats{
+		Requests:  atomic.LoadInt64(&b.requests),
+		Throttled: atomic.LoadInt64(&b.throttled),
+		Delayed:   atomic.LoadInt64(&b.delayed),
+		WaitTime:  time.Duration(atomic.LoadInt64(&b.waitTime)),
+		InFlight:  atomic.LoadInt64(&b.inFlight),
+		Rate:      rate,
+	}
+}
@@ -0,0 +1,78 @@
+package apitransport
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGovernorAdaptiveRate(t *testing.T) {
+	config := DefaultGovernorConfig()
+	config.Metadata = BucketConfig{Rate: 100, Burst: 1}
+	config.RecoverInterval = 50 * time.Millisecond
+	config.RecoverStep = 0.5
+	g := NewGovernor(config)
+
+	release, err := g.Acquire(context.Background(), RequestClassMetadata)
+	assert.NoError(t, err)
+	release()
+	assert.Equal(t, float64(100), g.Stats().Metadata.Rate)
+
+	// 被限流后速率减半，之后逐步恢复
+	g.Observe(RequestClassMetadata, http.StatusTooManyRequests)
+	stats := g.Stats().Metadata
+	assert.Equal(t, float64(50), stats.Rate)
+	assert.Equal(t, int64(1), stats.Throttled)
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, float64(100), g.Stats().Metadata.Rate)
+
+	// 数据传输使用独立的令牌桶
+	assert.Equal(t, int64(0), g.Stats().Transfer.Requests)
+	assert.Equal(t, int64(1), g.Stats().Metadata.Requests)
+}
+
+func TestGovernorDefaultConfig(t *testing.T) {
+	// 默认不限速，需要通过 WithGovernorConfig 开启
+	assert.Equal(t, float64(0), NewClientOptions().NewGovernor().Config().Metadata.Rate)
+	g := NewClientOptions(WithGovernorConfig(MetadataRateGovernorConfig(10))).NewGovernor()
+	assert.Equal(t, BucketConfig{Rate: 10, Burst: 10}, g.Config().Metadata)
+	assert.Equal(t, float64(10), g.Stats().Metadata.Rate)
+}
+
+func TestGovernorDoHoldsSlotUntilBodyClosed(t *testing.T) {
+	g := NewGovernor(GovernorConfig{Transfer: BucketConfig{MaxConcurrent: 1}})
+	resp, err := g.Do(RequestClassTransfer, func() (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader("data"))}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), g.Stats().Transfer.InFlight)
+
+	// 响应体关闭之前其他请求需要等待
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = g.Acquire(ctx, RequestClassTransfer)
+	assert.Error(t, err)
+
+	data, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, "data", string(data))
+	require.NoError(t, resp.Body.Close())
+	resp.Body.Close()
+	assert.Equal(t, int64(0), g.Stats().Transfer.InFlight)
+	release, err := g.Acquire(context.Background(), RequestClassTransfer)
+	require.NoError(t, err)
+	release()
+
+	// 没有响应时立即释放
+	_, err = g.Do(RequestClassTransfer, func() (*http.Response, error) {
+		return nil, errors.New("failed")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, int64(0), g.Stats().Transfer.InFlight)
+}
@@ -0,0 +1,61 @@
+package apitransport
+
+import (
+	"net/http"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/library-go/requester"
+)
+
+// WrapHTTPClient 在 requester.HTTPClient 原有的传输层外面包装一层。
+// requester.HTTPClient 在第一次请求时才会创建传输层并覆盖 Transport，所以这里需要先完成初始化
+func WrapHTTPClient(client *requester.HTTPClient, wrap func(base http.RoundTripper) http.RoundTripper) {
+	if client == nil || wrap == nil {
+		return
+	}
+	// 触发内部传输层的初始化
+	client.SetKeepAlive(true)
+	client.Client.Transport = wrap(client.Client.Transport)
+}
+
+// InstallRetry 为 requester.HTTPClient 安装重试传输层，返回的 RetryTransport 可以用于修改重试策略
+func InstallRetry(client *requester.HTTPClient, policy *aliyunpan.RetryPolicy) *RetryTransport {
+	var rt *RetryTransport
+	WrapHTTPClient(client, func(base http.RoundTripper) http.RoundTripper {
+		rt = NewRetryTransport(base, policy)
+		return rt
+	})
+	return rt
+}
+
+// InstallGovernor 让 requester.HTTPClient 的所有请求都经过限速器
+func InstallGovernor(client *requester.HTTPClient, governor *Governor) {
+	if governor == nil {
+		return
+	}
+	WrapHTTPClient(client, governor.Transport)
+}
+
+// InstallTransferGovernor 让 requester.HTTPClient 的所有请求都按照数据传输请求经过限速器
+func InstallTransferGovernor(client *requester.HTTPClient, governor *Governor) {
+	if governor == nil {
+		return
+	}
+	WrapHTTPClient(client, governor.TransferTransport)
+}
+
+// InstallObserver 让 requester.HTTPClient 的所有请求都经过观测器，需要在安装重试传输层之后调用
+func InstallObserver(client *requester.HTTPClient, observer *Observer) {
+	if observer == nil {
+		return
+	}
+	WrapHTTPClient(client, observer.Transport)
+}
+
+// InstallTransferObserver 让 requester.HTTPClient 的所有请求都按照数据传输请求经过观测器
+func InstallTransferObserver(client *requester.HTTPClient, observer *Observer) {
+	if observer == nil {
+		return
+	}
+	WrapHTTPClient(client, observer.TransferTransport)
+}
@@ -0,0 +1,332 @@
+package apitransport
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// RequestInfo 单次请求的信息，URL 已经脱敏，不包含请求头（Token）
+	RequestInfo struct {
+		// Method 请求方法
+		Method string
+		// Host 请求的域名
+		Host string
+		// Endpoint 接口路径模板，路径中的ID等会被替换成 {id}，适合作为监控指标的标签
+		Endpoint string
+		// URL 脱敏后的完整URL，签名、Token等参数的值会被替换
+		URL string
+		// Class 请求类别
+		Class RequestClass
+		// StartTime 请求开始时间
+		StartTime time.Time
+		// StatusCode HTTP状态码，请求失败时为0
+		StatusCode int
+		// Latency 请求耗时，包括重试以及读取响应体的时间
+		Latency time.Duration
+		// Retries 重试次数
+		Retries int
+		// BytesSent 发送的请求体字节数
+		BytesSent int64
+		// BytesReceived 接收的响应体字节数
+		BytesReceived int64
+		// RequestId 阿里云盘服务器返回的请求ID（x-request-id）
+		RequestId string
+		// Err 请求错误
+		Err error
+	}
+
+	// Hook 请求观测钩子，可以用于日志、监控指标、链路追踪
+	Hook interface {
+		// OnRequestStart 请求开始，返回的 context 会用于本次请求以及 OnRequestEnd，可以在里面保存链路追踪的 span
+		OnRequestStart(ctx context.Context, info *RequestInfo) context.Context
+		// OnRequestEnd 请求结束，即响应体读取完毕或者关闭，或者请求失败
+		OnRequestEnd(ctx context.Context, info *RequestInfo)
+	}
+
+	// HookFunc 只关心请求结束事件的钩子
+	HookFunc func(ctx context.Context, info *RequestInfo)
+
+	// Observer 请求观测器，把请求信息分发给所有的钩子
+	Observer struct {
+		mutex sync.RWMutex
+		hooks []Hook
+	}
+
+	// observation 一次请求的观测过程
+	observation struct {
+		ctx     context.Context
+		hooks   []Hook
+		info    *RequestInfo
+		retries *int32
+		once    sync.Once
+	}
+
+	// observedBody 统计响应体字节数，读取完毕或者关闭时结束观测
+	observedBody struct {
+		io.ReadCloser
+		ob *observation
+		n  int64
+	}
+
+	// observerTransport 经过观测器的传输层
+	observerTransport struct {
+		base     http.RoundTripper
+		observer *Observer
+		// class 固定的请求类别，小于0则使用 DefaultRequestClassifier 判断
+		class RequestClass
+	}
+
+	// retryCounterKey 在 context 中保存重试次数计数器
+	retryCounterKey struct{}
+)
+
+var (
+	// sensitiveQueryKeys 需要脱敏的URL参数名称（包含即匹配）
+	sensitiveQueryKeys = []string{"sign", "token", "auth", "key", "credential", "security", "secret", "password", "pwd", "code"}
+
+	// idSegmentPattern 路径中可以认为是ID的片段
+	idSegmentPattern = regexp.MustCompile(`^([0-9]+|[0-9a-fA-F]{16,}|[0-9A-Za-z_\-]{32,})$`)
+)
+
+const (
+	// RedactedValue 脱敏后的值
+	RedactedValue = "REDACTED"
+)
+
+// OnRequestStart 实现 Hook
+func (f HookFunc) OnRequestStart(ctx context.Context, info *RequestInfo) context.Context {
+	return ctx
+}
+
+// OnRequestEnd 实现 Hook
+func (f HookFunc) OnRequestEnd(ctx context.Context, info *RequestInfo) {
+	f(ctx, info)
+}
+
+// NewObserver 创建请求观测器
+func NewObserver() *Observer {
+	return &Observer{}
+}
+
+// AddHook 添加钩子
+func (o *Observer) AddHook(hook Hook) {
+	if hook == nil {
+		return
+	}
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.hooks = append(o.hooks, hook)
+}
+
+// ClearHooks 移除所有的钩子
+func (o *Observer) ClearHooks() {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.hooks = nil
+}
+
+func (o *Observer) snapshot() []Hook {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+	return o.hooks
+}
+
+// Transport 返回经过观测器的传输层，应该放在重试传输层的外面，才能统计到重试次数
+func (o *Observer) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &observerTransport{
+		base:     base,
+		observer: o,
+		class:    -1,
+	}
+}
+
+// TransferTransport 返回经过观测器的传输层，所有请求都按照数据传输请求统计
+func (o *Observer) TransferTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &observerTransport{
+		base:     base,
+		observer: o,
+		class:    RequestClassTransfer,
+	}
+}
+
+// Do 观测调用方自己发起的请求（例如上传、下载的回调函数）
+func (o *Observer) Do(class RequestClass, method, rawUrl string, fn func() (*http.Response, error)) (*http.Response, error) {
+	u, e := url.Parse(rawUrl)
+	if e != nil {
+		return fn()
+	}
+	ob := o.begin(context.Background(), method, u, class, 0)
+	if ob == nil {
+		return fn()
+	}
+	resp, err := fn()
+	return ob.end(resp, err), err
+}
+
+func (o *Observer) begin(ctx context.Context, method string, u *url.URL, class RequestClass, bytesSent int64) *observation {
+	hooks := o.snapshot()
+	if len(hooks) == 0 {
+		return nil
+	}
+	if bytesSent < 0 {
+		bytesSent = 0
+	}
+	ob := &observation{
+		hooks:   hooks,
+		retries: new(int32),
+		info: &RequestInfo{
+			Method:    method,
+			Host:      u.Host,
+			Endpoint:  EndpointTemplate(u.Path),
+			URL:       RedactURL(u),
+			Class:     class,
+			StartTime: time.Now(),
+			BytesSent: bytesSent,
+		},
+	}
+	ctx = context.WithValue(ctx, retryCounterKey{}, ob.retries)
+	for _, h := range hooks {
+		ctx = h.OnRequestStart(ctx, ob.info)
+	}
+	ob.ctx = ctx
+	return ob
+}
+
+// end 记录响应，返回的响应体会在读取完毕或者关闭时结束观测
+func (ob *observation) end(resp *http.Response, err error) *http.Response {
+	ob.info.Retries = int(atomic.LoadInt32(ob.retries))
+	if err != nil || resp == nil {
+		ob.info.Err = err
+		ob.finish()
+		return resp
+	}
+	ob.info.StatusCode = resp.StatusCode
+	ob.info.RequestId = resp.Header.Get("x-request-id")
+	if resp.Body == nil {
+		ob.finish()
+		return resp
+	}
+	resp.Body = &observedBody{ReadCloser: resp.Body, ob: ob}
+	return resp
+}
+
+func (ob *observation) finish() {
+	ob.once.Do(func() {
+		ob.info.Latency = time.Since(ob.info.StartTime)
+		for i := len(ob.hooks) - 1; i >= 0; i-- {
+			ob.hooks[i].OnRequestEnd(ob.ctx, ob.info)
+		}
+	})
+}
+
+func (b *observedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	if err != nil {
+		b.ob.info.BytesReceived = b.n
+		if err != io.EOF {
+			b.ob.info.Err = err
+		}
+		b.ob.finish()
+	}
+	return n, err
+}
+
+func (b *observedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.ob.info.BytesReceived = b.n
+	b.ob.finish()
+	return err
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *observerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	class := t.class
+	if class < 0 {
+		class = DefaultRequestClassifier(req)
+	}
+	ob := t.observer.begin(req.Context(), req.Method, req.URL, class, req.ContentLength)
+	if ob == nil {
+		return t.base.RoundTrip(req)
+	}
+	resp, err := t.base.RoundTrip(req.WithContext(ob.ctx))
+	return ob.end(resp, err), err
+}
+
+// countRetry 重试传输层每次重试时调用，累加观测器的重试次数
+func countRetry(ctx context.Context) {
+	if c, ok := ctx.Value(retryCounterKey{}).(*int32); ok {
+		atomic.AddInt32(c, 1)
+	}
+}
+
+// RedactURL 返回脱敏后的URL，签名、Token、密码等参数的值会被替换成 REDACTED
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	r := *u
+	if r.User != nil {
+		r.User = url.User(RedactedValue)
+	}
+	if r.RawQuery != "" {
+		query := r.Query()
+		for key := range query {
+			if isSensitiveKey(key) {
+				query[key] = []string{RedactedValue}
+			}
+		}
+		r.RawQuery = query.Encode()
+	}
+	return r.String()
+}
+
+// RedactURLString 返回脱敏后的URL，无法解析的URL只保留问号之前的部分
+func RedactURLString(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		if i := strings.Index(rawUrl, "?"); i >= 0 {
+			return rawUrl[:i]
+		}
+		return rawUrl
+	}
+	return RedactURL(u)
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range sensitiveQueryKeys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// EndpointTemplate 将请求路径转换成接口模板，路径中的ID片段替换成 {id}
+func EndpointTemplate(urlPath string) string {
+	if urlPath == "" {
+		return "/"
+	}
+	segments := strings.Split(urlPath, "/")
+	for i, s := range segments {
+		if s != "" && idSegmentPattern.MatchString(s) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,49 @@
+package apitransport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/library-go/requester"
+)
+
+func TestObserverHook(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-request-id", "req-1")
+		if atomic.AddInt32(&count, 1) < 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"items":[]}`))
+	}))
+	defer server.Close()
+
+	policy := aliyunpan.DefaultRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	client := requester.NewHTTPClient()
+	InstallRetry(client, policy)
+	observer := NewObserver()
+	InstallObserver(client, observer)
+
+	var info *RequestInfo
+	observer.AddHook(HookFunc(func(ctx context.Context, i *RequestInfo) {
+		info = i
+	}))
+	_, err := client.Fetch("POST", server.URL+"/adrive/v1.0/openFile/5f3b2c1d9e8a7b6c5d4e3f2a/list?x-oss-signature=abc&limit=10", nil, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, info)
+	assert.Equal(t, "/adrive/v1.0/openFile/{id}/list", info.Endpoint)
+	assert.Contains(t, info.URL, "x-oss-signature=REDACTED")
+	assert.Contains(t, info.URL, "limit=10")
+	assert.Equal(t, 200, info.StatusCode)
+	assert.Equal(t, 1, info.Retries)
+	assert.Equal(t, int64(12), info.BytesReceived)
+	assert.Equal(t, "req-1", info.RequestId)
+}
@@ -0,0 +1,146 @@
+package apitransport
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/library-go/logger"
+)
+
+type (
+	// RetryTransport 按照 aliyunpan.RetryPolicy 对失败请求进行重试的传输层
+	RetryTransport struct {
+		base   http.RoundTripper
+		mutex  sync.RWMutex
+		policy *aliyunpan.RetryPolicy
+	}
+)
+
+// NewRetryTransport 创建重试传输层，policy为nil则不重试
+func NewRetryTransport(base http.RoundTripper, policy *aliyunpan.RetryPolicy) *RetryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RetryTransport{
+		base:   base,
+		policy: policy,
+	}
+}
+
+// SetPolicy 设置重试策略，运行中可以随时修改
+func (t *RetryTransport) SetPolicy(policy *aliyunpan.RetryPolicy) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.policy = policy
+}
+
+// Policy 获取当前的重试策略
+func (t *RetryTransport) Policy() *aliyunpan.RetryPolicy {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.policy
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	policy := t.Policy()
+	if policy == nil || policy.MaxAttempts <= 1 {
+		return t.base.RoundTrip(req)
+	}
+	idempotent := policy.IsIdempotent(req.Method, req.URL.Path)
+	// 请求体无法重新读取的请求（例如上传的数据流）不能重试
+	rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	ctx := req.Context()
+	start := time.Now()
+	attempt := 0
+	for {
+		attempt++
+		resp, err := t.base.RoundTrip(req)
+		code := classifyResponse(resp, err)
+		if code == apierror.ApiCodeOk || ctx.Err() != nil || !rewindable ||
+			!policy.ShouldRetry(code, attempt, idempotent) {
+			return resp, err
+		}
+
+		wait := policy.Backoff(attempt)
+		if ra := retryAfter(resp); ra > 0 && ra > wait {
+			wait = ra
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			// 等待时间已经超过请求的超时时间，直接返回本次的结果
+			return resp, err
+		}
+		if !policy.WithinMaxElapsed(time.Since(start), wait) {
+			// 超过重试总时间上限
+			return resp, err
+		}
+		logger.Verboseln("request failed, code=", code, ", retry ", attempt, " after ", wait, ": ", req.URL.Path)
+		countRetry(ctx)
+
+		// 释放本次响应，复用连接
+		if resp != nil {
+			io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, e := req.GetBody()
+			if e != nil {
+				return nil, e
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+// classifyResponse 将传输层的结果归类成错误码，成功或者非传输层错误返回 ApiCodeOk
+func classifyResponse(resp *http.Response, err error) apierror.ApiCode {
+	if err != nil {
+		return apierror.ApiCodeNetError
+	}
+	if resp == nil {
+		return apierror.ApiCodeOk
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return apierror.ApiCodeTooManyRequests
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return apierror.ApiCodeBadGateway
+	}
+	return apierror.ApiCodeOk
+}
+
+// retryAfter 解析服务器要求的等待时间。OpenAPI使用 x-retry-after（毫秒），其他使用标准的 Retry-After（秒）
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	if v := resp.Header.Get("x-retry-after"); v != "" {
+		if num, err := strconv.Atoi(v); err == nil {
+			// 比官方要的延迟时间多1s
+			return time.Duration(num)*time.Millisecond + time.Second
+		}
+	}
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if num, err := strconv.Atoi(v); err == nil {
+			return time.Duration(num) * time.Second
+		}
+	}
+	return 0
+}
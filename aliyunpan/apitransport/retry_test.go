@@ -0,0 +1,83 @@
+package apitransport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/library-go/requester"
+)
+
+func TestRetryTransport(t *testing.T) {
+	var count int32
+	status := http.StatusTooManyRequests
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) < 3 {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	policy := aliyunpan.DefaultRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	client := requester.NewHTTPClient()
+	InstallRetry(client, policy)
+
+	// 429 代表请求没有被处理，非幂等的请求也可以重试
+	body, err := client.Fetch("POST", server.URL+"/adrive/v1.0/openFile/create", `{"name":"a"}`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", string(body))
+	assert.Equal(t, int32(3), count)
+
+	// 502 时非幂等的请求不能重试
+	atomic.StoreInt32(&count, 0)
+	status = http.StatusBadGateway
+	resp, err := client.Req("POST", server.URL+"/adrive/v1.0/openFile/create", strings.NewReader("{}"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, int32(1), count)
+
+	// 幂等的请求可以重试
+	atomic.StoreInt32(&count, 0)
+	resp, err = client.Req("POST", server.URL+"/adrive/v1.0/openFile/list", strings.NewReader("{}"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), count)
+}
+
+func TestRetryTransportMaxElapsed(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	policy := aliyunpan.DefaultRetryPolicy()
+	policy.MaxAttempts = 100
+	policy.BaseDelay = 20 * time.Millisecond
+	policy.MaxDelay = 20 * time.Millisecond
+	policy.MaxElapsed = 50 * time.Millisecond
+	policy.Jitter = 0
+	client := requester.NewHTTPClient()
+	InstallRetry(client, policy)
+
+	// 超过重试总时间上限后不再重试，返回最后一次的结果
+	resp, err := client.Req("POST", server.URL+"/adrive/v1.0/openFile/list", strings.NewReader("{}"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.True(t, count >= 2 && count <= 3, count)
+
+	// 默认策略的重试总时间在默认的请求超时时间内
+	policy = aliyunpan.DefaultRetryPolicy()
+	assert.True(t, policy.MaxElapsed < 30*time.Second)
+	assert.False(t, policy.WithinMaxElapsed(15*time.Second, 8*time.Second))
+	assert.True(t, policy.WithinMaxElapsed(0, 8*time.Second))
+}
@@ -0,0 +1,57 @@
+//go:build go1.21
+// +build go1.21
+
+package apitransport
+
+import (
+	"context"
+	"log/slog"
+)
+
+type (
+	// SlogHook 使用 log/slog 输出结构化请求日志
+	SlogHook struct {
+		logger *slog.Logger
+		level  slog.Level
+	}
+)
+
+// NewSlogHook 创建 slog 日志钩子，logger 为nil则使用 slog.Default()。成功的请求使用 level 级别输出，失败的请求使用 Warn 级别
+func NewSlogHook(logger *slog.Logger, level slog.Level) *SlogHook {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogHook{
+		logger: logger,
+		level:  level,
+	}
+}
+
+// OnRequestStart 实现 Hook
+func (h *SlogHook) OnRequestStart(ctx context.Context, info *RequestInfo) context.Context {
+	return ctx
+}
+
+// OnRequestEnd 实现 Hook
+func (h *SlogHook) OnRequestEnd(ctx context.Context, info *RequestInfo) {
+	level := h.level
+	attrs := []slog.Attr{
+		slog.String("method", info.Method),
+		slog.String("host", info.Host),
+		slog.String("endpoint", info.Endpoint),
+		slog.String("url", info.URL),
+		slog.Int("status", info.StatusCode),
+		slog.Duration("latency", info.Latency),
+		slog.Int("retries", info.Retries),
+		slog.Int64("bytes_sent", info.BytesSent),
+		slog.Int64("bytes_received", info.BytesReceived),
+		slog.String("request_id", info.RequestId),
+	}
+	if info.Err != nil || info.StatusCode >= 400 {
+		level = slog.LevelWarn
+	}
+	if info.Err != nil {
+		attrs = append(attrs, slog.String("error", info.Err.Error()))
+	}
+	h.logger.LogAttrs(ctx, level, "aliyunpan api request", attrs...)
+}
@@ -0,0 +1,180 @@
+package aliyunpan
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+type (
+	// BandwidthLimiter 传输带宽限速器（字节/秒），可以在传输过程中随时修改速率。
+	// 多个传输共用同一个限速器即为全局限速，单个传输使用自己的限速器即为单任务限速
+	BandwidthLimiter struct {
+		mutex   sync.Mutex
+		rate    int64
+		ceiling int64
+		tokens  float64
+		last    time.Time
+	}
+
+	// bandwidthLimitedReader 读取数据时按照限速器等待
+	bandwidthLimitedReader struct {
+		reader   io.Reader
+		limiters []*BandwidthLimiter
+	}
+
+	// bandwidthLimitedReadCloser 读取数据时按照限速器等待，并且可以关闭
+	bandwidthLimitedReadCloser struct {
+		bandwidthLimitedReader
+		closer io.Closer
+	}
+)
+
+const (
+	// minLimitedReadSize 限速时单次读取的最小字节数
+	minLimitedReadSize = 1024
+)
+
+// NewBandwidthLimiter 创建带宽限速器，bytesPerSecond 小于等于0代表不限速
+func NewBandwidthLimiter(bytesPerSecond int64) *BandwidthLimiter {
+	return &BandwidthLimiter{
+		rate: bytesPerSecond,
+		last: time.Now(),
+	}
+}
+
+// SetRate 设置限速（字节/秒），小于等于0代表不限速
+func (l *BandwidthLimiter) SetRate(bytesPerSecond int64) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.rate = bytesPerSecond
+	l.tokens = 0
+}
+
+// SetCeiling 设置速率上限（字节/秒），一般为服务器建议的速率，例如 GetFileDownloadUrlResult.Ratelimit.PartSpeed。小于等于0代表没有上限
+func (l *BandwidthLimiter) SetCeiling(bytesPerSecond int64) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.ceiling = bytesPerSecond
+	l.tokens = 0
+}
+
+// Rate 当前实际生效的速率（字节/秒），0代表不限速
+func (l *BandwidthLimiter) Rate() int64 {
+	if l == nil {
+		return 0
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.effectiveRate()
+}
+
+func (l *BandwidthLimiter) effectiveRate() int64 {
+	rate := l.rate
+	if l.ceiling > 0 && (rate <= 0 || l.ceiling < rate) {
+		rate = l.ceiling
+	}
+	if rate < 0 {
+		rate = 0
+	}
+	return rate
+}
+
+// WaitN 传输了 n 字节数据，按照限速等待
+func (l *BandwidthLimiter) WaitN(n int) {
+	if l == nil || n <= 0 {
+		return
+	}
+	l.mutex.Lock()
+	rate := l.effectiveRate()
+	now := time.Now()
+	if rate <= 0 {
+		l.last = now
+		l.mutex.Unlock()
+		return
+	}
+	// 最多允许积累1秒的流量
+	l.tokens += now.Sub(l.last).Seconds() * float64(rate)
+	if l.tokens > float64(rate) {
+		l.tokens = float64(rate)
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	wait := time.Duration(0)
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / float64(rate) * float64(time.Second))
+	}
+	l.mutex.Unlock()
+
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// readSize 限速时单次读取的字节数，避免一次读取过多数据导致长时间等待、调整速率后不能及时生效
+func readSize(size int, limiters []*BandwidthLimiter) int {
+	for _, l := range limiters {
+		if rate := l.Rate(); rate > 0 {
+			n := int(rate / 10)
+			if n < minLimitedReadSize {
+				n = minLimitedReadSize
+			}
+			if n < size {
+				size = n
+			}
+		}
+	}
+	return size
+}
+
+// NewBandwidthLimitedReader 返回按照限速器读取数据的 io.Reader，nil的限速器会被忽略
+func NewBandwidthLimitedReader(reader io.Reader, limiters ...*BandwidthLimiter) io.Reader {
+	ls := compactLimiters(limiters)
+	if len(ls) == 0 {
+		return reader
+	}
+	return &bandwidthLimitedReader{
+		reader:   reader,
+		limiters: ls,
+	}
+}
+
+// NewBandwidthLimitedReadCloser 返回按照限速器读取数据的 io.ReadCloser，一般用于包装下载的响应体
+func NewBandwidthLimitedReadCloser(reader io.ReadCloser, limiters ...*BandwidthLimiter) io.ReadCloser {
+	ls := compactLimiters(limiters)
+	if len(ls) == 0 {
+		return reader
+	}
+	return &bandwidthLimitedReadCloser{
+		bandwidthLimitedReader: bandwidthLimitedReader{
+			reader:   reader,
+			limiters: ls,
+		},
+		closer: reader,
+	}
+}
+
+func compactLimiters(limiters []*BandwidthLimiter) []*BandwidthLimiter {
+	var ls []*BandwidthLimiter
+	for _, l := range limiters {
+		if l != nil {
+			ls = append(ls, l)
+		}
+	}
+	return ls
+}
+
+func (r *bandwidthLimitedReader) Read(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return r.reader.Read(p)
+	}
+	n, err = r.reader.Read(p[:readSize(len(p), r.limiters)])
+	for _, l := range r.limiters {
+		l.WaitN(n)
+	}
+	return n, err
+}
+
+func (r *bandwidthLimitedReadCloser) Close() error {
+	return r.closer.Close()
+}
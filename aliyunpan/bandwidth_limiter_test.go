@@ -0,0 +1,131 @@
+package aliyunpan
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAll 通过限速器读取 size 字节，返回耗时
+func readAll(t *testing.T, size int, limiters ...*BandwidthLimiter) time.Duration {
+	start := time.Now()
+	n, err := io.Copy(ioutil.Discard, NewBandwidthLimitedReader(bytes.NewReader(make([]byte, size)), limiters...))
+	require.Nil(t, err)
+	require.Equal(t, int64(size), n)
+	return time.Since(start)
+}
+
+func TestBandwidthLimiterRate(t *testing.T) {
+	l := NewBandwidthLimiter(1024 * 1024)
+	elapsed := readAll(t, 300*1024, l)
+	assert.True(t, elapsed >= 250*time.Millisecond, elapsed)
+	assert.True(t, elapsed < time.Second, elapsed)
+
+	// nil 和不限速的限速器不等待
+	assert.True(t, readAll(t, 10*1024*1024, nil, NewBandwidthLimiter(0)) < 200*time.Millisecond)
+}
+
+func TestBandwidthLimiterSetRate(t *testing.T) {
+	l := NewBandwidthLimiter(200 * 1024)
+	elapsed := readAll(t, 40*1024, l)
+	assert.True(t, elapsed >= 150*time.Millisecond, elapsed)
+
+	// 传输过程中修改速率，下一次读取立即生效
+	done := make(chan time.Duration)
+	go func() {
+		done <- readAll(t, 2*1024*1024, l)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	l.SetRate(0)
+	select {
+	case elapsed = <-done:
+		// 200KB/s 读取2MB需要10秒
+		assert.True(t, elapsed < 2*time.Second, elapsed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("rate change did not take effect")
+	}
+}
+
+func TestBandwidthLimiterCeiling(t *testing.T) {
+	l := NewBandwidthLimiter(0)
+	assert.Equal(t, int64(0), l.Rate())
+	l.SetCeiling(100)
+	assert.Equal(t, int64(100), l.Rate())
+	l.SetRate(50)
+	assert.Equal(t, int64(50), l.Rate())
+	l.SetRate(500)
+	assert.Equal(t, int64(100), l.Rate())
+	l.SetCeiling(0)
+	assert.Equal(t, int64(500), l.Rate())
+	assert.Equal(t, int64(0), (*BandwidthLimiter)(nil).Rate())
+
+	// 服务器建议的速率作为单任务限速的上限
+	r := &GetFileDownloadUrlResult{}
+	r.Ratelimit.PartSpeed = 1024
+	assert.Equal(t, int64(1024), r.NewTransferLimiter(0).Rate())
+	assert.Equal(t, int64(512), r.NewTransferLimiter(512).Rate())
+	assert.Equal(t, int64(2048), (&GetFileDownloadUrlResult{}).NewTransferLimiter(2048).Rate())
+}
+
+func TestBandwidthLimitedReadSize(t *testing.T) {
+	// 限速时单次读取不超过速率的 1/10，调整速率能及时生效
+	l := NewBandwidthLimiter(100 * 1024)
+	r := NewBandwidthLimitedReadCloser(ioutil.NopCloser(bytes.NewReader(make([]byte, 64*1024))), l)
+	buf := make([]byte, 64*1024)
+	n, err := r.Read(buf)
+	require.Nil(t, err)
+	assert.Equal(t, 10*1024, n)
+	assert.Nil(t, r.Close())
+
+	l.SetRate(1)
+	assert.Equal(t, minLimitedReadSize, readSize(len(buf), []*BandwidthLimiter{l}))
+	assert.Equal(t, len(buf), readSize(len(buf), []*BandwidthLimiter{NewBandwidthLimiter(0)}))
+}
+
+func TestFileUploadChunkDataLimiter(t *testing.T) {
+	l := NewBandwidthLimiter(1024 * 1024)
+	data := &FileUploadChunkData{
+		Reader:    bytes.NewReader(make([]byte, 512*1024)),
+		ChunkSize: 300 * 1024,
+		Limiters:  []*BandwidthLimiter{l},
+	}
+	start := time.Now()
+	n, err := io.Copy(ioutil.Discard, io.LimitReader(data, data.Len()))
+	require.Nil(t, err)
+	assert.Equal(t, int64(300*1024), n)
+	elapsed := time.Since(start)
+	assert.True(t, elapsed >= 250*time.Millisecond, elapsed)
+}
+
+func TestDownloadRatelimits(t *testing.T) {
+	d := NewDownloadRatelimits()
+	r := &GetFileDownloadUrlResult{Url: "u1", CdnUrl: "c1"}
+	r.Ratelimit.PartSpeed = 1024
+	r.Ratelimit.PartSize = 10 * 1024 * 1024
+	d.Record(r)
+	assert.Equal(t, int64(1024), d.NewConnLimiter("u1").Rate())
+	assert.Equal(t, int64(1024), d.NewConnLimiter("c1").Rate())
+	// 每个连接使用独立的限速器
+	assert.NotSame(t, d.NewConnLimiter("u1"), d.NewConnLimiter("u1"))
+	assert.Nil(t, d.NewConnLimiter("u2"))
+
+	// 服务器返回 -1 代表不限速
+	unlimited := &GetFileDownloadUrlResult{Url: "u1"}
+	unlimited.Ratelimit.PartSpeed = -1
+	unlimited.Ratelimit.PartSize = -1
+	d.Record(unlimited)
+	assert.Nil(t, d.NewConnLimiter("u1"))
+	assert.NotNil(t, d.NewConnLimiter("c1"))
+
+	// 过期的链接不再限速
+	expired := &GetFileDownloadUrlResult{Url: "u3", Expiration: time.Now().Add(-time.Minute).Format("2006-01-02 15:04:05")}
+	expired.Ratelimit.PartSpeed = 1024
+	d.Record(expired)
+	assert.Nil(t, d.NewConnLimiter("u3"))
+	assert.Nil(t, (*DownloadRatelimits)(nil).NewConnLimiter("u1"))
+}
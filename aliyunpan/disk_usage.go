@@ -0,0 +1,293 @@
+package aliyunpan
+
+import (
+	"container/heap"
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+type (
+	// DiskUsageParam 统计目录占用空间参数
+	DiskUsageParam struct {
+		// DriveId 网盘ID
+		DriveId string `json:"drive_id"`
+		// Path 需要统计的目录绝对路径，为空代表整个网盘
+		Path string `json:"path"`
+		// MaxDepth 只返回不超过该深度的目录统计，统计目录本身深度为0，小于等于0代表不限制。更深的目录仍然会计入上层目录
+		MaxDepth int `json:"max_depth"`
+		// TopN 返回最大的N个文件，小于等于0代表不返回
+		TopN int `json:"top_n"`
+		// WithQuota 同时获取网盘空间总大小和已使用大小
+		WithQuota bool `json:"with_quota"`
+		// NoCache 不使用客户端的元数据缓存，每个目录都重新获取文件列表。
+		// 默认在元数据缓存有效期内直接使用缓存的目录列表，有效期内的修改不会被统计到
+		NoCache bool `json:"no_cache"`
+	}
+
+	// DiskUsageEntry 单个目录的统计结果，大小和数量都包含所有子孙目录
+	DiskUsageEntry struct {
+		// FileId 目录ID
+		FileId string `json:"file_id"`
+		// Path 目录完整路径
+		Path string `json:"path"`
+		// Depth 相对统计目录的深度
+		Depth int `json:"depth"`
+		// Size 文件总大小
+		Size int64 `json:"size"`
+		// FileCount 文件数量
+		FileCount int64 `json:"file_count"`
+		// FolderCount 文件夹数量，不包括目录本身
+		FolderCount int64 `json:"folder_count"`
+	}
+
+	// DiskUsageStat 按分类或者后缀名统计的结果
+	DiskUsageStat struct {
+		// Size 文件总大小
+		Size int64 `json:"size"`
+		// FileCount 文件数量
+		FileCount int64 `json:"file_count"`
+	}
+
+	// DiskUsageResult 统计目录占用空间结果，可以通过 SaveDiskUsageResult 保存，下次运行时 LoadDiskUsageResult 重新加载
+	DiskUsageResult struct {
+		// DriveId 网盘ID
+		DriveId string `json:"drive_id"`
+		// ScannedAt 统计完成的时间，格式为 2006-01-02 15:04:05
+		ScannedAt string `json:"scanned_at"`
+		// Root 统计目录本身的结果
+		Root *DiskUsageEntry `json:"root"`
+		// Entries 不超过 MaxDepth 的所有目录的统计结果，和 handler 的返回顺序一致
+		Entries []*DiskUsageEntry `json:"entries"`
+		// Completed 是否完整统计，handler 中途停止时为false
+		Completed bool `json:"completed"`
+		// Categories 按文件分类统计，例如：image/video/doc/others
+		Categories map[string]*DiskUsageStat `json:"categories"`
+		// Extensions 按小写的文件后缀名统计，没有后缀名的文件记为空字符串
+		Extensions map[string]*DiskUsageStat `json:"extensions"`
+		// TopFiles 最大的文件，按大小降序排列
+		TopFiles FileList `json:"top_files"`
+		// QuotaTotalSize 网盘空间总大小，和 UserInfo.TotalSize 一致，开启 WithQuota 才有
+		QuotaTotalSize uint64 `json:"quota_total_size"`
+		// QuotaUsedSize 网盘已使用空间大小，和 UserInfo.UsedSize 一致，开启 WithQuota 才有
+		QuotaUsedSize uint64 `json:"quota_used_size"`
+	}
+
+	// DiskUsageFunc 处理单个目录的统计结果，子目录总是在父目录之前返回，返回false停止统计
+	DiskUsageFunc func(entry *DiskUsageEntry) bool
+
+	// DiskUsageOperator 统计目录占用空间需要的网盘操作
+	DiskUsageOperator interface {
+		ResolveDrivePath(driveId, pathStr string) (string, string, *apierror.ApiError)
+		FileInfoByPath(driveId string, pathStr string) (*FileEntity, *apierror.ApiError)
+		// FileListGetAll 获取目录下的所有文件，DiskUsageParam.NoCache 为 true 时不能使用缓存
+		FileListGetAll(param *FileListParam, delayMilliseconds int) (FileList, *apierror.ApiError)
+		GetUserInfo() (*UserInfo, *apierror.ApiError)
+	}
+
+	diskUsageWalker struct {
+		op      DiskUsageOperator
+		param   *DiskUsageParam
+		handler DiskUsageFunc
+		result  *DiskUsageResult
+		top     fileSizeHeap
+		stopped bool
+	}
+
+	// fileSizeHeap 按文件大小排序的小顶堆，用于保留最大的N个文件
+	fileSizeHeap []*FileEntity
+)
+
+// UsedPercent 已使用空间占比，没有获取网盘空间时返回0
+func (r *DiskUsageResult) UsedPercent() float64 {
+	if r.QuotaTotalSize == 0 {
+		return 0
+	}
+	return float64(r.QuotaUsedSize) * 100 / float64(r.QuotaTotalSize)
+}
+
+// Entry 获取指定路径的目录统计结果，没有统计到该目录时返回 nil
+func (r *DiskUsageResult) Entry(pathStr string) *DiskUsageEntry {
+	pathStr = path.Clean(PathSeparator + pathStr)
+	for _, e := range r.Entries {
+		if e.Path == pathStr {
+			return e
+		}
+	}
+	return nil
+}
+
+// SaveDiskUsageResult 把统计结果保存到本地文件
+func SaveDiskUsageResult(filePath string, r *DiskUsageResult) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filePath, data, 0644)
+}
+
+// LoadDiskUsageResult 加载 SaveDiskUsageResult 保存的统计结果
+func LoadDiskUsageResult(filePath string) (*DiskUsageResult, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	r := &DiskUsageResult{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// DiskUsage 递归统计目录的占用空间、文件数量和文件夹数量。每个目录统计完成后通过 handler 返回，不需要等待整个目录树遍历结束。
+// 目录列表通过 FileListGetAll 获取，客户端设置了元数据缓存（SetMetaCache）时在缓存有效期内会使用缓存，需要最新的结果时设置 NoCache。
+// 开启 WithQuota 时结果中同时包含 GetUserInfo 返回的网盘空间总大小和已使用大小
+func DiskUsage(op DiskUsageOperator, param *DiskUsageParam, handler DiskUsageFunc) (*DiskUsageResult, *apierror.ApiError) {
+	if param == nil {
+		return nil, apierror.NewFailedApiError("参数不能为空")
+	}
+	driveId, rootPath, err := op.ResolveDrivePath(param.DriveId, param.Path)
+	if err != nil {
+		return nil, err
+	}
+	if rootPath == "" {
+		rootPath = PathSeparator
+	}
+	internalParam := *param
+	internalParam.DriveId = driveId
+	internalParam.Path = rootPath
+	param = &internalParam
+	folder, err := op.FileInfoByPath(param.DriveId, rootPath)
+	if err != nil {
+		return nil, err
+	}
+	if !folder.IsFolder() {
+		return nil, apierror.NewFailedApiError("不是文件夹：" + rootPath)
+	}
+	folder.Path = path.Clean(rootPath)
+
+	w := &diskUsageWalker{
+		op:      op,
+		param:   param,
+		handler: handler,
+		result: &DiskUsageResult{
+			DriveId:    param.DriveId,
+			Entries:    []*DiskUsageEntry{},
+			Categories: map[string]*DiskUsageStat{},
+			Extensions: map[string]*DiskUsageStat{},
+		},
+	}
+	root, err := w.walk(folder, 0)
+	if err != nil {
+		return nil, err
+	}
+	w.result.Root = root
+	w.result.Completed = !w.stopped
+	w.result.ScannedAt = time.Now().Format("2006-01-02 15:04:05")
+	for w.top.Len() > 0 {
+		w.result.TopFiles = append(w.result.TopFiles, heap.Pop(&w.top).(*FileEntity))
+	}
+	sort.SliceStable(w.result.TopFiles, func(i, j int) bool {
+		return w.result.TopFiles[i].FileSize > w.result.TopFiles[j].FileSize
+	})
+	if param.WithQuota {
+		userInfo, err := op.GetUserInfo()
+		if err != nil {
+			return nil, err
+		}
+		w.result.QuotaTotalSize = userInfo.TotalSize
+		w.result.QuotaUsedSize = userInfo.UsedSize
+	}
+	return w.result, nil
+}
+
+func (w *diskUsageWalker) walk(folder *FileEntity, depth int) (*DiskUsageEntry, *apierror.ApiError) {
+	entry := &DiskUsageEntry{
+		FileId: folder.FileId,
+		Path:   folder.Path,
+		Depth:  depth,
+	}
+	fileList, err := w.op.FileListGetAll(&FileListParam{DriveId: w.param.DriveId, ParentFileId: folder.FileId}, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fileList {
+		if w.stopped {
+			return entry, nil
+		}
+		f.Path = path.Join(folder.Path, f.FileName)
+		if f.IsFolder() {
+			child, err := w.walk(f, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			entry.Size += child.Size
+			entry.FileCount += child.FileCount
+			entry.FolderCount += child.FolderCount + 1
+			continue
+		}
+		entry.Size += f.FileSize
+		entry.FileCount++
+		w.addFile(f)
+	}
+	if w.stopped {
+		return entry, nil
+	}
+	if w.param.MaxDepth <= 0 || depth <= w.param.MaxDepth {
+		w.result.Entries = append(w.result.Entries, entry)
+		if w.handler != nil && !w.handler(entry) {
+			w.stopped = true
+		}
+	}
+	return entry, nil
+}
+
+func (w *diskUsageWalker) addFile(f *FileEntity) {
+	category := f.Category
+	if category == "" {
+		category = "others"
+	}
+	addDiskUsageStat(w.result.Categories, category, f.FileSize)
+	addDiskUsageStat(w.result.Extensions, strings.ToLower(f.FileExtension), f.FileSize)
+
+	if w.param.TopN <= 0 {
+		return
+	}
+	if w.top.Len() < w.param.TopN {
+		heap.Push(&w.top, f)
+	} else if w.top[0].FileSize < f.FileSize {
+		w.top[0] = f
+		heap.Fix(&w.top, 0)
+	}
+}
+
+func addDiskUsageStat(stats map[string]*DiskUsageStat, key string, size int64) {
+	s, ok := stats[key]
+	if !ok {
+		s = &DiskUsageStat{}
+		stats[key] = s
+	}
+	s.Size += size
+	s.FileCount++
+}
+
+func (h fileSizeHeap) Len() int           { return len(h) }
+func (h fileSizeHeap) Less(i, j int) bool { return h[i].FileSize < h[j].FileSize }
+func (h fileSizeHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *fileSizeHeap) Push(x interface{}) {
+	*h = append(*h, x.(*FileEntity))
+}
+
+func (h *fileSizeHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
@@ -0,0 +1,77 @@
+package aliyunpan
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+// diskUsageStub 在 stubDrive 上增加路径解析和网盘空间
+type diskUsageStub struct {
+	*stubDrive
+}
+
+func (s diskUsageStub) ResolveDrivePath(driveId, pathStr string) (string, string, *apierror.ApiError) {
+	return driveId, pathStr, nil
+}
+
+func (s diskUsageStub) GetUserInfo() (*UserInfo, *apierror.ApiError) {
+	return &UserInfo{TotalSize: 1000, UsedSize: 250}, nil
+}
+
+func newDiskUsageStub() diskUsageStub {
+	s := newStubDrive()
+	s.add("movies", DefaultRootParentFileId, "movies", 0, "", "")
+	s.add("hd", "movies", "hd", 0, "", "")
+	s.add("a", "hd", "a.mkv", 100, "A", "").FileExtension = "mkv"
+	s.add("b", "movies", "b.mp4", 50, "B", "")
+	s.add("x", DefaultRootParentFileId, "x.txt", 1, "X", "")
+	return diskUsageStub{s}
+}
+
+func TestDiskUsageEntries(t *testing.T) {
+	s := newDiskUsageStub()
+	handled := 0
+	result, err := DiskUsage(s, &DiskUsageParam{DriveId: "d", MaxDepth: 1, WithQuota: true}, func(entry *DiskUsageEntry) bool {
+		handled++
+		return true
+	})
+	require.Nil(t, err)
+	assert.True(t, result.Completed)
+	assert.Equal(t, "d", result.DriveId)
+	assert.NotEmpty(t, result.ScannedAt)
+	assert.Equal(t, int64(151), result.Root.Size)
+	assert.Equal(t, int64(2), result.Root.FolderCount)
+	assert.Equal(t, uint64(250), result.QuotaUsedSize)
+	assert.Equal(t, 25.0, result.UsedPercent())
+
+	// 超过深度的 /movies/hd 不记录，但是计入上层目录
+	require.Len(t, result.Entries, 2)
+	assert.Equal(t, handled, len(result.Entries))
+	assert.Nil(t, result.Entry("/movies/hd"))
+	assert.Equal(t, int64(150), result.Entry("movies").Size)
+	assert.Equal(t, result.Root, result.Entry("/"))
+}
+
+func TestDiskUsageSaveLoad(t *testing.T) {
+	s := newDiskUsageStub()
+	result, err := DiskUsage(s, &DiskUsageParam{DriveId: "d", Path: "/movies", TopN: 1}, nil)
+	require.Nil(t, err)
+
+	filePath := filepath.Join(t.TempDir(), "usage.json")
+	require.NoError(t, SaveDiskUsageResult(filePath, result))
+	loaded, e := LoadDiskUsageResult(filePath)
+	require.NoError(t, e)
+	assert.Equal(t, result.ScannedAt, loaded.ScannedAt)
+	assert.Equal(t, result.Root, loaded.Root)
+	assert.Equal(t, int64(100), loaded.Entry("/movies/hd").Size)
+	assert.Equal(t, int64(100), loaded.Extensions["mkv"].Size)
+	require.Len(t, loaded.TopFiles, 1)
+	assert.Equal(t, "/movies/hd/a.mkv", loaded.TopFiles[0].Path)
+
+	_, e = LoadDiskUsageResult(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, e)
+}
@@ -0,0 +1,131 @@
+package aliyunpan
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+const (
+	// DriveAliasBackup 备份盘
+	DriveAliasBackup = "backup"
+	// DriveAliasResource 资源库
+	DriveAliasResource = "resource"
+	// DriveAliasAlbum 相册
+	DriveAliasAlbum = "album"
+	// DriveAliasSafeBox 保险箱
+	DriveAliasSafeBox = "safebox"
+
+	// DrivePathSeparator 网盘别名和路径的分隔符，例如 resource:/Movies/x.mkv
+	DrivePathSeparator = ":"
+)
+
+type (
+	// DriveRegistry 网盘别名注册表，把 backup、resource 等别名映射到网盘ID
+	DriveRegistry struct {
+		mutex  sync.RWMutex
+		drives map[string]string
+		loaded bool
+	}
+)
+
+// NewDriveRegistry 创建网盘别名注册表
+func NewDriveRegistry() *DriveRegistry {
+	return &DriveRegistry{
+		drives: map[string]string{},
+	}
+}
+
+// LoadFromUserInfo 从用户信息加载网盘别名，网盘ID为空的别名不会注册
+func (r *DriveRegistry) LoadFromUserInfo(userInfo *UserInfo) {
+	if userInfo == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for alias, driveId := range map[string]string{
+		DriveAliasBackup:   userInfo.FileDriveId,
+		DriveAliasResource: userInfo.ResourceDriveId,
+		DriveAliasAlbum:    userInfo.AlbumDriveId,
+		DriveAliasSafeBox:  userInfo.SafeBoxDriveId,
+	} {
+		if driveId != "" {
+			r.drives[alias] = driveId
+		}
+	}
+	r.loaded = true
+}
+
+// Loaded 是否已经从用户信息加载过
+func (r *DriveRegistry) Loaded() bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.loaded
+}
+
+// Register 注册网盘别名，别名不区分大小写
+func (r *DriveRegistry) Register(alias, driveId string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.drives[strings.ToLower(alias)] = driveId
+}
+
+// Resolve 返回别名对应的网盘ID
+func (r *DriveRegistry) Resolve(alias string) (string, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	driveId, ok := r.drives[strings.ToLower(alias)]
+	return driveId, ok
+}
+
+// Aliases 返回所有的别名和网盘ID
+func (r *DriveRegistry) Aliases() map[string]string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	m := make(map[string]string, len(r.drives))
+	for k, v := range r.drives {
+		m[k] = v
+	}
+	return m
+}
+
+// ResolvePath 解析带别名的路径，例如 resource:/Movies/x.mkv 返回资源库网盘ID和 /Movies/x.mkv。
+// 不带别名的路径原样返回 driveId 和 pathStr
+func (r *DriveRegistry) ResolvePath(driveId, pathStr string) (string, string, *apierror.ApiError) {
+	alias, p, ok := ParseQualifiedPath(pathStr)
+	if !ok {
+		return driveId, pathStr, nil
+	}
+	if id, found := r.Resolve(alias); found {
+		return id, p, nil
+	}
+	return "", "", apierror.NewFailedApiError("未知的网盘别名：" + alias)
+}
+
+// ParseQualifiedPath 解析带别名的路径，返回别名和网盘内的绝对路径。
+// 只有 "别名:/路径" 或者 "别名:" 格式才会被识别，别名中不能包含路径分隔符
+func ParseQualifiedPath(pathStr string) (alias, p string, ok bool) {
+	idx := strings.Index(pathStr, DrivePathSeparator)
+	if idx <= 0 {
+		return "", pathStr, false
+	}
+	alias = pathStr[:idx]
+	if strings.ContainsAny(alias, "/\\"+ShellPatternCharacters) {
+		return "", pathStr, false
+	}
+	p = pathStr[idx+1:]
+	if p == "" {
+		p = PathSeparator
+	}
+	if !strings.HasPrefix(p, PathSeparator) {
+		return "", pathStr, false
+	}
+	return alias, p, true
+}
+
+// IsQualifiedPath 是否是带别名的路径
+func IsQualifiedPath(pathStr string) bool {
+	_, _, ok := ParseQualifiedPath(pathStr)
+	return ok
+}
@@ -0,0 +1,91 @@
+package aliyunpan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriveRegistryLoadFromUserInfo(t *testing.T) {
+	r := NewDriveRegistry()
+	r.LoadFromUserInfo(nil)
+	assert.False(t, r.Loaded())
+
+	r.LoadFromUserInfo(&UserInfo{FileDriveId: "1", ResourceDriveId: "2"})
+	assert.True(t, r.Loaded())
+	// 网盘ID为空的别名不会注册
+	assert.Equal(t, map[string]string{DriveAliasBackup: "1", DriveAliasResource: "2"}, r.Aliases())
+	_, ok := r.Resolve(DriveAliasAlbum)
+	assert.False(t, ok)
+
+	// 返回的是副本
+	r.Aliases()[DriveAliasSafeBox] = "3"
+	_, ok = r.Resolve(DriveAliasSafeBox)
+	assert.False(t, ok)
+}
+
+func TestDriveRegistryRegister(t *testing.T) {
+	r := NewDriveRegistry()
+	r.Register("Work", "9")
+	id, ok := r.Resolve("WORK")
+	assert.True(t, ok)
+	assert.Equal(t, "9", id)
+	assert.Equal(t, map[string]string{"work": "9"}, r.Aliases())
+
+	r.Register("work", "10")
+	id, _ = r.Resolve("Work")
+	assert.Equal(t, "10", id)
+}
+
+func TestDriveRegistryResolvePath(t *testing.T) {
+	r := NewDriveRegistry()
+	r.LoadFromUserInfo(&UserInfo{FileDriveId: "1", ResourceDriveId: "2"})
+
+	driveId, p, err := r.ResolvePath("1", "Resource:/Movies/x.mkv")
+	require.Nil(t, err)
+	assert.Equal(t, "2", driveId)
+	assert.Equal(t, "/Movies/x.mkv", p)
+
+	driveId, p, err = r.ResolvePath("1", "resource:")
+	require.Nil(t, err)
+	assert.Equal(t, "2", driveId)
+	assert.Equal(t, PathSeparator, p)
+
+	// 不带别名的路径原样返回
+	driveId, p, err = r.ResolvePath("1", "/a:b/c")
+	require.Nil(t, err)
+	assert.Equal(t, "1", driveId)
+	assert.Equal(t, "/a:b/c", p)
+
+	_, _, err = r.ResolvePath("1", "safebox:/x")
+	assert.NotNil(t, err)
+}
+
+func TestParseQualifiedPath(t *testing.T) {
+	testCases := []struct {
+		pathStr string
+		alias   string
+		p       string
+		ok      bool
+	}{
+		{"resource:/Movies", "resource", "/Movies", true},
+		{"backup:", "backup", "/", true},
+		{"backup:/a:b", "backup", "/a:b", true},
+		{"/Movies", "", "/Movies", false},
+		{":/Movies", "", ":/Movies", false},
+		// 冒号后不是绝对路径，视为普通文件名
+		{"a:b", "", "a:b", false},
+		{"/dir/a:/b", "", "/dir/a:/b", false},
+		{"C:\\dir", "", "C:\\dir", false},
+		{"*:/x", "", "*:/x", false},
+		{"", "", "", false},
+	}
+	for _, tc := range testCases {
+		alias, p, ok := ParseQualifiedPath(tc.pathStr)
+		assert.Equal(t, tc.ok, ok, tc.pathStr)
+		assert.Equal(t, tc.alias, alias, tc.pathStr)
+		assert.Equal(t, tc.p, p, tc.pathStr)
+		assert.Equal(t, tc.ok, IsQualifiedPath(tc.pathStr), tc.pathStr)
+	}
+}
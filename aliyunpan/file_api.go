@@ -43,9 +43,65 @@ const (
 
 	// PathSeparator 路径分隔符
 	PathSeparator = "/"
+
+	// FileConflictAutoRename 目标目录存在同名文件时自动重命名
+	FileConflictAutoRename FileConflictMode = "auto_rename"
+	// FileConflictSkip 目标目录存在同名文件时跳过
+	FileConflictSkip FileConflictMode = "skip"
+	// FileConflictOverwrite 目标目录存在同名文件时先把已存在的文件放入回收站，再执行操作
+	FileConflictOverwrite FileConflictMode = "overwrite"
+
+	// AsyncTaskStateSucceed 异步任务成功
+	AsyncTaskStateSucceed = "Succeed"
+	// AsyncTaskStateRunning 异步任务处理中
+	AsyncTaskStateRunning = "Running"
+	// AsyncTaskStateFailed 异步任务已失败
+	AsyncTaskStateFailed = "Failed"
+
+	// AsyncTaskPollInterval 查询异步任务状态的间隔
+	AsyncTaskPollInterval = 1 * time.Second
+	// AsyncTaskWaitTimeout 等待异步任务完成的最长时间
+	AsyncTaskWaitTimeout = 30 * time.Minute
 )
 
 type (
+	// FileConflictMode 同名文件冲突处理方式
+	FileConflictMode string
+
+	// FileCrossDriveParam 跨网盘复制、移动文件参数
+	FileCrossDriveParam struct {
+		// FromDriveId 源网盘ID
+		FromDriveId string `json:"from_drive_id"`
+		// FromFileIds 源文件ID列表
+		FromFileIds []string `json:"from_file_ids"`
+		// ToDriveId 目标网盘ID，必须和源网盘ID不一样
+		ToDriveId string `json:"to_drive_id"`
+		// ToParentFileId 目标目录ID、根目录为 root
+		ToParentFileId string `json:"to_parent_file_id"`
+		// ConflictMode 同名文件冲突处理方式，默认自动重命名
+		ConflictMode FileConflictMode `json:"conflict_mode"`
+	}
+
+	// FileCrossDriveResult 跨网盘复制、移动单个文件的结果
+	FileCrossDriveResult struct {
+		// SourceDriveId 源网盘ID
+		SourceDriveId string `json:"source_drive_id"`
+		// SourceFileId 源文件ID
+		SourceFileId string `json:"source_file_id"`
+		// FileName 源文件名称
+		FileName string `json:"file_name"`
+		// DriveId 目标网盘ID
+		DriveId string `json:"drive_id"`
+		// FileId 目标文件ID
+		FileId string `json:"file_id"`
+		// Success 是否成功
+		Success bool `json:"success"`
+		// Skipped 是否因为同名文件冲突而跳过
+		Skipped bool `json:"skipped"`
+		// Err 失败原因
+		Err *apierror.ApiError `json:"-"`
+	}
+
 	// FileCopyParam 文件复制参数
 	FileCopyParam struct {
 		// DriveId 网盘id
@@ -135,6 +191,10 @@ type (
 		ToDriveId string `json:"to_drive_id"`
 		// 目标文件夹ID
 		ToParentFileId string `json:"to_parent_file_id"`
+		// CheckNameMode 同名处理方式，auto_rename-自动重命名 refuse-同名不移动，为空使用服务器默认值
+		CheckNameMode string `json:"check_name_mode,omitempty"`
+		// NewName 移动后的新文件名，为空保持原文件名
+		NewName string `json:"new_name,omitempty"`
 	}
 
 	// FileMoveResult 文件移动返回值
@@ -169,18 +229,22 @@ type (
 					TsPreCount   int `json:"ts_pre_count"`
 				} `json:"live_transcoding_meta"`
 			} `json:"meta"`
-			LiveTranscodingTaskList []struct {
-				TemplateId     string `json:"template_id"`
-				TemplateName   string `json:"template_name"`
-				TemplateWidth  int    `json:"template_width"`
-				TemplateHeight int    `json:"template_height"`
-				Status         string `json:"status"`
-				Stage          string `json:"stage"`
-				URL            string `json:"url"`
-			} `json:"live_transcoding_task_list"`
+			LiveTranscodingTaskList []VideoTranscodingTask `json:"live_transcoding_task_list"`
 		} `json:"video_preview_play_info"`
 	}
 
+	// VideoTranscodingTask 视频转码任务
+	VideoTranscodingTask struct {
+		TemplateId     string `json:"template_id"`
+		TemplateName   string `json:"template_name"`
+		TemplateWidth  int    `json:"template_width"`
+		TemplateHeight int    `json:"template_height"`
+		// Status 状态，finished-转码完成，running-正在转码，failed-转码失败
+		Status string `json:"status"`
+		Stage  string `json:"stage"`
+		URL    string `json:"url"`
+	}
+
 	// MkdirResult 创建文件夹返回值
 	MkdirResult struct {
 		ParentFileId string `json:"parent_file_id"`
@@ -0,0 +1,55 @@
+package aliyunpan
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+type (
+	// FilePathOpResult 按路径移动、复制、重命名单个文件的结果
+	FilePathOpResult struct {
+		// SourcePath 源文件路径
+		SourcePath string `json:"source_path"`
+		// TargetPath 目标文件路径，自动重命名时为重命名后的路径
+		TargetPath string `json:"target_path"`
+		// FileId 目标文件ID
+		FileId string `json:"file_id"`
+		// Success 是否成功
+		Success bool `json:"success"`
+		// Skipped 是否因为同名文件冲突而跳过
+		Skipped bool `json:"skipped"`
+		// Err 失败原因
+		Err *apierror.ApiError `json:"-"`
+	}
+)
+
+// IsValid 是否是支持的冲突处理方式
+func (m FileConflictMode) IsValid() bool {
+	switch m {
+	case FileConflictAutoRename, FileConflictSkip, FileConflictOverwrite:
+		return true
+	}
+	return false
+}
+
+// UniqueFileName 生成不冲突的文件名，和网盘自动重命名的规则一致，例如 a.txt 冲突时返回 a(1).txt
+func UniqueFileName(name string, exists func(name string) bool) string {
+	if !exists(name) {
+		return name
+	}
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	if base == "" {
+		// 隐藏文件，例如 .gitignore
+		base, ext = name, ""
+	}
+	for i := 1; ; i++ {
+		n := fmt.Sprintf("%s(%d)%s", base, i, ext)
+		if !exists(n) {
+			return n
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package aliyunpan
 
-import "net/http"
+import (
+	"net/http"
+	"sync"
+	"time"
+)
 
 type (
 	DownloadFuncCallback func(httpMethod, fullUrl string, headers map[string]string) (resp *http.Response, err error)
@@ -28,10 +32,98 @@ type (
 		CdnUrl      string `json:"cdn_url"`
 		Expiration  string `json:"expiration"`
 		Size        int64  `json:"size"`
-		Ratelimit   struct {
+		// Ratelimit 服务器对该链接的下载限速，只有网页端接口返回，不限速时两个值都是 -1
+		Ratelimit struct {
+			// PartSpeed 每个分片（即每个下载连接）的速率上限，单位字节/秒，小于等于0代表不限速
 			PartSpeed int64 `json:"part_speed"`
-			PartSize  int64 `json:"part_size"`
+			// PartSize 服务器划分分片的大小，单位字节。限速按照连接计算，和请求的 Range 大小无关，
+			// 一个请求跨越多个分片时速率上限仍然是 PartSpeed，只作为分片下载大小的参考
+			PartSize int64 `json:"part_size"`
 		} `json:"ratelimit"`
 		Description string `json:"description"`
 	}
+
+	// DownloadRatelimits 记录获取下载链接时服务器返回的限速，下载该链接的数据时自动应用，并发安全
+	DownloadRatelimits struct {
+		mutex sync.Mutex
+		items map[string]*downloadRatelimit
+	}
+
+	downloadRatelimit struct {
+		partSpeed int64
+		expireAt  time.Time
+	}
 )
+
+const (
+	// downloadUrlMaxAge 下载链接的最长有效期
+	downloadUrlMaxAge = 4 * time.Hour
+)
+
+// ApplyRatelimit 将服务器返回的单个连接的下载速率（Ratelimit.PartSpeed，字节/秒）作为限速器的速率上限。
+// 限速器需要只用于一个下载连接，多个连接共用时总速率会低于服务器允许的速率
+func (r *GetFileDownloadUrlResult) ApplyRatelimit(limiter *BandwidthLimiter) {
+	if r == nil || limiter == nil {
+		return
+	}
+	limiter.SetCeiling(r.Ratelimit.PartSpeed)
+}
+
+// NewTransferLimiter 创建单个下载任务的限速器，bytesPerSecond 为单任务限速，小于等于0代表不限速。
+// 服务器返回的单个连接的下载速率会作为速率上限，可以传给 DownloadFileData、DownloadFileDataAndSave 的 limiters 参数。
+// 网页端客户端下载时已经按照 DownloadRatelimits 自动应用服务器限速，只需要单任务限速时才使用
+func (r *GetFileDownloadUrlResult) NewTransferLimiter(bytesPerSecond int64) *BandwidthLimiter {
+	limiter := NewBandwidthLimiter(bytesPerSecond)
+	r.ApplyRatelimit(limiter)
+	return limiter
+}
+
+// NewDownloadRatelimits 创建下载链接限速记录
+func NewDownloadRatelimits() *DownloadRatelimits {
+	return &DownloadRatelimits{
+		items: map[string]*downloadRatelimit{},
+	}
+}
+
+// Record 记录下载链接的服务器限速，没有限速的链接删除之前的记录。已经过期的链接同时被清理
+func (d *DownloadRatelimits) Record(r *GetFileDownloadUrlResult) {
+	if d == nil || r == nil {
+		return
+	}
+	now := time.Now()
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for u, item := range d.items {
+		if now.After(item.expireAt) {
+			delete(d.items, u)
+		}
+	}
+	expireAt := now.Add(downloadUrlMaxAge)
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", r.Expiration, time.Local); err == nil && t.Before(expireAt) {
+		expireAt = t
+	}
+	for _, u := range []string{r.Url, r.InternalUrl, r.CdnUrl} {
+		if u == "" {
+			continue
+		}
+		if r.Ratelimit.PartSpeed <= 0 {
+			delete(d.items, u)
+			continue
+		}
+		d.items[u] = &downloadRatelimit{partSpeed: r.Ratelimit.PartSpeed, expireAt: expireAt}
+	}
+}
+
+// NewConnLimiter 为下载链接的一个下载连接创建限速器，链接没有服务器限速时返回nil
+func (d *DownloadRatelimits) NewConnLimiter(downloadUrl string) *BandwidthLimiter {
+	if d == nil {
+		return nil
+	}
+	d.mutex.Lock()
+	item, ok := d.items[downloadUrl]
+	d.mutex.Unlock()
+	if !ok || time.Now().After(item.expireAt) {
+		return nil
+	}
+	return NewBandwidthLimiter(item.partSpeed)
+}
@@ -0,0 +1,355 @@
+package aliyunpan
+
+import (
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/library-go/logger"
+)
+
+const (
+	// DefaultDuplicateTrashBatchSize 查找重复文件时每次批量放入回收站的文件数量
+	DefaultDuplicateTrashBatchSize = 100
+)
+
+const (
+	// DuplicateKeepNewest 保留最后修改时间最新的文件
+	DuplicateKeepNewest DuplicateKeepStrategy = "newest"
+	// DuplicateKeepOldest 保留最后修改时间最早的文件
+	DuplicateKeepOldest DuplicateKeepStrategy = "oldest"
+	// DuplicateKeepShortestPath 保留路径最短的文件
+	DuplicateKeepShortestPath DuplicateKeepStrategy = "shortest_path"
+)
+
+type (
+	// DuplicateKeepStrategy 重复文件保留策略
+	DuplicateKeepStrategy string
+
+	// DuplicateRoot 查找重复文件的范围
+	DuplicateRoot struct {
+		// DriveId 网盘ID
+		DriveId string `json:"drive_id"`
+		// Path 文件夹绝对路径，为空代表整个网盘
+		Path string `json:"path"`
+	}
+
+	// DuplicateFindParam 查找重复文件参数
+	DuplicateFindParam struct {
+		// Roots 查找范围，可以是多个网盘或者多个文件夹，同一个文件只会统计一次
+		Roots []*DuplicateRoot `json:"roots"`
+		// MinSize 忽略小于该大小的文件，空文件总是被忽略（所有空文件的SHA1都相同，不是重复文件）
+		MinSize int64 `json:"min_size"`
+		// KeepStrategy 保留策略，默认保留路径最短的文件
+		KeepStrategy DuplicateKeepStrategy `json:"keep_strategy"`
+		// TrashDuplicates 是否把保留文件以外的重复文件放入回收站
+		TrashDuplicates bool `json:"trash_duplicates"`
+		// ConfirmTrash 开启后只有 handler 把 DuplicateGroup.Confirmed 设置为 true 的分组才会放入回收站，
+		// 未开启时 handler 返回后直接放入回收站。两种情况下 handler 都可以把文件的 Skip 设置为 true 保留该文件
+		ConfirmTrash bool `json:"confirm_trash"`
+		// BatchSize 每次批量放入回收站的文件数量，默认 DefaultDuplicateTrashBatchSize
+		BatchSize int `json:"batch_size"`
+	}
+
+	// DuplicateFile 重复文件组中的文件
+	DuplicateFile struct {
+		// DriveId 网盘ID
+		DriveId string `json:"drive_id"`
+		// FileId 文件ID
+		FileId string `json:"file_id"`
+		// Path 文件完整路径
+		Path string `json:"path"`
+		// UpdatedAt 最后修改时间
+		UpdatedAt string `json:"updated_at"`
+		// Keep 是否是按照策略保留的文件
+		Keep bool `json:"keep"`
+		// Skip handler 设置为 true 时不放入回收站
+		Skip bool `json:"skip"`
+		// Trashed 是否已经放入回收站，批量放入回收站后才会更新
+		Trashed bool `json:"trashed"`
+		// Err 放入回收站失败的原因
+		Err *apierror.ApiError `json:"-"`
+	}
+
+	// DuplicateGroup 内容相同（大小和SHA1都相同）的一组文件。
+	// 同一组重复文件会分多次返回：发现第二个相同的文件时第一次返回，之后每发现一个相同的文件再返回一次，
+	// Files 只包含目前应该保留的文件和新发现的文件，保留的文件排在第一位
+	DuplicateGroup struct {
+		// FileSize 文件大小
+		FileSize int64 `json:"file_size"`
+		// ContentHash 内容SHA1
+		ContentHash string `json:"content_hash"`
+		// Files 组内的文件，保留的文件排在第一位
+		Files []*DuplicateFile `json:"files"`
+		// WastedSize 多余文件占用的空间
+		WastedSize int64 `json:"wasted_size"`
+		// Confirmed 开启 ConfirmTrash 时 handler 设置为 true 才会把多余的文件放入回收站
+		Confirmed bool `json:"confirmed"`
+	}
+
+	// DuplicateFindResult 查找重复文件的统计结果
+	DuplicateFindResult struct {
+		// ScannedFiles 扫描的文件数量
+		ScannedFiles int `json:"scanned_files"`
+		// GroupCount 重复文件组数量，同一组分多次返回只统计一次
+		GroupCount int `json:"group_count"`
+		// DuplicateFiles 多余文件数量，不包括每组保留的文件
+		DuplicateFiles int `json:"duplicate_files"`
+		// WastedSize 多余文件占用的空间
+		WastedSize int64 `json:"wasted_size"`
+		// TrashedFiles 已经放入回收站的文件数量
+		TrashedFiles int `json:"trashed_files"`
+		// FreedSize 放入回收站的文件总大小
+		FreedSize int64 `json:"freed_size"`
+	}
+
+	// DuplicateGroupFunc 处理一组重复文件，返回false停止查找。handler 在文件放入回收站之前调用
+	DuplicateGroupFunc func(group *DuplicateGroup) bool
+
+	// DuplicateFileOperator 查找重复文件需要的网盘操作，WebPanClient 和 OpenPanClient 分别实现
+	DuplicateFileOperator interface {
+		FileInfoByPath(driveId string, pathStr string) (*FileEntity, *apierror.ApiError)
+		FileListGetAll(param *FileListParam, delayMilliseconds int) (FileList, *apierror.ApiError)
+		// RemoveFiles 批量把文件放入回收站，返回和 fileIds 一一对应的错误，成功为nil
+		RemoveFiles(driveId string, fileIds []string) []*apierror.ApiError
+	}
+
+	duplicateKey struct {
+		size int64
+		hash string
+	}
+
+	// duplicateFinder 遍历时只记录每个 duplicateKey 目前应该保留的文件，发现重复文件立即返回分组
+	duplicateFinder struct {
+		op       DuplicateFileOperator
+		param    *DuplicateFindParam
+		strategy DuplicateKeepStrategy
+		handler  DuplicateGroupFunc
+		result   *DuplicateFindResult
+		kept     map[duplicateKey]*DuplicateFile
+		grouped  map[duplicateKey]bool
+		visited  map[string]bool
+		// pending 等待批量放入回收站的文件
+		pending []*duplicateTrash
+		stopped bool
+	}
+
+	duplicateTrash struct {
+		file *DuplicateFile
+		size int64
+	}
+)
+
+// IsValid 是否是支持的保留策略
+func (s DuplicateKeepStrategy) IsValid() bool {
+	switch s {
+	case DuplicateKeepNewest, DuplicateKeepOldest, DuplicateKeepShortestPath:
+		return true
+	}
+	return false
+}
+
+// FindDuplicateFiles 遍历指定的网盘或者文件夹，按照文件大小和服务器记录的SHA1分组查找重复文件，不需要下载文件。
+// 遍历时每种内容只记录目前应该保留的一个文件，发现重复文件立即通过 handler 返回，不需要等待遍历完成。
+// 开启 TrashDuplicates 时每组只保留一个文件，其余的在 handler 返回后按照 BatchSize 批量放入回收站，
+// 开启 ConfirmTrash 时只处理 handler 确认过的分组
+func FindDuplicateFiles(op DuplicateFileOperator, param *DuplicateFindParam, handler DuplicateGroupFunc) (*DuplicateFindResult, *apierror.ApiError) {
+	if param == nil || len(param.Roots) == 0 {
+		return nil, apierror.NewFailedApiError("参数不能为空")
+	}
+	strategy := param.KeepStrategy
+	if strategy == "" {
+		strategy = DuplicateKeepShortestPath
+	}
+	if !strategy.IsValid() {
+		return nil, apierror.NewFailedApiError("不支持的保留策略：" + string(strategy))
+	}
+	opts := *param
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultDuplicateTrashBatchSize
+	}
+
+	f := &duplicateFinder{
+		op:       op,
+		param:    &opts,
+		strategy: strategy,
+		handler:  handler,
+		result:   &DuplicateFindResult{},
+		kept:     map[duplicateKey]*DuplicateFile{},
+		grouped:  map[duplicateKey]bool{},
+		visited:  map[string]bool{},
+	}
+	for _, root := range param.Roots {
+		if f.stopped {
+			break
+		}
+		rootPath := root.Path
+		if rootPath == "" {
+			rootPath = PathSeparator
+		}
+		folder, err := op.FileInfoByPath(root.DriveId, rootPath)
+		if err != nil {
+			f.flush()
+			return nil, err
+		}
+		folder.Path = path.Clean(rootPath)
+		if folder.IsFile() {
+			// 遍历时只记录文件夹，单独指定的文件需要记录，避免和其他范围重复统计
+			if f.visited[root.DriveId+"/"+folder.FileId] || f.visited[root.DriveId+"/"+folder.ParentFileId] {
+				continue
+			}
+			f.visited[root.DriveId+"/"+folder.FileId] = true
+		}
+		if err = f.walk(root.DriveId, folder); err != nil {
+			f.flush()
+			return nil, err
+		}
+	}
+	f.flush()
+	return f.result, nil
+}
+
+// walk 遍历文件夹下的所有文件，已经遍历过的文件夹会跳过
+func (f *duplicateFinder) walk(driveId string, folder *FileEntity) *apierror.ApiError {
+	if folder.IsFile() {
+		f.add(driveId, folder)
+		return nil
+	}
+	if f.visited[driveId+"/"+folder.FileId] {
+		return nil
+	}
+	f.visited[driveId+"/"+folder.FileId] = true
+
+	fileList, err := f.op.FileListGetAll(&FileListParam{DriveId: driveId, ParentFileId: folder.FileId}, 0)
+	if err != nil {
+		return err
+	}
+	for _, item := range fileList {
+		if f.stopped {
+			return nil
+		}
+		item.Path = path.Join(folder.Path, item.FileName)
+		if item.IsFile() && f.visited[driveId+"/"+item.FileId] {
+			// 已经作为单独的文件统计过
+			continue
+		}
+		if err = f.walk(driveId, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add 统计一个文件，和已经记录的相同内容的文件组成分组返回
+func (f *duplicateFinder) add(driveId string, entity *FileEntity) {
+	f.result.ScannedFiles++
+	if entity.FileSize <= 0 || entity.FileSize < f.param.MinSize || entity.ContentHash == "" {
+		return
+	}
+	if entity.ContentHashName != "" && !strings.EqualFold(entity.ContentHashName, "sha1") {
+		return
+	}
+	key := duplicateKey{size: entity.FileSize, hash: strings.ToUpper(entity.ContentHash)}
+	file := &DuplicateFile{
+		DriveId:   driveId,
+		FileId:    entity.FileId,
+		Path:      entity.Path,
+		UpdatedAt: entity.UpdatedAt,
+	}
+	keep, ok := f.kept[key]
+	if !ok {
+		f.kept[key] = file
+		return
+	}
+
+	// 目前应该保留的文件排在第一位
+	files := []*DuplicateFile{keep, file}
+	if preferDuplicateFile(file, keep, f.strategy) {
+		files = []*DuplicateFile{file, keep}
+	}
+	files[0].Keep, files[1].Keep = true, false
+	f.kept[key] = files[0]
+	group := &DuplicateGroup{
+		FileSize:    key.size,
+		ContentHash: key.hash,
+		Files:       files,
+		WastedSize:  key.size,
+	}
+	if !f.grouped[key] {
+		f.grouped[key] = true
+		f.result.GroupCount++
+	}
+	f.result.DuplicateFiles++
+	f.result.WastedSize += group.WastedSize
+
+	if f.handler != nil && !f.handler(group) {
+		f.stopped = true
+	}
+	if !f.param.TrashDuplicates || (f.param.ConfirmTrash && !group.Confirmed) {
+		return
+	}
+	for _, d := range files[1:] {
+		if d.Skip {
+			continue
+		}
+		f.pending = append(f.pending, &duplicateTrash{file: d, size: key.size})
+	}
+	if len(f.pending) >= f.param.BatchSize {
+		f.flush()
+	}
+}
+
+// flush 批量把等待中的文件放入回收站
+func (f *duplicateFinder) flush() {
+	pending := f.pending
+	f.pending = nil
+	for len(pending) > 0 {
+		// 同一个网盘的文件一起处理
+		driveId := pending[0].file.DriveId
+		batch, rest := []*duplicateTrash{}, []*duplicateTrash{}
+		for _, t := range pending {
+			if t.file.DriveId == driveId && len(batch) < f.param.BatchSize {
+				batch = append(batch, t)
+			} else {
+				rest = append(rest, t)
+			}
+		}
+		pending = rest
+
+		fileIds := []string{}
+		for _, t := range batch {
+			fileIds = append(fileIds, t.file.FileId)
+		}
+		errs := f.op.RemoveFiles(driveId, fileIds)
+		for i, t := range batch {
+			if i < len(errs) && errs[i] != nil {
+				t.file.Err = errs[i]
+				logger.Verboseln("trash duplicate file error ", t.file.Path, t.file.Err)
+				continue
+			}
+			t.file.Trashed = true
+			f.result.TrashedFiles++
+			f.result.FreedSize += t.size
+		}
+	}
+}
+
+// preferDuplicateFile 按照保留策略 a 是否比 b 更应该保留
+func preferDuplicateFile(a, b *DuplicateFile, strategy DuplicateKeepStrategy) bool {
+	switch strategy {
+	case DuplicateKeepNewest:
+		if a.UpdatedAt != b.UpdatedAt {
+			return a.UpdatedAt > b.UpdatedAt
+		}
+	case DuplicateKeepOldest:
+		if a.UpdatedAt != b.UpdatedAt {
+			return a.UpdatedAt < b.UpdatedAt
+		}
+	}
+	la, lb := utf8.RuneCountInString(a.Path), utf8.RuneCountInString(b.Path)
+	if la != lb {
+		return la < lb
+	}
+	return a.Path < b.Path
+}
@@ -0,0 +1,143 @@
+package aliyunpan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDuplicateFilesStreaming(t *testing.T) {
+	s := newStubDrive()
+	s.add("a", "root", "a", 0, "", "")
+	s.add("b", "root", "b", 0, "", "")
+	s.add("a1", "a", "x.jpg", 10, "h1", "2023-01-01 00:00:00")
+	s.add("a2", "a", "y.jpg", 10, "H1", "2023-01-02 00:00:00")
+	s.add("b1", "b", "z.jpg", 10, "h1", "2023-01-03 00:00:00")
+
+	groups := []*DuplicateGroup{}
+	listedAtFirstGroup := 0
+	result, err := FindDuplicateFiles(s, &DuplicateFindParam{
+		Roots:        []*DuplicateRoot{{DriveId: "d"}},
+		KeepStrategy: DuplicateKeepNewest,
+	}, func(group *DuplicateGroup) bool {
+		if len(groups) == 0 {
+			listedAtFirstGroup = len(s.listed)
+		}
+		groups = append(groups, group)
+		return true
+	})
+	require.Nil(t, err)
+
+	// 发现重复文件立即返回，不等待遍历完成
+	assert.Equal(t, 2, listedAtFirstGroup)
+	assert.Equal(t, 3, len(s.listed))
+	require.Len(t, groups, 2)
+	assert.Equal(t, []string{"a2", "a1"}, duplicateFileIds(groups[0]))
+	// 后发现的文件更新，成为新的保留文件
+	assert.Equal(t, []string{"b1", "a2"}, duplicateFileIds(groups[1]))
+	assert.True(t, groups[1].Files[0].Keep)
+	assert.False(t, groups[1].Files[1].Keep)
+	assert.Equal(t, 1, result.GroupCount)
+	assert.Equal(t, 2, result.DuplicateFiles)
+	assert.Equal(t, int64(20), result.WastedSize)
+	assert.Empty(t, s.removed)
+}
+
+func TestFindDuplicateFilesConfirm(t *testing.T) {
+	s := newStubDrive()
+	s.add("a1", "root", "a1.txt", 10, "A", "")
+	s.add("a2", "root", "a2.txt", 10, "A", "")
+	s.add("b1", "root", "b1.txt", 20, "B", "")
+	s.add("b2", "root", "b2.txt", 20, "B", "")
+	s.add("c1", "root", "c1.txt", 30, "C", "")
+	s.add("c2", "root", "c2.txt", 30, "C", "")
+	s.add("c3", "root", "c3.txt", 30, "C", "")
+
+	// 只放入回收站 handler 确认过的分组，Skip 的文件保留
+	result, err := FindDuplicateFiles(s, &DuplicateFindParam{
+		Roots:           []*DuplicateRoot{{DriveId: "d"}},
+		TrashDuplicates: true,
+		ConfirmTrash:    true,
+	}, func(group *DuplicateGroup) bool {
+		assert.False(t, group.Files[1].Trashed)
+		switch group.ContentHash {
+		case "A":
+			group.Confirmed = true
+		case "C":
+			group.Confirmed = true
+			group.Files[1].Skip = group.Files[1].FileId == "c2"
+		}
+		return true
+	})
+	require.Nil(t, err)
+	assert.Equal(t, [][]string{{"a2", "c3"}}, s.removed)
+	assert.Equal(t, 2, result.TrashedFiles)
+	assert.Equal(t, int64(40), result.FreedSize)
+	assert.Equal(t, 4, result.DuplicateFiles)
+}
+
+func TestFindDuplicateFilesBatch(t *testing.T) {
+	s := newStubDrive()
+	for _, id := range []string{"f1", "f2", "f3", "f4", "f5", "f6"} {
+		s.add(id, "root", id+".txt", 10, "A", "")
+	}
+	s.failRemove["f4"] = true
+
+	// 不需要确认时按照 BatchSize 批量放入回收站，handler 返回 false 后处理已经返回的分组并停止
+	calls := 0
+	var failed *DuplicateFile
+	result, err := FindDuplicateFiles(s, &DuplicateFindParam{
+		Roots:           []*DuplicateRoot{{DriveId: "d"}},
+		TrashDuplicates: true,
+		BatchSize:       2,
+	}, func(group *DuplicateGroup) bool {
+		calls++
+		if group.Files[1].FileId == "f4" {
+			failed = group.Files[1]
+		}
+		return calls < 4
+	})
+	require.Nil(t, err)
+	assert.Equal(t, [][]string{{"f2", "f3"}, {"f4", "f5"}}, s.removed)
+	assert.Equal(t, 3, result.TrashedFiles)
+	require.NotNil(t, failed)
+	assert.NotNil(t, failed.Err)
+	assert.False(t, failed.Trashed)
+	assert.Contains(t, s.files, "f6")
+
+	_, err = FindDuplicateFiles(s, &DuplicateFindParam{Roots: []*DuplicateRoot{{DriveId: "d"}}, KeepStrategy: "largest"}, nil)
+	assert.NotNil(t, err)
+}
+
+func duplicateFileIds(group *DuplicateGroup) []string {
+	ids := []string{}
+	for _, f := range group.Files {
+		ids = append(ids, f.FileId)
+	}
+	return ids
+}
+
+func TestFindDuplicateFilesSkipsEmptyFiles(t *testing.T) {
+	s := newStubDrive()
+	// 空文件的SHA1都相同，即使 MinSize 为0也不能作为重复文件
+	s.add("e1", "root", "e1.txt", 0, "DA39A3EE5E6B4B0D3255BFEF95601890AFD80709", "")
+	s.add("e2", "root", "e2.txt", 0, "DA39A3EE5E6B4B0D3255BFEF95601890AFD80709", "")
+	s.add("a1", "root", "a1.txt", 10, "A", "")
+	s.add("a2", "root", "a2.txt", 10, "A", "")
+
+	groups := []*DuplicateGroup{}
+	result, err := FindDuplicateFiles(s, &DuplicateFindParam{
+		Roots:           []*DuplicateRoot{{DriveId: "d"}},
+		TrashDuplicates: true,
+	}, func(group *DuplicateGroup) bool {
+		groups = append(groups, group)
+		return true
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 4, result.ScannedFiles)
+	require.Len(t, groups, 1)
+	assert.ElementsMatch(t, []string{"a1", "a2"}, duplicateFileIds(groups[0]))
+	assert.NotContains(t, s.removed, "e1")
+	assert.NotContains(t, s.removed, "e2")
+}
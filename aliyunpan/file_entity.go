@@ -44,12 +44,24 @@ type (
 		SyncFlag bool `json:"syncFlag"`
 		// SyncMeta 如果是同步盘的文件夹，则这里会记录该文件对应的同步机器和目录等信息
 		SyncMeta string `json:"syncMeta"`
-		// Thumbnail 缩略图URL地址，只有相册文件才有
+		// Thumbnail 缩略图URL地址，图片和视频文件才有，普通文件需要通过 FileThumbnail 获取。签名地址会过期，元数据缓存不保存该字段
 		Thumbnail string `json:"thumbnail"`
+		// Url 图片的预览图地址或者小文件的下载地址，通过 FileThumbnail 获取。签名地址会过期，元数据缓存不保存该字段
+		Url string `json:"url"`
+		// ImageMediaMetadata 图片的宽高和EXIF信息，只有图片文件才有
+		ImageMediaMetadata *ImageMediaMetadata `json:"imageMediaMetadata,omitempty"`
 		// AlbumId 所属相册ID，只有相册文件才有
 		AlbumId string `json:"albumId"`
 	}
 
+	// ImageMediaMetadata 图片元数据
+	ImageMediaMetadata struct {
+		Width  int `json:"width"`
+		Height int `json:"height"`
+		// Exif 原始的EXIF信息，JSON字符串
+		Exif string `json:"exif"`
+	}
+
 	FileOrderBy        string
 	FileOrderDirection string
 )
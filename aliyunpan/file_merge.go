@@ -0,0 +1,379 @@
+package aliyunpan
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/library-go/logger"
+)
+
+const (
+	// FileMergeActionCreated 新建文件夹
+	FileMergeActionCreated FileMergeAction = "created"
+	// FileMergeActionMerged 合并到已存在的同名文件夹
+	FileMergeActionMerged FileMergeAction = "merged"
+	// FileMergeActionRapidCopied 通过秒传复制文件
+	FileMergeActionRapidCopied FileMergeAction = "rapid_copied"
+	// FileMergeActionCopied 通过服务器复制文件
+	FileMergeActionCopied FileMergeAction = "copied"
+	// FileMergeActionSkipped 跳过
+	FileMergeActionSkipped FileMergeAction = "skipped"
+	// FileMergeActionFailed 失败
+	FileMergeActionFailed FileMergeAction = "failed"
+)
+
+type (
+	// FileMergeAction 递归复制时对单个文件的处理方式
+	FileMergeAction string
+
+	// FileMergeParam 递归复制、合并文件夹参数
+	FileMergeParam struct {
+		// FromDriveId 源网盘ID
+		FromDriveId string `json:"from_drive_id"`
+		// FromFileId 源文件或者文件夹ID
+		FromFileId string `json:"from_file_id"`
+		// ToDriveId 目标网盘ID，可以和源网盘ID一样
+		ToDriveId string `json:"to_drive_id"`
+		// ToParentFileId 目标文件夹ID、根目录为 root
+		ToParentFileId string `json:"to_parent_file_id"`
+		// ConflictMode 同名文件冲突处理方式，默认自动重命名。同名文件夹总是合并
+		ConflictMode FileConflictMode `json:"conflict_mode"`
+		// ContentsOnly 只复制源文件夹下的内容，不在目标文件夹下创建源文件夹本身
+		ContentsOnly bool `json:"contents_only"`
+		// DisableRapidCopy 禁用秒传，只使用服务器复制
+		DisableRapidCopy bool `json:"disable_rapid_copy"`
+	}
+
+	// FileMergeItem 递归复制单个文件或者文件夹的结果
+	FileMergeItem struct {
+		// SourcePath 源文件相对路径
+		SourcePath string `json:"source_path"`
+		// SourceFileId 源文件ID
+		SourceFileId string `json:"source_file_id"`
+		// TargetPath 目标文件相对路径，自动重命名时为重命名后的路径
+		TargetPath string `json:"target_path"`
+		// FileId 目标文件ID
+		FileId string `json:"file_id"`
+		// IsFolder 是否是文件夹
+		IsFolder bool `json:"is_folder"`
+		// Action 处理方式
+		Action FileMergeAction `json:"action"`
+		// Overwritten 是否覆盖了已存在的文件
+		Overwritten bool `json:"overwritten"`
+		// Err 失败原因
+		Err *apierror.ApiError `json:"-"`
+	}
+
+	// RapidCopyOperator 秒传复制网盘文件需要的网盘操作
+	RapidCopyOperator interface {
+		GetAccessToken() string
+		GetFileDownloadUrl(param *GetFileDownloadUrlParam) (*GetFileDownloadUrlResult, *apierror.ApiError)
+		CreateUploadFile(param *CreateFileUploadParam) (*CreateFileUploadResult, *apierror.ApiError)
+		FileRename(driveId, renameFileId, newName string) (bool, *apierror.ApiError)
+		// RemoveFile 把文件放入回收站
+		RemoveFile(driveId, fileId string) *apierror.ApiError
+		// TransferRequest 使用数据传输的 http 客户端发送请求，用于读取文件数据
+		TransferRequest(httpMethod, fullUrl string, headers map[string]string) (*http.Response, error)
+	}
+
+	// FileMergeOperator 递归复制需要的网盘操作，WebPanClient 和 OpenPanClient 分别实现
+	FileMergeOperator interface {
+		RapidCopyOperator
+		FileInfoById(driveId, fileId string) (*FileEntity, *apierror.ApiError)
+		FileListGetAll(param *FileListParam, delayMilliseconds int) (FileList, *apierror.ApiError)
+		Mkdir(driveId, parentFileId, dirName string) (*MkdirResult, *apierror.ApiError)
+		// ServerCopyFile 通过服务器复制文件，等待复制完成后返回新文件ID
+		ServerCopyFile(source *FileEntity, toDriveId, toParentFileId, name string) (string, *apierror.ApiError)
+	}
+
+	fileMerger struct {
+		op    FileMergeOperator
+		param *FileMergeParam
+		items []*FileMergeItem
+	}
+)
+
+// FileMergeCopy 递归复制文件夹并和目标文件夹中已有的内容合并：
+// 缺少的文件夹会被创建，同名文件夹会合并，同名文件按照 ConflictMode 处理，
+// 内容相同（大小和 ContentHash 一致）的同名文件直接跳过。返回每个文件的处理结果
+func FileMergeCopy(op FileMergeOperator, param *FileMergeParam) ([]*FileMergeItem, *apierror.ApiError) {
+	if param == nil || param.FromDriveId == "" || param.FromFileId == "" || param.ToDriveId == "" {
+		return nil, apierror.NewFailedApiError("参数不能为空")
+	}
+	p := *param
+	if p.ToParentFileId == "" {
+		p.ToParentFileId = DefaultRootParentFileId
+	}
+	if p.ConflictMode == "" {
+		p.ConflictMode = FileConflictAutoRename
+	}
+	if !p.ConflictMode.IsValid() {
+		return nil, apierror.NewFailedApiError("不支持的冲突处理方式：" + string(p.ConflictMode))
+	}
+
+	var source *FileEntity
+	if p.FromFileId == DefaultRootParentFileId {
+		source = NewFileEntityForRootDir()
+		source.DriveId = p.FromDriveId
+		p.ContentsOnly = true
+	} else {
+		var err *apierror.ApiError
+		if source, err = op.FileInfoById(p.FromDriveId, p.FromFileId); err != nil {
+			return nil, err
+		}
+	}
+	if p.FromDriveId == p.ToDriveId && source.IsFolder() && source.FileId == p.ToParentFileId {
+		return nil, apierror.NewFailedApiError("不能复制文件夹到自身")
+	}
+
+	m := &fileMerger{
+		op:    op,
+		param: &p,
+		items: []*FileMergeItem{},
+	}
+	existed, err := m.listTarget(p.ToParentFileId)
+	if err != nil {
+		return nil, err
+	}
+	if p.ContentsOnly && source.IsFolder() {
+		m.mergeChildren(source, "", p.ToParentFileId, "")
+	} else {
+		m.mergeEntry(source, "", p.ToParentFileId, "", existed)
+	}
+	return m.items, nil
+}
+
+func (m *fileMerger) listTarget(parentFileId string) (map[string]*FileEntity, *apierror.ApiError) {
+	fileList, err := m.op.FileListGetAll(&FileListParam{
+		DriveId:      m.param.ToDriveId,
+		ParentFileId: parentFileId,
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	existed := map[string]*FileEntity{}
+	for _, f := range fileList {
+		existed[f.FileName] = f
+	}
+	return existed, nil
+}
+
+func (m *fileMerger) mergeChildren(sourceDir *FileEntity, sourcePath, targetDirId, targetPath string) {
+	children, err := m.op.FileListGetAll(&FileListParam{
+		DriveId:      m.param.FromDriveId,
+		ParentFileId: sourceDir.FileId,
+	}, 0)
+	if err != nil {
+		m.fail(sourceDir, sourcePath, err)
+		return
+	}
+	existed, err := m.listTarget(targetDirId)
+	if err != nil {
+		m.fail(sourceDir, sourcePath, err)
+		return
+	}
+	for _, child := range children {
+		m.mergeEntry(child, sourcePath, targetDirId, targetPath, existed)
+	}
+}
+
+func (m *fileMerger) fail(source *FileEntity, sourcePath string, err *apierror.ApiError) {
+	m.items = append(m.items, &FileMergeItem{
+		SourcePath:   sourcePath,
+		SourceFileId: source.FileId,
+		IsFolder:     source.IsFolder(),
+		Action:       FileMergeActionFailed,
+		Err:          err,
+	})
+}
+
+func (m *fileMerger) mergeEntry(source *FileEntity, sourceDirPath, targetDirId, targetDirPath string, existed map[string]*FileEntity) {
+	name := source.FileName
+	item := &FileMergeItem{
+		SourcePath:   path.Join(sourceDirPath, name),
+		SourceFileId: source.FileId,
+		IsFolder:     source.IsFolder(),
+		Action:       FileMergeActionFailed,
+	}
+	m.items = append(m.items, item)
+
+	var overwrite *FileEntity
+	if target, ok := existed[name]; ok {
+		switch {
+		case source.IsFolder() && target.IsFolder():
+			// 同名文件夹合并
+			item.FileId = target.FileId
+			item.TargetPath = path.Join(targetDirPath, name)
+			item.Action = FileMergeActionMerged
+			m.mergeChildren(source, item.SourcePath, target.FileId, item.TargetPath)
+			return
+		case source.IsFile() && target.IsFile() && source.FileSize == target.FileSize &&
+			source.ContentHash != "" && source.ContentHash == target.ContentHash:
+			// 内容相同
+			item.FileId = target.FileId
+			item.TargetPath = path.Join(targetDirPath, name)
+			item.Action = FileMergeActionSkipped
+			return
+		}
+
+		switch m.param.ConflictMode {
+		case FileConflictSkip:
+			logger.Verboseln("merge skip existed file: ", path.Join(targetDirPath, name))
+			item.FileId = target.FileId
+			item.TargetPath = path.Join(targetDirPath, name)
+			item.Action = FileMergeActionSkipped
+			return
+		case FileConflictOverwrite:
+			if target.IsFolder() {
+				item.Err = apierror.NewFailedApiError("目标已存在同名文件夹，不能覆盖：" + path.Join(targetDirPath, name))
+				return
+			}
+			if source.IsFolder() {
+				item.Err = apierror.NewFailedApiError("目标已存在同名文件，不能覆盖为文件夹：" + path.Join(targetDirPath, name))
+				return
+			}
+			// 先复制为其他文件名，复制成功后再删除已存在的文件，避免复制失败丢失文件
+			overwrite = target
+			name = UniqueFileName(name, func(n string) bool {
+				_, ok := existed[n]
+				return ok
+			})
+		default:
+			name = UniqueFileName(name, func(n string) bool {
+				_, ok := existed[n]
+				return ok
+			})
+		}
+	}
+	item.TargetPath = path.Join(targetDirPath, name)
+
+	if source.IsFolder() {
+		r, err := m.op.Mkdir(m.param.ToDriveId, targetDirId, name)
+		if err != nil {
+			item.Err = err
+			return
+		}
+		item.FileId = r.FileId
+		item.Action = FileMergeActionCreated
+		existed[name] = &FileEntity{FileId: r.FileId, FileName: name, FileType: "folder"}
+		m.mergeChildren(source, item.SourcePath, r.FileId, item.TargetPath)
+		return
+	}
+
+	if !m.param.DisableRapidCopy && source.ContentHash != "" {
+		fileId, ok, err := RapidCopyFile(m.op, source, m.param.ToDriveId, targetDirId, name)
+		if err != nil {
+			logger.Verboseln("rapid copy error, fallback to server copy: ", err)
+		} else if ok {
+			item.FileId = fileId
+			item.Action = FileMergeActionRapidCopied
+		}
+	}
+	if item.Action != FileMergeActionRapidCopied {
+		if item.FileId, item.Err = m.op.ServerCopyFile(source, m.param.ToDriveId, targetDirId, name); item.Err != nil {
+			item.Action = FileMergeActionFailed
+			return
+		}
+		item.Action = FileMergeActionCopied
+	}
+	if overwrite != nil {
+		existed[name] = &FileEntity{FileId: item.FileId, FileName: name, FileType: "file"}
+		if item.Err = m.op.RemoveFile(m.param.ToDriveId, overwrite.FileId); item.Err != nil {
+			item.Action = FileMergeActionFailed
+			return
+		}
+		delete(existed, overwrite.FileName)
+		if _, item.Err = m.op.FileRename(m.param.ToDriveId, item.FileId, overwrite.FileName); item.Err != nil {
+			item.Action = FileMergeActionFailed
+			return
+		}
+		delete(existed, name)
+		name = overwrite.FileName
+		item.TargetPath = path.Join(targetDirPath, name)
+		item.Overwritten = true
+	}
+	existed[name] = &FileEntity{
+		FileId:      item.FileId,
+		FileName:    name,
+		FileType:    "file",
+		FileSize:    source.FileSize,
+		ContentHash: source.ContentHash,
+	}
+}
+
+// RapidCopyFile 通过 ContentHash 秒传复制网盘中已有的文件，返回新文件ID，不能秒传时返回 false。
+// 先用唯一的临时文件名秒传，成功后再重命名为 name，不会删除或者覆盖不是本次创建的文件
+func RapidCopyFile(op RapidCopyOperator, source *FileEntity, toDriveId, toParentFileId, name string) (string, bool, *apierror.ApiError) {
+	downloadUrl := ""
+	proofCode, e := CalcRemoteProofCode(op.GetAccessToken(), source.FileSize, func(offset, length int64) ([]byte, error) {
+		if downloadUrl == "" {
+			r, err := op.GetFileDownloadUrl(&GetFileDownloadUrlParam{
+				DriveId: source.DriveId,
+				FileId:  source.FileId,
+			})
+			if err != nil {
+				return nil, err
+			}
+			downloadUrl = r.Url
+		}
+		return readFileRange(op, downloadUrl, offset, length)
+	})
+	if e != nil {
+		return "", false, apierror.NewApiErrorWithError(e)
+	}
+
+	tmpName := fmt.Sprintf(".%s.%d.rapidcopy", name, time.Now().UnixNano())
+	r, err := op.CreateUploadFile(&CreateFileUploadParam{
+		Name:            tmpName,
+		DriveId:         toDriveId,
+		ParentFileId:    toParentFileId,
+		Size:            source.FileSize,
+		ContentHash:     source.ContentHash,
+		ContentHashName: "sha1",
+		CheckNameMode:   "refuse",
+		ProofCode:       proofCode,
+		ProofVersion:    "v1",
+	})
+	if err != nil {
+		return "", false, err
+	}
+	if r.Exist || (r.FileName != "" && r.FileName != tmpName) {
+		// 返回的是已存在的文件，不能删除
+		logger.Verboseln("rapid copy temp file name existed: ", tmpName)
+		return "", false, nil
+	}
+	if !r.RapidUpload {
+		// 没有秒传成功，删除本次创建的上传任务
+		if e := op.RemoveFile(toDriveId, r.FileId); e != nil {
+			logger.Verboseln("remove rapid copy upload task error: ", e)
+		}
+		return "", false, nil
+	}
+	if _, err = op.FileRename(toDriveId, r.FileId, name); err != nil {
+		op.RemoveFile(toDriveId, r.FileId)
+		return "", false, err
+	}
+	return r.FileId, true, nil
+}
+
+// readFileRange 读取下载地址指定范围的数据
+func readFileRange(op RapidCopyOperator, downloadUrl string, offset, length int64) ([]byte, error) {
+	resp, e := op.TransferRequest("GET", downloadUrl, map[string]string{
+		"referer": "https://www.aliyundrive.com/",
+		"range":   fmt.Sprintf("bytes=%d-%d", offset, offset+length-1),
+	})
+	if e != nil {
+		return nil, e
+	}
+	defer resp.Body.Close()
+	// 服务器不支持 Range 时只能接受从头读取
+	if resp.StatusCode != 206 && (resp.StatusCode != 200 || offset != 0) {
+		return nil, fmt.Errorf("unexpected http status code, %d", resp.StatusCode)
+	}
+	return ioutil.ReadAll(io.LimitReader(resp.Body, length))
+}
@@ -0,0 +1,80 @@
+package aliyunpan
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+// stubRapidCopier 秒传复制接口，create 决定创建上传文件的返回值
+type stubRapidCopier struct {
+	create  func(param *CreateFileUploadParam) *CreateFileUploadResult
+	removed []string
+	renamed map[string]string
+}
+
+func (s *stubRapidCopier) GetAccessToken() string {
+	return "token"
+}
+
+func (s *stubRapidCopier) GetFileDownloadUrl(param *GetFileDownloadUrlParam) (*GetFileDownloadUrlResult, *apierror.ApiError) {
+	return &GetFileDownloadUrlResult{Url: "https://download.fake/" + param.FileId}, nil
+}
+
+func (s *stubRapidCopier) CreateUploadFile(param *CreateFileUploadParam) (*CreateFileUploadResult, *apierror.ApiError) {
+	return s.create(param), nil
+}
+
+func (s *stubRapidCopier) FileRename(driveId, renameFileId, newName string) (bool, *apierror.ApiError) {
+	s.renamed[renameFileId] = newName
+	return true, nil
+}
+
+func (s *stubRapidCopier) RemoveFile(driveId, fileId string) *apierror.ApiError {
+	s.removed = append(s.removed, fileId)
+	return nil
+}
+
+func (s *stubRapidCopier) TransferRequest(httpMethod, fullUrl string, headers map[string]string) (*http.Response, error) {
+	return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewReader([]byte("12345678")))}, nil
+}
+
+func TestRapidCopyFile(t *testing.T) {
+	source := &FileEntity{DriveId: "d1", FileId: "src", FileName: "a.txt", FileSize: 8, ContentHash: "H"}
+
+	// rapid upload under a temp name, then renamed
+	s := &stubRapidCopier{renamed: map[string]string{}, create: func(param *CreateFileUploadParam) *CreateFileUploadResult {
+		assert.NotEqual(t, "a.txt", param.Name)
+		assert.Equal(t, "refuse", param.CheckNameMode)
+		return &CreateFileUploadResult{FileId: "new", FileName: param.Name, RapidUpload: true}
+	}}
+	fileId, ok, err := RapidCopyFile(s, source, "d1", "root", "a.txt")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "new", fileId)
+	assert.Equal(t, "a.txt", s.renamed["new"])
+	assert.Empty(t, s.removed)
+
+	// not rapid: only the created upload task is removed
+	s = &stubRapidCopier{renamed: map[string]string{}, create: func(param *CreateFileUploadParam) *CreateFileUploadResult {
+		return &CreateFileUploadResult{FileId: "task", FileName: param.Name}
+	}}
+	_, ok, err = RapidCopyFile(s, source, "d1", "root", "a.txt")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, []string{"task"}, s.removed)
+
+	// an existing file is returned, never delete it
+	s = &stubRapidCopier{renamed: map[string]string{}, create: func(param *CreateFileUploadParam) *CreateFileUploadResult {
+		return &CreateFileUploadResult{FileId: "existing", FileName: param.Name, Exist: true}
+	}}
+	_, ok, err = RapidCopyFile(s, source, "d1", "root", "a.txt")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	assert.Empty(t, s.removed)
+	assert.Empty(t, s.renamed)
+}
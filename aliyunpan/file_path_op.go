@@ -0,0 +1,304 @@
+package aliyunpan
+
+import (
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
+	"github.com/tickstep/library-go/logger"
+)
+
+const (
+	// FilePathOpMove 移动
+	FilePathOpMove FilePathOpKind = iota
+	// FilePathOpCopy 复制
+	FilePathOpCopy
+	// FilePathOpRename 重命名
+	FilePathOpRename
+)
+
+type (
+	// FilePathOpKind 按路径操作文件的类型
+	FilePathOpKind int
+
+	// FilePathOperator 按路径移动、复制、重命名需要的网盘操作，WebPanClient 和 OpenPanClient 分别实现
+	FilePathOperator interface {
+		ResolveDrivePath(driveId, pathStr string) (string, string, *apierror.ApiError)
+		FileInfoById(driveId, fileId string) (*FileEntity, *apierror.ApiError)
+		FileInfoByPath(driveId string, pathStr string) (*FileEntity, *apierror.ApiError)
+		MatchPathByShellPattern(driveId string, pattern string) (*FileList, *apierror.ApiError)
+		MkdirByFullPath(driveId, fullPath string) (*MkdirResult, *apierror.ApiError)
+		FileListGetAll(param *FileListParam, delayMilliseconds int) (FileList, *apierror.ApiError)
+		FileRename(driveId, renameFileId, newName string) (bool, *apierror.ApiError)
+		// MoveFile 移动文件到 toParentFileId 文件夹，name 和原文件名不同时通过 FileMoveParam.NewName 在移动时改名，
+		// name 和原文件名相同时目标已有同名文件返回错误，不会自动重命名
+		MoveFile(source *FileEntity, toParentFileId, name string) *apierror.ApiError
+		// CopyFile 同网盘复制文件并等待异步任务完成，返回新文件ID，同名时自动重命名
+		CopyFile(source *FileEntity, toParentFileId string) (string, *apierror.ApiError)
+		// RemoveFile 把文件放入回收站
+		RemoveFile(driveId, fileId string) *apierror.ApiError
+	}
+)
+
+// FilePathOp 按路径移动或者复制文件，语义和 mv、cp -r 命令一致：
+// dstPath 是已存在的文件夹或者以 / 结尾时，源文件放到该文件夹下，否则放到 dstPath 的上级文件夹并命名为 dstPath 的文件名。
+// srcPath 支持通配符，匹配多个文件时 dstPath 必须是文件夹，不存在会自动创建。
+// 路径支持网盘别名，例如 resource:/Movies，源和目标必须在同一个网盘。
+// 覆盖同名文件时先移动或者复制为自动重命名的文件，成功后才把同名文件放入回收站并改回原来的文件名
+func FilePathOp(op FilePathOperator, kind FilePathOpKind, driveId, srcPath, dstPath string, conflictMode FileConflictMode) ([]*FilePathOpResult, *apierror.ApiError) {
+	if conflictMode == "" {
+		conflictMode = FileConflictAutoRename
+	}
+	if !conflictMode.IsValid() {
+		return nil, apierror.NewFailedApiError("不支持的冲突处理方式：" + string(conflictMode))
+	}
+
+	// source
+	srcDriveId, srcPath, err := op.ResolveDrivePath(driveId, srcPath)
+	if err != nil {
+		return nil, err
+	}
+	dstDriveId, dstPath, err := op.ResolveDrivePath(driveId, dstPath)
+	if err != nil {
+		return nil, err
+	}
+	if srcDriveId != dstDriveId {
+		return nil, apierror.NewFailedApiError("不支持跨网盘操作，请使用 FileCrossDriveCopy 或者 FileCrossDriveMove")
+	}
+	driveId = srcDriveId
+
+	var sources FileList
+	if strings.ContainsAny(srcPath, ShellPatternCharacters) {
+		matched, err := op.MatchPathByShellPattern(driveId, srcPath)
+		if err != nil {
+			return nil, err
+		}
+		sources = *matched
+	} else {
+		source, err := op.FileInfoByPath(driveId, srcPath)
+		if err != nil {
+			return nil, err
+		}
+		sources = FileList{source}
+	}
+	if len(sources) == 0 {
+		return nil, apierror.NewApiError(apierror.ApiCodeFileNotFoundCode, "没有匹配的文件："+srcPath)
+	}
+	for _, source := range sources {
+		if source.IsDriveRootFolder() {
+			return nil, apierror.NewFailedApiError("不能移动或者复制根目录")
+		}
+	}
+
+	// target
+	dir, targetName, err := resolvePathOpTarget(op, driveId, dstPath, len(sources) > 1)
+	if err != nil {
+		return nil, err
+	}
+	return doFilePathOp(op, kind, driveId, sources, dir, targetName, conflictMode), nil
+}
+
+// FileRenameByPath 按路径重命名文件，newName 只能是文件名，同名冲突规则同 FilePathOp
+func FileRenameByPath(op FilePathOperator, driveId, pathStr, newName string, conflictMode FileConflictMode) (*FilePathOpResult, *apierror.ApiError) {
+	if newName == "" || strings.Contains(newName, PathSeparator) || !apiutil.CheckFileNameValid(newName) {
+		return nil, apierror.NewFailedApiError("文件名不能为空或者包含特殊字符：" + apiutil.FileNameSpecialChars)
+	}
+	if conflictMode == "" {
+		conflictMode = FileConflictAutoRename
+	}
+	if !conflictMode.IsValid() {
+		return nil, apierror.NewFailedApiError("不支持的冲突处理方式：" + string(conflictMode))
+	}
+	driveId, pathStr, err := op.ResolveDrivePath(driveId, pathStr)
+	if err != nil {
+		return nil, err
+	}
+	source, err := op.FileInfoByPath(driveId, pathStr)
+	if err != nil {
+		return nil, err
+	}
+	if source.IsDriveRootFolder() {
+		return nil, apierror.NewFailedApiError("不能重命名根目录")
+	}
+	dir, err := op.FileInfoByPath(driveId, path.Dir(source.Path))
+	if err != nil {
+		return nil, err
+	}
+	results := doFilePathOp(op, FilePathOpRename, driveId, FileList{source}, dir, newName, conflictMode)
+	return results[0], nil
+}
+
+// resolvePathOpTarget 解析目标路径，返回目标文件夹和新文件名，新文件名为空代表保持原文件名
+func resolvePathOpTarget(op FilePathOperator, driveId, dstPath string, multiSource bool) (*FileEntity, string, *apierror.ApiError) {
+	if !path.IsAbs(dstPath) {
+		return nil, "", apierror.NewFailedApiError("目标路径必须是绝对路径")
+	}
+	intoDir := strings.HasSuffix(dstPath, PathSeparator) || multiSource
+	dstPath = path.Clean(dstPath)
+
+	target, err := op.FileInfoByPath(driveId, dstPath)
+	if err != nil && !errors.Is(err, apierror.ErrNotFound) {
+		return nil, "", err
+	}
+	if err == nil {
+		if target.IsFolder() {
+			return target, "", nil
+		}
+		if intoDir {
+			return nil, "", apierror.NewFailedApiError("目标路径不是文件夹：" + dstPath)
+		}
+		// 目标是已存在的文件，按照冲突处理
+		dir, err := op.FileInfoByPath(driveId, path.Dir(dstPath))
+		if err != nil {
+			return nil, "", err
+		}
+		return dir, target.FileName, nil
+	}
+
+	// 目标不存在
+	dirPath, targetName := dstPath, ""
+	if !intoDir {
+		dirPath, targetName = path.Dir(dstPath), path.Base(dstPath)
+	}
+	dir, err := op.FileInfoByPath(driveId, dirPath)
+	if err != nil {
+		if !errors.Is(err, apierror.ErrNotFound) {
+			return nil, "", err
+		}
+		r, err := op.MkdirByFullPath(driveId, dirPath)
+		if err != nil {
+			return nil, "", err
+		}
+		dir = &FileEntity{
+			DriveId:  driveId,
+			FileId:   r.FileId,
+			FileName: path.Base(dirPath),
+			FileType: "folder",
+			Path:     dirPath,
+		}
+	}
+	return dir, targetName, nil
+}
+
+func doFilePathOp(op FilePathOperator, kind FilePathOpKind, driveId string, sources FileList, dir *FileEntity, targetName string, conflictMode FileConflictMode) []*FilePathOpResult {
+	results := []*FilePathOpResult{}
+
+	// 目标文件夹已有的文件
+	existed := map[string]*FileEntity{}
+	fileList, err := op.FileListGetAll(&FileListParam{
+		DriveId:      driveId,
+		ParentFileId: dir.FileId,
+	}, 0)
+	if err != nil {
+		for _, source := range sources {
+			results = append(results, &FilePathOpResult{SourcePath: source.Path, Err: err})
+		}
+		return results
+	}
+	for _, f := range fileList {
+		existed[f.FileName] = f
+	}
+
+	for _, source := range sources {
+		name := targetName
+		if name == "" {
+			name = source.FileName
+		}
+		r := &FilePathOpResult{
+			SourcePath: source.Path,
+		}
+		results = append(results, r)
+
+		if source.IsFolder() && (dir.Path == source.Path || strings.HasPrefix(dir.Path, source.Path+PathSeparator)) {
+			r.Err = apierror.NewFailedApiError("不能移动或者复制文件夹到自身的子目录：" + source.Path)
+			continue
+		}
+		if kind != FilePathOpCopy && source.ParentFileId == dir.FileId && source.FileName == name {
+			// 位置和名称都没有变化
+			r.FileId = source.FileId
+			r.TargetPath = source.Path
+			r.Success = true
+			continue
+		}
+
+		// 同名冲突
+		var overwrite *FileEntity
+		if f, ok := existed[name]; ok && (kind == FilePathOpCopy || f.FileId != source.FileId) {
+			switch conflictMode {
+			case FileConflictSkip:
+				logger.Verboseln("skip existed file: ", path.Join(dir.Path, name))
+				r.FileId = f.FileId
+				r.TargetPath = path.Join(dir.Path, name)
+				r.Skipped = true
+				continue
+			case FileConflictOverwrite:
+				if f.FileId == source.FileId {
+					r.Err = apierror.NewFailedApiError("源文件和目标文件相同：" + source.Path)
+					continue
+				}
+				// 先移动或者复制为其他文件名，成功后再把同名文件放入回收站，避免失败时丢失文件
+				overwrite = f
+				name = UniqueFileName(name, func(n string) bool {
+					_, ok := existed[n]
+					return ok
+				})
+			default:
+				name = UniqueFileName(name, func(n string) bool {
+					_, ok := existed[n]
+					return ok
+				})
+			}
+		}
+
+		switch kind {
+		case FilePathOpRename:
+			r.FileId = source.FileId
+			_, r.Err = op.FileRename(driveId, source.FileId, name)
+		case FilePathOpMove:
+			r.FileId = source.FileId
+			if r.Err = op.MoveFile(source, dir.FileId, name); r.Err == nil && name != source.FileName {
+				// 服务器只在同名冲突时使用 NewName
+				r.Err = ensureFileName(op, driveId, r.FileId, name)
+			}
+		case FilePathOpCopy:
+			if r.FileId, r.Err = op.CopyFile(source, dir.FileId); r.Err == nil && name != source.FileName {
+				r.Err = ensureFileName(op, driveId, r.FileId, name)
+			}
+		}
+		if r.Err != nil {
+			continue
+		}
+		if overwrite != nil {
+			// 失败时文件保留在自动重命名的位置
+			r.TargetPath = path.Join(dir.Path, name)
+			existed[name] = &FileEntity{FileId: r.FileId, FileName: name}
+			if r.Err = op.RemoveFile(driveId, overwrite.FileId); r.Err != nil {
+				continue
+			}
+			delete(existed, name)
+			name = overwrite.FileName
+			if r.Err = ensureFileName(op, driveId, r.FileId, name); r.Err != nil {
+				continue
+			}
+		}
+		r.TargetPath = path.Join(dir.Path, name)
+		r.Success = true
+		existed[name] = &FileEntity{FileId: r.FileId, FileName: name}
+	}
+	return results
+}
+
+// ensureFileName 移动、复制后服务器可能已经自动重命名，名称不一致时再重命名
+func ensureFileName(op FilePathOperator, driveId, fileId, name string) *apierror.ApiError {
+	f, err := op.FileInfoById(driveId, fileId)
+	if err != nil {
+		return err
+	}
+	if f.FileName == name {
+		return nil
+	}
+	_, err = op.FileRename(driveId, fileId, name)
+	return err
+}
@@ -1,6 +1,24 @@
 package aliyunpan
 
+import (
+	"time"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+const (
+	// ShareExpiredAll 不按过期状态过滤
+	ShareExpiredAll ShareExpiredFilter = ""
+	// ShareExpiredOnly 只返回已过期的分享
+	ShareExpiredOnly ShareExpiredFilter = "expired"
+	// ShareExpiredExclude 只返回未过期的分享
+	ShareExpiredExclude ShareExpiredFilter = "valid"
+)
+
 type (
+	// ShareExpiredFilter 分享过期状态过滤条件
+	ShareExpiredFilter string
+
 	// ShareCreateParam 创建分享
 	ShareCreateParam struct {
 		DriveId string `json:"drive_id"`
@@ -19,16 +37,68 @@ type (
 		SharePwd   string   `json:"share_pwd"`
 		ShareUrl   string   `json:"share_url"`
 		FileIdList []string `json:"file_id_list"`
-		SaveCount  int      `json:"save_count"`
+		// SaveCount 转存次数
+		SaveCount int `json:"save_count"`
+		// PreviewCount 浏览次数
+		PreviewCount int `json:"preview_count"`
+		// DownloadCount 下载次数
+		DownloadCount int `json:"download_count"`
 		// Expiration 过期时间，为空代表永不过期
 		Expiration string `json:"expiration"`
-		UpdatedAt  string `json:"updated_at"`
-		CreatedAt  string `json:"created_at"`
+		// Expired 服务端标记的是否已过期
+		Expired   bool   `json:"expired"`
+		UpdatedAt string `json:"updated_at"`
+		CreatedAt string `json:"created_at"`
 		// forbidden-已违规，enabled-正常
 		Status    string      `json:"status"`
 		FirstFile *FileEntity `json:"first_file"`
 	}
 
+	// ShareLinkListParam 分页获取分享链接的参数，除了 Creator、Limit、Marker 以外的条件都在客户端过滤
+	ShareLinkListParam struct {
+		// Creator 分享创建者的用户ID，开放接口不需要
+		Creator string `json:"creator"`
+		// Limit 每页数量，小于等于0时默认100
+		Limit int `json:"limit"`
+		// Marker 下一页的标记，为空代表第一页
+		Marker string `json:"marker"`
+		// IncludeCanceled 是否包含已取消的分享
+		IncludeCanceled bool `json:"include_canceled"`
+		// FileId 只返回包含该文件的分享，为空不过滤
+		FileId string `json:"file_id"`
+		// Status 只返回该状态的分享，例如：enabled、forbidden，为空不过滤
+		Status string `json:"status"`
+		// Expired 按过期状态过滤
+		Expired ShareExpiredFilter `json:"expired"`
+	}
+
+	// ShareLinkListResult 分享链接分页结果
+	ShareLinkListResult struct {
+		// Items 当前页过滤后的分享，数量可能小于 Limit
+		Items []*ShareEntity `json:"items"`
+		// NextMarker 下一页的标记，为空代表没有更多数据
+		NextMarker string `json:"next_marker"`
+	}
+
+	// ShareUpdateParam 修改分享的提取码和有效期
+	ShareUpdateParam struct {
+		ShareId string `json:"share_id"`
+		// SharePwd 新的提取码，4个字符，为空代表取消提取码
+		SharePwd string `json:"share_pwd"`
+		// Expiration 新的过期时间，为空代表永不过期。时间格式必须是这种：2021-07-23 09:22:19
+		Expiration string `json:"expiration"`
+	}
+
+	// ShareCancelResult 取消分享的结果
+	ShareCancelResult struct {
+		// 分享ID
+		Id string
+		// 是否成功
+		Success bool
+		// Err 取消失败的原因，成功时为 nil
+		Err *apierror.ApiError
+	}
+
 	// FastShareCreateParam 创建快传分享
 	FastShareCreateParam struct {
 		DriveId    string   `json:"drive_id"`
@@ -52,3 +122,48 @@ type (
 		Expired       bool                `json:"expired"`
 	}
 )
+
+// IsExpired 分享是否已经过期，服务端标记为过期或者过期时间早于 now 都认为已过期
+func (s *ShareEntity) IsExpired(now time.Time) bool {
+	if s.Expired {
+		return true
+	}
+	if s.Expiration == "" {
+		return false
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", s.Expiration, time.Local)
+	if err != nil {
+		return false
+	}
+	return t.Before(now)
+}
+
+// ContainsFile 分享是否包含指定的文件
+func (s *ShareEntity) ContainsFile(fileId string) bool {
+	for _, id := range s.FileIdList {
+		if id == fileId {
+			return true
+		}
+	}
+	return s.FirstFile != nil && s.FirstFile.FileId == fileId
+}
+
+// Match 分享是否满足过滤条件
+func (param *ShareLinkListParam) Match(s *ShareEntity, now time.Time) bool {
+	if s == nil {
+		return false
+	}
+	if param.FileId != "" && !s.ContainsFile(param.FileId) {
+		return false
+	}
+	if param.Status != "" && param.Status != s.Status {
+		return false
+	}
+	switch param.Expired {
+	case ShareExpiredOnly:
+		return s.IsExpired(now)
+	case ShareExpiredExclude:
+		return !s.IsExpired(now)
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package aliyunpan
+
+import "fmt"
+
+const (
+	// DefaultImageThumbnailWidth 图片缩略图的默认宽度
+	DefaultImageThumbnailWidth = 400
+	// DefaultImageUrlWidth 图片预览图的默认宽度
+	DefaultImageUrlWidth = 1920
+	// DefaultVideoThumbnailWidth 视频截图的默认宽度
+	DefaultVideoThumbnailWidth = 800
+
+	// FileThumbnailBatchSize 每次批量获取缩略图的最大文件数量
+	FileThumbnailBatchSize = 100
+)
+
+type (
+	// FileThumbnailParam 获取文件缩略图和预览图参数
+	FileThumbnailParam struct {
+		DriveId string
+		// FileIds 需要获取的文件ID，超过 FileThumbnailBatchSize 时分批获取
+		FileIds []string
+		// ImageThumbnailWidth 图片缩略图宽度，为空默认 DefaultImageThumbnailWidth
+		ImageThumbnailWidth int
+		// ImageUrlWidth 图片预览图宽度，为空默认 DefaultImageUrlWidth，只有网页端支持
+		ImageUrlWidth int
+		// VideoThumbnailWidth 视频截图宽度，为空默认 DefaultVideoThumbnailWidth
+		VideoThumbnailWidth int
+		// VideoThumbnailTime 视频截图的时间点，单位：毫秒
+		VideoThumbnailTime int
+	}
+)
+
+// WithDefaults 返回补全默认宽度的参数副本
+func (param *FileThumbnailParam) WithDefaults() *FileThumbnailParam {
+	p := *param
+	if p.ImageThumbnailWidth <= 0 {
+		p.ImageThumbnailWidth = DefaultImageThumbnailWidth
+	}
+	if p.ImageUrlWidth <= 0 {
+		p.ImageUrlWidth = DefaultImageUrlWidth
+	}
+	if p.VideoThumbnailWidth <= 0 {
+		p.VideoThumbnailWidth = DefaultVideoThumbnailWidth
+	}
+	if p.VideoThumbnailTime < 0 {
+		p.VideoThumbnailTime = 0
+	}
+	return &p
+}
+
+// ImageThumbnailProcess 网页端接口的 image_thumbnail_process 参数
+func (param *FileThumbnailParam) ImageThumbnailProcess() string {
+	return fmt.Sprintf("image/resize,w_%d/format,jpeg", param.WithDefaults().ImageThumbnailWidth)
+}
+
+// ImageUrlProcess 网页端接口的 image_url_process 参数
+func (param *FileThumbnailParam) ImageUrlProcess() string {
+	return fmt.Sprintf("image/resize,w_%d/format,jpeg", param.WithDefaults().ImageUrlWidth)
+}
+
+// VideoThumbnailProcess 网页端接口的 video_thumbnail_process 参数
+func (param *FileThumbnailParam) VideoThumbnailProcess() string {
+	p := param.WithDefaults()
+	return fmt.Sprintf("video/snapshot,t_%d,f_jpg,ar_auto,w_%d", p.VideoThumbnailTime, p.VideoThumbnailWidth)
+}
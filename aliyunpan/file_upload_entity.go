@@ -75,11 +75,13 @@ type (
 		PartInfoList []FileUploadPartInfoResult `json:"part_info_list"`
 		UploadId     string                     `json:"upload_id"`
 		// RapidUpload 是否秒传。true-已秒传，false-没有秒传，需要手动上传
-		RapidUpload bool   `json:"rapid_upload"`
-		Type        string `json:"type"`
-		FileId      string `json:"file_id"`
-		DomainId    string `json:"domain_id"`
-		DriveId     string `json:"drive_id"`
+		RapidUpload bool `json:"rapid_upload"`
+		// Exist 是否存在同名文件。CheckNameMode 为 refuse 时返回的是已存在文件的信息，不是新建的文件
+		Exist    bool   `json:"exist"`
+		Type     string `json:"type"`
+		FileId   string `json:"file_id"`
+		DomainId string `json:"domain_id"`
+		DriveId  string `json:"drive_id"`
 		// FileName 保存在网盘的名称，因为网盘会自动重命名同名的文件
 		FileName    string `json:"file_name"`
 		EncryptMode string `json:"encrypt_mode"`
@@ -113,8 +115,10 @@ type (
 
 	// FileUploadChunkData 文件上传数据块
 	FileUploadChunkData struct {
-		Reader       io.Reader
-		ChunkSize    int64
+		Reader    io.Reader
+		ChunkSize int64
+		// Limiters 上传带宽限速器，可以同时使用全局限速器和单个传输任务的限速器
+		Limiters     []*BandwidthLimiter
 		hasReadCount int64
 	}
 
@@ -184,12 +188,18 @@ func (d *FileUploadChunkData) Read(p []byte) (n int, err error) {
 		buf = make([]byte, realReadCount)
 		needCopy = true
 	}
+	if len(d.Limiters) > 0 && len(buf) > 0 {
+		buf = buf[:readSize(len(buf), d.Limiters)]
+	}
 
 	n, err = d.Reader.Read(buf)
 	if needCopy {
-		copy(p, buf)
+		copy(p, buf[:n])
 	}
 	d.hasReadCount += int64(n)
+	for _, l := range d.Limiters {
+		l.WaitN(n)
+	}
 	return n, err
 }
 
@@ -251,3 +261,38 @@ func GenerateFileUploadPartInfoListWithChunkSize(size, chunkSize int64) []FileUp
 	}
 	return r
 }
+
+type (
+	// RangeReadFunc 读取网盘文件指定范围的数据
+	RangeReadFunc func(offset, length int64) ([]byte, error)
+
+	// rangeReaderAt 按需读取网盘文件数据，实现 rio.ReaderAtLen64
+	rangeReaderAt struct {
+		size int64
+		read RangeReadFunc
+		err  error
+	}
+)
+
+func (r *rangeReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	data, err := r.read(off, int64(len(p)))
+	if err != nil {
+		r.err = err
+		return 0, err
+	}
+	return copy(p, data), nil
+}
+
+func (r *rangeReaderAt) Len() int64 {
+	return r.size
+}
+
+// CalcRemoteProofCode 计算网盘中已有文件的上传防伪码，只会读取计算需要的几个字节，用于网盘文件之间的秒传复制
+func CalcRemoteProofCode(accessToken string, fileSize int64, read RangeReadFunc) (string, error) {
+	r := &rangeReaderAt{
+		size: fileSize,
+		read: read,
+	}
+	proofCode := CalcProofCode(accessToken, r, fileSize)
+	return proofCode, r.err
+}
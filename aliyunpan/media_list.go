@@ -0,0 +1,169 @@
+package aliyunpan
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+const (
+	// DefaultMediaListConcurrency 同时获取视频播放信息的默认数量
+	DefaultMediaListConcurrency = 4
+)
+
+var (
+	// DefaultSubtitleExtensions 默认识别的字幕文件后缀名
+	DefaultSubtitleExtensions = []string{"srt", "ass", "vtt"}
+
+	// videoExtensions 文件没有分类信息时按照后缀名识别视频
+	videoExtensions = map[string]bool{
+		"mp4": true, "mkv": true, "mov": true, "avi": true, "wmv": true, "flv": true,
+		"webm": true, "m4v": true, "ts": true, "rmvb": true, "rm": true, "mpg": true, "mpeg": true,
+	}
+)
+
+type (
+	// MediaListParam 获取文件夹媒体列表参数
+	MediaListParam struct {
+		DriveId      string
+		ParentFileId string
+		// WithPlayInfo 同时获取视频的时长和分辨率。需要对每个视频调用 VideoGetPreviewPlayInfo，该接口会触发视频云端转码，默认不获取
+		WithPlayInfo bool
+		// Concurrency 同时获取视频播放信息的数量，为空默认 DefaultMediaListConcurrency
+		Concurrency int
+		// SubtitleExtensions 字幕文件后缀名，为空默认 DefaultSubtitleExtensions
+		SubtitleExtensions []string
+	}
+
+	// MediaItem 视频文件以及播放信息
+	MediaItem struct {
+		File *FileEntity
+		// Duration 时长，单位：秒，只有开启 WithPlayInfo 才有，Width、Height 同理
+		Duration float64
+		Width    int
+		Height   int
+		// Subtitles 同一个文件夹中文件名匹配的字幕文件，例如 movie.mp4 对应 movie.srt、movie.zh.ass
+		Subtitles FileList
+		// Err 获取播放信息失败的错误，不影响其他文件
+		Err *apierror.ApiError
+	}
+
+	// MediaList 媒体列表
+	MediaList []*MediaItem
+
+	// MediaListOperator 获取媒体列表需要的网盘操作
+	MediaListOperator interface {
+		FileListGetAll(param *FileListParam, delayMilliseconds int) (FileList, *apierror.ApiError)
+		VideoGetPreviewPlayInfo(param *VideoGetPreviewPlayInfoParam) (*VideoGetPreviewPlayInfoResult, error)
+	}
+)
+
+// IsVideo 是否是视频文件，优先使用文件分类，没有分类时按照后缀名判断
+func (f *FileEntity) IsVideo() bool {
+	if !f.IsFile() {
+		return false
+	}
+	if f.Category != "" {
+		return f.Category == "video"
+	}
+	return videoExtensions[strings.ToLower(strings.TrimPrefix(path.Ext(f.FileName), "."))]
+}
+
+// ListMedia 获取文件夹中的视频文件和同名字幕文件，开启 WithPlayInfo 时合并视频的时长和分辨率，结果按照文件名排序（不区分大小写）。
+// 单个视频获取播放信息失败时记录在 MediaItem.Err 中，只有获取文件列表失败时才返回错误
+func ListMedia(op MediaListOperator, param *MediaListParam) (MediaList, *apierror.ApiError) {
+	if param == nil || param.DriveId == "" {
+		return nil, apierror.NewFailedApiError("参数不能为空")
+	}
+	parentFileId := param.ParentFileId
+	if parentFileId == "" {
+		parentFileId = DefaultRootParentFileId
+	}
+	fileList, err := op.FileListGetAll(&FileListParam{
+		DriveId:      param.DriveId,
+		ParentFileId: parentFileId,
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	subExts := param.SubtitleExtensions
+	if len(subExts) == 0 {
+		subExts = DefaultSubtitleExtensions
+	}
+	isSubtitle := func(f *FileEntity) bool {
+		ext := strings.TrimPrefix(path.Ext(f.FileName), ".")
+		for _, e := range subExts {
+			if strings.EqualFold(strings.TrimPrefix(e, "."), ext) {
+				return true
+			}
+		}
+		return false
+	}
+
+	items := MediaList{}
+	subtitles := FileList{}
+	for _, f := range fileList {
+		if !f.IsFile() {
+			continue
+		}
+		if isSubtitle(f) {
+			subtitles = append(subtitles, f)
+		} else if f.IsVideo() {
+			items = append(items, &MediaItem{File: f, Subtitles: FileList{}})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return strings.ToLower(items[i].File.FileName) < strings.ToLower(items[j].File.FileName)
+	})
+	for _, item := range items {
+		base := strings.ToLower(strings.TrimSuffix(item.File.FileName, path.Ext(item.File.FileName)))
+		for _, s := range subtitles {
+			if subtitleMatches(base, s.FileName) {
+				item.Subtitles = append(item.Subtitles, s)
+			}
+		}
+	}
+
+	if !param.WithPlayInfo {
+		return items, nil
+	}
+	concurrency := param.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultMediaListConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	for _, item := range items {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(item *MediaItem) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			info, e := op.VideoGetPreviewPlayInfo(&VideoGetPreviewPlayInfoParam{
+				DriveId: item.File.DriveId,
+				FileId:  item.File.FileId,
+			})
+			if e != nil {
+				item.Err = apierror.NewApiErrorWithError(e)
+				return
+			}
+			item.Duration = info.VideoPreviewPlayInfo.Meta.Duration
+			item.Width = info.VideoPreviewPlayInfo.Meta.Width
+			item.Height = info.VideoPreviewPlayInfo.Meta.Height
+		}(item)
+	}
+	wg.Wait()
+	return items, nil
+}
+
+// subtitleMatches 字幕文件名去掉后缀名后与视频文件名相同，或者以视频文件名加 "." 开头（语言标记，例如 movie.zh.srt）
+func subtitleMatches(videoBase, subtitleName string) bool {
+	name := strings.ToLower(strings.TrimSuffix(subtitleName, path.Ext(subtitleName)))
+	return name == videoBase || strings.HasPrefix(name, videoBase+".")
+}
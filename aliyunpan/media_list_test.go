@@ -0,0 +1,67 @@
+package aliyunpan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+// mediaListStub 在 stubDrive 上返回视频播放信息，failPlayInfo 中的文件返回错误
+type mediaListStub struct {
+	*stubDrive
+	failPlayInfo  map[string]bool
+	playInfoCalls int
+}
+
+func (s *mediaListStub) VideoGetPreviewPlayInfo(param *VideoGetPreviewPlayInfoParam) (*VideoGetPreviewPlayInfoResult, error) {
+	s.playInfoCalls++
+	if s.failPlayInfo[param.FileId] {
+		return nil, apierror.NewApiError(apierror.ApiCodeFileNotFoundCode, "not found")
+	}
+	r := &VideoGetPreviewPlayInfoResult{DriveId: param.DriveId, FileId: param.FileId}
+	r.VideoPreviewPlayInfo.Meta.Duration = 12.5
+	r.VideoPreviewPlayInfo.Meta.Width = 1920
+	r.VideoPreviewPlayInfo.Meta.Height = 1080
+	return r, nil
+}
+
+func newMediaListStub() *mediaListStub {
+	s := &mediaListStub{stubDrive: newStubDrive(), failPlayInfo: map[string]bool{}}
+	s.add("v1", DefaultRootParentFileId, "b.MP4", 10, "V1", "")
+	s.add("v2", DefaultRootParentFileId, "A.mkv", 10, "V2", "")
+	s.add("s1", DefaultRootParentFileId, "a.en.srt", 1, "S1", "")
+	s.add("s2", DefaultRootParentFileId, "ab.srt", 1, "S2", "")
+	s.add("t1", DefaultRootParentFileId, "b.txt", 1, "T1", "")
+	return s
+}
+
+func TestListMediaSubtitles(t *testing.T) {
+	s := newMediaListStub()
+	items, err := ListMedia(s, &MediaListParam{DriveId: "d"})
+	require.Nil(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "A.mkv", items[0].File.FileName)
+	require.Len(t, items[0].Subtitles, 1)
+	assert.Equal(t, "a.en.srt", items[0].Subtitles[0].FileName)
+	assert.Empty(t, items[1].Subtitles)
+	// 默认不获取播放信息
+	assert.Equal(t, 0, s.playInfoCalls)
+	assert.Equal(t, 0, items[0].Width)
+}
+
+func TestListMediaPlayInfo(t *testing.T) {
+	s := newMediaListStub()
+	s.failPlayInfo["v1"] = true
+	items, err := ListMedia(s, &MediaListParam{DriveId: "d", WithPlayInfo: true, Concurrency: 1})
+	require.Nil(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, 2, s.playInfoCalls)
+	assert.Equal(t, 12.5, items[0].Duration)
+	assert.Equal(t, 1920, items[0].Width)
+	assert.Nil(t, items[0].Err)
+	// 单个视频失败不影响其他视频，错误保留原来的错误码
+	require.NotNil(t, items[1].Err)
+	assert.Equal(t, apierror.ApiCodeFileNotFoundCode, items[1].Err.Code)
+}
@@ -0,0 +1,263 @@
+package metacache
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+type (
+	// FetchFileByIdFunc 通过文件ID从服务器获取文件信息，用于校验缓存是否有效
+	FetchFileByIdFunc func(driveId, fileId string) (*aliyunpan.FileEntity, *apierror.ApiError)
+
+	// FetchFileListFunc 从服务器获取目录下的所有文件
+	FetchFileListFunc func() (aliyunpan.FileList, *apierror.ApiError)
+
+	// Cache 可跨进程共享的文件元数据缓存。
+	// 缓存项在 maxAge 时长内直接使用；路径缓存过期后逐级向服务器校验文件以及所有上级目录的文件名和父目录，
+	// 目录文件列表缓存过期后先获取目录自身的信息，目录的修改时间没有变化则继续使用缓存的列表，否则重新获取。
+	// 客户端修改文件后需要调用 Invalidate/InvalidateDir 删除相关缓存。
+	// 缩略图、预览图等签名地址会过期，不会缓存，从缓存返回的文件信息中这些字段为空
+	Cache struct {
+		store  Store
+		maxAge time.Duration
+	}
+)
+
+// NewCache 创建元数据缓存，maxAge为0代表每次使用缓存前都需要向服务器校验
+func NewCache(store Store, maxAge time.Duration) *Cache {
+	return &Cache{
+		store:  store,
+		maxAge: maxAge,
+	}
+}
+
+// Store 获取底层存储
+func (c *Cache) Store() Store {
+	return c.store
+}
+
+// Flush 将缓存写入持久化存储
+func (c *Cache) Flush() error {
+	return c.store.Flush()
+}
+
+// Close 写入并关闭持久化存储
+func (c *Cache) Close() error {
+	return c.store.Close()
+}
+
+func (c *Cache) isFresh(cachedAt int64) bool {
+	if c.maxAge <= 0 {
+		return false
+	}
+	return time.Now().Unix()-cachedAt < int64(c.maxAge/time.Second)
+}
+
+// LookupPathFresh 获取有效期内的路径缓存，不会向服务器校验
+func (c *Cache) LookupPathFresh(driveId, pathStr string) *aliyunpan.FileEntity {
+	pathStr = formatPathStyle(pathStr)
+	record := c.freshRecord(driveId, pathStr)
+	if record == nil {
+		return nil
+	}
+	f := cloneFileEntity(record.File)
+	f.Path = pathStr
+	return f
+}
+
+// freshRecord 获取有效期内的路径缓存项
+func (c *Cache) freshRecord(driveId, pathStr string) *FileRecord {
+	fileId := c.store.GetFileIdByPath(driveId, pathStr)
+	if fileId == "" {
+		return nil
+	}
+	record := c.store.GetFile(driveId, fileId)
+	if record == nil || record.File == nil || !c.isFresh(record.CachedAt) {
+		return nil
+	}
+	return record
+}
+
+// LookupPath 获取路径缓存，缓存过期则通过 fetchById 从文件开始逐级向上校验文件名和父目录，
+// 直到遇到有效期内的上级目录缓存或者根目录，任意一级校验失败都会删除缓存并返回nil
+func (c *Cache) LookupPath(driveId, pathStr string, fetchById FetchFileByIdFunc) *aliyunpan.FileEntity {
+	pathStr = formatPathStyle(pathStr)
+	if record := c.freshRecord(driveId, pathStr); record != nil {
+		f := cloneFileEntity(record.File)
+		f.Path = pathStr
+		return f
+	}
+	fileId := c.store.GetFileIdByPath(driveId, pathStr)
+	if fileId == "" || fetchById == nil {
+		return nil
+	}
+
+	// revalidate the whole ancestor chain
+	var leaf *aliyunpan.FileEntity
+	expectedId := fileId
+	curPath := pathStr
+	for {
+		f, err := fetchById(driveId, expectedId)
+		if err != nil || f == nil || f.FileId != expectedId || f.FileName != path.Base(curPath) {
+			c.store.DeleteFile(driveId, expectedId)
+			if expectedId != fileId {
+				c.store.DeleteFile(driveId, fileId)
+			}
+			return nil
+		}
+		f.Path = curPath
+		c.store.PutFile(driveId, curPath, &FileRecord{
+			CachedAt: time.Now().Unix(),
+			File:     cloneFileEntity(f),
+		})
+		if leaf == nil {
+			leaf = f
+		}
+
+		parentPath := path.Dir(curPath)
+		if parentPath == "/" {
+			if f.ParentFileId != aliyunpan.DefaultRootParentFileId {
+				c.store.DeleteFile(driveId, fileId)
+				return nil
+			}
+			return leaf
+		}
+		if parent := c.freshRecord(driveId, parentPath); parent != nil {
+			if parent.File.FileId == f.ParentFileId {
+				return leaf
+			}
+			c.store.DeleteFile(driveId, fileId)
+			return nil
+		}
+		expectedId = f.ParentFileId
+		curPath = parentPath
+	}
+}
+
+// PutPath 存储路径缓存
+func (c *Cache) PutPath(driveId, pathStr string, fileEntity *aliyunpan.FileEntity) {
+	if fileEntity == nil || fileEntity.FileId == "" || fileEntity.IsDriveRootFolder() {
+		return
+	}
+	pathStr = formatPathStyle(pathStr)
+	f := cloneFileEntity(fileEntity)
+	f.Path = pathStr
+	c.store.PutFile(driveId, pathStr, &FileRecord{
+		CachedAt: time.Now().Unix(),
+		File:     f,
+	})
+}
+
+// Invalidate 删除文件、文件所在目录的文件列表以及文件夹自身的文件列表缓存，文件夹下所有子路径的缓存也会失效。
+// 文件被删除、移动、重命名后调用
+func (c *Cache) Invalidate(driveId, fileId string) {
+	if record := c.store.GetFile(driveId, fileId); record != nil && record.File != nil {
+		c.store.DeleteDirListings(driveId, record.File.ParentFileId)
+	}
+	c.store.DeleteDirListings(driveId, fileId)
+	c.store.DeleteFile(driveId, fileId)
+}
+
+// InvalidateDir 删除目录的文件列表缓存，目录中新建、复制或者移入文件后调用
+func (c *Cache) InvalidateDir(driveId, parentFileId string) {
+	if parentFileId == "" {
+		parentFileId = aliyunpan.DefaultRootParentFileId
+	}
+	c.store.DeleteDirListings(driveId, parentFileId)
+}
+
+// FileListGetAll 获取目录下所有文件，有效期内的缓存直接使用。缓存过期后通过 fetchById 获取目录信息，
+// 目录的修改时间和缓存时一致则继续使用缓存并刷新缓存时间，否则调用 fetchAll 重新获取并缓存。
+// fetchById 为nil或者是根目录时（根目录没有文件信息）过期后直接重新获取。
+// 目录下的文件信息也会单独缓存，以便修改文件时通过 Invalidate 找到所在目录
+func (c *Cache) FileListGetAll(param *aliyunpan.FileListParam, fetchById FetchFileByIdFunc, fetchAll FetchFileListFunc) (aliyunpan.FileList, *apierror.ApiError) {
+	if param.Marker != "" {
+		// 分页中途的请求不缓存
+		return fetchAll()
+	}
+	parentFileId := param.ParentFileId
+	if parentFileId == "" {
+		parentFileId = aliyunpan.DefaultRootParentFileId
+	}
+	orderKey := dirListingOrderKey(param)
+
+	listing := c.store.GetDirListing(param.DriveId, parentFileId, orderKey)
+	if listing != nil && c.isFresh(listing.CachedAt) {
+		return cloneFileList(listing.Items), nil
+	}
+
+	// 获取列表之前先获取目录的修改时间，列表获取期间目录发生变化时下次校验会重新获取
+	folderUpdatedAt := ""
+	if fetchById != nil && parentFileId != aliyunpan.DefaultRootParentFileId {
+		if folder, err := fetchById(param.DriveId, parentFileId); err == nil && folder != nil {
+			folderUpdatedAt = folder.UpdatedAt
+		}
+	}
+	if listing != nil && folderUpdatedAt != "" && listing.FolderUpdatedAt == folderUpdatedAt {
+		listing.CachedAt = time.Now().Unix()
+		c.store.PutDirListing(param.DriveId, parentFileId, orderKey, listing)
+		return cloneFileList(listing.Items), nil
+	}
+
+	fileList, err := fetchAll()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	for _, f := range fileList {
+		if f == nil || f.FileId == "" {
+			continue
+		}
+		if record := c.store.GetFile(param.DriveId, f.FileId); record != nil && record.File != nil &&
+			record.File.ParentFileId == f.ParentFileId && record.File.FileName == f.FileName {
+			// 已经存在的路径映射仍然有效，保留路径
+			item := cloneFileEntity(f)
+			item.Path = record.File.Path
+			c.store.PutFile(param.DriveId, "", &FileRecord{CachedAt: now, File: item})
+			continue
+		}
+		c.store.DeleteFile(param.DriveId, f.FileId)
+		c.store.PutFile(param.DriveId, "", &FileRecord{CachedAt: now, File: cloneFileEntity(f)})
+	}
+	c.store.PutDirListing(param.DriveId, parentFileId, orderKey, &DirListing{
+		CachedAt:        now,
+		Items:           cloneFileList(fileList),
+		FolderUpdatedAt: folderUpdatedAt,
+	})
+	return fileList, nil
+}
+
+func dirListingOrderKey(param *aliyunpan.FileListParam) string {
+	return string(param.OrderBy) + "|" + string(param.OrderDirection)
+}
+
+// cloneFileEntity 复制文件信息，去掉会过期的缩略图和预览图签名地址，缓存中不保存这些地址
+func cloneFileEntity(f *aliyunpan.FileEntity) *aliyunpan.FileEntity {
+	c := *f
+	c.Thumbnail = ""
+	c.Url = ""
+	return &c
+}
+
+func cloneFileList(fl aliyunpan.FileList) aliyunpan.FileList {
+	r := make(aliyunpan.FileList, 0, len(fl))
+	for _, f := range fl {
+		if f == nil {
+			continue
+		}
+		r = append(r, cloneFileEntity(f))
+	}
+	return r
+}
+
+func formatPathStyle(pathStr string) string {
+	pathStr = strings.ReplaceAll(pathStr, "\\", "/")
+	if pathStr != "/" {
+		pathStr = strings.TrimSuffix(pathStr, "/")
+	}
+	return pathStr
+}
@@ -0,0 +1,210 @@
+package metacache
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+func TestJsonFileStorePersist(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "meta.json")
+	store, err := NewJsonFileStore(cacheFile)
+	assert.NoError(t, err)
+	c := NewCache(store, time.Hour)
+	c.PutPath("d1", "/a/b.txt", &aliyunpan.FileEntity{FileId: "f1", FileName: "b.txt", ParentFileId: "p1"})
+	assert.NoError(t, c.Close())
+
+	store2, err := NewJsonFileStore(cacheFile)
+	assert.NoError(t, err)
+	f := NewCache(store2, time.Hour).LookupPathFresh("d1", "/a/b.txt")
+	assert.NotNil(t, f)
+	assert.Equal(t, "f1", f.FileId)
+}
+
+func TestCacheStripsSignedUrls(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "meta.json")
+	store, _ := NewJsonFileStore(cacheFile)
+	c := NewCache(store, time.Hour)
+	c.PutPath("d1", "/a.jpg", &aliyunpan.FileEntity{FileId: "f1", FileName: "a.jpg", ParentFileId: "root",
+		Thumbnail: "https://thumb/a?x-oss-signature=1", Url: "https://url/a?x-oss-signature=1"})
+	fetchAll := func() (aliyunpan.FileList, *apierror.ApiError) {
+		return aliyunpan.FileList{{FileId: "f1", FileName: "a.jpg", ParentFileId: "root", Thumbnail: "https://thumb/a"}}, nil
+	}
+	c.FileListGetAll(&aliyunpan.FileListParam{DriveId: "d1"}, nil, fetchAll)
+	assert.NoError(t, c.Flush())
+
+	content, _ := ioutil.ReadFile(cacheFile)
+	assert.NotContains(t, string(content), "https://")
+	f := c.LookupPathFresh("d1", "/a.jpg")
+	assert.Equal(t, "", f.Thumbnail)
+	assert.Equal(t, "", f.Url)
+}
+
+func TestJsonFileStoreFlushMerge(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "meta.json")
+	store1, err := NewJsonFileStore(cacheFile)
+	assert.NoError(t, err)
+	store2, err := NewJsonFileStore(cacheFile)
+	assert.NoError(t, err)
+
+	// two processes sharing the same file must not overwrite each other
+	NewCache(store1, time.Hour).PutPath("d1", "/a", &aliyunpan.FileEntity{FileId: "f1", FileName: "a", ParentFileId: "root"})
+	NewCache(store2, time.Hour).PutPath("d1", "/b", &aliyunpan.FileEntity{FileId: "f2", FileName: "b", ParentFileId: "root"})
+	assert.NoError(t, store1.Flush())
+	assert.NoError(t, store2.Flush())
+	assert.Equal(t, "f1", store2.GetFileIdByPath("d1", "/a"))
+
+	store3, err := NewJsonFileStore(cacheFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "f1", store3.GetFileIdByPath("d1", "/a"))
+	assert.Equal(t, "f2", store3.GetFileIdByPath("d1", "/b"))
+
+	// deletes are merged too
+	store1.DeleteFile("d1", "f2")
+	assert.NoError(t, store1.Flush())
+	store4, _ := NewJsonFileStore(cacheFile)
+	assert.Equal(t, "", store4.GetFileIdByPath("d1", "/b"))
+	assert.Equal(t, "f1", store4.GetFileIdByPath("d1", "/a"))
+}
+
+func TestCacheRevalidate(t *testing.T) {
+	store, _ := NewJsonFileStore("")
+	c := NewCache(store, 0)
+	c.PutPath("d1", "/a", &aliyunpan.FileEntity{FileId: "f1", FileName: "a", ParentFileId: "root"})
+
+	// renamed on server
+	f := c.LookupPath("d1", "/a", func(driveId, fileId string) (*aliyunpan.FileEntity, *apierror.ApiError) {
+		return &aliyunpan.FileEntity{FileId: "f1", FileName: "a2", ParentFileId: "root"}, nil
+	})
+	assert.Nil(t, f)
+	assert.Nil(t, store.GetFile("d1", "f1"))
+
+	// directory listing is refetched once expired
+	fetchCount := 0
+	fetchAll := func() (aliyunpan.FileList, *apierror.ApiError) {
+		fetchCount++
+		return aliyunpan.FileList{{FileId: "x", ParentFileId: "dir1"}}, nil
+	}
+	param := &aliyunpan.FileListParam{DriveId: "d1", ParentFileId: "dir1"}
+	c.FileListGetAll(param, nil, fetchAll)
+	fl, _ := c.FileListGetAll(param, nil, fetchAll)
+	assert.Equal(t, 2, fetchCount)
+	assert.Equal(t, 1, len(fl))
+}
+
+func TestCacheRevalidateAncestors(t *testing.T) {
+	store, _ := NewJsonFileStore("")
+	c := NewCache(store, 0)
+	c.PutPath("d1", "/a", &aliyunpan.FileEntity{FileId: "fa", FileName: "a", ParentFileId: "root"})
+	c.PutPath("d1", "/a/b", &aliyunpan.FileEntity{FileId: "fb", FileName: "b", ParentFileId: "fa"})
+	c.PutPath("d1", "/a/b/c.txt", &aliyunpan.FileEntity{FileId: "fc", FileName: "c.txt", ParentFileId: "fb"})
+
+	server := map[string]*aliyunpan.FileEntity{
+		"fa": {FileId: "fa", FileName: "a", ParentFileId: "root"},
+		"fb": {FileId: "fb", FileName: "b", ParentFileId: "fa"},
+		"fc": {FileId: "fc", FileName: "c.txt", ParentFileId: "fb"},
+	}
+	fetchById := func(driveId, fileId string) (*aliyunpan.FileEntity, *apierror.ApiError) {
+		if f, ok := server[fileId]; ok {
+			r := *f
+			return &r, nil
+		}
+		return nil, apierror.NewFailedApiError("not found")
+	}
+	f := c.LookupPath("d1", "/a/b/c.txt", fetchById)
+	assert.NotNil(t, f)
+	assert.Equal(t, "fc", f.FileId)
+
+	// the leaf is unchanged but its ancestor was renamed
+	server["fa"].FileName = "a2"
+	assert.Nil(t, c.LookupPath("d1", "/a/b/c.txt", fetchById))
+	assert.Equal(t, "", store.GetFileIdByPath("d1", "/a/b/c.txt"))
+
+	// a fresh ancestor record stops the walk
+	c2 := NewCache(store, time.Hour)
+	c2.PutPath("d1", "/x", &aliyunpan.FileEntity{FileId: "fx", FileName: "x", ParentFileId: "root"})
+	store.PutFile("d1", "/x/y", &FileRecord{CachedAt: 0, File: &aliyunpan.FileEntity{FileId: "fy", FileName: "y", ParentFileId: "fx"}})
+	server["fy"] = &aliyunpan.FileEntity{FileId: "fy", FileName: "y", ParentFileId: "fx"}
+	assert.NotNil(t, c2.LookupPath("d1", "/x/y", fetchById))
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	store, _ := NewJsonFileStore("")
+	c := NewCache(store, time.Hour)
+	fetchCount := 0
+	fetchAll := func() (aliyunpan.FileList, *apierror.ApiError) {
+		fetchCount++
+		return aliyunpan.FileList{{FileId: "f1", FileName: "a", ParentFileId: "dir1"}}, nil
+	}
+	param := &aliyunpan.FileListParam{DriveId: "d1", ParentFileId: "dir1"}
+	c.PutPath("d1", "/dir1/a", &aliyunpan.FileEntity{FileId: "f1", FileName: "a", ParentFileId: "dir1"})
+	c.PutPath("d1", "/dir1/a/b", &aliyunpan.FileEntity{FileId: "f2", FileName: "b", ParentFileId: "f1"})
+	c.FileListGetAll(param, nil, fetchAll)
+	c.FileListGetAll(param, nil, fetchAll)
+	assert.Equal(t, 1, fetchCount)
+	// listing keeps the path mapping of its items
+	assert.Equal(t, "f1", store.GetFileIdByPath("d1", "/dir1/a"))
+
+	// moving a folder drops its listing, its parent listing and all descendant paths
+	c.Invalidate("d1", "f1")
+	assert.Equal(t, "", store.GetFileIdByPath("d1", "/dir1/a"))
+	assert.Equal(t, "", store.GetFileIdByPath("d1", "/dir1/a/b"))
+	c.FileListGetAll(param, nil, fetchAll)
+	assert.Equal(t, 2, fetchCount)
+
+	c.InvalidateDir("d1", "dir1")
+	c.FileListGetAll(param, nil, fetchAll)
+	assert.Equal(t, 3, fetchCount)
+}
+
+func TestCacheRevalidateDirListing(t *testing.T) {
+	store, _ := NewJsonFileStore("")
+	c := NewCache(store, 0)
+	folder := &aliyunpan.FileEntity{FileId: "dir1", FileName: "dir1", ParentFileId: "root", UpdatedAt: "2023-01-01 08:00:00"}
+	getCount := 0
+	fetchById := func(driveId, fileId string) (*aliyunpan.FileEntity, *apierror.ApiError) {
+		getCount++
+		r := *folder
+		return &r, nil
+	}
+	fetchCount := 0
+	fetchAll := func() (aliyunpan.FileList, *apierror.ApiError) {
+		fetchCount++
+		return aliyunpan.FileList{{FileId: "x", FileName: "x", ParentFileId: "dir1"}}, nil
+	}
+	param := &aliyunpan.FileListParam{DriveId: "d1", ParentFileId: "dir1"}
+	c.FileListGetAll(param, fetchById, fetchAll)
+	assert.Equal(t, 1, fetchCount)
+
+	// the folder is unchanged, the expired listing is kept
+	fl, err := c.FileListGetAll(param, fetchById, fetchAll)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(fl))
+	assert.Equal(t, 1, fetchCount)
+	assert.Equal(t, 2, getCount)
+
+	// the folder was modified, the listing is refetched
+	folder.UpdatedAt = "2023-01-02 08:00:00"
+	c.FileListGetAll(param, fetchById, fetchAll)
+	assert.Equal(t, 2, fetchCount)
+
+	// the folder info is unavailable, the listing is refetched
+	fetchFail := func(driveId, fileId string) (*aliyunpan.FileEntity, *apierror.ApiError) {
+		return nil, apierror.NewFailedApiError("failed")
+	}
+	c.FileListGetAll(param, fetchFail, fetchAll)
+	assert.Equal(t, 3, fetchCount)
+
+	// the root folder has no file info and is always refetched
+	rootParam := &aliyunpan.FileListParam{DriveId: "d1", ParentFileId: aliyunpan.DefaultRootParentFileId}
+	getCount = 0
+	c.FileListGetAll(rootParam, fetchById, fetchAll)
+	c.FileListGetAll(rootParam, fetchById, fetchAll)
+	assert.Equal(t, 5, fetchCount)
+	assert.Equal(t, 0, getCount)
+}
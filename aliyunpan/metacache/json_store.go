@@ -0,0 +1,282 @@
+package metacache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// jsonStoreLockTimeout 等待其他进程释放缓存文件锁的最长时间
+	jsonStoreLockTimeout = 10 * time.Second
+	// jsonStoreLockStale 锁文件超过该时长没有释放，认为持有锁的进程已经异常退出
+	jsonStoreLockStale = 30 * time.Second
+)
+
+type (
+	// driveData 单个网盘的缓存数据
+	driveData struct {
+		Files map[string]*FileRecord `json:"files"`
+		Paths map[string]string      `json:"paths"`
+		// Dirs 目录ID -> 排序方式 -> 文件列表
+		Dirs map[string]map[string]*DirListing `json:"dirs"`
+	}
+
+	// storeData 所有网盘的缓存数据
+	storeData map[string]*driveData
+
+	// storeOp 对缓存数据的修改，Flush 时在最新的文件内容上重放
+	storeOp func(data storeData)
+
+	// JsonFileStore 基于本地JSON文件的元数据存储，数据常驻内存，Flush/Close 时写入文件。
+	// Flush 时会加文件锁并重新读取文件，把本进程的修改合并到其他进程已经写入的数据上，多个进程可以共享同一个缓存文件
+	JsonFileStore struct {
+		filePath string
+		mutex    sync.RWMutex
+		data     storeData
+		// journal 上次 Flush 之后的修改
+		journal []storeOp
+	}
+)
+
+// NewJsonFileStore 创建基于本地JSON文件的元数据存储，filePath为空则只缓存在内存中
+func NewJsonFileStore(filePath string) (*JsonFileStore, error) {
+	s := &JsonFileStore{
+		filePath: filePath,
+		data:     storeData{},
+	}
+	if filePath == "" {
+		return s, nil
+	}
+	data, err := readStoreData(filePath)
+	if err != nil {
+		return nil, err
+	}
+	s.data = data
+	return s, nil
+}
+
+// readStoreData 读取缓存文件，文件不存在或者损坏时返回空数据
+func readStoreData(filePath string) (storeData, error) {
+	data := storeData{}
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return data, nil
+		}
+		return nil, err
+	}
+	if len(content) == 0 {
+		return data, nil
+	}
+	if err = json.Unmarshal(content, &data); err != nil {
+		// 缓存文件损坏，直接丢弃
+		return storeData{}, nil
+	}
+	return data, nil
+}
+
+func (d storeData) drive(driveId string) *driveData {
+	dd, ok := d[driveId]
+	if !ok || dd == nil {
+		dd = &driveData{}
+		d[driveId] = dd
+	}
+	if dd.Files == nil {
+		dd.Files = map[string]*FileRecord{}
+	}
+	if dd.Paths == nil {
+		dd.Paths = map[string]string{}
+	}
+	if dd.Dirs == nil {
+		dd.Dirs = map[string]map[string]*DirListing{}
+	}
+	return dd
+}
+
+// apply 修改内存中的数据并记录下来，调用方需要持有写锁
+func (s *JsonFileStore) apply(op storeOp) {
+	op(s.data)
+	if s.filePath != "" {
+		s.journal = append(s.journal, op)
+	}
+}
+
+// GetFile 通过文件ID获取缓存的文件信息
+func (s *JsonFileStore) GetFile(driveId, fileId string) *FileRecord {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if d, ok := s.data[driveId]; ok && d != nil {
+		return d.Files[fileId]
+	}
+	return nil
+}
+
+// GetFileIdByPath 通过文件路径获取缓存的文件ID
+func (s *JsonFileStore) GetFileIdByPath(driveId, pathStr string) string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if d, ok := s.data[driveId]; ok && d != nil {
+		return d.Paths[pathStr]
+	}
+	return ""
+}
+
+// PutFile 存储文件信息，pathStr不为空则同时存储路径映射
+func (s *JsonFileStore) PutFile(driveId, pathStr string, record *FileRecord) {
+	if record == nil || record.File == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.apply(func(data storeData) {
+		d := data.drive(driveId)
+		d.Files[record.File.FileId] = record
+		if pathStr != "" {
+			d.Paths[pathStr] = record.File.FileId
+		}
+	})
+}
+
+// DeleteFile 删除文件信息、对应的路径映射以及这些路径下所有子路径的映射
+func (s *JsonFileStore) DeleteFile(driveId, fileId string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.apply(func(data storeData) {
+		d, ok := data[driveId]
+		if !ok || d == nil {
+			return
+		}
+		delete(d.Files, fileId)
+		prefixes := []string{}
+		for p, id := range d.Paths {
+			if id == fileId {
+				delete(d.Paths, p)
+				prefixes = append(prefixes, strings.TrimSuffix(p, "/")+"/")
+			}
+		}
+		if len(prefixes) == 0 {
+			return
+		}
+		for p := range d.Paths {
+			for _, prefix := range prefixes {
+				if strings.HasPrefix(p, prefix) {
+					delete(d.Paths, p)
+					break
+				}
+			}
+		}
+	})
+}
+
+// GetDirListing 获取目录文件列表
+func (s *JsonFileStore) GetDirListing(driveId, parentFileId, orderKey string) *DirListing {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if d, ok := s.data[driveId]; ok && d != nil {
+		return d.Dirs[parentFileId][orderKey]
+	}
+	return nil
+}
+
+// PutDirListing 存储目录文件列表
+func (s *JsonFileStore) PutDirListing(driveId, parentFileId, orderKey string, listing *DirListing) {
+	if listing == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.apply(func(data storeData) {
+		d := data.drive(driveId)
+		if d.Dirs[parentFileId] == nil {
+			d.Dirs[parentFileId] = map[string]*DirListing{}
+		}
+		d.Dirs[parentFileId][orderKey] = listing
+	})
+}
+
+// DeleteDirListings 删除目录所有排序方式的文件列表
+func (s *JsonFileStore) DeleteDirListings(driveId, parentFileId string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.apply(func(data storeData) {
+		if d, ok := data[driveId]; ok && d != nil {
+			delete(d.Dirs, parentFileId)
+		}
+	})
+}
+
+// Flush 将数据写入文件。加文件锁后重新读取文件，在其他进程写入的数据上重放本进程的修改，
+// 先写临时文件再重命名，避免进程中断导致缓存文件损坏
+func (s *JsonFileStore) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.filePath == "" || len(s.journal) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.filePath), 0755); err != nil {
+		return err
+	}
+	unlock, err := lockFile(s.filePath + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	merged, err := readStoreData(s.filePath)
+	if err != nil {
+		return err
+	}
+	for _, op := range s.journal {
+		op(merged)
+	}
+	content, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	tmpFile := fmt.Sprintf("%s.%d.tmp", s.filePath, os.Getpid())
+	if err = ioutil.WriteFile(tmpFile, content, 0600); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpFile, s.filePath); err != nil {
+		os.Remove(tmpFile)
+		return err
+	}
+	s.data = merged
+	s.journal = nil
+	return nil
+}
+
+// Close 写入数据并关闭存储
+func (s *JsonFileStore) Close() error {
+	return s.Flush()
+}
+
+// lockFile 通过独占创建锁文件实现跨进程的互斥锁，返回释放锁的函数。锁文件过期时认为持有者已经退出
+func lockFile(lockPath string) (func(), error) {
+	deadline := time.Now().Add(jsonStoreLockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			fmt.Fprintf(f, "%d", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, e := os.Stat(lockPath); e == nil && time.Since(info.ModTime()) > jsonStoreLockStale {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("metacache: wait for lock file %s timeout", lockPath)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
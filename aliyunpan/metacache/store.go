@@ -0,0 +1,48 @@
+package metacache
+
+import (
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+type (
+	// DirListing 目录文件列表缓存项
+	DirListing struct {
+		// CachedAt 缓存时间，Unix时间戳，单位秒
+		CachedAt int64 `json:"cachedAt"`
+		// Items 目录下的文件列表
+		Items aliyunpan.FileList `json:"items"`
+		// FolderUpdatedAt 获取列表时目录自身的修改时间，缓存过期后用于校验列表是否有变化，为空代表无法校验
+		FolderUpdatedAt string `json:"folderUpdatedAt,omitempty"`
+	}
+
+	// FileRecord 文件信息缓存项
+	FileRecord struct {
+		// CachedAt 缓存时间，Unix时间戳，单位秒
+		CachedAt int64 `json:"cachedAt"`
+		// File 文件信息
+		File *aliyunpan.FileEntity `json:"file"`
+	}
+
+	// Store 元数据持久化存储接口，可以基于本地文件、bbolt、SQLite等实现。
+	// 多个进程共享同一个存储时，Flush 需要和其他进程已经写入的数据合并，不能直接覆盖
+	Store interface {
+		// GetFile 通过文件ID获取缓存的文件信息
+		GetFile(driveId, fileId string) *FileRecord
+		// GetFileIdByPath 通过文件路径获取缓存的文件ID
+		GetFileIdByPath(driveId, pathStr string) string
+		// PutFile 存储文件信息，pathStr不为空则同时存储路径映射
+		PutFile(driveId, pathStr string, record *FileRecord)
+		// DeleteFile 删除文件信息、对应的路径映射以及这些路径下所有子路径的映射
+		DeleteFile(driveId, fileId string)
+		// GetDirListing 获取目录文件列表，orderKey 为排序方式
+		GetDirListing(driveId, parentFileId, orderKey string) *DirListing
+		// PutDirListing 存储目录文件列表
+		PutDirListing(driveId, parentFileId, orderKey string, listing *DirListing)
+		// DeleteDirListings 删除目录所有排序方式的文件列表
+		DeleteDirListings(driveId, parentFileId string)
+		// Flush 将数据写入持久化存储
+		Flush() error
+		// Close 关闭存储
+		Close() error
+	}
+)
@@ -0,0 +1,602 @@
+package aliyunpan
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+const (
+	// PlanActionFileDelete 删除文件到回收站
+	PlanActionFileDelete PlanAction = "file_delete"
+	// PlanActionRecycleBinFileDelete 回收站彻底删除文件
+	PlanActionRecycleBinFileDelete PlanAction = "recycle_bin_file_delete"
+	// PlanActionRecycleBinFileClear 清空回收站
+	PlanActionRecycleBinFileClear PlanAction = "recycle_bin_file_clear"
+	// PlanActionFileDeleteCompletely 不经回收站彻底删除文件
+	PlanActionFileDeleteCompletely PlanAction = "file_delete_completely"
+	// PlanActionFileMove 移动文件
+	PlanActionFileMove PlanAction = "file_move"
+	// PlanActionShareLinkCancel 取消分享链接
+	PlanActionShareLinkCancel PlanAction = "share_link_cancel"
+)
+
+type (
+	// PlanAction 预演计划中的操作类型
+	PlanAction string
+
+	// PlanTarget 预演计划中受影响的目标，记录的是预演时目标的状态，执行前会用来检查目标是否已经变化
+	PlanTarget struct {
+		// DriveId 网盘ID
+		DriveId string `json:"drive_id"`
+		// FileId 文件ID，取消分享时为空
+		FileId string `json:"file_id"`
+		// FileName 文件名
+		FileName string `json:"file_name"`
+		// FileType 文件类别 folder / file
+		FileType string `json:"file_type"`
+		// Path 文件的完整路径，回收站中的文件可能为空
+		Path string `json:"path"`
+		// FileSize 文件大小，文件夹为0
+		FileSize int64 `json:"file_size"`
+		// FolderSize 记录时文件夹内所有文件（包括子孙文件夹中的文件）的总大小，文件为0
+		FolderSize int64 `json:"folder_size,omitempty"`
+		// ParentFileId 父文件夹ID
+		ParentFileId string `json:"parent_file_id"`
+		// ContentHash 内容Hash值
+		ContentHash string `json:"content_hash"`
+		// UpdatedAt 最后修改时间
+		UpdatedAt string `json:"updated_at"`
+
+		// ToDriveId 移动的目标网盘ID
+		ToDriveId string `json:"to_drive_id,omitempty"`
+		// ToParentFileId 移动的目标文件夹ID
+		ToParentFileId string `json:"to_parent_file_id,omitempty"`
+		// ToPath 移动的目标文件夹路径
+		ToPath string `json:"to_path,omitempty"`
+
+		// ShareId 分享ID，只有取消分享才有
+		ShareId string `json:"share_id,omitempty"`
+		// ShareName 分享名称
+		ShareName string `json:"share_name,omitempty"`
+		// ShareUrl 分享链接
+		ShareUrl string `json:"share_url,omitempty"`
+
+		// Chained 目标在计划中之前的步骤里已经被操作过，记录的是之前的步骤执行之后的预期状态，执行时不比较修改时间
+		Chained bool `json:"chained,omitempty"`
+	}
+
+	// PlanStep 预演计划中的一次调用
+	PlanStep struct {
+		// Action 操作类型
+		Action PlanAction `json:"action"`
+		// DriveId 网盘ID，清空回收站时使用
+		DriveId string `json:"drive_id,omitempty"`
+		// Targets 受影响的目标
+		Targets []*PlanTarget `json:"targets"`
+	}
+
+	// PlanApplyResult 执行计划中单个目标的结果
+	PlanApplyResult struct {
+		// Action 操作类型
+		Action PlanAction `json:"action"`
+		// Target 目标
+		Target *PlanTarget `json:"target"`
+		// Success 是否成功
+		Success bool `json:"success"`
+		// Err 失败原因，目标已经变化时为 apierror.ErrPlanTargetChanged
+		Err *apierror.ApiError `json:"-"`
+	}
+
+	// PlanOperator 记录和执行预演计划需要的网盘操作
+	PlanOperator interface {
+		// FileInfoById 获取文件当前的状态
+		FileInfoById(driveId, fileId string) (*FileEntity, *apierror.ApiError)
+		// FilePathById 获取文件的完整路径
+		FilePathById(driveId, fileId string) (string, *apierror.ApiError)
+		// FileListGetAll 获取目录下的所有文件，用于统计文件夹的大小
+		FileListGetAll(param *FileListParam, delayMilliseconds int) (FileList, *apierror.ApiError)
+		// RecycleBinFiles 获取回收站中的所有文件，不支持回收站的客户端返回错误
+		RecycleBinFiles(driveId string) (FileList, *apierror.ApiError)
+		// ShareLinks 获取当前用户的所有分享
+		ShareLinks() ([]*ShareEntity, *apierror.ApiError)
+		// ApplyPlanStep 执行步骤中检查通过的目标，返回的错误和 targets 一一对应，成功为 nil。
+		// clearAll 为 true 时 targets 就是回收站中的所有文件，可以直接清空回收站
+		ApplyPlanStep(action PlanAction, driveId string, targets []*PlanTarget, clearAll bool) ([]*apierror.ApiError, *apierror.ApiError)
+	}
+
+	// OperationPlan 预演计划。预演模式下破坏性的操作不会被执行，而是记录到计划中，检查无误后再执行
+	OperationPlan struct {
+		mutex sync.Mutex
+		steps []*PlanStep
+		// states 已经记录的步骤执行之后文件的预期状态，key 为 driveId/fileId
+		states map[string]*planFileState
+	}
+
+	planFileState struct {
+		target *PlanTarget
+		// trashed 文件已经被放入回收站
+		trashed bool
+		// removed 文件已经被彻底删除，或者被移动到其他网盘
+		removed bool
+	}
+)
+
+// NewPlanTargetFromFile 根据文件信息创建计划目标
+func NewPlanTargetFromFile(f *FileEntity) *PlanTarget {
+	if f == nil {
+		return nil
+	}
+	return &PlanTarget{
+		DriveId:      f.DriveId,
+		FileId:       f.FileId,
+		FileName:     f.FileName,
+		FileType:     f.FileType,
+		Path:         f.Path,
+		FileSize:     f.FileSize,
+		ParentFileId: f.ParentFileId,
+		ContentHash:  f.ContentHash,
+		UpdatedAt:    f.UpdatedAt,
+	}
+}
+
+// IsFolder 是否是文件夹
+func (t *PlanTarget) IsFolder() bool {
+	return t.FileType == "folder"
+}
+
+// Size 受影响的数据大小，文件为文件大小，文件夹为记录时文件夹内所有文件的总大小
+func (t *PlanTarget) Size() int64 {
+	if t.IsFolder() {
+		return t.FolderSize
+	}
+	return t.FileSize
+}
+
+// ChangedFrom 检查文件当前的状态和预演时是否一致，文件不存在也认为已经变化
+func (t *PlanTarget) ChangedFrom(current *FileEntity) bool {
+	if current == nil {
+		return true
+	}
+	return current.FileId != t.FileId ||
+		current.FileName != t.FileName ||
+		current.ParentFileId != t.ParentFileId ||
+		current.FileSize != t.FileSize ||
+		current.ContentHash != t.ContentHash ||
+		(!t.Chained && current.UpdatedAt != t.UpdatedAt)
+}
+
+// NewPlanTargetChangedError 创建目标已经变化的错误
+func NewPlanTargetChangedError(t *PlanTarget) *apierror.ApiError {
+	name := t.Path
+	if name == "" {
+		name = t.FileName
+	}
+	if name == "" {
+		name = t.ShareId
+	}
+	return apierror.NewApiError(apierror.ApiCodePlanTargetChanged, fmt.Sprintf("目标已经发生变化：%s", name))
+}
+
+// NewOperationPlan 创建空的预演计划
+func NewOperationPlan() *OperationPlan {
+	return &OperationPlan{}
+}
+
+// AddStep 记录一次调用，并更新步骤执行之后文件的预期状态
+func (p *OperationPlan) AddStep(step *PlanStep) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.steps = append(p.steps, step)
+	if p.states == nil {
+		p.states = map[string]*planFileState{}
+	}
+	for _, t := range step.Targets {
+		if t.FileId == "" || step.Action == PlanActionShareLinkCancel {
+			continue
+		}
+		expected := *t
+		expected.Chained = true
+		expected.ToDriveId, expected.ToParentFileId, expected.ToPath = "", "", ""
+		state := &planFileState{target: &expected}
+		switch step.Action {
+		case PlanActionFileDelete:
+			state.trashed = true
+		case PlanActionFileMove:
+			if t.ToDriveId != "" && t.ToDriveId != t.DriveId {
+				// 移动到其他网盘之后文件ID会变化
+				state.removed = true
+			}
+			expected.ParentFileId = t.ToParentFileId
+			if t.ToPath != "" {
+				expected.Path = path.Join(t.ToPath, t.FileName)
+			}
+		default:
+			state.removed = true
+		}
+		p.states[planFileKey(t.DriveId, t.FileId)] = state
+	}
+}
+
+func planFileKey(driveId, fileId string) string {
+	return driveId + "/" + fileId
+}
+
+// expectedState 文件在已经记录的步骤执行之后的预期状态，没有被操作过的文件返回 nil
+func (p *OperationPlan) expectedState(driveId, fileId string) *planFileState {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.states[planFileKey(driveId, fileId)]
+}
+
+// Steps 计划中记录的所有调用，按调用顺序排列
+func (p *OperationPlan) Steps() []*PlanStep {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]*PlanStep{}, p.steps...)
+}
+
+// Len 计划中记录的调用数量
+func (p *OperationPlan) Len() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return len(p.steps)
+}
+
+// Reset 清空计划
+func (p *OperationPlan) Reset() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.steps = nil
+	p.states = nil
+}
+
+// TargetCount 计划中受影响的目标数量
+func (p *OperationPlan) TargetCount() int {
+	count := 0
+	for _, step := range p.Steps() {
+		count += len(step.Targets)
+	}
+	return count
+}
+
+// TotalSize 计划中受影响的文件总大小，文件夹按照记录时文件夹内所有文件的总大小计算
+func (p *OperationPlan) TotalSize() int64 {
+	var size int64
+	for _, step := range p.Steps() {
+		for _, t := range step.Targets {
+			size += t.Size()
+		}
+	}
+	return size
+}
+
+// String 计划的文本描述，每个目标一行
+func (p *OperationPlan) String() string {
+	sb := &strings.Builder{}
+	for _, step := range p.Steps() {
+		if len(step.Targets) == 0 {
+			fmt.Fprintf(sb, "%s drive=%s (empty)\n", step.Action, step.DriveId)
+		}
+		for _, t := range step.Targets {
+			switch step.Action {
+			case PlanActionShareLinkCancel:
+				fmt.Fprintf(sb, "%s %s %s %s\n", step.Action, t.ShareId, t.ShareName, t.ShareUrl)
+			case PlanActionFileMove:
+				fmt.Fprintf(sb, "%s %s -> %s size=%d\n", step.Action, planTargetName(t), t.ToPath, t.Size())
+			default:
+				fmt.Fprintf(sb, "%s %s size=%d\n", step.Action, planTargetName(t), t.Size())
+			}
+		}
+	}
+	return sb.String()
+}
+
+func planTargetName(t *PlanTarget) string {
+	if t.Path != "" {
+		return t.Path
+	}
+	return t.FileName
+}
+
+// RecordFiles 记录对文件的删除、彻底删除、移动，删除时只使用参数中的 DriveId 和 FileId。
+// 文件在计划中之前的步骤里已经被操作过时，目标是之前的步骤执行之后的预期状态；之前的步骤执行之后无法再进行的操作会直接返回错误，
+// 例如移动已经放入回收站的文件
+func (p *OperationPlan) RecordFiles(op PlanOperator, action PlanAction, items []*FileMoveParam) *apierror.ApiError {
+	if items == nil {
+		return apierror.NewFailedApiError("参数不能为空")
+	}
+	step := &PlanStep{Action: action}
+	for _, item := range items {
+		target, err := p.fileTarget(op, action, item.DriveId, item.FileId)
+		if err != nil {
+			return err
+		}
+		if action == PlanActionFileMove {
+			toDriveId := item.ToDriveId
+			if toDriveId == "" {
+				toDriveId = item.DriveId
+			}
+			toPath, err := p.folderPath(op, toDriveId, item.ToParentFileId)
+			if err != nil {
+				return err
+			}
+			target.ToDriveId = toDriveId
+			target.ToParentFileId = item.ToParentFileId
+			target.ToPath = toPath
+		}
+		step.Targets = append(step.Targets, target)
+	}
+	p.AddStep(step)
+	return nil
+}
+
+// RecordRecycleBinClear 记录清空回收站，回收站中当前所有的文件，以及计划中之前的步骤放入回收站的文件都会记录到计划中
+func (p *OperationPlan) RecordRecycleBinClear(op PlanOperator, driveId string) *apierror.ApiError {
+	fileList, err := op.RecycleBinFiles(driveId)
+	if err != nil {
+		return err
+	}
+	step := &PlanStep{
+		Action:  PlanActionRecycleBinFileClear,
+		DriveId: driveId,
+		Targets: []*PlanTarget{},
+	}
+	recorded := map[string]bool{}
+	for _, f := range fileList {
+		target := NewPlanTargetFromFile(f)
+		if target.IsFolder() {
+			// 回收站中的文件夹可能无法获取文件列表，统计失败时按0计算
+			target.FolderSize, _ = planFolderSize(op, driveId, f.FileId)
+		}
+		step.Targets = append(step.Targets, target)
+		recorded[f.FileId] = true
+	}
+	for _, s := range p.Steps() {
+		if s.Action != PlanActionFileDelete {
+			continue
+		}
+		for _, t := range s.Targets {
+			state := p.expectedState(t.DriveId, t.FileId)
+			if t.DriveId != driveId || recorded[t.FileId] || state == nil || !state.trashed {
+				continue
+			}
+			expected := *state.target
+			step.Targets = append(step.Targets, &expected)
+			recorded[t.FileId] = true
+		}
+	}
+	p.AddStep(step)
+	return nil
+}
+
+// RecordShareLinkCancel 记录取消分享链接
+func (p *OperationPlan) RecordShareLinkCancel(op PlanOperator, shareIdList []string) *apierror.ApiError {
+	shares, err := planShareLinkMap(op)
+	if err != nil {
+		return err
+	}
+	step := &PlanStep{Action: PlanActionShareLinkCancel}
+	for _, shareId := range shareIdList {
+		share, ok := shares[shareId]
+		if !ok {
+			return apierror.NewApiError(apierror.ApiCodeFileNotFoundCode, "分享不存在："+shareId)
+		}
+		step.Targets = append(step.Targets, newShareLinkPlanTarget(share))
+	}
+	p.AddStep(step)
+	return nil
+}
+
+// fileTarget 获取文件的状态和完整路径，计划中已经操作过的文件使用预期状态
+func (p *OperationPlan) fileTarget(op PlanOperator, action PlanAction, driveId, fileId string) (*PlanTarget, *apierror.ApiError) {
+	if state := p.expectedState(driveId, fileId); state != nil {
+		name := planTargetName(state.target)
+		switch {
+		case state.removed:
+			return nil, apierror.NewFailedApiError(fmt.Sprintf("文件在计划中已经被删除或者移动到其他网盘：%s", name))
+		case state.trashed && action != PlanActionRecycleBinFileDelete:
+			return nil, apierror.NewFailedApiError(fmt.Sprintf("文件在计划中已经放入回收站：%s", name))
+		case !state.trashed && action == PlanActionRecycleBinFileDelete:
+			return nil, apierror.NewFailedApiError(fmt.Sprintf("文件不在回收站中：%s", name))
+		}
+		expected := *state.target
+		return &expected, nil
+	}
+
+	f, err := op.FileInfoById(driveId, fileId)
+	if err != nil {
+		return nil, err
+	}
+	target := NewPlanTargetFromFile(f)
+	if target.DriveId == "" {
+		target.DriveId = driveId
+	}
+	if pathStr, e := op.FilePathById(driveId, fileId); e == nil {
+		target.Path = pathStr
+	}
+	if target.IsFolder() {
+		size, err := planFolderSize(op, target.DriveId, target.FileId)
+		if err != nil && action != PlanActionRecycleBinFileDelete {
+			return nil, err
+		}
+		// 回收站中的文件夹可能无法获取文件列表，统计失败时按0计算
+		target.FolderSize = size
+	}
+	return target, nil
+}
+
+// planFolderSize 递归统计文件夹内所有文件的总大小
+func planFolderSize(op PlanOperator, driveId, folderId string) (int64, *apierror.ApiError) {
+	fileList, err := op.FileListGetAll(&FileListParam{DriveId: driveId, ParentFileId: folderId}, 0)
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	for _, f := range fileList {
+		if !f.IsFolder() {
+			size += f.FileSize
+			continue
+		}
+		childSize, err := planFolderSize(op, driveId, f.FileId)
+		if err != nil {
+			return 0, err
+		}
+		size += childSize
+	}
+	return size, nil
+}
+
+func (p *OperationPlan) folderPath(op PlanOperator, driveId, fileId string) (string, *apierror.ApiError) {
+	if fileId == "" || fileId == DefaultRootParentFileId {
+		return PathSeparator, nil
+	}
+	if state := p.expectedState(driveId, fileId); state != nil && !state.trashed && !state.removed && state.target.Path != "" {
+		return state.target.Path, nil
+	}
+	return op.FilePathById(driveId, fileId)
+}
+
+// Apply 按记录的顺序执行计划。执行前会重新获取每个目标的状态，和预演时（或者之前的步骤执行之后的预期状态）不一致的目标不会执行，
+// 对应结果的错误为 apierror.ErrPlanTargetChanged，之前的步骤没有执行成功的目标在后面的步骤中同样不会执行。
+// 接口调用失败时返回已经执行的结果和错误，剩下的步骤不再执行。执行后计划不会清空，需要的话调用 Reset
+func (p *OperationPlan) Apply(op PlanOperator) ([]*PlanApplyResult, *apierror.ApiError) {
+	results := []*PlanApplyResult{}
+	for _, step := range p.Steps() {
+		r, err := applyPlanStep(op, step)
+		results = append(results, r...)
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// planStepChecker 执行前检查步骤中的目标是否和预期状态一致
+type planStepChecker struct {
+	op   PlanOperator
+	step *PlanStep
+	// recycleBin 回收站中当前的文件，key 为 driveId
+	recycleBin map[string]map[string]*FileEntity
+	shares     map[string]*ShareEntity
+}
+
+func (c *planStepChecker) unchanged(t *PlanTarget) (bool, *apierror.ApiError) {
+	switch c.step.Action {
+	case PlanActionFileDelete, PlanActionFileDeleteCompletely, PlanActionFileMove:
+		f, err := c.op.FileInfoById(t.DriveId, t.FileId)
+		if err != nil || t.ChangedFrom(f) {
+			return false, nil
+		}
+		if c.step.Action == PlanActionFileMove && t.ToParentFileId != "" && t.ToParentFileId != DefaultRootParentFileId {
+			folder, err := c.op.FileInfoById(t.ToDriveId, t.ToParentFileId)
+			return err == nil && folder.IsFolder(), nil
+		}
+		return true, nil
+	case PlanActionRecycleBinFileDelete, PlanActionRecycleBinFileClear:
+		// 只有仍然在回收站中的文件才会被彻底删除
+		driveId := t.DriveId
+		if c.step.Action == PlanActionRecycleBinFileClear {
+			driveId = c.step.DriveId
+		}
+		if _, ok := c.recycleBin[driveId]; !ok {
+			fileList, err := c.op.RecycleBinFiles(driveId)
+			if err != nil {
+				return false, err
+			}
+			c.recycleBin[driveId] = map[string]*FileEntity{}
+			for _, f := range fileList {
+				c.recycleBin[driveId][f.FileId] = f
+			}
+		}
+		return !t.ChangedFrom(c.recycleBin[driveId][t.FileId]), nil
+	case PlanActionShareLinkCancel:
+		if c.shares == nil {
+			shares, err := planShareLinkMap(c.op)
+			if err != nil {
+				return false, err
+			}
+			c.shares = shares
+		}
+		share, ok := c.shares[t.ShareId]
+		return ok && share.UpdatedAt == t.UpdatedAt, nil
+	}
+	return false, apierror.NewFailedApiError("不支持的操作：" + string(c.step.Action))
+}
+
+func applyPlanStep(op PlanOperator, step *PlanStep) ([]*PlanApplyResult, *apierror.ApiError) {
+	checker := &planStepChecker{op: op, step: step, recycleBin: map[string]map[string]*FileEntity{}}
+	results := []*PlanApplyResult{}
+	pending := []*PlanApplyResult{}
+	targets := []*PlanTarget{}
+	for _, t := range step.Targets {
+		ok, err := checker.unchanged(t)
+		if err != nil {
+			return results, err
+		}
+		result := &PlanApplyResult{Action: step.Action, Target: t}
+		results = append(results, result)
+		if !ok {
+			result.Err = NewPlanTargetChangedError(t)
+			continue
+		}
+		pending = append(pending, result)
+		targets = append(targets, t)
+	}
+	if len(pending) == 0 {
+		return results, nil
+	}
+
+	// 回收站和预演时完全一致才清空，否则只彻底删除记录的并且没有变化的文件，新放入回收站的文件不受影响
+	clearAll := step.Action == PlanActionRecycleBinFileClear && len(pending) == len(step.Targets) &&
+		len(checker.recycleBin[step.DriveId]) == len(step.Targets)
+	errs, err := op.ApplyPlanStep(step.Action, step.DriveId, targets, clearAll)
+	if err != nil {
+		for _, result := range pending {
+			result.Err = err
+		}
+		return results, err
+	}
+	for i, result := range pending {
+		if i < len(errs) && errs[i] != nil {
+			result.Err = errs[i]
+			continue
+		}
+		result.Success = true
+	}
+	return results, nil
+}
+
+func planShareLinkMap(op PlanOperator) (map[string]*ShareEntity, *apierror.ApiError) {
+	shareList, err := op.ShareLinks()
+	if err != nil {
+		return nil, err
+	}
+	shares := map[string]*ShareEntity{}
+	for _, share := range shareList {
+		shares[share.ShareId] = share
+	}
+	return shares, nil
+}
+
+func newShareLinkPlanTarget(share *ShareEntity) *PlanTarget {
+	t := &PlanTarget{
+		DriveId:   share.DriveId,
+		ShareId:   share.ShareId,
+		ShareName: share.ShareName,
+		ShareUrl:  share.ShareUrl,
+		UpdatedAt: share.UpdatedAt,
+	}
+	if len(share.FileIdList) == 1 {
+		t.FileId = share.FileIdList[0]
+	}
+	if share.FirstFile != nil {
+		t.FileName = share.FirstFile.FileName
+		t.FileType = share.FirstFile.FileType
+		t.FileSize = share.FirstFile.FileSize
+	}
+	return t
+}
@@ -0,0 +1,178 @@
+package aliyunpan
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+// planStub 在 stubDrive 上模拟回收站和分享，记录执行的步骤
+type planStub struct {
+	*stubDrive
+	trashed map[string]*FileEntity
+	shares  []*ShareEntity
+	// applied 每次执行的操作和目标文件ID
+	applied []string
+	// failAction 执行时返回错误的操作
+	failAction PlanAction
+}
+
+func newPlanStub() *planStub {
+	return &planStub{stubDrive: newStubDrive(), trashed: map[string]*FileEntity{}}
+}
+
+func (s *planStub) FilePathById(driveId, fileId string) (string, *apierror.ApiError) {
+	f, ok := s.files[fileId]
+	if !ok {
+		return "", apierror.NewApiError(apierror.ApiCodeFileNotFoundCode, "not found: "+fileId)
+	}
+	return s.pathOf(f), nil
+}
+
+func (s *planStub) RecycleBinFiles(driveId string) (FileList, *apierror.ApiError) {
+	fileList := FileList{}
+	for _, f := range s.trashed {
+		c := *f
+		fileList = append(fileList, &c)
+	}
+	return fileList, nil
+}
+
+func (s *planStub) ShareLinks() ([]*ShareEntity, *apierror.ApiError) {
+	return s.shares, nil
+}
+
+func (s *planStub) ApplyPlanStep(action PlanAction, driveId string, targets []*PlanTarget, clearAll bool) ([]*apierror.ApiError, *apierror.ApiError) {
+	if action == s.failAction {
+		return nil, apierror.NewFailedApiError("apply failed")
+	}
+	if clearAll {
+		s.applied = append(s.applied, "clear_all")
+		s.trashed = map[string]*FileEntity{}
+		return make([]*apierror.ApiError, len(targets)), nil
+	}
+	for _, t := range targets {
+		s.applied = append(s.applied, string(action)+":"+t.FileId+t.ShareId)
+		switch action {
+		case PlanActionFileDelete:
+			s.trashed[t.FileId] = s.files[t.FileId]
+			delete(s.files, t.FileId)
+		case PlanActionFileMove:
+			s.files[t.FileId].ParentFileId = t.ToParentFileId
+			s.files[t.FileId].UpdatedAt = "2023-02-01 00:00:00"
+		case PlanActionRecycleBinFileDelete, PlanActionRecycleBinFileClear:
+			delete(s.trashed, t.FileId)
+		}
+	}
+	return make([]*apierror.ApiError, len(targets)), nil
+}
+
+func TestOperationPlanChainedSteps(t *testing.T) {
+	s := newPlanStub()
+	s.add("docs", DefaultRootParentFileId, "docs", 0, "", "")
+	s.add("archive", DefaultRootParentFileId, "archive", 0, "", "")
+	s.add("a", "docs", "a.txt", 10, "AAA", "")
+	s.add("b", "docs", "b.txt", 20, "BBB", "")
+	plan := NewOperationPlan()
+
+	require.Nil(t, plan.RecordFiles(s, PlanActionFileMove, []*FileMoveParam{{DriveId: "d", FileId: "a", ToParentFileId: "archive"}}))
+	require.Nil(t, plan.RecordFiles(s, PlanActionFileDelete, []*FileMoveParam{{DriveId: "d", FileId: "a"}, {DriveId: "d", FileId: "b"}}))
+	// 放入回收站之后不能再删除或者移动，但是可以彻底删除
+	assert.NotNil(t, plan.RecordFiles(s, PlanActionFileDelete, []*FileMoveParam{{DriveId: "d", FileId: "a"}}))
+	assert.NotNil(t, plan.RecordFiles(s, PlanActionFileMove, []*FileMoveParam{{DriveId: "d", FileId: "b", ToParentFileId: "archive"}}))
+	require.Nil(t, plan.RecordFiles(s, PlanActionRecycleBinFileDelete, []*FileMoveParam{{DriveId: "d", FileId: "b"}}))
+	// 彻底删除之后不能再有任何操作
+	assert.NotNil(t, plan.RecordFiles(s, PlanActionFileMove, []*FileMoveParam{{DriveId: "d", FileId: "b"}}))
+	// 没有放入回收站的文件不能彻底删除
+	s.add("c", "docs", "c.txt", 30, "CCC", "")
+	require.Nil(t, plan.RecordFiles(s, PlanActionFileMove, []*FileMoveParam{{DriveId: "d", FileId: "c", ToParentFileId: "archive"}}))
+	assert.NotNil(t, plan.RecordFiles(s, PlanActionRecycleBinFileDelete, []*FileMoveParam{{DriveId: "d", FileId: "c"}}))
+	require.Nil(t, plan.RecordRecycleBinClear(s, "d"))
+
+	steps := plan.Steps()
+	require.Len(t, steps, 5)
+	assert.Equal(t, "/docs/a.txt", steps[0].Targets[0].Path)
+	assert.False(t, steps[0].Targets[0].Chained)
+	assert.Equal(t, "/archive/a.txt", steps[1].Targets[0].Path)
+	assert.Equal(t, "archive", steps[1].Targets[0].ParentFileId)
+	assert.True(t, steps[1].Targets[0].Chained)
+	// 清空回收站包括之前放入回收站并且没有被彻底删除的 a
+	require.Len(t, steps[4].Targets, 1)
+	assert.Equal(t, "a", steps[4].Targets[0].FileId)
+	assert.Empty(t, s.applied)
+
+	results, err := plan.Apply(s)
+	require.Nil(t, err)
+	require.Len(t, results, 6)
+	for _, r := range results {
+		assert.True(t, r.Success, r.Target.FileId)
+	}
+	assert.Equal(t, []string{"file_move:a", "file_delete:a", "file_delete:b", "recycle_bin_file_delete:b", "file_move:c", "clear_all"}, s.applied)
+	assert.Empty(t, s.trashed)
+	assert.NotContains(t, s.files, "a")
+	assert.Equal(t, "archive", s.files["c"].ParentFileId)
+}
+
+func TestOperationPlanApplySkipsChanged(t *testing.T) {
+	s := newPlanStub()
+	s.add("docs", DefaultRootParentFileId, "docs", 0, "", "")
+	s.add("archive", DefaultRootParentFileId, "archive", 0, "", "")
+	s.add("a", "docs", "a.txt", 10, "AAA", "")
+	s.add("b", "docs", "b.txt", 20, "BBB", "")
+	s.shares = []*ShareEntity{{ShareId: "s1", UpdatedAt: "2023-01-01 00:00:00"}}
+	plan := NewOperationPlan()
+	require.Nil(t, plan.RecordFiles(s, PlanActionFileMove, []*FileMoveParam{{DriveId: "d", FileId: "a", ToParentFileId: "archive"}}))
+	require.Nil(t, plan.RecordFiles(s, PlanActionFileDelete, []*FileMoveParam{{DriveId: "d", FileId: "a"}}))
+	require.Nil(t, plan.RecordShareLinkCancel(s, []string{"s1"}))
+	require.Nil(t, plan.RecordFiles(s, PlanActionFileDelete, []*FileMoveParam{{DriveId: "d", FileId: "b"}}))
+	assert.NotNil(t, plan.RecordShareLinkCancel(s, []string{"missing"}))
+
+	// a 预演之后被修改过，移动和之后的删除都不会执行；分享预演之后被修改过
+	s.files["a"].FileSize = 11
+	s.shares[0].UpdatedAt = "2023-01-02 00:00:00"
+	s.failAction = PlanActionFileDelete
+	results, err := plan.Apply(s)
+	require.Len(t, results, 4)
+	assert.True(t, errors.Is(results[0].Err, apierror.ErrPlanTargetChanged))
+	assert.True(t, errors.Is(results[1].Err, apierror.ErrPlanTargetChanged))
+	assert.True(t, errors.Is(results[2].Err, apierror.ErrPlanTargetChanged))
+	// 接口调用失败时返回错误
+	assert.NotNil(t, err)
+	assert.False(t, results[3].Success)
+	assert.Equal(t, err, results[3].Err)
+	assert.Empty(t, s.applied)
+}
+
+func TestOperationPlanFolderSize(t *testing.T) {
+	s := newPlanStub()
+	s.add("docs", DefaultRootParentFileId, "docs", 0, "", "")
+	s.add("sub", "docs", "sub", 0, "", "")
+	s.add("a", "docs", "a.txt", 10, "AAA", "")
+	s.add("b", "sub", "b.txt", 20, "BBB", "")
+	s.add("c", DefaultRootParentFileId, "c.txt", 5, "CCC", "")
+	plan := NewOperationPlan()
+
+	// 文件夹记录时递归统计文件夹内所有文件的大小
+	require.Nil(t, plan.RecordFiles(s, PlanActionFileDelete, []*FileMoveParam{{DriveId: "d", FileId: "docs"}, {DriveId: "d", FileId: "c"}}))
+	target := plan.Steps()[0].Targets[0]
+	assert.Equal(t, int64(0), target.FileSize)
+	assert.Equal(t, int64(30), target.FolderSize)
+	assert.Equal(t, int64(35), plan.TotalSize())
+	assert.Contains(t, plan.String(), "file_delete /docs size=30")
+	assert.Contains(t, plan.String(), "file_delete /c.txt size=5")
+
+	// 之后步骤中的文件夹使用预期状态中记录的大小
+	require.Nil(t, plan.RecordFiles(s, PlanActionRecycleBinFileDelete, []*FileMoveParam{{DriveId: "d", FileId: "docs"}}))
+	assert.Equal(t, int64(30), plan.Steps()[1].Targets[0].Size())
+	assert.Equal(t, int64(65), plan.TotalSize())
+
+	// 执行前的检查仍然比较文件夹自身的大小
+	results, err := plan.Apply(s)
+	require.Nil(t, err)
+	for _, r := range results {
+		assert.True(t, r.Success, r.Target.FileId)
+	}
+}
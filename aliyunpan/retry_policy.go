@@ -0,0 +1,145 @@
+package aliyunpan
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+type (
+	// IdempotentFunc 判断请求是否是幂等的，幂等请求失败后可以安全地重新发送
+	IdempotentFunc func(httpMethod, urlPath string) bool
+
+	// RetryPolicy 请求失败重试策略，网页端和OpenAPI客户端共用。
+	//
+	// 重试在 http 客户端内部进行，客户端的超时时间（SetTimeout、WithTimeout，默认30秒）包含所有重试和等待时间，
+	// 超时之后不会再重试。MaxElapsed 应该小于超时时间，给最后一次请求留出时间
+	RetryPolicy struct {
+		// MaxAttempts 最大尝试次数，包含第一次请求。小于等于1代表不重试
+		MaxAttempts int
+		// BaseDelay 第一次重试前的等待时间，之后每次重试等待时间翻倍
+		BaseDelay time.Duration
+		// MaxDelay 重试等待时间上限
+		MaxDelay time.Duration
+		// MaxElapsed 从第一次请求开始计算的重试总时间上限，等待之后会超过该时间则不再重试。0代表不限制
+		MaxElapsed time.Duration
+		// Jitter 等待时间随机抖动比例，取值 0~1，避免多个客户端同时重试
+		Jitter float64
+		// RejectedCodes 请求已被服务器拒绝、没有被处理的错误码，无论请求是否幂等都可以重试，例如：429请求被限流
+		RejectedCodes []apierror.ApiCode
+		// RetryableCodes 请求可能已经被服务器处理的错误码，只有幂等请求才会重试，例如：502网关错误、网络错误
+		RetryableCodes []apierror.ApiCode
+		// Idempotent 判断请求是否幂等，为nil则使用 DefaultIdempotentFunc
+		Idempotent IdempotentFunc
+	}
+)
+
+// DefaultRetryPolicy 默认重试策略，最多尝试5次，等待时间为1、2、4、8秒，重试总时间不超过20秒，
+// 保证在默认30秒的请求超时时间内完成。调小超时时间时需要同时调小 MaxElapsed
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   1 * time.Second,
+		MaxDelay:    8 * time.Second,
+		MaxElapsed:  20 * time.Second,
+		Jitter:      0.2,
+		RejectedCodes: []apierror.ApiCode{
+			apierror.ApiCodeTooManyRequests,
+		},
+		RetryableCodes: []apierror.ApiCode{
+			apierror.ApiCodeBadGateway,
+			apierror.ApiCodeNetError,
+		},
+		Idempotent: DefaultIdempotentFunc,
+	}
+}
+
+// NoRetryPolicy 不进行任何重试的策略
+func NoRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 1,
+	}
+}
+
+// DefaultIdempotentFunc 默认的幂等判断。阿里云盘接口基本都是POST请求，
+// 这里根据接口名称判断：get、list、search 开头的查询类接口是幂等的，创建、移动、删除等接口不是
+func DefaultIdempotentFunc(httpMethod, urlPath string) bool {
+	switch strings.ToUpper(httpMethod) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	urlPath = strings.TrimSuffix(urlPath, "/")
+	name := urlPath[strings.LastIndex(urlPath, "/")+1:]
+	name = strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	return strings.HasPrefix(name, "get") ||
+		strings.HasPrefix(name, "list") ||
+		strings.HasPrefix(name, "search") ||
+		strings.HasPrefix(name, "starredlist")
+}
+
+// ShouldRetry 判断失败的请求是否需要重试，attempt 为已经尝试的次数
+func (r *RetryPolicy) ShouldRetry(code apierror.ApiCode, attempt int, idempotent bool) bool {
+	if r == nil || attempt >= r.MaxAttempts {
+		return false
+	}
+	for _, c := range r.RejectedCodes {
+		if c == code {
+			return true
+		}
+	}
+	if !idempotent {
+		return false
+	}
+	for _, c := range r.RetryableCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsIdempotent 判断请求是否幂等
+func (r *RetryPolicy) IsIdempotent(httpMethod, urlPath string) bool {
+	if r == nil {
+		return false
+	}
+	if r.Idempotent != nil {
+		return r.Idempotent(httpMethod, urlPath)
+	}
+	return DefaultIdempotentFunc(httpMethod, urlPath)
+}
+
+// CanRetry 是否还有剩余的重试次数，attempt 为已经尝试的次数
+func (r *RetryPolicy) CanRetry(attempt int) bool {
+	return r != nil && attempt < r.MaxAttempts
+}
+
+// WithinMaxElapsed 判断已经用时 elapsed 之后再等待 wait 是否还在重试总时间上限内
+func (r *RetryPolicy) WithinMaxElapsed(elapsed, wait time.Duration) bool {
+	return r != nil && (r.MaxElapsed <= 0 || elapsed+wait <= r.MaxElapsed)
+}
+
+// Backoff 计算第 attempt 次重试前需要等待的时间（指数退避 + 随机抖动）
+func (r *RetryPolicy) Backoff(attempt int) time.Duration {
+	if r == nil || r.BaseDelay <= 0 {
+		return 0
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := float64(r.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if r.MaxDelay > 0 && d > float64(r.MaxDelay) {
+		d = float64(r.MaxDelay)
+	}
+	if r.Jitter > 0 {
+		d += d * r.Jitter * (rand.Float64()*2 - 1)
+	}
+	if d < 0 {
+		d = 0
+	}
+	return time.Duration(d)
+}
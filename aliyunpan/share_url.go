@@ -0,0 +1,110 @@
+package aliyunpan
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+const (
+	// ShareUrlPrefix 规范的分享链接前缀
+	ShareUrlPrefix = "https://www.alipan.com/s/"
+)
+
+type (
+	// ShareUrlInfo 从分享链接中解析出来的信息
+	ShareUrlInfo struct {
+		// ShareId 分享ID
+		ShareId string `json:"share_id"`
+		// SharePwd 提取码，没有则为空
+		SharePwd string `json:"share_pwd"`
+		// FolderId 分享链接中指定的子文件夹ID，为空代表分享的根目录
+		FolderId string `json:"folder_id"`
+		// FileId 分享链接中指定的文件ID，没有则为空
+		FileId string `json:"file_id"`
+	}
+)
+
+var (
+	shareUrlRegexp    = regexp.MustCompile(`(?i)(?:https?://)?(?:www\.)?(?:alipan|aliyundrive)\.com/s/([0-9a-zA-Z]+)(?:/(folder|file)/([0-9a-zA-Z]+))?`)
+	sharePwdRegexp    = regexp.MustCompile(`(?i)(?:[?&]pwd=|提取码[:：]?\s*|访问码[:：]?\s*|密码[:：]?\s*)([0-9a-zA-Z]+)`)
+	shareIdRegexp     = regexp.MustCompile(`^[0-9a-zA-Z]{8,32}$`)
+	sharePwdValRegexp = regexp.MustCompile(`^[0-9a-zA-Z]{4}$`)
+	shareFileIdRegexp = regexp.MustCompile(`^[0-9a-zA-Z]{1,64}$`)
+)
+
+// ParseShareUrl 解析分享链接，支持 alipan.com 和 aliyundrive.com 的链接（包括 /folder/ 和 /file/ 子路径）、
+// FastShareCreateResult.FullShareMsg 这类带提取码的分享文本以及单独的分享ID。解析结果会经过 Validate 校验
+func ParseShareUrl(text string) (*ShareUrlInfo, *apierror.ApiError) {
+	text = strings.TrimSpace(text)
+	info := &ShareUrlInfo{}
+	if m := shareUrlRegexp.FindStringSubmatch(text); m != nil {
+		info.ShareId = m[1]
+		switch strings.ToLower(m[2]) {
+		case "folder":
+			if m[3] != DefaultRootParentFileId {
+				info.FolderId = m[3]
+			}
+		case "file":
+			info.FileId = m[3]
+		}
+		if m := sharePwdRegexp.FindStringSubmatch(text); m != nil {
+			info.SharePwd = m[1]
+		}
+	} else if shareIdRegexp.MatchString(text) {
+		info.ShareId = text
+	} else {
+		return nil, apierror.NewApiError(apierror.ApiCodeBadRequest, "无效的分享链接："+text)
+	}
+	if err := info.Validate(); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Validate 校验分享ID、提取码和文件ID的格式
+func (info *ShareUrlInfo) Validate() *apierror.ApiError {
+	if !shareIdRegexp.MatchString(info.ShareId) {
+		return apierror.NewApiError(apierror.ApiCodeBadRequest, "无效的分享ID："+info.ShareId)
+	}
+	if info.SharePwd != "" && !sharePwdValRegexp.MatchString(info.SharePwd) {
+		return apierror.NewApiError(apierror.ApiCodeBadRequest, "提取码必须是4个字母或数字："+info.SharePwd)
+	}
+	if info.FolderId != "" && info.FileId != "" {
+		return apierror.NewApiError(apierror.ApiCodeBadRequest, "分享链接不能同时指定文件夹和文件")
+	}
+	for _, id := range []string{info.FolderId, info.FileId} {
+		if id != "" && !shareFileIdRegexp.MatchString(id) {
+			return apierror.NewApiError(apierror.ApiCodeBadRequest, "无效的文件ID："+id)
+		}
+	}
+	return nil
+}
+
+// Url 返回规范的分享链接，例如：https://www.alipan.com/s/GSHjrcPt9pP/folder/63a1b2?pwd=8a2b
+func (info *ShareUrlInfo) Url() string {
+	sb := &strings.Builder{}
+	sb.WriteString(ShareUrlPrefix)
+	sb.WriteString(info.ShareId)
+	if info.FolderId != "" {
+		sb.WriteString("/folder/" + info.FolderId)
+	} else if info.FileId != "" {
+		sb.WriteString("/file/" + info.FileId)
+	}
+	if info.SharePwd != "" {
+		sb.WriteString("?pwd=" + url.QueryEscape(info.SharePwd))
+	}
+	return sb.String()
+}
+
+// ShareMsg 返回不带 pwd 参数的分享链接和提取码文本，例如：https://www.alipan.com/s/GSHjrcPt9pP 提取码: 8a2b
+func (info *ShareUrlInfo) ShareMsg() string {
+	u := *info
+	u.SharePwd = ""
+	if info.SharePwd == "" {
+		return u.Url()
+	}
+	return u.Url() + " 提取码: " + info.SharePwd
+}
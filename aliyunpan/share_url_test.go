@@ -0,0 +1,68 @@
+package aliyunpan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseShareUrl(t *testing.T) {
+	info, err := ParseShareUrl("「电影」https://www.alipan.com/s/GSHjrcPt9pP/folder/63a1b2 提取码: 8a2b")
+	require.Nil(t, err)
+	assert.Equal(t, "GSHjrcPt9pP", info.ShareId)
+	assert.Equal(t, "63a1b2", info.FolderId)
+	assert.Equal(t, "8a2b", info.SharePwd)
+
+	info, err = ParseShareUrl("https://www.aliyundrive.com/s/GSHjrcPt9pP?pwd=x9y8")
+	require.Nil(t, err)
+	assert.Equal(t, "x9y8", info.SharePwd)
+
+	_, err = ParseShareUrl("https://example.com/s/abc")
+	assert.NotNil(t, err)
+
+	// 快传返回的分享文本
+	info, err = ParseShareUrl("「a.mkv」https://www.alipan.com/s/GSHjrcPt9pP\n点击链接保存，或者复制本段内容，打开「阿里云盘」APP")
+	require.Nil(t, err)
+	assert.Equal(t, "GSHjrcPt9pP", info.ShareId)
+	assert.Equal(t, "", info.SharePwd)
+
+	info, err = ParseShareUrl("aliyundrive.com/s/GSHjrcPt9pP/file/63a1b2 访问码：K9m2")
+	require.Nil(t, err)
+	assert.Equal(t, "63a1b2", info.FileId)
+	assert.Equal(t, "K9m2", info.SharePwd)
+
+	info, err = ParseShareUrl("https://www.alipan.com/s/GSHjrcPt9pP/folder/root")
+	require.Nil(t, err)
+	assert.Equal(t, "", info.FolderId)
+
+	info, err = ParseShareUrl("GSHjrcPt9pP")
+	require.Nil(t, err)
+	assert.Equal(t, "GSHjrcPt9pP", info.ShareId)
+
+	// 提取码必须是4个字符
+	_, err = ParseShareUrl("https://www.alipan.com/s/GSHjrcPt9pP?pwd=12345")
+	assert.NotNil(t, err)
+	_, err = ParseShareUrl("abc")
+	assert.NotNil(t, err)
+}
+
+func TestShareUrlRoundTrip(t *testing.T) {
+	for _, info := range []*ShareUrlInfo{
+		{ShareId: "GSHjrcPt9pP"},
+		{ShareId: "GSHjrcPt9pP", SharePwd: "8a2b"},
+		{ShareId: "GSHjrcPt9pP", SharePwd: "8a2b", FolderId: "63a1b2"},
+		{ShareId: "GSHjrcPt9pP", FileId: "63a1b2"},
+	} {
+		require.Nil(t, info.Validate())
+		r, err := ParseShareUrl(info.Url())
+		require.Nil(t, err)
+		assert.Equal(t, info, r)
+		r, err = ParseShareUrl(info.ShareMsg())
+		require.Nil(t, err)
+		assert.Equal(t, info, r)
+	}
+	assert.Equal(t, "https://www.alipan.com/s/GSHjrcPt9pP/folder/63a1b2?pwd=8a2b",
+		(&ShareUrlInfo{ShareId: "GSHjrcPt9pP", SharePwd: "8a2b", FolderId: "63a1b2"}).Url())
+	assert.NotNil(t, (&ShareUrlInfo{ShareId: "GSHjrcPt9pP", FolderId: "a", FileId: "b"}).Validate())
+}
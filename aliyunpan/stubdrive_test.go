@@ -0,0 +1,107 @@
+package aliyunpan
+
+import (
+	"path"
+	"sort"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+// stubDrive 内存中的文件树，实现各个 Operator 接口中查询和删除文件的部分，供算法的单元测试使用
+type stubDrive struct {
+	files map[string]*FileEntity
+	// listed 按顺序记录获取文件列表的文件夹ID
+	listed []string
+	// removed 每次 RemoveFiles 调用删除的文件ID
+	removed [][]string
+	// failRemove 放入回收站时返回错误的文件ID
+	failRemove map[string]bool
+}
+
+func newStubDrive() *stubDrive {
+	return &stubDrive{files: map[string]*FileEntity{}, failRemove: map[string]bool{}}
+}
+
+// add 添加文件，driveId 固定为 d，updatedAt 为空时使用固定时间
+func (s *stubDrive) add(fileId, parentFileId, name string, size int64, hash, updatedAt string) *FileEntity {
+	fileType := "file"
+	if hash == "" && size == 0 {
+		fileType = "folder"
+	}
+	if updatedAt == "" {
+		updatedAt = "2023-01-01 00:00:00"
+	}
+	f := &FileEntity{
+		DriveId:      "d",
+		FileId:       fileId,
+		ParentFileId: parentFileId,
+		FileName:     name,
+		FileType:     fileType,
+		FileSize:     size,
+		ContentHash:  hash,
+		UpdatedAt:    updatedAt,
+	}
+	s.files[fileId] = f
+	return f
+}
+
+func (s *stubDrive) pathOf(f *FileEntity) string {
+	if f.FileId == DefaultRootParentFileId {
+		return PathSeparator
+	}
+	parent, ok := s.files[f.ParentFileId]
+	if !ok {
+		return PathSeparator + f.FileName
+	}
+	return path.Join(s.pathOf(parent), f.FileName)
+}
+
+func (s *stubDrive) FileInfoByPath(driveId string, pathStr string) (*FileEntity, *apierror.ApiError) {
+	if pathStr == PathSeparator {
+		return &FileEntity{DriveId: driveId, FileId: DefaultRootParentFileId, FileType: "folder", Path: PathSeparator}, nil
+	}
+	for _, f := range s.files {
+		if s.pathOf(f) == path.Clean(pathStr) {
+			c := *f
+			c.Path = pathStr
+			return &c, nil
+		}
+	}
+	return nil, apierror.NewApiError(apierror.ApiCodeFileNotFoundCode, "not found: "+pathStr)
+}
+
+func (s *stubDrive) FileListGetAll(param *FileListParam, delayMilliseconds int) (FileList, *apierror.ApiError) {
+	s.listed = append(s.listed, param.ParentFileId)
+	fileList := FileList{}
+	for _, f := range s.files {
+		if f.ParentFileId == param.ParentFileId {
+			c := *f
+			fileList = append(fileList, &c)
+		}
+	}
+	// 按照文件名排序，保证遍历顺序固定
+	sort.Slice(fileList, func(i, j int) bool { return fileList[i].FileName < fileList[j].FileName })
+	return fileList, nil
+}
+
+func (s *stubDrive) RemoveFiles(driveId string, fileIds []string) []*apierror.ApiError {
+	s.removed = append(s.removed, fileIds)
+	errs := make([]*apierror.ApiError, len(fileIds))
+	for i, fileId := range fileIds {
+		if s.failRemove[fileId] {
+			errs[i] = apierror.NewFailedApiError("remove failed")
+			continue
+		}
+		delete(s.files, fileId)
+	}
+	return errs
+}
+
+func (s *stubDrive) FileInfoById(driveId, fileId string) (*FileEntity, *apierror.ApiError) {
+	f, ok := s.files[fileId]
+	if !ok {
+		return nil, apierror.NewApiError(apierror.ApiCodeFileNotFoundCode, "not found: "+fileId)
+	}
+	c := *f
+	return &c, nil
+}
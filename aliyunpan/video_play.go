@@ -0,0 +1,420 @@
+package aliyunpan
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+const (
+	// VideoTranscodeStatusFinished 转码完成，可以获取到播放地址
+	VideoTranscodeStatusFinished = "finished"
+
+	// DefaultVideoPlayRefreshInterval 播放地址的默认刷新间隔，转码播放地址的有效期一般为15分钟
+	DefaultVideoPlayRefreshInterval = 10 * time.Minute
+)
+
+var (
+	// hlsUriAttrRegexp 播放列表标签中的 URI 属性，例如 #EXT-X-KEY:METHOD=AES-128,URI="key.bin"
+	hlsUriAttrRegexp = regexp.MustCompile(`URI="([^"]*)"`)
+
+	// videoTemplateHeights 转码模板对应的高度，接口没有返回分辨率时使用
+	videoTemplateHeights = map[string]int{
+		"LD":  360,
+		"SD":  540,
+		"HD":  720,
+		"FHD": 1080,
+		"QHD": 1440,
+		"UHD": 2160,
+	}
+)
+
+type (
+	// VideoPlaySource 选中的视频播放源
+	VideoPlaySource struct {
+		// Url 播放地址，转码时为 m3u8 地址，IsOriginal 时为原文件下载地址
+		Url string
+		// TemplateId 转码模板，IsOriginal 时为空
+		TemplateId string
+		Width      int
+		Height     int
+		// IsOriginal 没有可用的转码，使用原文件
+		IsOriginal bool
+	}
+
+	// VideoPlayOperator 视频播放需要的网盘操作
+	VideoPlayOperator interface {
+		VideoGetPreviewPlayInfo(param *VideoGetPreviewPlayInfoParam) (*VideoGetPreviewPlayInfoResult, error)
+		GetFileDownloadUrl(param *GetFileDownloadUrlParam) (*GetFileDownloadUrlResult, *apierror.ApiError)
+		// FetchUrl 请求上游的播放列表和视频分片
+		FetchUrl(url string) (*http.Response, error)
+	}
+
+	// VideoHlsProxy 本地 HLS 代理，把网盘的转码播放列表转换为本地地址，上游地址过期时自动刷新。
+	// 支持的路径：/master.m3u8、/<TemplateId>/media.m3u8、/<TemplateId>/<序号>.<后缀名>，
+	// 媒体播放列表中的分片和 #EXT-X-KEY、#EXT-X-MAP 等标签的 URI 都会改写为本地地址
+	VideoHlsProxy struct {
+		op        VideoPlayOperator
+		driveId   string
+		fileId    string
+		maxHeight int
+		// RefreshInterval 播放地址的刷新间隔，为0时使用 DefaultVideoPlayRefreshInterval
+		RefreshInterval time.Duration
+
+		// mutex 保护下面的状态，刷新播放地址和获取播放列表时一直持有，并发请求只会刷新一次
+		mutex     sync.Mutex
+		tasks     []VideoTranscodingTask
+		fetchedAt time.Time
+		// generation 每次刷新播放地址后加1
+		generation int
+		// medias 每个转码模板的媒体播放列表
+		medias map[string]*hlsMedia
+	}
+
+	// hlsMedia 改写后的媒体播放列表
+	hlsMedia struct {
+		generation int
+		playlist   string
+		// resources 本地序号对应的上游地址
+		resources []string
+	}
+)
+
+// Height 转码的高度，接口没有返回时按照模板名称推断
+func (t *VideoTranscodingTask) Height() int {
+	if t.TemplateHeight > 0 {
+		return t.TemplateHeight
+	}
+	return videoTemplateHeights[strings.ToUpper(t.TemplateId)]
+}
+
+// IsFinished 转码是否完成并且有播放地址
+func (t *VideoTranscodingTask) IsFinished() bool {
+	return t.Status == VideoTranscodeStatusFinished && t.URL != ""
+}
+
+// PlayableTranscodes 已完成并且高度不超过 maxHeight 的转码，按照清晰度从高到低排列，maxHeight 小于等于0时不限制
+func (r *VideoGetPreviewPlayInfoResult) PlayableTranscodes(maxHeight int) []VideoTranscodingTask {
+	tasks := []VideoTranscodingTask{}
+	for _, t := range r.VideoPreviewPlayInfo.LiveTranscodingTaskList {
+		if t.IsFinished() && (maxHeight <= 0 || t.Height() <= maxHeight) {
+			tasks = append(tasks, t)
+		}
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Height() > tasks[j].Height()
+	})
+	return tasks
+}
+
+// BestTranscode 清晰度最高并且高度不超过 maxHeight 的已完成转码，没有则返回 nil
+func (r *VideoGetPreviewPlayInfoResult) BestTranscode(maxHeight int) *VideoTranscodingTask {
+	tasks := r.PlayableTranscodes(maxHeight)
+	if len(tasks) == 0 {
+		return nil
+	}
+	return &tasks[0]
+}
+
+// ChooseVideoSource 选择视频的播放源，优先使用高度不超过 maxHeight 的最高清晰度转码，
+// 没有可用的转码（转码未完成、失败或者视频不支持转码）时使用原文件下载地址
+func ChooseVideoSource(op VideoPlayOperator, driveId, fileId string, maxHeight int) (*VideoPlaySource, *apierror.ApiError) {
+	info, err := op.VideoGetPreviewPlayInfo(&VideoGetPreviewPlayInfoParam{DriveId: driveId, FileId: fileId})
+	if err == nil {
+		if t := info.BestTranscode(maxHeight); t != nil {
+			return &VideoPlaySource{
+				Url:        t.URL,
+				TemplateId: t.TemplateId,
+				Width:      t.TemplateWidth,
+				Height:     t.Height(),
+			}, nil
+		}
+	}
+	r, apiErr := op.GetFileDownloadUrl(&GetFileDownloadUrlParam{DriveId: driveId, FileId: fileId})
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	source := &VideoPlaySource{Url: r.Url, IsOriginal: true}
+	if info != nil {
+		source.Width = info.VideoPreviewPlayInfo.Meta.Width
+		source.Height = info.VideoPreviewPlayInfo.Meta.Height
+	}
+	return source, nil
+}
+
+// NewVideoHlsProxy 创建视频的本地 HLS 代理，只提供高度不超过 maxHeight 的转码，maxHeight 小于等于0时不限制
+func NewVideoHlsProxy(op VideoPlayOperator, driveId, fileId string, maxHeight int) *VideoHlsProxy {
+	return &VideoHlsProxy{
+		op:        op,
+		driveId:   driveId,
+		fileId:    fileId,
+		maxHeight: maxHeight,
+		medias:    map[string]*hlsMedia{},
+	}
+}
+
+// ServeHTTP 实现 http.Handler
+func (p *VideoHlsProxy) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	var err error
+	switch {
+	case len(parts) == 1 && parts[0] == "master.m3u8":
+		err = p.serveMaster(w)
+	case len(parts) == 2 && parts[1] == "media.m3u8":
+		err = p.serveMedia(w, parts[0])
+	case len(parts) == 2 && strings.Contains(parts[1], "."):
+		index, e := strconv.Atoi(parts[1][:strings.Index(parts[1], ".")])
+		if e != nil {
+			http.NotFound(w, req)
+			return
+		}
+		err = p.serveResource(w, parts[0], index)
+	default:
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		if apiErr, ok := err.(*apierror.ApiError); ok && apiErr.Code == apierror.ApiCodeFileNotFoundCode {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	}
+}
+
+func (p *VideoHlsProxy) serveMaster(w http.ResponseWriter) error {
+	tasks, err := p.playableTasks()
+	if err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		return apierror.NewApiError(apierror.ApiCodeFileNotFoundCode, "没有可以播放的转码")
+	}
+	sb := &strings.Builder{}
+	sb.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+	for _, t := range tasks {
+		fmt.Fprintf(sb, "#EXT-X-STREAM-INF:BANDWIDTH=%d", estimateVideoBandwidth(t.Height()))
+		if t.TemplateWidth > 0 && t.Height() > 0 {
+			fmt.Fprintf(sb, ",RESOLUTION=%dx%d", t.TemplateWidth, t.Height())
+		}
+		fmt.Fprintf(sb, ",NAME=\"%s\"\n%s/media.m3u8\n", t.TemplateId, url.PathEscape(t.TemplateId))
+	}
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	_, e := io.WriteString(w, sb.String())
+	return e
+}
+
+func (p *VideoHlsProxy) serveMedia(w http.ResponseWriter, templateId string) error {
+	m, err := p.media(templateId, -1)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	_, e := io.WriteString(w, m.playlist)
+	return e
+}
+
+// serveResource 转发分片、密钥等上游资源，上游地址过期时刷新一次播放地址后重试
+func (p *VideoHlsProxy) serveResource(w http.ResponseWriter, templateId string, index int) error {
+	m, err := p.media(templateId, -1)
+	for retry := 0; ; retry++ {
+		if err != nil {
+			return err
+		}
+		if index < 0 || index >= len(m.resources) {
+			return apierror.NewApiError(apierror.ApiCodeFileNotFoundCode, "分片不存在")
+		}
+		resp, e := p.op.FetchUrl(m.resources[index])
+		if e == nil && (resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusPartialContent) {
+			defer resp.Body.Close()
+			contentType := resp.Header.Get("Content-Type")
+			if contentType == "" {
+				contentType = "video/mp2t"
+			}
+			w.Header().Set("Content-Type", contentType)
+			if resp.ContentLength > 0 {
+				w.Header().Set("Content-Length", strconv.FormatInt(resp.ContentLength, 10))
+			}
+			_, e = io.Copy(w, resp.Body)
+			return e
+		}
+		if e == nil {
+			resp.Body.Close()
+			e = fmt.Errorf("unexpected http status code, %d", resp.StatusCode)
+		}
+		if retry > 0 {
+			return e
+		}
+		// 上游地址过期，刷新播放地址。其他请求已经刷新过时直接使用新的地址
+		m, err = p.media(templateId, m.generation)
+	}
+}
+
+// playableTasks 获取可以播放的转码
+func (p *VideoHlsProxy) playableTasks() ([]VideoTranscodingTask, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if err := p.refreshTasksLocked(-1); err != nil {
+		return nil, err
+	}
+	return p.tasks, nil
+}
+
+// refreshTasksLocked 还没有获取过、超过刷新间隔或者 staleGeneration 等于当前版本时重新获取播放地址，调用方需要持有 mutex
+func (p *VideoHlsProxy) refreshTasksLocked(staleGeneration int) error {
+	interval := p.RefreshInterval
+	if interval <= 0 {
+		interval = DefaultVideoPlayRefreshInterval
+	}
+	if p.tasks != nil && time.Since(p.fetchedAt) <= interval && staleGeneration != p.generation {
+		return nil
+	}
+	info, err := p.op.VideoGetPreviewPlayInfo(&VideoGetPreviewPlayInfoParam{DriveId: p.driveId, FileId: p.fileId})
+	if err != nil {
+		return err
+	}
+	p.tasks = info.PlayableTranscodes(p.maxHeight)
+	p.fetchedAt = time.Now()
+	p.generation++
+	p.medias = map[string]*hlsMedia{}
+	return nil
+}
+
+// media 获取转码的媒体播放列表，staleGeneration 为使用过期地址的版本号，为 -1 代表不需要强制刷新。
+// 上游播放列表获取失败时刷新一次播放地址后重试
+func (p *VideoHlsProxy) media(templateId string, staleGeneration int) (*hlsMedia, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	generation := p.generation
+	if err := p.refreshTasksLocked(staleGeneration); err != nil {
+		return nil, err
+	}
+	if m, ok := p.medias[templateId]; ok {
+		return m, nil
+	}
+	m, err := p.fetchMediaLocked(templateId)
+	if err != nil && generation == p.generation {
+		if apiErr, ok := err.(*apierror.ApiError); ok && apiErr.Code == apierror.ApiCodeFileNotFoundCode {
+			return nil, err
+		}
+		if err = p.refreshTasksLocked(p.generation); err != nil {
+			return nil, err
+		}
+		m, err = p.fetchMediaLocked(templateId)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.medias[templateId] = m
+	return m, nil
+}
+
+// fetchMediaLocked 获取上游的媒体播放列表，把分片和标签中的 URI 改写为本地地址，调用方需要持有 mutex
+func (p *VideoHlsProxy) fetchMediaLocked(templateId string) (*hlsMedia, error) {
+	var task *VideoTranscodingTask
+	for i := range p.tasks {
+		if p.tasks[i].TemplateId == templateId {
+			task = &p.tasks[i]
+		}
+	}
+	if task == nil {
+		return nil, apierror.NewApiError(apierror.ApiCodeFileNotFoundCode, "没有可以播放的转码："+templateId)
+	}
+
+	resp, e := p.op.FetchUrl(task.URL)
+	if e != nil {
+		return nil, e
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("unexpected http status code, %d", resp.StatusCode)
+	}
+	base, e := url.Parse(task.URL)
+	if e != nil {
+		return nil, e
+	}
+
+	m := &hlsMedia{generation: p.generation, resources: []string{}}
+	// local 把上游地址保存到 resources 中，返回本地地址，非 http 地址（例如 data:、skd:）原样返回
+	local := func(uri string) (string, error) {
+		u, e := base.Parse(uri)
+		if e != nil {
+			return "", e
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return uri, nil
+		}
+		ext := path.Ext(u.Path)
+		if ext == "" {
+			ext = ".bin"
+		}
+		name := strconv.Itoa(len(m.resources)) + ext
+		m.resources = append(m.resources, u.String())
+		return name, nil
+	}
+
+	sb := &strings.Builder{}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			var err error
+			line = hlsUriAttrRegexp.ReplaceAllStringFunc(line, func(attr string) string {
+				name, e := local(hlsUriAttrRegexp.FindStringSubmatch(attr)[1])
+				if e != nil {
+					err = e
+					return attr
+				}
+				return `URI="` + name + `"`
+			})
+			if err != nil {
+				return nil, err
+			}
+			sb.WriteString(line + "\n")
+			continue
+		}
+		name, e := local(line)
+		if e != nil {
+			return nil, e
+		}
+		sb.WriteString(name + "\n")
+	}
+	if e := scanner.Err(); e != nil {
+		return nil, e
+	}
+	m.playlist = sb.String()
+	return m, nil
+}
+
+// estimateVideoBandwidth 按照高度估算码率，用于主播放列表的 BANDWIDTH
+func estimateVideoBandwidth(height int) int {
+	switch {
+	case height <= 0:
+		return 1500000
+	case height <= 360:
+		return 800000
+	case height <= 540:
+		return 1500000
+	case height <= 720:
+		return 3000000
+	case height <= 1080:
+		return 6000000
+	case height <= 1440:
+		return 10000000
+	}
+	return 16000000
+}
@@ -0,0 +1,156 @@
+package aliyunpan
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+// stubVideoPlayer 内存中的视频播放接口，version 变化代表播放地址刷新
+type stubVideoPlayer struct {
+	mutex     sync.Mutex
+	version   string
+	infoCalls int
+	tasks     func(version string) []VideoTranscodingTask
+	contents  map[string]string
+	expired   map[string]bool
+}
+
+func (s *stubVideoPlayer) VideoGetPreviewPlayInfo(param *VideoGetPreviewPlayInfoParam) (*VideoGetPreviewPlayInfoResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.infoCalls++
+	r := &VideoGetPreviewPlayInfoResult{DriveId: param.DriveId, FileId: param.FileId}
+	r.VideoPreviewPlayInfo.Meta.Width = 3840
+	r.VideoPreviewPlayInfo.Meta.Height = 2160
+	r.VideoPreviewPlayInfo.LiveTranscodingTaskList = s.tasks(s.version)
+	return r, nil
+}
+
+func (s *stubVideoPlayer) GetFileDownloadUrl(param *GetFileDownloadUrlParam) (*GetFileDownloadUrlResult, *apierror.ApiError) {
+	return &GetFileDownloadUrlResult{Url: "https://download.stub/" + param.FileId}, nil
+}
+
+func (s *stubVideoPlayer) FetchUrl(url string) (*http.Response, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	status, content := 200, s.contents[url]
+	if s.expired[url] {
+		status = 403
+	} else if _, ok := s.contents[url]; !ok {
+		status = 404
+	}
+	return &http.Response{StatusCode: status, Header: http.Header{}, Body: ioutil.NopCloser(bytes.NewReader([]byte(content)))}, nil
+}
+
+func newStubVideoPlayer() *stubVideoPlayer {
+	return &stubVideoPlayer{
+		version: "v1",
+		tasks: func(version string) []VideoTranscodingTask {
+			return []VideoTranscodingTask{
+				{TemplateId: "LD", TemplateWidth: 640, Status: "finished", URL: "https://cdn.stub/" + version + "/LD/index.m3u8"},
+				{TemplateId: "HD", TemplateWidth: 1280, TemplateHeight: 720, Status: "finished", URL: "https://cdn.stub/" + version + "/HD/index.m3u8"},
+				{TemplateId: "FHD", TemplateWidth: 1920, Status: "running"},
+			}
+		},
+		contents: map[string]string{},
+		expired:  map[string]bool{},
+	}
+}
+
+func serveStub(h http.Handler, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	return w
+}
+
+func TestBestTranscode(t *testing.T) {
+	s := newStubVideoPlayer()
+	info, _ := s.VideoGetPreviewPlayInfo(&VideoGetPreviewPlayInfoParam{})
+	assert.Equal(t, "HD", info.BestTranscode(0).TemplateId)
+	assert.Equal(t, "LD", info.BestTranscode(480).TemplateId)
+	assert.Equal(t, 360, info.BestTranscode(480).Height())
+	assert.Nil(t, info.BestTranscode(240))
+
+	source, err := ChooseVideoSource(s, "d1", "f1", 240)
+	require.Nil(t, err)
+	assert.True(t, source.IsOriginal)
+	assert.Equal(t, "https://download.stub/f1", source.Url)
+	assert.Equal(t, 2160, source.Height)
+}
+
+func TestVideoHlsProxyRewritesUriAttributes(t *testing.T) {
+	s := newStubVideoPlayer()
+	s.contents["https://cdn.stub/v1/HD/index.m3u8"] = "#EXTM3U\n" +
+		"#EXT-X-MAP:URI=\"init.mp4\"\n" +
+		"#EXT-X-KEY:METHOD=AES-128,URI=\"https://key.stub/k1\",IV=0x01\n" +
+		"#EXT-X-SESSION-KEY:METHOD=SAMPLE-AES,URI=\"skd://fairplay\"\n" +
+		"#EXTINF:10,\nseg0.ts\n#EXT-X-ENDLIST\n"
+	s.contents["https://cdn.stub/v1/HD/init.mp4"] = "init"
+	s.contents["https://key.stub/k1"] = "key"
+	s.contents["https://cdn.stub/v1/HD/seg0.ts"] = "seg0"
+	proxy := NewVideoHlsProxy(s, "d1", "f1", 0)
+
+	w := serveStub(proxy, "/HD/media.m3u8")
+	require.Equal(t, 200, w.Code, w.Body.String())
+	assert.Equal(t, "#EXTM3U\n"+
+		"#EXT-X-MAP:URI=\"0.mp4\"\n"+
+		"#EXT-X-KEY:METHOD=AES-128,URI=\"1.bin\",IV=0x01\n"+
+		"#EXT-X-SESSION-KEY:METHOD=SAMPLE-AES,URI=\"skd://fairplay\"\n"+
+		"#EXTINF:10,\n2.ts\n#EXT-X-ENDLIST\n", w.Body.String())
+	assert.Equal(t, "init", serveStub(proxy, "/HD/0.mp4").Body.String())
+	assert.Equal(t, "key", serveStub(proxy, "/HD/1.bin").Body.String())
+	w = serveStub(proxy, "/HD/2.ts")
+	assert.Equal(t, "seg0", w.Body.String())
+	assert.Equal(t, "video/mp2t", w.Header().Get("Content-Type"))
+}
+
+func TestVideoHlsProxyRefreshOnce(t *testing.T) {
+	s := newStubVideoPlayer()
+	for _, v := range []string{"v1", "v2"} {
+		s.contents["https://cdn.stub/"+v+"/HD/index.m3u8"] = "#EXTM3U\n#EXTINF:10,\nseg0.ts\n#EXTINF:10,\nseg1.ts\n"
+		s.contents["https://cdn.stub/"+v+"/HD/seg0.ts"] = v + "-seg0"
+		s.contents["https://cdn.stub/"+v+"/HD/seg1.ts"] = v + "-seg1"
+	}
+	proxy := NewVideoHlsProxy(s, "d1", "f1", 0)
+	require.Equal(t, 200, serveStub(proxy, "/HD/media.m3u8").Code)
+	assert.Equal(t, 1, s.infoCalls)
+
+	// 所有上游地址同时过期，并发请求只刷新一次
+	s.mutex.Lock()
+	s.version = "v2"
+	s.expired["https://cdn.stub/v1/HD/seg0.ts"] = true
+	s.expired["https://cdn.stub/v1/HD/seg1.ts"] = true
+	s.mutex.Unlock()
+	wg := sync.WaitGroup{}
+	bodies := make([]string, 8)
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bodies[i] = serveStub(proxy, "/HD/"+[]string{"0", "1"}[i%2]+".ts").Body.String()
+		}(i)
+	}
+	wg.Wait()
+	for i, body := range bodies {
+		assert.Equal(t, []string{"v2-seg0", "v2-seg1"}[i%2], body)
+	}
+	assert.Equal(t, 2, s.infoCalls)
+
+	// 上游的播放列表过期时也会刷新
+	s.mutex.Lock()
+	s.version = "v3"
+	s.contents["https://cdn.stub/v3/HD/index.m3u8"] = "#EXTM3U\n#EXTINF:10,\nseg0.ts\n"
+	s.expired["https://cdn.stub/v2/HD/seg0.ts"] = true
+	s.mutex.Unlock()
+	proxy.RefreshInterval = 1
+	assert.Equal(t, "#EXTM3U\n#EXTINF:10,\n0.ts\n", serveStub(proxy, "/HD/media.m3u8").Body.String())
+	assert.Equal(t, 404, serveStub(proxy, "/FHD/media.m3u8").Code)
+}
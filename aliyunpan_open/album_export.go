@@ -0,0 +1,74 @@
+package aliyunpan_open
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+type (
+	// albumExportOperator 实现 aliyunpan.AlbumExportOperator，shared 为 true 时导出共享相册
+	albumExportOperator struct {
+		*OpenPanClient
+		albumId string
+		shared  bool
+	}
+)
+
+// AlbumExport 把个人相簿中的所有文件下载到本地文件夹，支持增量导出，详见 aliyunpan.ExportAlbum
+func (p *OpenPanClient) AlbumExport(albumId string, param *aliyunpan.AlbumExportParam, handler aliyunpan.AlbumExportFunc) ([]*aliyunpan.AlbumExportItem, *apierror.ApiError) {
+	return aliyunpan.ExportAlbum(&albumExportOperator{OpenPanClient: p, albumId: albumId}, param, handler)
+}
+
+// ShareAlbumExport 把共享相册中的所有文件下载到本地文件夹，支持增量导出，详见 aliyunpan.ExportAlbum
+func (p *OpenPanClient) ShareAlbumExport(albumId string, param *aliyunpan.AlbumExportParam, handler aliyunpan.AlbumExportFunc) ([]*aliyunpan.AlbumExportItem, *apierror.ApiError) {
+	return aliyunpan.ExportAlbum(&albumExportOperator{OpenPanClient: p, albumId: albumId, shared: true}, param, handler)
+}
+
+// AlbumFileList 获取相簿中的所有文件
+func (o *albumExportOperator) AlbumFileList() (aliyunpan.FileList, *apierror.ApiError) {
+	if o.shared {
+		return o.ShareAlbumListFileGetAll(&aliyunpan.ShareAlbumListFileParam{AlbumId: o.albumId})
+	}
+	return o.AlbumListFileGetAll(&aliyunpan.AlbumListFileParam{AlbumId: o.albumId})
+}
+
+// AlbumFileUrl 获取相簿文件的下载地址
+func (o *albumExportOperator) AlbumFileUrl(file *aliyunpan.FileEntity) (*aliyunpan.ShareAlbumGetFileUrlResult, *apierror.ApiError) {
+	if o.shared {
+		return o.ShareAlbumGetFileDownloadUrl(&aliyunpan.ShareAlbumGetFileUrlParam{
+			AlbumId: o.albumId,
+			DriveId: file.DriveId,
+			FileId:  file.FileId,
+		})
+	}
+	r, err := o.GetFileDownloadUrl(&aliyunpan.GetFileDownloadUrlParam{
+		DriveId: file.DriveId,
+		FileId:  file.FileId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &aliyunpan.ShareAlbumGetFileUrlResult{
+		Url:        r.Url,
+		Expiration: r.Expiration,
+		Size:       r.Size,
+	}, nil
+}
+
+// OpenUrl 打开下载地址，使用数据传输的 http 客户端，不受接口请求超时时间的限制
+func (o *albumExportOperator) OpenUrl(url string) (io.ReadCloser, error) {
+	resp, err := o.transferClient.Req("GET", url, nil, map[string]string{
+		"referer": "https://www.aliyundrive.com/",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 && resp.StatusCode != 206 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected http status code, %d", resp.StatusCode)
+	}
+	return aliyunpan.NewBandwidthLimitedReadCloser(resp.Body, o.downloadLimiter), nil
+}
@@ -0,0 +1,112 @@
+package aliyunpan_open
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+func fakeLivp(t *testing.T) []byte {
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for name, content := range map[string]string{"IMG_0001.HEIC": "heic", "IMG_0001.MOV": "mov"} {
+		f, err := w.Create(name)
+		require.Nil(t, err)
+		f.Write([]byte(content))
+	}
+	require.Nil(t, w.Close())
+	return buf.Bytes()
+}
+
+func readLocal(t *testing.T, dir, name string) string {
+	data, err := ioutil.ReadFile(filepath.Join(dir, name))
+	require.Nil(t, err, name)
+	return string(data)
+}
+
+func TestAlbumExport(t *testing.T) {
+	d := newFakeDrive()
+	sub := d.add("d1", "root", "sub", "folder", 0, "")
+	a1 := d.add("d1", "root", "a.jpg", "file", 8, "")
+	a2 := d.add("d1", sub, "a.jpg", "file", 8, "")
+	live := d.add("d1", "root", "live.livp", "file", 100, "")
+	d.contents["/d1/"+live] = fakeLivp(t)
+	d.contents["/stream/"+live+".jpeg"] = []byte("jpeg")
+	d.contents["/stream/"+live+".mov"] = []byte("stream-mov")
+	p := newFakeClient(t, d)
+
+	album, err := p.AlbumCreate(&aliyunpan.AlbumCreateParam{Name: "旅行"})
+	require.Nil(t, err)
+	addParam := &aliyunpan.AlbumFileParam{AlbumId: album.AlbumId}
+	for _, id := range []string{a1, a2, live} {
+		addParam.AddFileItem("d1", id)
+	}
+	_, err = p.AlbumAddFile(addParam)
+	require.Nil(t, err)
+
+	// 实况图片解压为图片和视频，同名文件自动重命名
+	dir := t.TempDir()
+	items, err := p.AlbumExport(album.AlbumId, &aliyunpan.AlbumExportParam{LocalDir: dir}, nil)
+	require.Nil(t, err)
+	require.Len(t, items, 3)
+	for _, item := range items {
+		require.Nil(t, item.Err)
+	}
+	assert.Equal(t, "12345678", readLocal(t, dir, "a.jpg"))
+	assert.Equal(t, "12345678", readLocal(t, dir, "a(1).jpg"))
+	assert.Equal(t, "heic", readLocal(t, dir, "live.heic"))
+	assert.Equal(t, "mov", readLocal(t, dir, "live.mov"))
+	assert.NoFileExists(t, filepath.Join(dir, "live.livp.tmp"))
+	info, e := os.Stat(filepath.Join(dir, "a.jpg"))
+	require.Nil(t, e)
+	created := d.get("d1", a1).CreatedAt
+	expected, _ := time.Parse(time.RFC3339, created)
+	assert.True(t, expected.Equal(info.ModTime()), info.ModTime().String())
+
+	// 再次导出时跳过没有变化的文件
+	downloads := d.count("/" + a1)
+	items, err = p.AlbumExport(album.AlbumId, &aliyunpan.AlbumExportParam{LocalDir: dir}, nil)
+	require.Nil(t, err)
+	for _, item := range items {
+		assert.True(t, item.Skipped)
+	}
+	assert.Equal(t, downloads, d.count("/"+a1))
+
+	// 本地文件被删除后重新导出，文件名保持不变
+	require.Nil(t, os.Remove(filepath.Join(dir, "live.mov")))
+	skipped := 0
+	_, err = p.AlbumExport(album.AlbumId, &aliyunpan.AlbumExportParam{LocalDir: dir}, func(item *aliyunpan.AlbumExportItem) {
+		if item.Skipped {
+			skipped++
+		}
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 2, skipped)
+	assert.Equal(t, "mov", readLocal(t, dir, "live.mov"))
+	assert.NoFileExists(t, filepath.Join(dir, "live(1).heic"))
+
+	// 切换为保留 livp 文件
+	items, err = p.AlbumExport(album.AlbumId, &aliyunpan.AlbumExportParam{LocalDir: dir, LivePhotoMode: aliyunpan.LivePhotoLivp}, nil)
+	require.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "live.livp")}, items[2].LocalPaths)
+	assert.NoFileExists(t, filepath.Join(dir, "live.heic"))
+
+	// 共享相册使用单独的图片和视频下载流
+	d.albumFiles["sa1"] = []string{fakeKey("d1", live), fakeKey("d1", a1)}
+	sharedDir := t.TempDir()
+	items, err = p.ShareAlbumExport("sa1", &aliyunpan.AlbumExportParam{LocalDir: sharedDir, LivePhotoStill: aliyunpan.LivePhotoStillJpeg}, nil)
+	require.Nil(t, err)
+	require.Len(t, items, 2)
+	require.Nil(t, items[0].Err)
+	assert.Equal(t, "jpeg", readLocal(t, sharedDir, "live.jpg"))
+	assert.Equal(t, "stream-mov", readLocal(t, sharedDir, "live.mov"))
+	assert.Equal(t, "12345678", readLocal(t, sharedDir, "a.jpg"))
+}
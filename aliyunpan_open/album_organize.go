@@ -0,0 +1,43 @@
+package aliyunpan_open
+
+import (
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+type (
+	// albumOrganizeOperator 实现 aliyunpan.AlbumOrganizeOperator
+	albumOrganizeOperator struct {
+		*OpenPanClient
+	}
+)
+
+// AlbumOrganize 按照规则把网盘文件夹中的图片和视频归类到个人相簿中，可以重复执行，详见 aliyunpan.OrganizeAlbums
+func (p *OpenPanClient) AlbumOrganize(param *aliyunpan.AlbumOrganizeParam) (*aliyunpan.AlbumOrganizeResult, *apierror.ApiError) {
+	return aliyunpan.OrganizeAlbums(albumOrganizeOperator{p}, param)
+}
+
+// ListAlbums 获取所有相簿
+func (o albumOrganizeOperator) ListAlbums() (aliyunpan.AlbumList, *apierror.ApiError) {
+	return o.AlbumListGetAll(&aliyunpan.AlbumListParam{})
+}
+
+// CreateAlbum 创建相簿
+func (o albumOrganizeOperator) CreateAlbum(name string) (*aliyunpan.AlbumEntity, *apierror.ApiError) {
+	return o.AlbumCreate(&aliyunpan.AlbumCreateParam{Name: name})
+}
+
+// ListAlbumFiles 获取相簿中的所有文件
+func (o albumOrganizeOperator) ListAlbumFiles(albumId string) (aliyunpan.FileList, *apierror.ApiError) {
+	return o.AlbumListFileGetAll(&aliyunpan.AlbumListFileParam{AlbumId: albumId})
+}
+
+// AddAlbumFiles 把文件加入相簿
+func (o albumOrganizeOperator) AddAlbumFiles(albumId string, files aliyunpan.FileList) *apierror.ApiError {
+	param := &aliyunpan.AlbumFileParam{AlbumId: albumId}
+	for _, f := range files {
+		param.AddFileItem(f.DriveId, f.FileId)
+	}
+	_, err := o.AlbumAddFile(param)
+	return err
+}
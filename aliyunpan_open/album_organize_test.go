@@ -0,0 +1,80 @@
+package aliyunpan_open
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+func TestAlbumOrganize(t *testing.T) {
+	d := newFakeDrive()
+	dcim := d.add("d1", "root", "DCIM", "folder", 0, "")
+	camera := d.add("d1", dcim, "Camera", "folder", 0, "")
+	screenshots := d.add("d1", dcim, "Screenshots", "folder", 0, "")
+	media := func(parent, name, category, createdAt string) string {
+		id := d.add("d1", parent, name, "file", 10, "")
+		f := d.get("d1", id)
+		f.Category, f.CreatedAt = category, createdAt
+		return id
+	}
+	a := media(camera, "a.jpg", "image", "2023-03-15T12:00:00.000Z")
+	media(camera, "b.mp4", "video", "2023-03-20T12:00:00.000Z")
+	media(camera, "c.jpg", "image", "2023-04-10T12:00:00.000Z")
+	media(camera, "doc.txt", "doc", "2023-04-10T12:00:00.000Z")
+	media(screenshots, "s.png", "image", "2023-04-11T12:00:00.000Z")
+	p := newFakeClient(t, d)
+
+	// 已经存在的相簿和其中的文件
+	march, err := p.AlbumCreate(&aliyunpan.AlbumCreateParam{Name: "2023-03"})
+	require.Nil(t, err)
+	addParam := &aliyunpan.AlbumFileParam{AlbumId: march.AlbumId}
+	addParam.AddFileItem("d1", a)
+	_, err = p.AlbumAddFile(addParam)
+	require.Nil(t, err)
+	addCalls := d.count("/album/addFile")
+
+	param := &aliyunpan.AlbumOrganizeParam{
+		DriveId: "d1",
+		Path:    "/DCIM",
+		Rules: []*aliyunpan.AlbumOrganizeRule{
+			{Type: aliyunpan.AlbumRuleByMonth},
+			{Type: aliyunpan.AlbumRuleByFolder, Prefix: "文件夹-"},
+		},
+		BatchSize: 2,
+	}
+	r, err := p.AlbumOrganize(param)
+	require.Nil(t, err)
+	assert.Equal(t, 5, r.ScannedCount)
+	assert.Equal(t, 4, r.MatchedCount)
+	counts := map[string][3]int{}
+	for _, album := range r.Albums {
+		created := 0
+		if album.Created {
+			created = 1
+		}
+		counts[album.Album.Name] = [3]int{created, album.AddedCount, album.SkippedCount}
+	}
+	assert.Equal(t, map[string][3]int{
+		"2023-03":         {0, 1, 1},
+		"2023-04":         {1, 2, 0},
+		"文件夹-Camera":      {1, 3, 0},
+		"文件夹-Screenshots": {1, 1, 0},
+	}, counts)
+	// 每批最多2个文件
+	assert.Equal(t, addCalls+5, d.count("/album/addFile"))
+	assert.Len(t, d.albums, 4)
+	assert.Len(t, d.albumFiles[march.AlbumId], 2)
+
+	// 重复执行不会创建相簿，也不会重复加入文件
+	addCalls = d.count("/album/addFile")
+	r, err = p.AlbumOrganize(param)
+	require.Nil(t, err)
+	for _, album := range r.Albums {
+		assert.False(t, album.Created)
+		assert.Equal(t, 0, album.AddedCount)
+	}
+	assert.Len(t, d.albums, 4)
+	assert.Equal(t, addCalls, d.count("/album/addFile"))
+}
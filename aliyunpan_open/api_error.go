@@ -1,7 +1,6 @@
 package aliyunpan_open
 
 import (
-	"strconv"
 	"time"
 
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
@@ -31,48 +30,15 @@ func (p *OpenPanClient) ParseAliApiError(respErr *openapi.AliApiErrResult) *apie
 		return nil
 	}
 
-	switch respErr.HttpStatusCode {
-	case 200:
-		return apierror.NewFailedApiError(respErr.Message)
-	case 400:
-		if respErr.Code == "NotFound.File" {
-			return apierror.NewApiError(apierror.ApiCodeFileNotFoundCode, respErr.Message)
-		} else if respErr.Code == "NotFound.UploadId" {
-			return apierror.NewApiError(apierror.ApiCodeUploadIdNotFound, respErr.Message)
-		}
-	case 401:
-		if respErr.Code == "AccessTokenExpired" {
-			return apierror.NewApiError(apierror.ApiCodeTokenExpiredCode, respErr.Message)
-		} else if respErr.Code == "AccessTokenInvalid" {
-			return apierror.NewApiError(apierror.ApiCodeTokenExpiredCode, respErr.Message)
-		} else if respErr.Code == "RefreshTokenExpired" {
-			return apierror.NewApiError(apierror.ApiCodeRefreshTokenExpiredCode, respErr.Message)
-		}
-	case 403:
-		if respErr.Code == "PermissionDenied" {
-			return apierror.NewApiError(apierror.ApiCodePermissionDenied, respErr.Message)
-		} else if respErr.Code == "UserNotAllowedAccessDrive" {
-			return apierror.NewApiError(apierror.ApiCodeUserNotAllowedAccessDrive, respErr.Message)
-		} else if respErr.Code == "FileShareNotAllowed" {
-			return apierror.NewApiError(apierror.ApiCodeFileShareNotAllowed, respErr.Message)
-		}
-	case 404:
-		if respErr.Code == "NotFound.FileId" {
-			return apierror.NewApiError(apierror.ApiCodeFileNotFoundCode, respErr.Message)
-		} else if respErr.Code == "Not Found" {
-			return apierror.NewApiError(apierror.ApiCodeFailed, respErr.Message)
-		}
-	case 409:
-	case 429:
-		if respErr.Code == "TooManyRequests" {
-			return apierror.NewApiError(apierror.ApiCodeTooManyRequests, respErr.Message)
-		}
-	case 413:
-		if respErr.Code == "Payload Too Large" {
-			return apierror.NewApiError(apierror.ApiCodeUploadPayloadTooLarge, respErr.Message)
-		}
+	if cause := respErr.Cause(); cause != nil {
+		// 网络错误、本地处理错误，保留底层错误
+		return apierror.NewApiErrorWithError(cause)
+	}
+	if respErr.HttpStatusCode == 200 {
+		// 本地封装的错误
+		return apierror.NewFailedApiError(respErr.Message).WithRequestId(respErr.RequestId)
 	}
-	return apierror.NewFailedApiError(respErr.Message)
+	return apierror.NewServerApiError(respErr.HttpStatusCode, respErr.Code, respErr.Message, respErr.RequestId)
 }
 
 // HandleAliApiError 处理公共错误
@@ -88,29 +54,12 @@ func (p *OpenPanClient) HandleAliApiError(respErr *openapi.AliApiErrResult, retr
 			logger.Verboseln("get new access token from server error: ", tokenErr)
 			return NewApiErrorHandleResp(false, myApiErr)
 		}
-		// retry check
-		if *retryTime < ApiRetryMaxTimes {
-			*retryTime++
-			return NewApiErrorHandleResp(true, myApiErr)
-		}
-	} else if myApiErr.Code == apierror.ApiCodeTooManyRequests {
-		// sleep
-		// 可以根据429和 x-retry-after 头部来判断等待重试的时间
-		if retryMillisecond := respErr.GetExtra("x-retry-after"); retryMillisecond != nil {
-			num, err := strconv.Atoi(retryMillisecond.(string))
-			if err == nil {
-				// 比官方要的延迟时间多1s
-				time.Sleep(time.Duration(int64(num)+1000) * time.Millisecond)
-			}
-		} else {
-			time.Sleep(time.Duration(int64(*retryTime+1)*2) * time.Second)
-		}
-
 		// retry check
 		if *retryTime < ApiRetryMaxTimes {
 			*retryTime++
 			return NewApiErrorHandleResp(true, myApiErr)
 		}
 	}
+	// 限流（429 x-retry-after）、网关错误等已经由传输层按照 RetryPolicy 重试过了，这里不再重复重试
 	return NewApiErrorHandleResp(false, myApiErr)
 }
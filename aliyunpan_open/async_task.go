@@ -0,0 +1,53 @@
+package aliyunpan_open
+
+import (
+	"time"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan_open/openapi"
+)
+
+// AsyncTaskQueryStatus 查询异步任务状态
+func (p *OpenPanClient) AsyncTaskQueryStatus(asyncTaskId string) (*openapi.AsyncTaskQueryStatusResult, *apierror.ApiError) {
+	retryTime := 0
+
+RetryBegin:
+	opParam := &openapi.AsyncTaskQueryStatusParam{
+		AsyncTaskId: asyncTaskId,
+	}
+	if result, err := p.apiClient.AsyncTaskQueryStatus(opParam); err == nil {
+		return result, nil
+	} else {
+		// handle common error
+		if apiErrorHandleResp := p.HandleAliApiError(err, &retryTime); apiErrorHandleResp.NeedRetry {
+			goto RetryBegin
+		} else {
+			return nil, apiErrorHandleResp.ApiErr
+		}
+	}
+}
+
+// WaitAsyncTask 等待异步任务完成，asyncTaskId 为空说明操作已经同步完成
+func (p *OpenPanClient) WaitAsyncTask(asyncTaskId string) *apierror.ApiError {
+	if asyncTaskId == "" {
+		return nil
+	}
+	deadline := time.Now().Add(aliyunpan.AsyncTaskWaitTimeout)
+	for {
+		result, err := p.AsyncTaskQueryStatus(asyncTaskId)
+		if err != nil {
+			return err
+		}
+		switch result.State {
+		case aliyunpan.AsyncTaskStateSucceed:
+			return nil
+		case aliyunpan.AsyncTaskStateFailed:
+			return apierror.NewFailedApiError("异步任务执行失败: " + asyncTaskId)
+		}
+		if time.Now().After(deadline) {
+			return apierror.NewFailedApiError("等待异步任务超时: " + asyncTaskId)
+		}
+		time.Sleep(aliyunpan.AsyncTaskPollInterval)
+	}
+}
@@ -0,0 +1,30 @@
+package aliyunpan_open
+
+import (
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+type (
+	// diskUsageOperator 实现 aliyunpan.DiskUsageOperator，noCache 为 true 时不使用元数据缓存
+	diskUsageOperator struct {
+		*OpenPanClient
+		noCache bool
+	}
+)
+
+// DiskUsage 递归统计目录的占用空间，每个目录统计完成后通过 handler 返回，详见 aliyunpan.DiskUsage
+func (p *OpenPanClient) DiskUsage(param *aliyunpan.DiskUsageParam, handler aliyunpan.DiskUsageFunc) (*aliyunpan.DiskUsageResult, *apierror.ApiError) {
+	if param == nil {
+		return nil, apierror.NewFailedApiError("参数不能为空")
+	}
+	return aliyunpan.DiskUsage(diskUsageOperator{p, param.NoCache}, param, handler)
+}
+
+// FileListGetAll 获取目录下的所有文件
+func (o diskUsageOperator) FileListGetAll(param *aliyunpan.FileListParam, delayMilliseconds int) (aliyunpan.FileList, *apierror.ApiError) {
+	if o.noCache {
+		return o.fileListGetAll(param, delayMilliseconds)
+	}
+	return o.OpenPanClient.FileListGetAll(param, delayMilliseconds)
+}
@@ -0,0 +1,86 @@
+package aliyunpan_open
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/metacache"
+)
+
+func TestDiskUsage(t *testing.T) {
+	d := newFakeDrive()
+	movies := d.add("backup", "root", "movies", "folder", 0, "")
+	hd := d.add("backup", movies, "hd", "folder", 0, "")
+	d.add("backup", hd, "a.MKV", "file", 1000, "A")
+	d.add("backup", hd, "b.mkv", "file", 3000, "B")
+	d.add("backup", movies, "c.mp4", "file", 500, "C")
+	docs := d.add("backup", "root", "docs", "folder", 0, "")
+	d.add("backup", docs, "readme", "file", 10, "D")
+	d.add("backup", "root", "x.txt", "file", 1, "E")
+	p := newFakeClient(t, d)
+
+	entries := []*aliyunpan.DiskUsageEntry{}
+	result, err := p.DiskUsage(&aliyunpan.DiskUsageParam{
+		DriveId:  "backup",
+		MaxDepth: 1,
+		TopN:     2,
+	}, func(entry *aliyunpan.DiskUsageEntry) bool {
+		entries = append(entries, entry)
+		return true
+	})
+	require.Nil(t, err)
+	assert.True(t, result.Completed)
+	assert.Equal(t, int64(4511), result.Root.Size)
+	assert.Equal(t, int64(5), result.Root.FileCount)
+	assert.Equal(t, int64(3), result.Root.FolderCount)
+
+	// 子目录先返回，超过深度的目录不返回但是计入上层目录
+	paths := []string{}
+	for _, e := range entries {
+		paths = append(paths, e.Path)
+	}
+	assert.Equal(t, []string{"/docs", "/movies", "/"}, paths)
+	assert.Equal(t, int64(4500), entries[1].Size)
+	assert.Equal(t, int64(1), entries[1].FolderCount)
+
+	assert.Equal(t, int64(4000), result.Extensions["mkv"].Size)
+	assert.Equal(t, int64(2), result.Extensions["mkv"].FileCount)
+	assert.Equal(t, int64(10), result.Extensions[""].Size)
+	assert.Equal(t, int64(5), result.Categories["others"].FileCount)
+	require.Len(t, result.TopFiles, 2)
+	assert.Equal(t, "/movies/hd/b.mkv", result.TopFiles[0].Path)
+	assert.Equal(t, "/movies/hd/a.MKV", result.TopFiles[1].Path)
+
+	// 中途停止
+	result, err = p.DiskUsage(&aliyunpan.DiskUsageParam{DriveId: "backup", Path: "/movies"}, func(entry *aliyunpan.DiskUsageEntry) bool {
+		return false
+	})
+	require.Nil(t, err)
+	assert.False(t, result.Completed)
+}
+
+func TestDiskUsageNoCache(t *testing.T) {
+	d := newFakeDrive()
+	docs := d.add("d1", "root", "docs", "folder", 0, "")
+	d.add("d1", docs, "a.txt", "file", 10, "A")
+	p := newFakeClient(t, d)
+	store, _ := metacache.NewJsonFileStore("")
+	p.SetMetaCache(metacache.NewCache(store, time.Hour))
+
+	result, err := p.DiskUsage(&aliyunpan.DiskUsageParam{DriveId: "d1"}, nil)
+	require.Nil(t, err)
+	assert.Equal(t, int64(10), result.Root.Size)
+
+	// 缓存有效期内使用缓存的目录列表，看不到其他客户端新增的文件
+	d.add("d1", docs, "b.txt", "file", 20, "B")
+	result, err = p.DiskUsage(&aliyunpan.DiskUsageParam{DriveId: "d1"}, nil)
+	require.Nil(t, err)
+	assert.Equal(t, int64(10), result.Root.Size)
+
+	result, err = p.DiskUsage(&aliyunpan.DiskUsageParam{DriveId: "d1", NoCache: true}, nil)
+	require.Nil(t, err)
+	assert.Equal(t, int64(30), result.Root.Size)
+}
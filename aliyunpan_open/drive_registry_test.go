@@ -0,0 +1,49 @@
+package aliyunpan_open
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+func TestQualifiedPath(t *testing.T) {
+	for _, c := range []struct {
+		in, alias, path string
+		ok              bool
+	}{
+		{"resource:/Movies/x.mkv", "resource", "/Movies/x.mkv", true},
+		{"backup:", "backup", "/", true},
+		{"/Movies/a:b.mkv", "", "/Movies/a:b.mkv", false},
+		{"resource:Movies", "", "resource:Movies", false},
+		{"*:/a", "", "*:/a", false},
+	} {
+		alias, p, ok := aliyunpan.ParseQualifiedPath(c.in)
+		assert.Equal(t, c.ok, ok, c.in)
+		assert.Equal(t, c.alias, alias, c.in)
+		assert.Equal(t, c.path, p, c.in)
+	}
+
+	d := newFakeDrive()
+	dir := d.add("resource", "root", "Movies", "folder", 0, "")
+	d.add("resource", dir, "x.mkv", "file", 100, "X")
+	p := newFakeClient(t, d)
+	p.GetDriveRegistry().LoadFromUserInfo(&aliyunpan.UserInfo{FileDriveId: "backup", ResourceDriveId: "resource"})
+
+	f, err := p.FileInfoByPath("backup", "resource:/Movies/x.mkv")
+	require.Nil(t, err)
+	assert.Equal(t, "resource", f.DriveId)
+	assert.Equal(t, "/Movies/x.mkv", f.Path)
+
+	files, err := p.MatchPathByShellPattern("", "resource:/Movies/*.mkv")
+	require.Nil(t, err)
+	require.Len(t, *files, 1)
+
+	r, err := p.MkdirByFullPath("", "resource:/Movies/2023")
+	require.Nil(t, err)
+	assert.Equal(t, "resource", d.get("resource", r.FileId).DriveId)
+
+	_, err = p.FileInfoByPath("", "safebox:/a")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,470 @@
+package aliyunpan_open
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
+	"github.com/tickstep/aliyunpan-api/aliyunpan_open/openapi"
+)
+
+// fakeDrive 内存中的网盘，实现 OpenAPI 常用的文件接口，用于测试
+type fakeDrive struct {
+	mutex  sync.Mutex
+	files  map[string]*openapi.FileItem
+	nextId int
+	// calls 记录请求的接口路径
+	calls []string
+	// shares 当前用户的分享，取消后从列表中删除
+	shares []*openapi.FileShareItem
+	// albums 个人相簿，albumFiles 保存相簿中文件的 fakeKey
+	albums     []*openapi.AlbumItem
+	albumFiles map[string][]string
+	// contents 下载地址路径对应的文件内容，没有则返回 12345678
+	contents map[string][]byte
+	// expired 已经过期的下载地址路径，请求时返回 403
+	expired map[string]bool
+	// transcodes 视频文件的转码，key 为 fakeKey
+	transcodes map[string][]*openapi.LiveTranscodingTask
+}
+
+func newFakeDrive() *fakeDrive {
+	return &fakeDrive{files: map[string]*openapi.FileItem{}, albumFiles: map[string][]string{}, contents: map[string][]byte{},
+		expired: map[string]bool{}, transcodes: map[string][]*openapi.LiveTranscodingTask{}}
+}
+
+// newFakeClient 创建访问 fakeDrive 的客户端
+func newFakeClient(t *testing.T, d *fakeDrive) *OpenPanClient {
+	p := NewOpenPanClient(openapi.ApiConfig{}, openapi.ApiToken{AccessToken: "test"}, nil, apitransport.WithTransport(d))
+	p.SetRetryPolicy(aliyunpan.NoRetryPolicy())
+	// 不限速，避免测试变慢
+	p.GetGovernor().SetConfig(apitransport.GovernorConfig{})
+	return p
+}
+
+func fakeKey(driveId, fileId string) string {
+	return driveId + "/" + fileId
+}
+
+// add 添加文件，返回文件ID
+func (d *fakeDrive) add(driveId, parentFileId, name, fileType string, size int64, contentHash string) string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.addLocked(driveId, parentFileId, name, fileType, size, contentHash)
+}
+
+func (d *fakeDrive) addLocked(driveId, parentFileId, name, fileType string, size int64, contentHash string) string {
+	d.nextId++
+	f := &openapi.FileItem{
+		DriveId:      driveId,
+		ParentFileId: parentFileId,
+		FileId:       fmt.Sprintf("f%04d", d.nextId),
+		Name:         name,
+		Type:         fileType,
+		Size:         size,
+		ContentHash:  contentHash,
+		CreatedAt:    fmt.Sprintf("2023-01-01T00:00:%02d.000Z", d.nextId%60),
+		UpdatedAt:    fmt.Sprintf("2023-01-01T00:00:%02d.000Z", d.nextId%60),
+	}
+	if fileType == "file" {
+		f.FileExtension = strings.TrimPrefix(path.Ext(name), ".")
+		f.ContentHashName = "sha1"
+	}
+	d.files[fakeKey(driveId, f.FileId)] = f
+	return f.FileId
+}
+
+func (d *fakeDrive) get(driveId, fileId string) *openapi.FileItem {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.files[fakeKey(driveId, fileId)]
+}
+
+// names 返回目录下的文件名，按名称排序
+func (d *fakeDrive) names(driveId, parentFileId string) []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	names := []string{}
+	for _, f := range d.children(driveId, parentFileId) {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// count 返回接口的调用次数
+func (d *fakeDrive) count(apiPath string) int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	n := 0
+	for _, c := range d.calls {
+		if strings.HasSuffix(c, apiPath) {
+			n++
+		}
+	}
+	return n
+}
+
+func (d *fakeDrive) children(driveId, parentFileId string) []*openapi.FileItem {
+	items := []*openapi.FileItem{}
+	for _, f := range d.files {
+		if f.DriveId == driveId && f.ParentFileId == parentFileId {
+			items = append(items, f)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items
+}
+
+func (d *fakeDrive) findByName(driveId, parentFileId, name string) *openapi.FileItem {
+	for _, f := range d.children(driveId, parentFileId) {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func (d *fakeDrive) uniqueName(driveId, parentFileId, name string) string {
+	if d.findByName(driveId, parentFileId, name) == nil {
+		return name
+	}
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; ; i++ {
+		n := fmt.Sprintf("%s(%d)%s", base, i, ext)
+		if d.findByName(driveId, parentFileId, n) == nil {
+			return n
+		}
+	}
+}
+
+func (d *fakeDrive) copyTree(f *openapi.FileItem, toDriveId, toParentFileId, name string) string {
+	id := d.addLocked(toDriveId, toParentFileId, name, f.Type, f.Size, f.ContentHash)
+	for _, c := range d.children(f.DriveId, f.FileId) {
+		d.copyTree(c, toDriveId, id, c.Name)
+	}
+	return id
+}
+
+func (d *fakeDrive) removeTree(f *openapi.FileItem) {
+	for _, c := range d.children(f.DriveId, f.FileId) {
+		d.removeTree(c)
+	}
+	delete(d.files, fakeKey(f.DriveId, f.FileId))
+}
+
+func (d *fakeDrive) byPath(driveId, filePath string) *openapi.FileItem {
+	parentFileId := aliyunpan.DefaultRootParentFileId
+	var f *openapi.FileItem
+	for _, name := range strings.Split(strings.Trim(filePath, "/"), "/") {
+		if f = d.findByName(driveId, parentFileId, name); f == nil {
+			return nil
+		}
+		parentFileId = f.FileId
+	}
+	return f
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (d *fakeDrive) RoundTrip(req *http.Request) (*http.Response, error) {
+	param := map[string]interface{}{}
+	if req.Body != nil {
+		data, _ := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		json.Unmarshal(data, &param)
+	}
+	str := func(key string) string {
+		v, _ := param[key].(string)
+		return v
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.calls = append(d.calls, req.URL.Path)
+
+	if req.URL.Host == "download.fake" {
+		// 文件内容
+		if d.expired[req.URL.Path] {
+			return &http.Response{StatusCode: 403, Header: http.Header{}, Body: ioutil.NopCloser(bytes.NewReader(nil)), Request: req}, nil
+		}
+		content, ok := d.contents[req.URL.Path]
+		if !ok {
+			content = []byte("12345678")
+		}
+		return &http.Response{
+			StatusCode: 206,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(bytes.NewReader(content)),
+			Request:    req,
+		}, nil
+	}
+
+	notFound := map[string]interface{}{"code": "NotFound.File", "message": "file not found"}
+	var status = 200
+	var result interface{}
+	switch strings.TrimPrefix(req.URL.Path, "/adrive/v1.0/openFile/") {
+	case "list":
+		result = map[string]interface{}{"items": d.children(str("drive_id"), str("parent_file_id")), "next_marker": ""}
+	case "get":
+		if f := d.files[fakeKey(str("drive_id"), str("file_id"))]; f != nil {
+			result = f
+		} else {
+			status, result = 404, notFound
+		}
+	case "get_by_path":
+		if f := d.byPath(str("drive_id"), str("file_path")); f != nil {
+			result = f
+		} else {
+			status, result = 404, notFound
+		}
+	case "create":
+		name := str("name")
+		if str("check_name_mode") == "auto_rename" {
+			name = d.uniqueName(str("drive_id"), str("parent_file_id"), name)
+		} else if f := d.findByName(str("drive_id"), str("parent_file_id"), name); f != nil && str("type") == "file" {
+			// refuse 模式下返回已存在的文件
+			result = map[string]interface{}{"drive_id": f.DriveId, "parent_file_id": f.ParentFileId, "file_id": f.FileId, "file_name": f.Name, "exist": true}
+			break
+		}
+		size, _ := param["size"].(float64)
+		id := d.addLocked(str("drive_id"), str("parent_file_id"), name, str("type"), int64(size), str("content_hash"))
+		// 网盘中有相同内容的文件并且带了防伪码才能秒传
+		rapid := false
+		for _, f := range d.files {
+			if f.FileId != id && f.ContentHash != "" && f.ContentHash == str("content_hash") && (size == 0 || str("proof_code") != "") {
+				rapid = true
+			}
+		}
+		result = map[string]interface{}{"drive_id": str("drive_id"), "parent_file_id": str("parent_file_id"), "file_id": id, "file_name": name, "rapid_upload": rapid}
+	case "getDownloadUrl":
+		result = map[string]interface{}{"url": "https://download.fake/" + str("drive_id") + "/" + str("file_id"), "method": "GET"}
+	case "batch/get":
+		// 按照后缀名返回缩略图和图片元数据
+		items := []*openapi.FileItem{}
+		fileList, _ := param["file_list"].([]interface{})
+		for _, v := range fileList {
+			pair := v.(map[string]interface{})
+			f := d.files[fakeKey(pair["drive_id"].(string), pair["file_id"].(string))]
+			if f == nil {
+				continue
+			}
+			item := *f
+			switch item.FileExtension {
+			case "jpg":
+				item.Thumbnail = fmt.Sprintf("https://thumb.fake/%s?w=%v", f.FileId, param["image_thumbnail_width"])
+				item.Url = "https://download.fake/preview/" + f.FileId
+				item.ImageMediaMetadata = &openapi.ImageMediaMetadata{Width: 4032, Height: 3024, Exif: `{"Model":"fake"}`}
+			case "mp4":
+				item.Thumbnail = fmt.Sprintf("https://thumb.fake/%s?w=%v&t=%v", f.FileId, param["video_thumbnail_width"], param["video_thumbnail_time"])
+			}
+			items = append(items, &item)
+		}
+		result = map[string]interface{}{"items": items}
+	case "getVideoPreviewPlayInfo":
+		if f := d.files[fakeKey(str("drive_id"), str("file_id"))]; f != nil {
+			info := map[string]interface{}{
+				"category":                   str("category"),
+				"meta":                       map[string]interface{}{"duration": 60.5, "width": 3840, "height": 2160},
+				"live_transcoding_task_list": d.transcodes[fakeKey(f.DriveId, f.FileId)],
+			}
+			result = map[string]interface{}{"drive_id": f.DriveId, "file_id": f.FileId, "video_preview_play_info": info}
+		} else {
+			status, result = 404, notFound
+		}
+	case "copy":
+		f := d.files[fakeKey(str("drive_id"), str("file_id"))]
+		if f == nil {
+			status, result = 404, notFound
+			break
+		}
+		toDriveId := str("to_drive_id")
+		if toDriveId == "" {
+			toDriveId = f.DriveId
+		}
+		name := f.Name
+		if auto, _ := param["auto_rename"].(bool); auto {
+			name = d.uniqueName(toDriveId, str("to_parent_file_id"), name)
+		}
+		id := d.copyTree(f, toDriveId, str("to_parent_file_id"), name)
+		result = map[string]interface{}{"drive_id": toDriveId, "file_id": id, "async_task_id": "task-" + id}
+	case "move":
+		f := d.files[fakeKey(str("drive_id"), str("file_id"))]
+		if f == nil {
+			status, result = 404, notFound
+			break
+		}
+		name := f.Name
+		if str("new_name") != "" {
+			name = str("new_name")
+		}
+		if d.findByName(f.DriveId, str("to_parent_file_id"), name) != nil {
+			if str("check_name_mode") == "refuse" {
+				result = map[string]interface{}{"drive_id": f.DriveId, "file_id": f.FileId, "exist": true}
+				break
+			}
+			if str("check_name_mode") == "auto_rename" {
+				name = d.uniqueName(f.DriveId, str("to_parent_file_id"), name)
+			}
+		}
+		f.ParentFileId = str("to_parent_file_id")
+		f.Name = name
+		result = map[string]interface{}{"drive_id": f.DriveId, "file_id": f.FileId}
+	case "update":
+		f := d.files[fakeKey(str("drive_id"), str("file_id"))]
+		if f == nil {
+			status, result = 404, notFound
+			break
+		}
+		if str("name") != "" {
+			f.Name = str("name")
+		}
+		result = f
+	case "recyclebin/trash", "delete":
+		f := d.files[fakeKey(str("drive_id"), str("file_id"))]
+		if f == nil {
+			status, result = 404, notFound
+			break
+		}
+		d.removeTree(f)
+		result = map[string]interface{}{"drive_id": f.DriveId, "file_id": f.FileId}
+	case "listShare":
+		// 按照 limit 分页，marker 为下一页的起始位置
+		start, _ := strconv.Atoi(str("marker"))
+		end := start + int(param["limit"].(float64))
+		nextMarker := strconv.Itoa(end)
+		if end >= len(d.shares) {
+			end, nextMarker = len(d.shares), ""
+		}
+		result = map[string]interface{}{"items": d.shares[start:end], "next_marker": nextMarker}
+	case "updateShare", "cancelShare":
+		status, result = 404, map[string]interface{}{"code": "NotFound.ShareLink", "message": "share not found"}
+		for i, share := range d.shares {
+			if share.ShareId != str("shareId") {
+				continue
+			}
+			if strings.HasPrefix(req.URL.Path, "/adrive/v1.0/openFile/cancel") {
+				d.shares = append(d.shares[:i], d.shares[i+1:]...)
+				status, result = 200, map[string]interface{}{}
+				break
+			}
+			share.SharePwd = str("sharePwd")
+			share.Expiration = str("expiration")
+			share.Expired = false
+			status, result = 200, share
+			break
+		}
+	case "/adrive/v1.0/album/list":
+		// 按照 limit 分页，marker 为下一页的起始位置
+		start, _ := strconv.Atoi(str("marker"))
+		end := start + int(param["limit"].(float64))
+		nextMarker := strconv.Itoa(end)
+		if end >= len(d.albums) {
+			end, nextMarker = len(d.albums), ""
+		}
+		result = map[string]interface{}{"items": d.albums[start:end], "nextMarker": nextMarker}
+	case "/adrive/v1.0/album/create":
+		d.nextId++
+		album := &openapi.AlbumItem{AlbumId: fmt.Sprintf("a%04d", d.nextId), Name: str("name"), Description: str("description"), CreatedAt: int64(d.nextId)}
+		d.albums = append(d.albums, album)
+		result = album
+	case "/adrive/v1.0/album/update", "/adrive/v1.0/album/get", "/adrive/v1.0/album/delete":
+		status, result = 404, map[string]interface{}{"code": "NotFound.Album", "message": "album not found"}
+		for i, album := range d.albums {
+			if album.AlbumId != str("albumId") {
+				continue
+			}
+			status, result = 200, album
+			switch path.Base(req.URL.Path) {
+			case "update":
+				album.Name, album.Description = str("name"), str("description")
+			case "delete":
+				d.albums = append(d.albums[:i], d.albums[i+1:]...)
+				delete(d.albumFiles, album.AlbumId)
+			}
+			break
+		}
+	case "/adrive/v1.0/sharedAlbum/listFile":
+		items := []*openapi.FileItem{}
+		for _, key := range d.albumFiles[str("sharedAlbumId")] {
+			items = append(items, d.files[key])
+		}
+		result = map[string]interface{}{"items": items, "nextMarker": ""}
+	case "/adrive/v1.0/sharedAlbum/getDownloadUrl":
+		// 共享相册中的实况图片分别返回图片和视频的下载流
+		f := d.files[fakeKey(str("drive_id"), str("file_id"))]
+		if strings.HasSuffix(f.Name, ".livp") {
+			result = map[string]interface{}{"streams_url": map[string]string{
+				"jpeg": "https://download.fake/stream/" + f.FileId + ".jpeg",
+				"mov":  "https://download.fake/stream/" + f.FileId + ".mov",
+			}}
+		} else {
+			result = map[string]interface{}{"url": "https://download.fake/" + f.DriveId + "/" + f.FileId, "size": f.Size}
+		}
+	case "/adrive/v1.0/album/listFile":
+		items := []*openapi.FileItem{}
+		for _, key := range d.albumFiles[str("albumId")] {
+			items = append(items, d.files[key])
+		}
+		result = map[string]interface{}{"items": items, "nextMarker": ""}
+	case "/adrive/v1.0/album/addFile", "/adrive/v1.0/album/deleteFile":
+		albumId := str("albumId")
+		items := []*openapi.FileItem{}
+		list, _ := param["drive_file_list"].([]interface{})
+		for _, v := range list {
+			m := v.(map[string]interface{})
+			key := fakeKey(m["drive_id"].(string), m["file_id"].(string))
+			keys := []string{}
+			for _, k := range d.albumFiles[albumId] {
+				if k != key {
+					keys = append(keys, k)
+				}
+			}
+			if path.Base(req.URL.Path) == "addFile" && d.files[key] != nil {
+				keys = append(keys, key)
+				items = append(items, d.files[key])
+			}
+			d.albumFiles[albumId] = keys
+		}
+		result = map[string]interface{}{"file_list": items}
+		d.updateAlbumCount(albumId)
+	case "async_task/get":
+		result = map[string]interface{}{"state": aliyunpan.AsyncTaskStateSucceed, "async_task_id": str("async_task_id")}
+	default:
+		status, result = 404, map[string]interface{}{"code": "NotFound", "message": "unknown api " + req.URL.Path}
+	}
+
+	body, _ := json.Marshal(result)
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func (d *fakeDrive) updateAlbumCount(albumId string) {
+	for _, album := range d.albums {
+		if album.AlbumId == albumId {
+			album.FileCount, album.ImageCount, album.VideoCount = 0, 0, 0
+			for _, key := range d.albumFiles[albumId] {
+				album.FileCount++
+				switch d.files[key].Category {
+				case "image":
+					album.ImageCount++
+				case "video":
+					album.VideoCount++
+				}
+			}
+		}
+	}
+}
@@ -148,3 +148,316 @@ RetryBegin:
 		}
 	}
 }
+
+// AlbumListGetAll 获取所有个人相簿列表
+func (p *OpenPanClient) AlbumListGetAll(param *aliyunpan.AlbumListParam) (aliyunpan.AlbumList, *apierror.ApiError) {
+	internalParam := &aliyunpan.AlbumListParam{
+		OrderBy:        param.OrderBy,
+		OrderDirection: param.OrderDirection,
+		Limit:          param.Limit,
+		Marker:         param.Marker,
+	}
+
+	albumList := aliyunpan.AlbumList{}
+	for {
+		result, err := p.AlbumList(internalParam)
+		if err != nil {
+			return nil, err
+		}
+		albumList = append(albumList, result.Items...)
+		if result.NextMarker == "" {
+			return albumList, nil
+		}
+		internalParam.Marker = result.NextMarker
+	}
+}
+
+// AlbumList 获取个人相簿列表
+func (p *OpenPanClient) AlbumList(param *aliyunpan.AlbumListParam) (*aliyunpan.AlbumListResult, *apierror.ApiError) {
+	retryTime := 0
+
+RetryBegin:
+	opParam := &openapi.AlbumListParam{
+		OrderBy:        string(param.OrderBy),
+		OrderDirection: string(param.OrderDirection),
+		Limit:          param.Limit,
+		Marker:         param.Marker,
+	}
+	if opParam.OrderBy == "" {
+		opParam.OrderBy = "created_at"
+	}
+	if opParam.OrderDirection == "" {
+		opParam.OrderDirection = "ASC"
+	}
+	if opParam.Limit <= 0 {
+		opParam.Limit = 100
+	}
+	if result, err := p.apiClient.AlbumList(opParam); err == nil {
+		albumList := aliyunpan.AlbumList{}
+		for _, item := range result.Items {
+			if item == nil {
+				continue
+			}
+			albumList = append(albumList, createAlbumEntity(item))
+		}
+		return &aliyunpan.AlbumListResult{
+			Items:      albumList,
+			NextMarker: result.NextMarker,
+		}, nil
+	} else {
+		// handle common error
+		if apiErrorHandleResp := p.HandleAliApiError(err, &retryTime); apiErrorHandleResp.NeedRetry {
+			goto RetryBegin
+		} else {
+			return nil, apiErrorHandleResp.ApiErr
+		}
+	}
+}
+
+// AlbumCreate 创建个人相簿
+func (p *OpenPanClient) AlbumCreate(param *aliyunpan.AlbumCreateParam) (*aliyunpan.AlbumEntity, *apierror.ApiError) {
+	retryTime := 0
+
+	if param.Name == "" {
+		return nil, apierror.NewFailedApiError("album name cannot be empty")
+	}
+RetryBegin:
+	opParam := &openapi.AlbumCreateParam{
+		Name:        param.Name,
+		Description: param.Description,
+	}
+	if result, err := p.apiClient.AlbumCreate(opParam); err == nil {
+		return createAlbumEntity(result), nil
+	} else {
+		// handle common error
+		if apiErrorHandleResp := p.HandleAliApiError(err, &retryTime); apiErrorHandleResp.NeedRetry {
+			goto RetryBegin
+		} else {
+			return nil, apiErrorHandleResp.ApiErr
+		}
+	}
+}
+
+// AlbumEdit 编辑个人相簿的名称和简介
+func (p *OpenPanClient) AlbumEdit(param *aliyunpan.AlbumEditParam) (*aliyunpan.AlbumEntity, *apierror.ApiError) {
+	retryTime := 0
+
+	if param.AlbumId == "" {
+		return nil, apierror.NewFailedApiError("album id cannot be empty")
+	}
+	if param.Name == "" {
+		return nil, apierror.NewFailedApiError("album name cannot be empty")
+	}
+RetryBegin:
+	opParam := &openapi.AlbumUpdateParam{
+		AlbumId:     param.AlbumId,
+		Name:        param.Name,
+		Description: param.Description,
+	}
+	if result, err := p.apiClient.AlbumUpdate(opParam); err == nil {
+		return createAlbumEntity(result), nil
+	} else {
+		// handle common error
+		if apiErrorHandleResp := p.HandleAliApiError(err, &retryTime); apiErrorHandleResp.NeedRetry {
+			goto RetryBegin
+		} else {
+			return nil, apiErrorHandleResp.ApiErr
+		}
+	}
+}
+
+// AlbumDelete 删除个人相簿，相簿中的文件不会被删除
+func (p *OpenPanClient) AlbumDelete(param *aliyunpan.AlbumDeleteParam) (bool, *apierror.ApiError) {
+	retryTime := 0
+
+	if param.AlbumId == "" {
+		return false, apierror.NewFailedApiError("album id cannot be empty")
+	}
+RetryBegin:
+	opParam := &openapi.AlbumDeleteParam{
+		AlbumId: param.AlbumId,
+	}
+	if result, err := p.apiClient.AlbumDelete(opParam); err == nil {
+		return result, nil
+	} else {
+		// handle common error
+		if apiErrorHandleResp := p.HandleAliApiError(err, &retryTime); apiErrorHandleResp.NeedRetry {
+			goto RetryBegin
+		} else {
+			return false, apiErrorHandleResp.ApiErr
+		}
+	}
+}
+
+// AlbumGet 获取个人相簿信息
+func (p *OpenPanClient) AlbumGet(param *aliyunpan.AlbumGetParam) (*aliyunpan.AlbumEntity, *apierror.ApiError) {
+	retryTime := 0
+
+	if param.AlbumId == "" {
+		return nil, apierror.NewFailedApiError("album id cannot be empty")
+	}
+RetryBegin:
+	opParam := &openapi.AlbumGetParam{
+		AlbumId: param.AlbumId,
+	}
+	if result, err := p.apiClient.AlbumGet(opParam); err == nil {
+		return createAlbumEntity(result), nil
+	} else {
+		// handle common error
+		if apiErrorHandleResp := p.HandleAliApiError(err, &retryTime); apiErrorHandleResp.NeedRetry {
+			goto RetryBegin
+		} else {
+			return nil, apiErrorHandleResp.ApiErr
+		}
+	}
+}
+
+// AlbumListFileGetAll 获取个人相簿下的所有文件列表
+func (p *OpenPanClient) AlbumListFileGetAll(param *aliyunpan.AlbumListFileParam) (aliyunpan.FileList, *apierror.ApiError) {
+	internalParam := &aliyunpan.AlbumListFileParam{
+		AlbumId:             param.AlbumId,
+		Limit:               param.Limit,
+		Marker:              param.Marker,
+		ImageThumbnailWidth: param.ImageThumbnailWidth,
+	}
+
+	fileList := aliyunpan.FileList{}
+	for {
+		result, err := p.AlbumListFile(internalParam)
+		if err != nil {
+			return nil, err
+		}
+		fileList = append(fileList, result.FileList...)
+		if result.NextMarker == "" {
+			return fileList, nil
+		}
+		internalParam.Marker = result.NextMarker
+	}
+}
+
+// AlbumListFile 获取个人相簿下的文件列表
+func (p *OpenPanClient) AlbumListFile(param *aliyunpan.AlbumListFileParam) (*aliyunpan.FileListResult, *apierror.ApiError) {
+	retryTime := 0
+
+	if param.AlbumId == "" {
+		return nil, apierror.NewFailedApiError("album id cannot be empty")
+	}
+RetryBegin:
+	opParam := &openapi.AlbumListFileParam{
+		AlbumId:             param.AlbumId,
+		OrderBy:             "joined_at",
+		OrderDirection:      "DESC",
+		Marker:              param.Marker,
+		Limit:               param.Limit,
+		ImageThumbnailWidth: param.ImageThumbnailWidth,
+	}
+	if opParam.Limit <= 0 {
+		opParam.Limit = 100
+	}
+	if opParam.ImageThumbnailWidth <= 0 {
+		opParam.ImageThumbnailWidth = 480
+	}
+	if result, err := p.apiClient.AlbumListFile(opParam); err == nil {
+		fileList := aliyunpan.FileList{}
+		for _, item := range result.Items {
+			if item == nil {
+				continue
+			}
+			f := createFileEntity(item)
+			f.AlbumId = param.AlbumId
+			f.Thumbnail = item.Thumbnail
+			fileList = append(fileList, f)
+		}
+		return &aliyunpan.FileListResult{
+			FileList:   fileList,
+			NextMarker: result.NextMarker,
+		}, nil
+	} else {
+		// handle common error
+		if apiErrorHandleResp := p.HandleAliApiError(err, &retryTime); apiErrorHandleResp.NeedRetry {
+			goto RetryBegin
+		} else {
+			return nil, apiErrorHandleResp.ApiErr
+		}
+	}
+}
+
+// AlbumAddFile 个人相簿增加文件，返回加入相簿的文件列表
+func (p *OpenPanClient) AlbumAddFile(param *aliyunpan.AlbumFileParam) (*aliyunpan.FileList, *apierror.ApiError) {
+	retryTime := 0
+
+	if param.AlbumId == "" {
+		return nil, apierror.NewFailedApiError("album id cannot be empty")
+	}
+RetryBegin:
+	if result, err := p.apiClient.AlbumAddFile(createAlbumFileParam(param)); err == nil {
+		fileList := aliyunpan.FileList{}
+		for _, item := range result.Items {
+			if item == nil {
+				continue
+			}
+			f := createFileEntity(item)
+			f.AlbumId = param.AlbumId
+			f.Thumbnail = item.Thumbnail
+			fileList = append(fileList, f)
+		}
+		return &fileList, nil
+	} else {
+		// handle common error
+		if apiErrorHandleResp := p.HandleAliApiError(err, &retryTime); apiErrorHandleResp.NeedRetry {
+			goto RetryBegin
+		} else {
+			return nil, apiErrorHandleResp.ApiErr
+		}
+	}
+}
+
+// AlbumDeleteFile 个人相簿移除文件，文件本身不会被删除
+func (p *OpenPanClient) AlbumDeleteFile(param *aliyunpan.AlbumFileParam) (bool, *apierror.ApiError) {
+	retryTime := 0
+
+	if param.AlbumId == "" {
+		return false, apierror.NewFailedApiError("album id cannot be empty")
+	}
+RetryBegin:
+	if result, err := p.apiClient.AlbumDeleteFile(createAlbumFileParam(param)); err == nil {
+		return result, nil
+	} else {
+		// handle common error
+		if apiErrorHandleResp := p.HandleAliApiError(err, &retryTime); apiErrorHandleResp.NeedRetry {
+			goto RetryBegin
+		} else {
+			return false, apiErrorHandleResp.ApiErr
+		}
+	}
+}
+
+func createAlbumFileParam(param *aliyunpan.AlbumFileParam) *openapi.AlbumFileParam {
+	opParam := &openapi.AlbumFileParam{
+		AlbumId:       param.AlbumId,
+		DriveFileList: []openapi.AlbumFileItem{},
+	}
+	for _, item := range param.DriveFileList {
+		opParam.DriveFileList = append(opParam.DriveFileList, openapi.AlbumFileItem{
+			DriveId: item.DriveId,
+			FileId:  item.FileId,
+		})
+	}
+	return opParam
+}
+
+func createAlbumEntity(item *openapi.AlbumItem) *aliyunpan.AlbumEntity {
+	if item == nil {
+		return nil
+	}
+	return &aliyunpan.AlbumEntity{
+		AlbumId:     item.AlbumId,
+		Name:        item.Name,
+		Description: item.Description,
+		FileCount:   item.FileCount,
+		ImageCount:  item.ImageCount,
+		VideoCount:  item.VideoCount,
+		CreatedAt:   item.CreatedAt,
+		UpdatedAt:   item.UpdatedAt,
+	}
+}
@@ -0,0 +1,72 @@
+package aliyunpan_open
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+func TestAlbumManage(t *testing.T) {
+	d := newFakeDrive()
+	photo := d.add("d1", "root", "a.jpg", "file", 100, "")
+	video := d.add("d1", "root", "b.mp4", "file", 200, "")
+	d.get("d1", photo).Category = "image"
+	d.get("d1", video).Category = "video"
+	p := newFakeClient(t, d)
+	var _ aliyunpan.AlbumOperator = p
+
+	_, err := p.AlbumCreate(&aliyunpan.AlbumCreateParam{})
+	assert.NotNil(t, err)
+	for _, name := range []string{"旅行", "家庭", "宠物"} {
+		_, err := p.AlbumCreate(&aliyunpan.AlbumCreateParam{Name: name})
+		require.Nil(t, err)
+	}
+
+	// 分页获取所有相簿
+	albums, err := p.AlbumListGetAll(&aliyunpan.AlbumListParam{Limit: 2})
+	require.Nil(t, err)
+	require.Len(t, albums, 3)
+	assert.Equal(t, "宠物", albums[2].Name)
+	albumId := albums[0].AlbumId
+
+	album, err := p.AlbumEdit(&aliyunpan.AlbumEditParam{AlbumId: albumId, Name: "2023旅行", Description: "海边"})
+	require.Nil(t, err)
+	assert.Equal(t, "2023旅行", album.Name)
+
+	// 增加和移除文件
+	addParam := &aliyunpan.AlbumFileParam{AlbumId: albumId}
+	addParam.AddFileItem("d1", photo)
+	addParam.AddFileItem("d1", video)
+	added, err := p.AlbumAddFile(addParam)
+	require.Nil(t, err)
+	require.Len(t, *added, 2)
+	assert.Equal(t, albumId, (*added)[0].AlbumId)
+
+	album, err = p.AlbumGet(&aliyunpan.AlbumGetParam{AlbumId: albumId})
+	require.Nil(t, err)
+	assert.Equal(t, "海边", album.Description)
+	assert.Equal(t, 2, album.FileCount)
+	assert.Equal(t, 1, album.ImageCount)
+	assert.Equal(t, 1, album.VideoCount)
+
+	deleteParam := &aliyunpan.AlbumFileParam{AlbumId: albumId}
+	deleteParam.AddFileItem("d1", photo)
+	ok, err := p.AlbumDeleteFile(deleteParam)
+	require.Nil(t, err)
+	assert.True(t, ok)
+	files, err := p.AlbumListFileGetAll(&aliyunpan.AlbumListFileParam{AlbumId: albumId})
+	require.Nil(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "b.mp4", files[0].FileName)
+	// 文件本身不会被删除
+	assert.NotNil(t, d.get("d1", photo))
+
+	ok, err = p.AlbumDelete(&aliyunpan.AlbumDeleteParam{AlbumId: albumId})
+	require.Nil(t, err)
+	assert.True(t, ok)
+	_, err = p.AlbumGet(&aliyunpan.AlbumGetParam{AlbumId: albumId})
+	assert.NotNil(t, err)
+	assert.NotNil(t, d.get("d1", video))
+}
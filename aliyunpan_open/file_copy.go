@@ -19,6 +19,7 @@ RetryBegin:
 		AutoRename:     true,
 	}
 	if result, err := p.apiClient.FileCopy(opParam); err == nil {
+		p.removeDirListFromCache(param.DriveId, param.ToParentFileId)
 		return &aliyunpan.FileAsyncTaskResult{
 			DriveId:     result.DriveId,
 			FileId:      result.FileId,
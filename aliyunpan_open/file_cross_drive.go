@@ -0,0 +1,127 @@
+package aliyunpan_open
+
+import (
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan_open/openapi"
+	"github.com/tickstep/library-go/logger"
+)
+
+// FileCrossDriveCopy 跨网盘复制文件，支持备份盘、资源库之间复制文件或文件夹。
+// 会等待异步任务完成，每个文件的处理结果在返回值中单独给出，只有参数错误或者获取目标目录失败才会返回错误
+func (p *OpenPanClient) FileCrossDriveCopy(param *aliyunpan.FileCrossDriveParam) ([]*aliyunpan.FileCrossDriveResult, *apierror.ApiError) {
+	return p.fileCrossDrive(param, false)
+}
+
+// FileCrossDriveMove 跨网盘移动文件。OpenAPI 的移动接口不支持跨网盘，
+// 这里先复制到目标网盘，复制成功后再把源文件放入回收站
+func (p *OpenPanClient) FileCrossDriveMove(param *aliyunpan.FileCrossDriveParam) ([]*aliyunpan.FileCrossDriveResult, *apierror.ApiError) {
+	return p.fileCrossDrive(param, true)
+}
+
+func (p *OpenPanClient) fileCrossDrive(param *aliyunpan.FileCrossDriveParam, deleteSource bool) ([]*aliyunpan.FileCrossDriveResult, *apierror.ApiError) {
+	if param == nil || param.FromDriveId == "" || param.ToDriveId == "" {
+		return nil, apierror.NewFailedApiError("网盘ID不能为空")
+	}
+	if param.FromDriveId == param.ToDriveId {
+		return nil, apierror.NewFailedApiError("目标网盘ID和源网盘ID必须不一样")
+	}
+	toParentFileId := param.ToParentFileId
+	if toParentFileId == "" {
+		toParentFileId = aliyunpan.DefaultRootParentFileId
+	}
+	conflictMode := param.ConflictMode
+	if conflictMode == "" {
+		conflictMode = aliyunpan.FileConflictAutoRename
+	}
+
+	// 目标目录已有的文件，用于处理同名冲突
+	existed := map[string]*aliyunpan.FileEntity{}
+	if conflictMode != aliyunpan.FileConflictAutoRename {
+		fileList, err := p.fileListGetAll(&aliyunpan.FileListParam{
+			DriveId:      param.ToDriveId,
+			ParentFileId: toParentFileId,
+		}, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fileList {
+			existed[f.FileName] = f
+		}
+	}
+
+	results := []*aliyunpan.FileCrossDriveResult{}
+	for _, fileId := range param.FromFileIds {
+		r := &aliyunpan.FileCrossDriveResult{
+			SourceDriveId: param.FromDriveId,
+			SourceFileId:  fileId,
+			DriveId:       param.ToDriveId,
+		}
+		results = append(results, r)
+
+		source, err := p.FileInfoById(param.FromDriveId, fileId)
+		if err != nil {
+			r.Err = err
+			continue
+		}
+		r.FileName = source.FileName
+
+		target, overwrite := existed[source.FileName]
+		if overwrite && conflictMode == aliyunpan.FileConflictSkip {
+			logger.Verboseln("cross drive skip existed file: ", source.FileName)
+			r.FileId = target.FileId
+			r.Skipped = true
+			continue
+		}
+
+		// 覆盖时先复制（自动重命名），复制成功后再把同名文件放入回收站并改回原来的文件名
+		if r.FileId, r.Err = p.fileCopyToDrive(param.FromDriveId, fileId, param.ToDriveId, toParentFileId); r.Err != nil {
+			continue
+		}
+		if overwrite {
+			if _, r.Err = p.FileDelete(&aliyunpan.FileBatchActionParam{DriveId: param.ToDriveId, FileId: target.FileId}); r.Err != nil {
+				continue
+			}
+			delete(existed, source.FileName)
+			if r.Err = p.ensureFileName(param.ToDriveId, r.FileId, source.FileName); r.Err != nil {
+				continue
+			}
+		}
+		if deleteSource {
+			if _, err := p.FileDelete(&aliyunpan.FileBatchActionParam{DriveId: param.FromDriveId, FileId: fileId}); err != nil {
+				r.Err = err
+				continue
+			}
+		}
+		r.Success = true
+	}
+	return results, nil
+}
+
+// fileCopyToDrive 复制文件到指定网盘并等待异步任务完成，返回新文件的ID
+func (p *OpenPanClient) fileCopyToDrive(driveId, fileId, toDriveId, toParentFileId string) (string, *apierror.ApiError) {
+	retryTime := 0
+
+RetryBegin:
+	opParam := &openapi.FileCopyParam{
+		DriveId:        driveId,
+		FileId:         fileId,
+		ToDriveId:      toDriveId,
+		ToParentFileId: toParentFileId,
+		AutoRename:     true,
+	}
+	result, err := p.apiClient.FileCopy(opParam)
+	if err != nil {
+		// handle common error
+		if apiErrorHandleResp := p.HandleAliApiError(err, &retryTime); apiErrorHandleResp.NeedRetry {
+			goto RetryBegin
+		} else {
+			return "", apiErrorHandleResp.ApiErr
+		}
+	}
+	p.removeDirListFromCache(toDriveId, toParentFileId)
+	if e := p.WaitAsyncTask(result.AsyncTaskId); e != nil {
+		return "", e
+	}
+	return result.FileId, nil
+}
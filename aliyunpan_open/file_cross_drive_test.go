@@ -0,0 +1,60 @@
+package aliyunpan_open
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+func TestFileCrossDriveCopy(t *testing.T) {
+	d := newFakeDrive()
+	a := d.add("backup", "root", "a.txt", "file", 10, "A")
+	dir := d.add("backup", "root", "docs", "folder", 0, "")
+	d.add("backup", dir, "b.txt", "file", 20, "B")
+	d.add("resource", "root", "a.txt", "file", 1, "OLD")
+	p := newFakeClient(t, d)
+
+	// skip
+	results, err := p.FileCrossDriveCopy(&aliyunpan.FileCrossDriveParam{
+		FromDriveId:  "backup",
+		FromFileIds:  []string{a, dir, "missing"},
+		ToDriveId:    "resource",
+		ConflictMode: aliyunpan.FileConflictSkip,
+	})
+	require.Nil(t, err)
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Skipped)
+	assert.True(t, results[1].Success)
+	assert.NotNil(t, results[2].Err)
+	assert.Equal(t, []string{"a.txt", "docs"}, d.names("resource", "root"))
+	assert.Equal(t, []string{"b.txt"}, d.names("resource", results[1].FileId))
+	assert.Equal(t, 1, d.count("async_task/get"))
+
+	// overwrite
+	results, err = p.FileCrossDriveCopy(&aliyunpan.FileCrossDriveParam{
+		FromDriveId:  "backup",
+		FromFileIds:  []string{a},
+		ToDriveId:    "resource",
+		ConflictMode: aliyunpan.FileConflictOverwrite,
+	})
+	require.Nil(t, err)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, "A", d.get("resource", results[0].FileId).ContentHash)
+	assert.Equal(t, []string{"a.txt", "docs"}, d.names("resource", "root"))
+
+	// auto rename & move
+	results, err = p.FileCrossDriveMove(&aliyunpan.FileCrossDriveParam{
+		FromDriveId: "backup",
+		FromFileIds: []string{a},
+		ToDriveId:   "resource",
+	})
+	require.Nil(t, err)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, []string{"a(1).txt", "a.txt", "docs"}, d.names("resource", "root"))
+	assert.Equal(t, []string{"docs"}, d.names("backup", "root"))
+
+	_, err = p.FileCrossDriveCopy(&aliyunpan.FileCrossDriveParam{FromDriveId: "backup", ToDriveId: "backup"})
+	assert.NotNil(t, err)
+}
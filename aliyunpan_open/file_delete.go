@@ -16,6 +16,7 @@ RetryBegin:
 		FileId:  param.FileId,
 	}
 	if result, err := p.apiClient.FileTrash(opParam); err == nil {
+		p.removeFileFromCache(param.DriveId, param.FileId)
 		return &aliyunpan.FileBatchActionResult{
 			FileId:  result.FileId,
 			Success: true,
@@ -40,6 +41,7 @@ RetryBegin:
 		FileId:  param.FileId,
 	}
 	if result, err := p.apiClient.FileDelete(opParam); err == nil {
+		p.removeFileFromCache(param.DriveId, param.FileId)
 		return &aliyunpan.FileBatchActionResult{
 			FileId:  result.FileId,
 			Success: true,
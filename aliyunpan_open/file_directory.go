@@ -14,7 +14,7 @@ func createFileEntity(f *openapi.FileItem) *aliyunpan.FileEntity {
 	if f == nil {
 		return nil
 	}
-	return &aliyunpan.FileEntity{
+	entity := &aliyunpan.FileEntity{
 		DriveId:         f.DriveId,
 		DomainId:        f.DomainId,
 		FileId:          f.FileId,
@@ -29,7 +29,17 @@ func createFileEntity(f *openapi.FileItem) *aliyunpan.FileEntity {
 		ContentHashName: f.ContentHashName,
 		Path:            "",
 		Category:        f.Category,
+		Thumbnail:       f.Thumbnail,
+		Url:             f.Url,
 	}
+	if f.ImageMediaMetadata != nil {
+		entity.ImageMediaMetadata = &aliyunpan.ImageMediaMetadata{
+			Width:  f.ImageMediaMetadata.Width,
+			Height: f.ImageMediaMetadata.Height,
+			Exif:   f.ImageMediaMetadata.Exif,
+		}
+	}
+	return entity
 }
 
 // FileList 获取文件列表
@@ -74,6 +84,15 @@ RetryBegin:
 
 // FileListGetAll 获取指定目录下的所有文件列表
 func (p *OpenPanClient) FileListGetAll(param *aliyunpan.FileListParam, delayMilliseconds int) (aliyunpan.FileList, *apierror.ApiError) {
+	if p.metaCache != nil {
+		return p.metaCache.FileListGetAll(param, p.FileInfoById, func() (aliyunpan.FileList, *apierror.ApiError) {
+			return p.fileListGetAll(param, delayMilliseconds)
+		})
+	}
+	return p.fileListGetAll(param, delayMilliseconds)
+}
+
+func (p *OpenPanClient) fileListGetAll(param *aliyunpan.FileListParam, delayMilliseconds int) (aliyunpan.FileList, *apierror.ApiError) {
 	internalParam := &aliyunpan.FileListParam{
 		OrderBy:        param.OrderBy,
 		OrderDirection: param.OrderDirection,
@@ -133,6 +152,10 @@ RetryBegin:
 // FileInfoByPath 通过路径获取文件详情，pathStr是绝对路径
 func (p *OpenPanClient) FileInfoByPath(driveId string, pathStr string) (fileInfo *aliyunpan.FileEntity, error *apierror.ApiError) {
 	retryTime := 0
+	driveId, pathStr, apiErr := p.ResolveDrivePath(driveId, pathStr)
+	if apiErr != nil {
+		return nil, apiErr
+	}
 
 	if pathStr == "" {
 		pathStr = "/"
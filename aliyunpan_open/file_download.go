@@ -2,11 +2,13 @@ package aliyunpan_open
 
 import (
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/tickstep/aliyunpan-api/aliyunpan"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/aliyunpan-api/aliyunpan_open/openapi"
 	"github.com/tickstep/library-go/logger"
@@ -42,12 +44,13 @@ RetryBegin:
 	}
 }
 
-// DownloadFileData 下载文件内容
-func (p *OpenPanClient) DownloadFileData(downloadFileUrl string, fileRange aliyunpan.FileDownloadRange, downloadFunc aliyunpan.DownloadFuncCallback) *apierror.ApiError {
+// DownloadFileData 下载文件内容。回调返回的响应体会按照全局下载限速读取，
+// limiters 为单个传输任务的限速器，会和全局下载限速同时生效，例如 GetFileDownloadUrlResult.NewTransferLimiter
+func (p *OpenPanClient) DownloadFileData(downloadFileUrl string, fileRange aliyunpan.FileDownloadRange, downloadFunc aliyunpan.DownloadFuncCallback, limiters ...*aliyunpan.BandwidthLimiter) *apierror.ApiError {
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s", downloadFileUrl)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// header
 	headers := map[string]string{
@@ -63,10 +66,19 @@ func (p *OpenPanClient) DownloadFileData(downloadFileUrl string, fileRange aliyu
 		}
 		headers["range"] = rangeStr
 	}
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// request callback
-	_, err := downloadFunc("GET", fullUrl.String(), headers)
+	_, err := p.apiClient.GetObserver().Do(apitransport.RequestClassTransfer, "GET", fullUrl.String(), func() (*http.Response, error) {
+		return p.GetGovernor().Do(apitransport.RequestClassTransfer, func() (*http.Response, error) {
+			resp, err := downloadFunc("GET", fullUrl.String(), headers)
+			if resp != nil && resp.Body != nil {
+				// 响应体按照全局下载限速和单个传输任务的限速读取
+				resp.Body = aliyunpan.NewBandwidthLimitedReadCloser(resp.Body, append([]*aliyunpan.BandwidthLimiter{p.downloadLimiter}, limiters...)...)
+			}
+			return resp, err
+		})
+	})
 	//resp, err := p.client.Req("GET", fullUrl.String(), nil, headers)
 
 	if err != nil {
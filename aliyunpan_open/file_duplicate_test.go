@@ -0,0 +1,59 @@
+package aliyunpan_open
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+func TestFindDuplicateFiles(t *testing.T) {
+	d := newFakeDrive()
+	photos := d.add("backup", "root", "photos", "folder", 0, "")
+	deep := d.add("backup", photos, "2023", "folder", 0, "")
+	d.add("backup", "root", "a.jpg", "file", 100, "AAA")
+	d.add("backup", photos, "a copy.jpg", "file", 100, "aaa")
+	d.add("backup", deep, "a.jpg", "file", 100, "AAA")
+	d.add("backup", photos, "b.jpg", "file", 200, "BBB")
+	d.add("backup", photos, "small.txt", "file", 1, "CCC")
+	d.add("backup", deep, "small.txt", "file", 1, "CCC")
+	d.add("resource", "root", "b.jpg", "file", 200, "BBB")
+	p := newFakeClient(t, d)
+
+	groups := []*aliyunpan.DuplicateGroup{}
+	result, err := p.FindDuplicateFiles(&aliyunpan.DuplicateFindParam{
+		Roots: []*aliyunpan.DuplicateRoot{
+			{DriveId: "backup"},
+			{DriveId: "backup", Path: "/photos"},
+			{DriveId: "resource"},
+		},
+		MinSize:      10,
+		KeepStrategy: aliyunpan.DuplicateKeepNewest,
+	}, func(group *aliyunpan.DuplicateGroup) bool {
+		groups = append(groups, group)
+		return true
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 7, result.ScannedFiles)
+	assert.Equal(t, 2, result.GroupCount)
+	assert.Equal(t, 3, result.DuplicateFiles)
+	assert.Equal(t, int64(400), result.WastedSize)
+	// 同一组分多次返回，每次返回目前保留的文件和新发现的文件
+	require.Len(t, groups, 3)
+	assert.Equal(t, "/photos/2023/a.jpg", groups[1].Files[0].Path)
+	assert.Equal(t, "resource", groups[2].Files[0].DriveId)
+	assert.Equal(t, 0, d.count("/adrive/v1.0/openFile/recyclebin/trash"))
+
+	// 保留路径最短的文件，其余放入回收站
+	result, err = p.FindDuplicateFiles(&aliyunpan.DuplicateFindParam{
+		Roots:           []*aliyunpan.DuplicateRoot{{DriveId: "backup"}},
+		TrashDuplicates: true,
+	}, nil)
+	require.Nil(t, err)
+	assert.Equal(t, 3, result.TrashedFiles)
+	assert.Equal(t, int64(201), result.FreedSize)
+	assert.Equal(t, []string{"a.jpg", "photos"}, d.names("backup", "root"))
+	assert.Equal(t, []string{"2023", "b.jpg", "small.txt"}, d.names("backup", photos))
+	assert.Empty(t, d.names("backup", deep))
+}
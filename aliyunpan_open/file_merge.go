@@ -0,0 +1,61 @@
+package aliyunpan_open
+
+import (
+	"net/http"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+type (
+	// fileMergeOperator 实现 aliyunpan.FileMergeOperator 和 aliyunpan.DuplicateFileOperator
+	fileMergeOperator struct {
+		*OpenPanClient
+	}
+)
+
+// FileCopyRecursive 递归复制文件夹并和目标文件夹已有的内容合并，支持跨网盘。
+// 文件优先通过 ContentHash 秒传，不能秒传时使用服务器复制。返回每个文件的处理结果
+func (p *OpenPanClient) FileCopyRecursive(param *aliyunpan.FileMergeParam) ([]*aliyunpan.FileMergeItem, *apierror.ApiError) {
+	return aliyunpan.FileMergeCopy(fileMergeOperator{p}, param)
+}
+
+// FindDuplicateFiles 按照文件大小和SHA1查找重复文件，每一组重复文件通过 handler 逐个返回。
+// 可以按照保留策略把多余的文件放入回收站，详见 aliyunpan.FindDuplicateFiles
+func (p *OpenPanClient) FindDuplicateFiles(param *aliyunpan.DuplicateFindParam, handler aliyunpan.DuplicateGroupFunc) (*aliyunpan.DuplicateFindResult, *apierror.ApiError) {
+	return aliyunpan.FindDuplicateFiles(fileMergeOperator{p}, param, handler)
+}
+
+// RemoveFile 把文件放入回收站
+func (o fileMergeOperator) RemoveFile(driveId, fileId string) *apierror.ApiError {
+	_, err := o.FileDelete(&aliyunpan.FileBatchActionParam{DriveId: driveId, FileId: fileId})
+	return err
+}
+
+// RemoveFiles 把文件放入回收站，开放平台没有批量接口，逐个处理
+func (o fileMergeOperator) RemoveFiles(driveId string, fileIds []string) []*apierror.ApiError {
+	errs := make([]*apierror.ApiError, len(fileIds))
+	for i, fileId := range fileIds {
+		errs[i] = o.RemoveFile(driveId, fileId)
+	}
+	return errs
+}
+
+// ServerCopyFile 通过服务器复制文件
+func (o fileMergeOperator) ServerCopyFile(source *aliyunpan.FileEntity, toDriveId, toParentFileId, name string) (string, *apierror.ApiError) {
+	fileId, err := o.fileCopyToDrive(source.DriveId, source.FileId, toDriveId, toParentFileId)
+	if err != nil {
+		return "", err
+	}
+	if name != source.FileName {
+		if err := o.ensureFileName(toDriveId, fileId, name); err != nil {
+			return fileId, err
+		}
+	}
+	return fileId, nil
+}
+
+// TransferRequest 使用数据传输的 http 客户端发送请求，不受接口请求超时时间的限制
+func (o fileMergeOperator) TransferRequest(httpMethod, fullUrl string, headers map[string]string) (*http.Response, error) {
+	return o.transferClient.Req(httpMethod, fullUrl, nil, headers)
+}
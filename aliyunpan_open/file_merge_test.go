@@ -0,0 +1,64 @@
+package aliyunpan_open
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+func TestFileCopyRecursive(t *testing.T) {
+	d := newFakeDrive()
+	src := d.add("backup", "root", "photos", "folder", 0, "")
+	d.add("backup", src, "a.jpg", "file", 10, "A")
+	d.add("backup", src, "b.jpg", "file", 20, "B")
+	sub := d.add("backup", src, "2023", "folder", 0, "")
+	d.add("backup", sub, "c.jpg", "file", 30, "C")
+
+	dst := d.add("resource", "root", "photos", "folder", 0, "")
+	d.add("resource", dst, "a.jpg", "file", 10, "A")
+	d.add("resource", dst, "b.jpg", "file", 5, "OLD")
+	p := newFakeClient(t, d)
+
+	items, err := p.FileCopyRecursive(&aliyunpan.FileMergeParam{
+		FromDriveId: "backup",
+		FromFileId:  src,
+		ToDriveId:   "resource",
+	})
+	require.Nil(t, err)
+	actions := map[string]aliyunpan.FileMergeAction{}
+	targets := map[string]string{}
+	for _, item := range items {
+		require.Nil(t, item.Err, item.SourcePath)
+		actions[item.SourcePath] = item.Action
+		targets[item.SourcePath] = item.TargetPath
+	}
+	assert.Equal(t, aliyunpan.FileMergeActionMerged, actions["photos"])
+	assert.Equal(t, aliyunpan.FileMergeActionSkipped, actions["photos/a.jpg"])
+	assert.Equal(t, aliyunpan.FileMergeActionRapidCopied, actions["photos/b.jpg"])
+	assert.Equal(t, "photos/b(1).jpg", targets["photos/b.jpg"])
+	assert.Equal(t, aliyunpan.FileMergeActionCreated, actions["photos/2023"])
+	assert.Equal(t, aliyunpan.FileMergeActionRapidCopied, actions["photos/2023/c.jpg"])
+	assert.Equal(t, []string{"2023", "a.jpg", "b(1).jpg", "b.jpg"}, d.names("resource", dst))
+
+	// 覆盖，禁用秒传
+	items, err = p.FileCopyRecursive(&aliyunpan.FileMergeParam{
+		FromDriveId:      "backup",
+		FromFileId:       src,
+		ToDriveId:        "resource",
+		ToParentFileId:   dst,
+		ConflictMode:     aliyunpan.FileConflictOverwrite,
+		ContentsOnly:     true,
+		DisableRapidCopy: true,
+	})
+	require.Nil(t, err)
+	for _, item := range items {
+		require.Nil(t, item.Err, item.SourcePath)
+		if item.SourcePath == "b.jpg" {
+			assert.True(t, item.Overwritten)
+			assert.Equal(t, aliyunpan.FileMergeActionCopied, item.Action)
+		}
+	}
+	assert.Equal(t, []string{"2023", "a.jpg", "b(1).jpg", "b.jpg"}, d.names("resource", dst))
+}
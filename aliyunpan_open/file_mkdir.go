@@ -24,6 +24,7 @@ RetryBegin:
 		CheckNameMode: "auto_rename",
 	}
 	if result, err := p.apiClient.FileUploadCreate(opParam); err == nil {
+		p.removeDirListFromCache(driveId, parentFileId)
 		return &aliyunpan.MkdirResult{
 			ParentFileId: result.ParentFileId,
 			Type:         "folder",
@@ -43,6 +44,10 @@ RetryBegin:
 
 // MkdirByFullPath 通过绝对路径创建文件夹
 func (p *OpenPanClient) MkdirByFullPath(driveId, fullPath string) (*aliyunpan.MkdirResult, *apierror.ApiError) {
+	driveId, fullPath, apiErr := p.ResolveDrivePath(driveId, fullPath)
+	if apiErr != nil {
+		return nil, apiErr
+	}
 	fullPath = strings.ReplaceAll(fullPath, "//", "/")
 	fullPath = strings.Trim(fullPath, " ")
 	if fullPath == "/" {
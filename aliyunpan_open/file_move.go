@@ -15,8 +15,19 @@ RetryBegin:
 		DriveId:        param.DriveId,
 		FileId:         param.FileId,
 		ToParentFileId: param.ToParentFileId,
+		CheckNameMode:  param.CheckNameMode,
+		NewName:        param.NewName,
 	}
 	if result, err := p.apiClient.FileMove(opParam); err == nil {
+		if result.Exist {
+			// refuse 模式下目标已有同名文件，没有移动
+			return nil, apierror.NewApiError(apierror.ApiCodeFileAlreadyExisted, "目标文件夹已存在同名文件")
+		}
+		p.removeFileFromCache(param.DriveId, param.FileId)
+		p.removeDirListFromCache(param.DriveId, param.ToParentFileId)
+		if e := p.WaitAsyncTask(result.AsyncTaskId); e != nil {
+			return nil, e
+		}
 		return &aliyunpan.FileMoveResult{
 			FileId:  result.FileId,
 			Success: true,
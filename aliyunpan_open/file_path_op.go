@@ -0,0 +1,70 @@
+package aliyunpan_open
+
+import (
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+type (
+	// filePathOperator 实现 aliyunpan.FilePathOperator
+	filePathOperator struct {
+		fileMergeOperator
+	}
+)
+
+// MoveByPath 按路径移动文件，语义和 mv 命令一致：
+// dstPath 是已存在的文件夹或者以 / 结尾时，源文件移动到该文件夹下，否则移动并重命名为 dstPath。
+// srcPath 支持通配符，匹配多个文件时 dstPath 必须是文件夹，不存在会自动创建。
+// 路径支持网盘别名，例如 resource:/Movies，源和目标必须在同一个网盘
+func (p *OpenPanClient) MoveByPath(driveId, srcPath, dstPath string, conflictMode aliyunpan.FileConflictMode) ([]*aliyunpan.FilePathOpResult, *apierror.ApiError) {
+	return aliyunpan.FilePathOp(filePathOperator{fileMergeOperator{p}}, aliyunpan.FilePathOpMove, driveId, srcPath, dstPath, conflictMode)
+}
+
+// CopyByPath 按路径复制文件，语义和 cp -r 命令一致，参数规则同 MoveByPath。会等待异步复制任务完成
+func (p *OpenPanClient) CopyByPath(driveId, srcPath, dstPath string, conflictMode aliyunpan.FileConflictMode) ([]*aliyunpan.FilePathOpResult, *apierror.ApiError) {
+	return aliyunpan.FilePathOp(filePathOperator{fileMergeOperator{p}}, aliyunpan.FilePathOpCopy, driveId, srcPath, dstPath, conflictMode)
+}
+
+// RenameByPath 按路径重命名文件，newName 只能是文件名
+func (p *OpenPanClient) RenameByPath(driveId, pathStr, newName string, conflictMode aliyunpan.FileConflictMode) (*aliyunpan.FilePathOpResult, *apierror.ApiError) {
+	return aliyunpan.FileRenameByPath(filePathOperator{fileMergeOperator{p}}, driveId, pathStr, newName, conflictMode)
+}
+
+// FileListGetAll 不使用缓存，同名冲突检查需要最新的文件列表
+func (o filePathOperator) FileListGetAll(param *aliyunpan.FileListParam, delayMilliseconds int) (aliyunpan.FileList, *apierror.ApiError) {
+	return o.fileListGetAll(param, delayMilliseconds)
+}
+
+// MoveFile 移动文件，改名通过 NewName 在移动时完成
+func (o filePathOperator) MoveFile(source *aliyunpan.FileEntity, toParentFileId, name string) *apierror.ApiError {
+	param := &aliyunpan.FileMoveParam{
+		DriveId:        source.DriveId,
+		FileId:         source.FileId,
+		ToParentFileId: toParentFileId,
+		CheckNameMode:  "refuse",
+	}
+	if name != source.FileName {
+		param.CheckNameMode = "auto_rename"
+		param.NewName = name
+	}
+	_, err := o.FileMove(param)
+	return err
+}
+
+// CopyFile 同网盘复制文件并等待异步任务完成
+func (o filePathOperator) CopyFile(source *aliyunpan.FileEntity, toParentFileId string) (string, *apierror.ApiError) {
+	return o.fileCopyToDrive(source.DriveId, source.FileId, source.DriveId, toParentFileId)
+}
+
+// ensureFileName 移动、复制后服务器可能已经自动重命名，名称不一致时再重命名
+func (p *OpenPanClient) ensureFileName(driveId, fileId, name string) *apierror.ApiError {
+	f, err := p.FileInfoById(driveId, fileId)
+	if err != nil {
+		return err
+	}
+	if f.FileName == name {
+		return nil
+	}
+	_, err = p.FileRename(driveId, fileId, name)
+	return err
+}
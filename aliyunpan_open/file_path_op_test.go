@@ -0,0 +1,90 @@
+package aliyunpan_open
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+func TestMoveByPath(t *testing.T) {
+	d := newFakeDrive()
+	docs := d.add("d", "root", "docs", "folder", 0, "")
+	d.add("d", "root", "a.txt", "file", 1, "A")
+	d.add("d", "root", "b.txt", "file", 2, "B")
+	d.add("d", "root", "c.log", "file", 3, "C")
+	d.add("d", docs, "a.txt", "file", 4, "OLD")
+	p := newFakeClient(t, d)
+
+	// 移动到文件夹，同名跳过
+	results, err := p.MoveByPath("d", "/*.txt", "/docs", aliyunpan.FileConflictSkip)
+	require.Nil(t, err)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Skipped)
+	assert.True(t, results[1].Success)
+	assert.Equal(t, "/docs/b.txt", results[1].TargetPath)
+	assert.Equal(t, []string{"a.txt", "c.log", "docs"}, d.names("d", "root"))
+	assert.Equal(t, []string{"a.txt", "b.txt"}, d.names("d", docs))
+
+	// 移动并改名，目标目录不存在会自动创建，改名在移动时通过 new_name 完成
+	calls := len(d.calls)
+	results, err = p.MoveByPath("d", "/c.log", "/logs/2023/app.log", "")
+	require.Nil(t, err)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, "/logs/2023/app.log", results[0].TargetPath)
+	assert.NotContains(t, d.calls[calls:], "/adrive/v1.0/openFile/update")
+	f, err := p.FileInfoByPath("d", "/logs/2023/app.log")
+	require.Nil(t, err)
+	assert.Equal(t, "C", f.ContentHash)
+
+	// 覆盖，先移动再把同名文件放入回收站
+	results, err = p.MoveByPath("d", "/a.txt", "/docs/a.txt", aliyunpan.FileConflictOverwrite)
+	require.Nil(t, err)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, "/docs/a.txt", results[0].TargetPath)
+	assert.Equal(t, []string{"a.txt", "b.txt"}, d.names("d", docs))
+	f, err = p.FileInfoByPath("d", "/docs/a.txt")
+	require.Nil(t, err)
+	assert.Equal(t, "A", f.ContentHash)
+
+	// 不能移动到自身子目录
+	results, err = p.MoveByPath("d", "/docs", "/docs/sub/", "")
+	require.Nil(t, err)
+	assert.NotNil(t, results[0].Err)
+
+	_, err = p.MoveByPath("d", "/nothing*", "/docs", "")
+	assert.NotNil(t, err)
+}
+
+func TestCopyAndRenameByPath(t *testing.T) {
+	d := newFakeDrive()
+	docs := d.add("d", "root", "docs", "folder", 0, "")
+	d.add("d", docs, "a.txt", "file", 1, "A")
+	p := newFakeClient(t, d)
+
+	// 复制文件夹，自动重命名
+	results, err := p.CopyByPath("d", "/docs", "/", "")
+	require.Nil(t, err)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, "/docs(1)", results[0].TargetPath)
+	assert.Equal(t, []string{"a.txt"}, d.names("d", results[0].FileId))
+
+	// 复制为新名字
+	results, err = p.CopyByPath("d", "/docs/a.txt", "/docs/b.txt", "")
+	require.Nil(t, err)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, []string{"a.txt", "b.txt"}, d.names("d", docs))
+
+	r, err := p.RenameByPath("d", "/docs/b.txt", "a.txt", "")
+	require.Nil(t, err)
+	assert.True(t, r.Success)
+	assert.Equal(t, "/docs/a(1).txt", r.TargetPath)
+
+	r, err = p.RenameByPath("d", "/docs/a(1).txt", "a.txt", aliyunpan.FileConflictSkip)
+	require.Nil(t, err)
+	assert.True(t, r.Skipped)
+
+	_, err = p.RenameByPath("d", "/docs/a.txt", "x/y", "")
+	assert.NotNil(t, err)
+}
@@ -17,6 +17,7 @@ RetryBegin:
 		CheckNameMode: "refuse",
 	}
 	if result, err := p.apiClient.FileUpdate(opParam); err == nil {
+		p.removeFileFromCache(driveId, renameFileId)
 		return result.Name != "", nil
 	} else {
 		// handle common error
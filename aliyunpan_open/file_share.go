@@ -5,6 +5,8 @@ import (
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/aliyunpan-api/aliyunpan_open/openapi"
+	"github.com/tickstep/library-go/logger"
+	"time"
 )
 
 // ShareLinkCreate 创建文件分享
@@ -87,3 +89,147 @@ RetryBegin:
 		}
 	}
 }
+
+// ShareLinkListPage 分页获取当前用户的分享链接，并按照 param 中的文件、状态、过期条件过滤当前页
+func (p *OpenPanClient) ShareLinkListPage(param *aliyunpan.ShareLinkListParam) (*aliyunpan.ShareLinkListResult, *apierror.ApiError) {
+	retryTime := 0
+
+	opParam := &openapi.FileShareListParam{
+		Limit:           param.Limit,
+		Marker:          param.Marker,
+		IncludeCanceled: param.IncludeCanceled,
+	}
+	if opParam.Limit <= 0 {
+		opParam.Limit = 100
+	}
+RetryBegin:
+	if result, err := p.apiClient.FileShareList(opParam); err == nil {
+		now := time.Now()
+		r := &aliyunpan.ShareLinkListResult{
+			Items:      []*aliyunpan.ShareEntity{},
+			NextMarker: result.NextMarker,
+		}
+		for _, item := range result.Items {
+			share := createShareEntity(item)
+			if param.Match(share, now) {
+				r.Items = append(r.Items, share)
+			}
+		}
+		return r, nil
+	} else {
+		// handle common error
+		if apiErrorHandleResp := p.HandleAliApiError(err, &retryTime); apiErrorHandleResp.NeedRetry {
+			goto RetryBegin
+		} else {
+			return nil, apiErrorHandleResp.ApiErr
+		}
+	}
+}
+
+// ShareLinkListAll 获取所有满足过滤条件的分享链接，param.Marker 会被忽略
+func (p *OpenPanClient) ShareLinkListAll(param *aliyunpan.ShareLinkListParam) ([]*aliyunpan.ShareEntity, *apierror.ApiError) {
+	resultList := []*aliyunpan.ShareEntity{}
+	pageParam := *param
+	pageParam.Marker = ""
+	for {
+		r, err := p.ShareLinkListPage(&pageParam)
+		if err != nil {
+			return nil, err
+		}
+		resultList = append(resultList, r.Items...)
+		if r.NextMarker == "" {
+			return resultList, nil
+		}
+		pageParam.Marker = r.NextMarker
+	}
+}
+
+// ShareLinkUpdate 修改分享的提取码和有效期，两者都会被更新为 param 中的值
+func (p *OpenPanClient) ShareLinkUpdate(param aliyunpan.ShareUpdateParam) (*aliyunpan.ShareEntity, *apierror.ApiError) {
+	retryTime := 0
+
+	// check pwd
+	if param.SharePwd != "" && len(param.SharePwd) != 4 {
+		return nil, apierror.NewFailedApiError("密码必须是4个字符")
+	}
+	opParam := &openapi.FileShareUpdateParam{
+		ShareId:    param.ShareId,
+		SharePwd:   param.SharePwd,
+		Expiration: apiutil.LocalTime2UtcFormat(param.Expiration),
+	}
+RetryBegin:
+	if result, err := p.apiClient.FileShareUpdate(opParam); err == nil {
+		return createShareEntity(result), nil
+	} else {
+		// handle common error
+		if apiErrorHandleResp := p.HandleAliApiError(err, &retryTime); apiErrorHandleResp.NeedRetry {
+			goto RetryBegin
+		} else {
+			return nil, apiErrorHandleResp.ApiErr
+		}
+	}
+}
+
+// ShareLinkCancel 取消分享链接，单个分享取消失败不会中断，结果中 Success 为 false，Err 为失败原因
+func (p *OpenPanClient) ShareLinkCancel(shareIdList []string) ([]*aliyunpan.ShareCancelResult, *apierror.ApiError) {
+	results := []*aliyunpan.ShareCancelResult{}
+	for _, shareId := range shareIdList {
+		retryTime := 0
+		r := &aliyunpan.ShareCancelResult{Id: shareId}
+	RetryBegin:
+		if ok, err := p.apiClient.FileShareCancel(&openapi.FileShareCancelParam{ShareId: shareId}); err == nil {
+			r.Success = ok
+			if !ok {
+				r.Err = apierror.NewFailedApiError("取消分享失败：" + shareId)
+			}
+		} else {
+			// handle common error
+			apiErrorHandleResp := p.HandleAliApiError(err, &retryTime)
+			if apiErrorHandleResp.NeedRetry {
+				goto RetryBegin
+			}
+			logger.Verboseln("cancel share error ", shareId, apiErrorHandleResp.ApiErr)
+			r.Err = apiErrorHandleResp.ApiErr
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// ShareLinkCancelExpired 取消当前用户所有已过期的分享链接，没有过期的分享时返回空列表
+func (p *OpenPanClient) ShareLinkCancelExpired() ([]*aliyunpan.ShareCancelResult, *apierror.ApiError) {
+	shareList, err := p.ShareLinkListAll(&aliyunpan.ShareLinkListParam{
+		Expired: aliyunpan.ShareExpiredOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+	shareIdList := []string{}
+	for _, share := range shareList {
+		shareIdList = append(shareIdList, share.ShareId)
+	}
+	return p.ShareLinkCancel(shareIdList)
+}
+
+func createShareEntity(item *openapi.FileShareItem) *aliyunpan.ShareEntity {
+	if item == nil {
+		return nil
+	}
+	return &aliyunpan.ShareEntity{
+		Creator:       item.Creator,
+		DriveId:       item.DriveId,
+		ShareId:       item.ShareId,
+		ShareName:     item.ShareName,
+		SharePwd:      item.SharePwd,
+		ShareUrl:      item.ShareUrl,
+		FileIdList:    item.FileIdList,
+		SaveCount:     item.SaveCount,
+		PreviewCount:  item.PreviewCount,
+		DownloadCount: item.DownloadCount,
+		Expiration:    apiutil.UtcTime2LocalFormat(item.Expiration),
+		Expired:       item.Expired,
+		UpdatedAt:     apiutil.UtcTime2LocalFormat(item.UpdatedAt),
+		CreatedAt:     apiutil.UtcTime2LocalFormat(item.CreatedAt),
+		Status:        item.Status,
+	}
+}
@@ -0,0 +1,66 @@
+package aliyunpan_open
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan_open/openapi"
+)
+
+func TestShareLinkManage(t *testing.T) {
+	d := newFakeDrive()
+	d.shares = []*openapi.FileShareItem{
+		{ShareId: "s1", FileIdList: []string{"f1"}, Status: "enabled", PreviewCount: 3, SaveCount: 2, DownloadCount: 1},
+		{ShareId: "s2", FileIdList: []string{"f2"}, Status: "enabled", Expiration: "2020-01-01T00:00:00.000Z"},
+		{ShareId: "s3", FileIdList: []string{"f1", "f3"}, Status: "forbidden", Expired: true},
+	}
+	p := newFakeClient(t, d)
+
+	// 分页并且按文件过滤
+	page, err := p.ShareLinkListPage(&aliyunpan.ShareLinkListParam{Limit: 2, FileId: "f1"})
+	require.Nil(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "s1", page.Items[0].ShareId)
+	assert.Equal(t, 3, page.Items[0].PreviewCount)
+	assert.Equal(t, 2, page.Items[0].SaveCount)
+	assert.Equal(t, 1, page.Items[0].DownloadCount)
+	assert.Equal(t, "2", page.NextMarker)
+
+	all, err := p.ShareLinkListAll(&aliyunpan.ShareLinkListParam{Limit: 2, FileId: "f1"})
+	require.Nil(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "s3", all[1].ShareId)
+
+	valid, err := p.ShareLinkListAll(&aliyunpan.ShareLinkListParam{Expired: aliyunpan.ShareExpiredExclude})
+	require.Nil(t, err)
+	require.Len(t, valid, 1)
+	assert.Equal(t, "s1", valid[0].ShareId)
+
+	// 修改提取码和有效期
+	_, err = p.ShareLinkUpdate(aliyunpan.ShareUpdateParam{ShareId: "s1", SharePwd: "abc"})
+	assert.NotNil(t, err)
+	share, err := p.ShareLinkUpdate(aliyunpan.ShareUpdateParam{ShareId: "s2", SharePwd: "8a2b"})
+	require.Nil(t, err)
+	assert.Equal(t, "8a2b", share.SharePwd)
+	assert.Equal(t, "", share.Expiration)
+	assert.False(t, share.IsExpired(time.Now()))
+
+	// 只取消已过期的分享
+	results, err := p.ShareLinkCancelExpired()
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "s3", results[0].Id)
+	assert.True(t, results[0].Success)
+	require.Len(t, d.shares, 2)
+
+	results, err = p.ShareLinkCancel([]string{"s1", "missing"})
+	require.Nil(t, err)
+	assert.True(t, results[0].Success)
+	assert.Nil(t, results[0].Err)
+	assert.False(t, results[1].Success)
+	assert.NotNil(t, results[1].Err)
+	require.Len(t, d.shares, 1)
+}
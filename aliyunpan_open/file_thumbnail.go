@@ -0,0 +1,63 @@
+package aliyunpan_open
+
+import (
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan_open/openapi"
+)
+
+// FileThumbnail 批量获取文件的缩略图、预览地址和图片元数据，图片和视频返回 Thumbnail，
+// 图片和小文件（包括文档）返回 Url，图片返回 ImageMediaMetadata。开放接口不支持 ImageUrlWidth
+func (p *OpenPanClient) FileThumbnail(param *aliyunpan.FileThumbnailParam) (aliyunpan.FileList, *apierror.ApiError) {
+	if param == nil || param.DriveId == "" {
+		return nil, apierror.NewFailedApiError("参数不能为空")
+	}
+	param = param.WithDefaults()
+	fileList := aliyunpan.FileList{}
+	for start := 0; start < len(param.FileIds); start += aliyunpan.FileThumbnailBatchSize {
+		end := start + aliyunpan.FileThumbnailBatchSize
+		if end > len(param.FileIds) {
+			end = len(param.FileIds)
+		}
+		r, err := p.fileThumbnailBatch(param, param.FileIds[start:end])
+		if err != nil {
+			return nil, err
+		}
+		fileList = append(fileList, r...)
+	}
+	return fileList, nil
+}
+
+func (p *OpenPanClient) fileThumbnailBatch(param *aliyunpan.FileThumbnailParam, fileIds []string) (aliyunpan.FileList, *apierror.ApiError) {
+	retryTime := 0
+
+RetryBegin:
+	opParam := []*openapi.FileIdentityPair{}
+	for _, fileId := range fileIds {
+		opParam = append(opParam, &openapi.FileIdentityPair{
+			DriveId: param.DriveId,
+			FileId:  fileId,
+		})
+	}
+	if result, err := p.apiClient.FileGetDetailInfoBatch(opParam, &openapi.FileThumbnailOption{
+		ImageThumbnailWidth: param.ImageThumbnailWidth,
+		VideoThumbnailWidth: param.VideoThumbnailWidth,
+		VideoThumbnailTime:  param.VideoThumbnailTime,
+	}); err == nil {
+		fileList := aliyunpan.FileList{}
+		for _, item := range result.Items {
+			if item == nil {
+				continue
+			}
+			fileList = append(fileList, createFileEntity(item))
+		}
+		return fileList, nil
+	} else {
+		// handle common error
+		if apiErrorHandleResp := p.HandleAliApiError(err, &retryTime); apiErrorHandleResp.NeedRetry {
+			goto RetryBegin
+		} else {
+			return nil, apiErrorHandleResp.ApiErr
+		}
+	}
+}
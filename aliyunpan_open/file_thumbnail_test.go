@@ -0,0 +1,53 @@
+package aliyunpan_open
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+func TestFileThumbnail(t *testing.T) {
+	d := newFakeDrive()
+	img := d.add("d1", "root", "a.jpg", "file", 100, "")
+	video := d.add("d1", "root", "b.mp4", "file", 100, "")
+	doc := d.add("d1", "root", "c.pdf", "file", 100, "")
+	p := newFakeClient(t, d)
+
+	fileList, err := p.FileThumbnail(&aliyunpan.FileThumbnailParam{
+		DriveId:            "d1",
+		FileIds:            []string{img, video, doc},
+		VideoThumbnailTime: 1000,
+	})
+	require.Nil(t, err)
+	require.Len(t, fileList, 3)
+	assert.Equal(t, "https://thumb.fake/"+img+"?w=400", fileList[0].Thumbnail)
+	assert.Equal(t, "https://download.fake/preview/"+img, fileList[0].Url)
+	require.NotNil(t, fileList[0].ImageMediaMetadata)
+	assert.Equal(t, 4032, fileList[0].ImageMediaMetadata.Width)
+	assert.Equal(t, 3024, fileList[0].ImageMediaMetadata.Height)
+	assert.Equal(t, `{"Model":"fake"}`, fileList[0].ImageMediaMetadata.Exif)
+	assert.Equal(t, "https://thumb.fake/"+video+"?w=800&t=1000", fileList[1].Thumbnail)
+	assert.Nil(t, fileList[1].ImageMediaMetadata)
+	assert.Empty(t, fileList[2].Thumbnail)
+
+	fileList, err = p.FileThumbnail(&aliyunpan.FileThumbnailParam{DriveId: "d1", FileIds: []string{img}, ImageThumbnailWidth: 120})
+	require.Nil(t, err)
+	assert.Equal(t, "https://thumb.fake/"+img+"?w=120", fileList[0].Thumbnail)
+}
+
+func TestFileThumbnailBatch(t *testing.T) {
+	d := newFakeDrive()
+	fileIds := []string{}
+	for i := 0; i < aliyunpan.FileThumbnailBatchSize+1; i++ {
+		fileIds = append(fileIds, d.add("d1", "root", fmt.Sprintf("%03d.jpg", i), "file", 1, ""))
+	}
+	p := newFakeClient(t, d)
+
+	fileList, err := p.FileThumbnail(&aliyunpan.FileThumbnailParam{DriveId: "d1", FileIds: fileIds})
+	require.Nil(t, err)
+	assert.Len(t, fileList, len(fileIds))
+	assert.Equal(t, 2, d.count("batch/get"))
+}
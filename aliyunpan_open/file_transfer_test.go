@@ -0,0 +1,50 @@
+package aliyunpan_open
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+func TestUploadFileDataGlobalLimit(t *testing.T) {
+	p := newFakeClient(t, newFakeDrive())
+	p.SetUploadSpeedLimit(1024 * 1024)
+
+	// 数据由回调发送，分片完成后按照请求大小等待全局上传限速
+	start := time.Now()
+	err := p.UploadFileData("https://upload.fake/part1", func(httpMethod, fullUrl string, headers map[string]string) (*http.Response, error) {
+		req, _ := http.NewRequest(httpMethod, fullUrl, bytes.NewReader(make([]byte, 300*1024)))
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewReader(nil)), Request: req}, nil
+	})
+	require.Nil(t, err)
+	elapsed := time.Since(start)
+	assert.True(t, elapsed >= 250*time.Millisecond, elapsed)
+}
+
+func TestDownloadFileDataTransferLimit(t *testing.T) {
+	d := newFakeDrive()
+	d.contents["/d/f1"] = make([]byte, 300*1024)
+	p := newFakeClient(t, d)
+
+	// 单个传输任务的限速和全局下载限速同时作用于回调返回的响应体
+	var resp *http.Response
+	err := p.DownloadFileData("https://download.fake/d/f1", aliyunpan.FileDownloadRange{}, func(httpMethod, fullUrl string, headers map[string]string) (*http.Response, error) {
+		req, _ := http.NewRequest(httpMethod, fullUrl, nil)
+		var e error
+		resp, e = d.RoundTrip(req)
+		return resp, e
+	}, aliyunpan.NewBandwidthLimiter(1024*1024))
+	require.Nil(t, err)
+	start := time.Now()
+	data, e := ioutil.ReadAll(resp.Body)
+	require.Nil(t, e)
+	assert.Len(t, data, 300*1024)
+	elapsed := time.Since(start)
+	assert.True(t, elapsed >= 250*time.Millisecond, elapsed)
+}
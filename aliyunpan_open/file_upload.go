@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"github.com/tickstep/aliyunpan-api/aliyunpan"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan_open/openapi"
 	"github.com/tickstep/library-go/logger"
+	"net/http"
 	"strings"
 )
 
@@ -85,6 +87,7 @@ RetryBegin:
 	}
 
 	if result, err := p.apiClient.FileUploadCreate(opParam); err == nil {
+		p.removeDirListFromCache(opParam.DriveId, opParam.ParentFileId)
 		partInfoListResult := []aliyunpan.FileUploadPartInfoResult{}
 		for _, v := range result.PartInfoList {
 			partInfoListResult = append(partInfoListResult, aliyunpan.FileUploadPartInfoResult{
@@ -99,6 +102,7 @@ RetryBegin:
 			PartInfoList: partInfoListResult,
 			UploadId:     result.UploadId,
 			RapidUpload:  result.RapidUpload,
+			Exist:        result.Exist,
 			Type:         "",
 			FileId:       result.FileId,
 			DomainId:     "",
@@ -163,7 +167,19 @@ RetryBegin:
 	}
 }
 
-// UploadFileData 上传文件数据
+// LimitUploadChunkData 返回使用全局上传限速的数据块，数据块原有的限速器（单个传输任务的限速）仍然生效。
+// 用于自行发送数据的场景，UploadFileData 已经按照全局上传限速，不需要再包装
+func (p *OpenPanClient) LimitUploadChunkData(data *aliyunpan.FileUploadChunkData) *aliyunpan.FileUploadChunkData {
+	if data == nil {
+		return nil
+	}
+	chunk := *data
+	chunk.Limiters = append([]*aliyunpan.BandwidthLimiter{p.uploadLimiter}, data.Limiters...)
+	return &chunk
+}
+
+// UploadFileData 上传文件数据。上传的数据由回调函数提供，每个分片上传完成后按照请求的 ContentLength 等待全局上传限速，
+// 所以回调中的数据块不需要再使用 LimitUploadChunkData 包装，单个传输任务的限速可以直接设置数据块的 Limiters
 func (p *OpenPanClient) UploadFileData(uploadUrl string, uploadFunc aliyunpan.UploadFunc) *apierror.ApiError {
 	// header
 	header := map[string]string{
@@ -173,15 +189,53 @@ func (p *OpenPanClient) UploadFileData(uploadUrl string, uploadFunc aliyunpan.Up
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s", uploadUrl)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// request
 	if uploadFunc != nil {
-		resp, err := uploadFunc("PUT", fullUrl.String(), header)
+		resp, err := p.apiClient.GetObserver().Do(apitransport.RequestClassTransfer, "PUT", fullUrl.String(), func() (*http.Response, error) {
+			return p.GetGovernor().Do(apitransport.RequestClassTransfer, func() (*http.Response, error) {
+				return uploadFunc("PUT", fullUrl.String(), header)
+			})
+		})
 		if err != nil || (resp != nil && resp.StatusCode != 200) {
 			logger.Verboseln("upload file data chunk error ", err)
 			return apierror.NewFailedApiError("update data error")
 		}
+		if resp != nil && resp.Request != nil && resp.Request.ContentLength > 0 {
+			// 数据由回调发送，只能在分片上传完成后按照分片大小等待全局上传限速
+			p.uploadLimiter.WaitN(int(resp.Request.ContentLength))
+		}
+	}
+	return nil
+}
+
+// UploadDataChunk 上传数据。该方法是同步阻塞的，数据按照全局上传限速以及数据块自身的限速器上传
+func (p *OpenPanClient) UploadDataChunk(url string, data *aliyunpan.FileUploadChunkData) *apierror.ApiError {
+	// header
+	header := map[string]string{
+		"referer": "https://www.aliyundrive.com/",
+	}
+
+	// url
+	fullUrl := &strings.Builder{}
+	fmt.Fprintf(fullUrl, "%s", url)
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
+
+	// data
+	if data == nil || data.Reader == nil || data.Len() == 0 {
+		return apierror.NewFailedApiError("数据块错误")
+	}
+	// request
+	resp, err := p.transferClient.Req("PUT", fullUrl.String(), p.LimitUploadChunkData(data), header)
+	if err != nil {
+		logger.Verboseln("upload file data chunk error ", err)
+		return apierror.NewApiErrorWithError(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		logger.Verboseln("upload file data chunk error, http status ", resp.StatusCode)
+		return apierror.NewFailedApiError("upload data chunk error").WithHttpStatus(resp.StatusCode).WithRequestId(resp.Header.Get("x-request-id"))
 	}
 	return nil
 }
@@ -254,6 +308,7 @@ RetryBegin:
 		UploadId: param.UploadId,
 	}
 	if result, err := p.apiClient.FileUploadComplete(opParam); err == nil {
+		p.removeDirListFromCache(result.DriveId, result.ParentFileId)
 		return &aliyunpan.CompleteUploadFileResult{
 			DriveId:         result.DriveId,
 			DomainId:        "",
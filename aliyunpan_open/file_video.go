@@ -16,10 +16,29 @@ RetryBegin:
 		Category: "live_transcoding",
 	}
 	if result, err := p.apiClient.VideoGetPreviewPlayInfo(opParam); err == nil {
-		return &aliyunpan.VideoGetPreviewPlayInfoResult{
+		r := &aliyunpan.VideoGetPreviewPlayInfoResult{
 			DriveId: result.DriveId,
 			FileId:  result.FileId,
-		}, nil
+		}
+		r.VideoPreviewPlayInfo.Category = result.VideoPreviewPlayInfo.Category
+		r.VideoPreviewPlayInfo.Meta.Duration = result.VideoPreviewPlayInfo.Meta.Duration
+		r.VideoPreviewPlayInfo.Meta.Width = result.VideoPreviewPlayInfo.Meta.Width
+		r.VideoPreviewPlayInfo.Meta.Height = result.VideoPreviewPlayInfo.Meta.Height
+		for _, task := range result.VideoPreviewPlayInfo.LiveTranscodingTaskList {
+			if task == nil {
+				continue
+			}
+			r.VideoPreviewPlayInfo.LiveTranscodingTaskList = append(r.VideoPreviewPlayInfo.LiveTranscodingTaskList, aliyunpan.VideoTranscodingTask{
+				TemplateId:     task.TemplateId,
+				TemplateName:   task.TemplateName,
+				TemplateWidth:  task.TemplateWidth,
+				TemplateHeight: task.TemplateHeight,
+				Status:         task.Status,
+				Stage:          task.Stage,
+				URL:            task.Url,
+			})
+		}
+		return r, nil
 	} else {
 		// handle common error
 		if apiErrorHandleResp := p.HandleAliApiError(err, &retryTime); apiErrorHandleResp.NeedRetry {
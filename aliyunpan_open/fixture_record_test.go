@@ -0,0 +1,195 @@
+package aliyunpan_open
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
+	"github.com/tickstep/aliyunpan-api/aliyunpan_open/openapi"
+)
+
+var recordFixtures = flag.Bool("record", false, "重新录制 testdata 下的接口响应")
+
+type (
+	// fixtureResponse 没有账号时录制使用的示例响应，敏感字段使用包含 sample-secret 的值，录制后不能出现在文件中
+	fixtureResponse struct {
+		path       string
+		statusCode int
+		requestId  string
+		body       string
+	}
+
+	// fixtureTransport 代替网络按顺序返回示例响应
+	fixtureTransport struct {
+		responses []*fixtureResponse
+	}
+
+	// recordSwitch 客户端的基础传输层，recorder 为nil时不录制，用于跳过获取账号信息等准备请求
+	recordSwitch struct {
+		base     http.RoundTripper
+		recorder http.RoundTripper
+	}
+
+	// fixtureIds 录制时使用的ID，使用示例响应时为示例数据中的ID，访问真实账号时从账号信息和环境变量获取
+	fixtureIds struct {
+		driveId string
+		albumId string
+	}
+
+	// fixture 一个录制文件，record 中调用需要录制的接口
+	fixture struct {
+		name      string
+		record    func(p *OpenPanClient, ids *fixtureIds)
+		responses []*fixtureResponse
+	}
+)
+
+// sampleFixtureIds 示例响应中的ID
+var sampleFixtureIds = &fixtureIds{
+	driveId: "11001",
+	albumId: "a3d0bd7f6b2e4c5a8e9f1d2c3b4a5e6f",
+}
+
+func (f *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(f.responses) == 0 || f.responses[0].path != req.URL.Path {
+		return nil, fmt.Errorf("no sample response for %s", req.URL.Path)
+	}
+	r := f.responses[0]
+	f.responses = f.responses[1:]
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("x-request-id", r.requestId)
+	return &http.Response{
+		StatusCode: r.statusCode,
+		Header:     header,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(r.body))),
+		Request:    req,
+	}, nil
+}
+
+func (s *recordSwitch) RoundTrip(req *http.Request) (*http.Response, error) {
+	if s.recorder != nil {
+		return s.recorder.RoundTrip(req)
+	}
+	return s.base.RoundTrip(req)
+}
+
+var openFixtures = []*fixture{
+	{
+		name: "file_list.json",
+		record: func(p *OpenPanClient, ids *fixtureIds) {
+			p.FileList(&aliyunpan.FileListParam{DriveId: ids.driveId, ParentFileId: aliyunpan.DefaultRootParentFileId, Limit: 2})
+			// 不存在的目录
+			p.FileList(&aliyunpan.FileListParam{DriveId: ids.driveId, ParentFileId: "6320d19ec3a4b1d3a8c74d6f8d3e5b1b2e2f7a99"})
+		},
+		responses: []*fixtureResponse{
+			{
+				path:       "/adrive/v1.0/openFile/list",
+				statusCode: 200,
+				requestId:  "0bc13b0116660546641984077e4c01",
+				body: `{"items":[` +
+					`{"drive_id":"11001","domain_id":"bj29","file_id":"6320d19ec3a4b1d3a8c74d6f8d3e5b1b2e2f7a10","name":"我的文档","type":"folder","created_at":"2022-09-13T18:58:38.104Z","updated_at":"2022-09-14T02:10:00.000Z","hidden":false,"starred":false,"status":"available","parent_file_id":"root","encrypt_mode":"none","creator_id":"sample-secret-user","last_modifier_id":"sample-secret-user"},` +
+					`{"drive_id":"11001","domain_id":"bj29","file_id":"6320d1a3b27d6f0ad1934fe4a3c43b2cb4ac2c15","name":"IMG_0001.JPG","type":"file","content_type":"application/oct-stream","created_at":"2022-09-13T18:58:43.563Z","updated_at":"2022-09-13T18:58:43.563Z","file_extension":"jpg","mime_type":"image/jpeg","hidden":false,"size":2736451,"starred":true,"status":"available","parent_file_id":"root","content_hash":"0D4E6A2F8A9B1C3D5E7F90A1B2C3D4E5F6A7B8C9","content_hash_name":"sha1","url":"https://cn-beijing-data.aliyundrive.net/xxx/IMG_0001.JPG?di=bj29&dr=11001&x-oss-signature=sample-secret-sig&security-token=sample-secret-sts","thumbnail":"https://cn-beijing-data.aliyundrive.net/xxx/IMG_0001.JPG?x-oss-process=image%2Fresize%2Cw_480&x-oss-signature=sample-secret-sig","category":"image","punish_flag":0,"image_media_metadata":{"width":4032,"height":3024,"exif":"{\"Orientation\":{\"value\":\"1\"}}"},"creator_id":"sample-secret-user"}` +
+					`],"next_marker":"WyI2MzIwZDFhM2IyN2Q2ZjBhZDE5MzRmZTRhM2M0M2IyY2I0YWMyYzE1Il0"}`,
+			},
+			{
+				path:       "/adrive/v1.0/openFile/list",
+				statusCode: 404,
+				requestId:  "0bc13b0116660546641984077e4c02",
+				body:       `{"code":"NotFound.File","message":"The resource file cannot be found. file not exist","requestId":"0bc13b0116660546641984077e4c02"}`,
+			},
+		},
+	},
+	{
+		name: "album_list_file.json",
+		record: func(p *OpenPanClient, ids *fixtureIds) {
+			p.AlbumListFile(&aliyunpan.AlbumListFileParam{AlbumId: ids.albumId, Limit: 2})
+		},
+		responses: []*fixtureResponse{
+			{
+				path:       "/adrive/v1.0/album/listFile",
+				statusCode: 200,
+				requestId:  "0bc13b0116660546641984077e4c03",
+				body: `{"items":[` +
+					`{"drive_id":"33003","domain_id":"bj29","file_id":"6321a8f4c9e1b2d3a4f5e6d7c8b9a0f1e2d3c4b5","name":"IMG_0002.livp","type":"file","content_type":"application/oct-stream","created_at":"2022-09-14T08:00:00.000Z","updated_at":"2022-09-14T08:00:01.000Z","file_extension":"livp","mime_type":"application/zip","hidden":false,"size":3145728,"starred":false,"status":"available","parent_file_id":"root","content_hash":"1A2B3C4D5E6F708192A3B4C5D6E7F8091A2B3C4D","content_hash_name":"sha1","thumbnail":"https://cn-beijing-data.aliyundrive.net/xxx/IMG_0002.livp?x-oss-process=image%2Fresize%2Cw_480&x-oss-signature=sample-secret-sig","category":"image","punish_flag":0,"image_media_metadata":{"width":3024,"height":4032,"exif":"{}"},"creator_id":"sample-secret-user"},` +
+					`{"drive_id":"33003","domain_id":"bj29","file_id":"6321a8f9d0e2c3f4b5a6f7e8d9c0b1a2f3e4d5c6","name":"VID_0003.MOV","type":"file","content_type":"application/oct-stream","created_at":"2022-09-14T09:30:00.000Z","updated_at":"2022-09-14T09:30:05.000Z","file_extension":"mov","mime_type":"video/quicktime","hidden":false,"size":52428800,"starred":false,"status":"available","parent_file_id":"root","content_hash":"2B3C4D5E6F708192A3B4C5D6E7F8091A2B3C4D5E","content_hash_name":"sha1","thumbnail":"https://cn-beijing-data.aliyundrive.net/xxx/VID_0003.MOV?x-oss-process=video%2Fsnapshot%2Ct_0&x-oss-signature=sample-secret-sig","category":"video","punish_flag":0,"creator_id":"sample-secret-user"}` +
+					`],"nextMarker":""}`,
+			},
+		},
+	},
+}
+
+// liveOpenClient 使用环境变量中的真实账号创建客户端并获取录制需要的ID，这些准备请求不录制。
+// 返回录制文件中不能出现的账号敏感数据
+func liveOpenClient(t *testing.T, token string, transport *recordSwitch) (*OpenPanClient, *fixtureIds, []string) {
+	p := NewOpenPanClient(openapi.ApiConfig{}, openapi.ApiToken{AccessToken: token}, nil, apitransport.WithTransport(transport))
+	p.SetRetryPolicy(aliyunpan.NoRetryPolicy())
+
+	ui, err := p.GetUserInfo()
+	require.Nil(t, err)
+	albumId := os.Getenv("ALIYUNPAN_OPEN_ALBUM_ID")
+	if albumId == "" {
+		albums, err := p.AlbumList(&aliyunpan.AlbumListParam{})
+		require.Nil(t, err)
+		require.NotEmpty(t, albums.Items, "没有相簿，需要设置 ALIYUNPAN_OPEN_ALBUM_ID")
+		albumId = albums.Items[0].AlbumId
+	}
+	ids := &fixtureIds{
+		driveId: ui.FileDriveId,
+		albumId: albumId,
+	}
+	secrets := []string{token, ui.UserId}
+	for _, s := range []string{ui.UserName, ui.Nickname, ui.Phone} {
+		if s != "" {
+			secrets = append(secrets, s)
+		}
+	}
+	return p, ids, secrets
+}
+
+// TestRecordFixtures 使用 go test -run TestRecordFixtures -record 重新录制 testdata 下的接口响应，录制的数据经过录制传输层脱敏后保存。
+// 设置了 ALIYUNPAN_OPEN_ACCESS_TOKEN 时使用真实账号录制，网盘ID从账号信息获取，可选设置 ALIYUNPAN_OPEN_ALBUM_ID（默认为第一个相簿）。
+// 没有设置时使用示例响应录制，testdata 中的文件目前是示例响应录制的，使用真实账号重新录制后需要按照录制的数据更新 parser_test 中的断言
+func TestRecordFixtures(t *testing.T) {
+	if !*recordFixtures {
+		t.Skip("使用 -record 重新录制")
+	}
+	token := os.Getenv("ALIYUNPAN_OPEN_ACCESS_TOKEN")
+	require.NoError(t, os.MkdirAll("testdata", 0755))
+	transport := &recordSwitch{base: http.DefaultTransport}
+	var p *OpenPanClient
+	ids, secrets := sampleFixtureIds, []string{"sample-secret"}
+	if token != "" {
+		p, ids, secrets = liveOpenClient(t, token, transport)
+	} else {
+		p = NewOpenPanClient(openapi.ApiConfig{}, openapi.ApiToken{}, nil, apitransport.WithTransport(transport))
+		p.SetRetryPolicy(aliyunpan.NoRetryPolicy())
+	}
+
+	for _, f := range openFixtures {
+		if token == "" {
+			transport.base = &fixtureTransport{responses: f.responses}
+		}
+		cassette := apitransport.NewCassette()
+		transport.recorder = apitransport.NewRecordingTransport(transport.base, cassette)
+		f.record(p, ids)
+		transport.recorder = nil
+
+		fixturePath := filepath.Join("testdata", f.name)
+		require.NoError(t, cassette.Save(fixturePath))
+		data, err := ioutil.ReadFile(fixturePath)
+		require.NoError(t, err)
+		for _, secret := range secrets {
+			assert.NotContains(t, string(data), secret, f.name)
+		}
+	}
+}
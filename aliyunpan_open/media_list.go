@@ -0,0 +1,11 @@
+package aliyunpan_open
+
+import (
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+// MediaList 获取文件夹中的视频文件和同名字幕文件，可选获取时长和分辨率，详见 aliyunpan.ListMedia
+func (p *OpenPanClient) MediaList(param *aliyunpan.MediaListParam) (aliyunpan.MediaList, *apierror.ApiError) {
+	return aliyunpan.ListMedia(p, param)
+}
@@ -0,0 +1,49 @@
+package aliyunpan_open
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+func TestMediaList(t *testing.T) {
+	d := newFakeDrive()
+	sub := d.add("d1", "root", "season", "folder", 0, "")
+	movie := d.add("d1", "root", "Movie.mkv", "file", 100, "")
+	clip := d.add("d1", "root", "clip.mp4", "file", 100, "")
+	d.add("d1", "root", "movie.srt", "file", 1, "")
+	d.add("d1", "root", "Movie.zh.ass", "file", 1, "")
+	d.add("d1", "root", "movies.vtt", "file", 1, "")
+	d.add("d1", "root", "clip.txt", "file", 1, "")
+	d.add("d1", "root", "cover.jpg", "file", 1, "")
+	d.add("d1", sub, "e01.mp4", "file", 100, "")
+	p := newFakeClient(t, d)
+
+	items, err := p.MediaList(&aliyunpan.MediaListParam{DriveId: "d1", WithPlayInfo: true, Concurrency: 1})
+	require.Nil(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, clip, items[0].File.FileId)
+	assert.Empty(t, items[0].Subtitles)
+	assert.Equal(t, movie, items[1].File.FileId)
+	assert.Equal(t, 60.5, items[1].Duration)
+	assert.Equal(t, 3840, items[1].Width)
+	assert.Equal(t, 2160, items[1].Height)
+	assert.Nil(t, items[1].Err)
+	names := []string{}
+	for _, s := range items[1].Subtitles {
+		names = append(names, s.FileName)
+	}
+	assert.ElementsMatch(t, []string{"movie.srt", "Movie.zh.ass"}, names)
+	assert.Equal(t, 2, d.count("getVideoPreviewPlayInfo"))
+
+	// 只识别指定的字幕后缀名
+	items, err = p.MediaList(&aliyunpan.MediaListParam{DriveId: "d1", ParentFileId: "root", SubtitleExtensions: []string{".ass"}})
+	require.Nil(t, err)
+	require.Len(t, items[1].Subtitles, 1)
+	assert.Equal(t, "Movie.zh.ass", items[1].Subtitles[0].FileName)
+	// 默认不获取播放信息，不会触发转码
+	assert.Equal(t, 0.0, items[1].Duration)
+	assert.Equal(t, 2, d.count("getVideoPreviewPlayInfo"))
+}
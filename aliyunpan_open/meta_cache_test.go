@@ -0,0 +1,47 @@
+package aliyunpan_open
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/metacache"
+)
+
+func TestMetaCacheInvalidatedOnMutation(t *testing.T) {
+	d := newFakeDrive()
+	dirId := d.add("d1", "root", "dir", "folder", 0, "")
+	d.add("d1", dirId, "a.txt", "file", 1, "h1")
+	p := newFakeClient(t, d)
+	store, _ := metacache.NewJsonFileStore("")
+	p.SetMetaCache(metacache.NewCache(store, time.Hour))
+
+	list := func() aliyunpan.FileList {
+		fl, err := p.FileListGetAll(&aliyunpan.FileListParam{DriveId: "d1", ParentFileId: dirId}, 0)
+		assert.Nil(t, err)
+		return fl
+	}
+	assert.Equal(t, 1, len(list()))
+	assert.Equal(t, 1, len(list()))
+	assert.Equal(t, 1, d.count("list"))
+
+	// mkdir invalidates the parent listing
+	_, err := p.Mkdir("d1", dirId, "sub")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(list()))
+	assert.Equal(t, 2, d.count("list"))
+
+	// rename drops the cached path
+	f, err := p.FileInfoByPath("d1", "/dir/a.txt")
+	assert.Nil(t, err)
+	_, err = p.FileRename("d1", f.FileId, "b.txt")
+	assert.Nil(t, err)
+	_, err = p.FileInfoByPath("d1", "/dir/a.txt")
+	assert.NotNil(t, err)
+	names := []string{}
+	for _, f := range list() {
+		names = append(names, f.FileName)
+	}
+	assert.Contains(t, names, "b.txt")
+}
@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/metacache"
 	"github.com/tickstep/aliyunpan-api/aliyunpan_open/openapi"
 	"github.com/tickstep/library-go/logger"
 	"github.com/tickstep/library-go/requester"
@@ -14,7 +17,7 @@ import (
 )
 
 const (
-	// ApiRetryMaxTimes API失败重试次数
+	// ApiRetryMaxTimes Token过期刷新后的重试次数，限流、网关错误等的重试由 aliyunpan.RetryPolicy 控制
 	ApiRetryMaxTimes int = 3
 )
 
@@ -25,7 +28,14 @@ type (
 	// OpenPanClient 开放接口客户端
 	OpenPanClient struct {
 		httpClient *requester.HTTPClient // http 客户端
-		apiClient  *openapi.AliPanClient
+		// transferClient 数据传输（下载文件、播放视频分片）使用的 http 客户端，使用数据传输的超时时间和限速
+		transferClient *requester.HTTPClient
+		apiClient      *openapi.AliPanClient
+		// 请求失败重试
+		retryTransport *apitransport.RetryTransport
+		// 全局上传、下载带宽限速
+		uploadLimiter   *aliyunpan.BandwidthLimiter
+		downloadLimiter *aliyunpan.BandwidthLimiter
 
 		accessTokenRefreshCallback AccessTokenRefreshCallback
 
@@ -34,20 +44,38 @@ type (
 		useCache   bool
 		// 网盘文件绝对路径到网盘文件信息实体映射缓存，避免FileInfoByPath频繁访问服务器触发风控
 		filePathCacheMap sync.Map
+		// 可持久化的元数据缓存，可以跨进程共享
+		metaCache *metacache.Cache
+		// 网盘别名注册表
+		driveRegistry *aliyunpan.DriveRegistry
 	}
 )
 
-// NewOpenPanClient 创建开放接口客户端
-func NewOpenPanClient(apiConfig openapi.ApiConfig, apiToken openapi.ApiToken, tokenCallback AccessTokenRefreshCallback) *OpenPanClient {
-	myclient := requester.NewHTTPClient()
+// NewOpenPanClient 创建开放接口客户端。opts 可以注入自定义的 http 客户端、传输层、代理等，Token刷新请求也会使用同样的配置。
+// 默认不限制请求速率，批量操作需要限速时使用 apitransport.WithGovernorConfig
+func NewOpenPanClient(apiConfig openapi.ApiConfig, apiToken openapi.ApiToken, tokenCallback AccessTokenRefreshCallback, opts ...apitransport.ClientOption) *OpenPanClient {
+	apiClient := openapi.NewAliPanClient(apiToken, apiConfig, opts...)
+	options := apitransport.NewClientOptions(opts...)
+	myclient := options.NewHTTPClient()
+	apitransport.InstallGovernor(myclient, apiClient.GetGovernor())
+	retryTransport := apitransport.InstallRetry(myclient, aliyunpan.DefaultRetryPolicy())
+	apitransport.InstallObserver(myclient, apiClient.GetObserver())
+	transferClient := options.NewTransferHTTPClient()
+	apitransport.InstallTransferGovernor(transferClient, apiClient.GetGovernor())
+	apitransport.InstallTransferObserver(transferClient, apiClient.GetObserver())
 
 	return &OpenPanClient{
 		httpClient:                 myclient,
-		apiClient:                  openapi.NewAliPanClient(apiToken, apiConfig),
+		transferClient:             transferClient,
+		retryTransport:             retryTransport,
+		apiClient:                  apiClient,
 		accessTokenRefreshCallback: tokenCallback,
 		cacheMutex:                 &sync.Mutex{},
 		useCache:                   false,
 		filePathCacheMap:           sync.Map{},
+		uploadLimiter:              aliyunpan.NewBandwidthLimiter(0),
+		downloadLimiter:            aliyunpan.NewBandwidthLimiter(0),
+		driveRegistry:              aliyunpan.NewDriveRegistry(),
 	}
 }
 
@@ -56,7 +84,9 @@ func (p *OpenPanClient) SetAccessTokenRefreshCallback(tokenCallback AccessTokenR
 	p.accessTokenRefreshCallback = tokenCallback
 }
 
-// SetTimeout 设置 http 请求超时时间
+// SetTimeout 设置 http 请求超时时间，会覆盖创建客户端时 WithTimeout、WithHTTPClient 设置的超时时间。
+// 不影响数据传输，数据传输的超时时间使用 WithTransferTimeout 设置。
+// 超时时间包含请求失败重试的时间，调小超时时间时需要同时调整重试策略的 MaxElapsed，详见 aliyunpan.RetryPolicy
 func (p *OpenPanClient) SetTimeout(t time.Duration) {
 	if p.apiClient != nil {
 		p.apiClient.SetTimeout(t)
@@ -67,6 +97,47 @@ func (p *OpenPanClient) SetTimeout(t time.Duration) {
 	}
 }
 
+// SetRetryPolicy 设置请求失败重试策略，为nil则不重试。策略同时作用于开放接口请求和Token刷新请求
+func (p *OpenPanClient) SetRetryPolicy(policy *aliyunpan.RetryPolicy) {
+	p.apiClient.SetRetryPolicy(policy)
+	p.retryTransport.SetPolicy(policy)
+}
+
+// GetRetryPolicy 获取当前的请求失败重试策略
+func (p *OpenPanClient) GetRetryPolicy() *aliyunpan.RetryPolicy {
+	return p.apiClient.GetRetryPolicy()
+}
+
+// GetGovernor 获取请求限速器，开放接口请求、Token刷新和数据传输共用同一个限速器
+func (p *OpenPanClient) GetGovernor() *apitransport.Governor {
+	return p.apiClient.GetGovernor()
+}
+
+// AddRequestHook 添加请求观测钩子，开放接口请求、Token刷新和数据传输都会经过钩子。URL中的签名、Token等参数已经脱敏
+func (p *OpenPanClient) AddRequestHook(hook apitransport.Hook) {
+	p.apiClient.AddRequestHook(hook)
+}
+
+// SetUploadSpeedLimit 设置全局上传限速（字节/秒），小于等于0代表不限速。传输过程中修改立即生效
+func (p *OpenPanClient) SetUploadSpeedLimit(bytesPerSecond int64) {
+	p.uploadLimiter.SetRate(bytesPerSecond)
+}
+
+// SetDownloadSpeedLimit 设置全局下载限速（字节/秒），小于等于0代表不限速。传输过程中修改立即生效
+func (p *OpenPanClient) SetDownloadSpeedLimit(bytesPerSecond int64) {
+	p.downloadLimiter.SetRate(bytesPerSecond)
+}
+
+// GetUploadLimiter 获取全局上传限速器
+func (p *OpenPanClient) GetUploadLimiter() *aliyunpan.BandwidthLimiter {
+	return p.uploadLimiter
+}
+
+// GetDownloadLimiter 获取全局下载限速器
+func (p *OpenPanClient) GetDownloadLimiter() *aliyunpan.BandwidthLimiter {
+	return p.downloadLimiter
+}
+
 // GetAccessToken 获取AccessToken鉴权字符串
 func (p *OpenPanClient) GetAccessToken() string {
 	return p.apiClient.GetAccessToken()
@@ -80,7 +151,7 @@ func (p *OpenPanClient) RefreshNewAccessToken() error {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "https://api.tickstep.com/auth/tickstep/aliyunpan/token/openapi/%s/refresh?userId=%s",
 		p.apiClient.GetApiConfig().TicketId, p.apiClient.GetApiConfig().UserId)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// request
 	h := p.apiClient.Headers()
@@ -134,6 +205,25 @@ func (p *OpenPanClient) UpdateUserId(userId string) {
 	p.apiClient.UpdateApiConfig(c)
 }
 
+// GetDriveRegistry 获取网盘别名注册表，调用 GetUserInfo 后会自动填充
+func (p *OpenPanClient) GetDriveRegistry() *aliyunpan.DriveRegistry {
+	return p.driveRegistry
+}
+
+// ResolveDrivePath 解析带网盘别名的路径，例如 resource:/Movies/x.mkv，返回网盘ID和网盘内的路径。
+// 别名还没有加载时会先获取一次用户信息
+func (p *OpenPanClient) ResolveDrivePath(driveId, pathStr string) (string, string, *apierror.ApiError) {
+	if !aliyunpan.IsQualifiedPath(pathStr) {
+		return driveId, pathStr, nil
+	}
+	if !p.driveRegistry.Loaded() {
+		if _, err := p.GetUserInfo(); err != nil {
+			return "", "", err
+		}
+	}
+	return p.driveRegistry.ResolvePath(driveId, pathStr)
+}
+
 // EnableCache 启用缓存
 func (p *OpenPanClient) EnableCache() {
 	p.cacheMutex.Lock()
@@ -155,8 +245,23 @@ func (p *OpenPanClient) DisableCache() {
 	p.useCache = false
 }
 
+// SetMetaCache 设置可持久化的元数据缓存，为nil则不使用。缓存会被 FileInfoByPath、FileListGetAll、MatchPathByShellPattern 共享
+func (p *OpenPanClient) SetMetaCache(cache *metacache.Cache) {
+	p.cacheMutex.Lock()
+	defer p.cacheMutex.Unlock()
+	p.metaCache = cache
+}
+
+// GetMetaCache 获取元数据缓存
+func (p *OpenPanClient) GetMetaCache() *metacache.Cache {
+	return p.metaCache
+}
+
 // storeFilePathToCache 存储文件信息到缓存
 func (p *OpenPanClient) storeFilePathToCache(driveId, pathStr string, fileEntity *aliyunpan.FileEntity) {
+	if p.metaCache != nil {
+		p.metaCache.PutPath(driveId, pathStr, fileEntity)
+	}
 	p.cacheMutex.Lock()
 	p.cacheMutex.Unlock()
 	if !p.useCache {
@@ -171,15 +276,20 @@ func (p *OpenPanClient) storeFilePathToCache(driveId, pathStr string, fileEntity
 func (p *OpenPanClient) loadFilePathFromCache(driveId, pathStr string) *aliyunpan.FileEntity {
 	p.cacheMutex.Lock()
 	p.cacheMutex.Unlock()
-	if !p.useCache {
-		return nil
+	if p.useCache {
+		pathStr = formatPathStyle(pathStr)
+		cache, _ := p.filePathCacheMap.LoadOrStore(driveId, &sync.Map{})
+		s := cache.(*sync.Map)
+		if v, ok := s.Load(pathStr); ok {
+			logger.Verboseln("file path cache hit: ", pathStr)
+			return v.(*aliyunpan.FileEntity)
+		}
 	}
-	pathStr = formatPathStyle(pathStr)
-	cache, _ := p.filePathCacheMap.LoadOrStore(driveId, &sync.Map{})
-	s := cache.(*sync.Map)
-	if v, ok := s.Load(pathStr); ok {
-		logger.Verboseln("file path cache hit: ", pathStr)
-		return v.(*aliyunpan.FileEntity)
+	if p.metaCache != nil {
+		if v := p.metaCache.LookupPath(driveId, pathStr, p.FileInfoById); v != nil {
+			logger.Verboseln("file path meta cache hit: ", pathStr)
+			return v
+		}
 	}
 	return nil
 }
@@ -191,3 +301,53 @@ func formatPathStyle(pathStr string) string {
 	}
 	return pathStr
 }
+
+// removeFilePathFromCache 删除路径以及子路径的缓存，文件移动、重命名、删除后调用
+func (p *OpenPanClient) removeFilePathFromCache(driveId string, fileEntity *aliyunpan.FileEntity) {
+	p.removeFileFromCache(driveId, fileEntity.FileId)
+	if fileEntity.Path != "" {
+		p.removeMemoryPathCache(driveId, formatPathStyle(fileEntity.Path))
+	}
+}
+
+// removeFileFromCache 删除文件ID对应的所有缓存，包括文件所在目录的文件列表以及文件夹下所有子路径
+func (p *OpenPanClient) removeFileFromCache(driveId, fileId string) {
+	if p.metaCache != nil {
+		p.metaCache.Invalidate(driveId, fileId)
+	}
+	cache, ok := p.filePathCacheMap.Load(driveId)
+	if !ok {
+		return
+	}
+	paths := []string{}
+	cache.(*sync.Map).Range(func(key, value interface{}) bool {
+		if f, ok := value.(*aliyunpan.FileEntity); ok && f.FileId == fileId {
+			paths = append(paths, key.(string))
+		}
+		return true
+	})
+	for _, pathStr := range paths {
+		p.removeMemoryPathCache(driveId, pathStr)
+	}
+}
+
+// removeMemoryPathCache 删除内存中路径以及子路径的缓存
+func (p *OpenPanClient) removeMemoryPathCache(driveId, pathStr string) {
+	cache, ok := p.filePathCacheMap.Load(driveId)
+	if !ok {
+		return
+	}
+	cache.(*sync.Map).Range(func(key, value interface{}) bool {
+		if k := key.(string); k == pathStr || strings.HasPrefix(k, pathStr+"/") {
+			cache.(*sync.Map).Delete(key)
+		}
+		return true
+	})
+}
+
+// removeDirListFromCache 删除目录文件列表的缓存，目录中新建、复制或者移入文件后调用
+func (p *OpenPanClient) removeDirListFromCache(driveId, parentFileId string) {
+	if p.metaCache != nil {
+		p.metaCache.InvalidateDir(driveId, parentFileId)
+	}
+}
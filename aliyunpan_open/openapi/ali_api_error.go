@@ -13,7 +13,10 @@ type (
 		HttpStatusCode int                    `json:"http_status_code"`
 		Code           string                 `json:"code"`
 		Message        string                 `json:"message"`
+		RequestId      string                 `json:"requestId"`
 		extra          map[string]interface{} `json:"-"`
+		// cause 底层错误，例如网络错误、json解析错误
+		cause error
 	}
 
 	// AliApiDefaultErrResult openapi默认错误响应，例如404错误
@@ -39,6 +42,26 @@ func NewAliApiHttpError(msg string) *AliApiErrResult {
 		Message:        msg,
 	}
 }
+
+// NewAliApiHttpErrorWithCause 请求失败，保留底层错误
+func NewAliApiHttpErrorWithCause(err error) *AliApiErrResult {
+	r := NewAliApiHttpError(err.Error())
+	r.cause = err
+	return r
+}
+
+// NewAliApiAppErrorWithCause 本地处理失败，保留底层错误
+func NewAliApiAppErrorWithCause(err error) *AliApiErrResult {
+	r := NewAliApiAppError(err.Error())
+	r.cause = err
+	return r
+}
+
+// Cause 返回底层错误
+func (a *AliApiErrResult) Cause() error {
+	return a.cause
+}
+
 func NewAliApiAppError(msg string) *AliApiErrResult {
 	return &AliApiErrResult{
 		HttpStatusCode: 200,
@@ -70,10 +93,15 @@ func ParseCommonOpenApiError(resp *http.Response) ([]byte, *AliApiErrResult) {
 		return nil, nil
 	}
 
+	requestId := resp.Header.Get("x-request-id")
+
 	// read response text
 	data, e := ioutil.ReadAll(resp.Body)
 	if e != nil {
-		return nil, NewAliApiError(resp.StatusCode, "TS.ReadError", e.Error())
+		r := NewAliApiError(resp.StatusCode, "TS.ReadError", e.Error())
+		r.RequestId = requestId
+		r.cause = e
+		return nil, r
 	}
 
 	// 非json错误
@@ -85,6 +113,7 @@ func ParseCommonOpenApiError(resp *http.Response) ([]byte, *AliApiErrResult) {
 				HttpStatusCode: resp.StatusCode,
 				Code:           plainText,
 				Message:        plainText,
+				RequestId:      requestId,
 			}
 		}
 	}
@@ -97,6 +126,7 @@ func ParseCommonOpenApiError(resp *http.Response) ([]byte, *AliApiErrResult) {
 				HttpStatusCode: resp.StatusCode,
 				Code:           errDefaultResult.Error,
 				Message:        errDefaultResult.Error,
+				RequestId:      requestId,
 			}
 			return nil, errResult
 		}
@@ -107,6 +137,9 @@ func ParseCommonOpenApiError(resp *http.Response) ([]byte, *AliApiErrResult) {
 	if err := json.Unmarshal(data, errResult); err == nil {
 		if errResult.Code != "" {
 			errResult.HttpStatusCode = resp.StatusCode
+			if errResult.RequestId == "" {
+				errResult.RequestId = requestId
+			}
 			// headers
 			if hv := resp.Header.Get("x-retry-after"); hv != "" {
 				errResult.PutExtra("x-retry-after", hv)
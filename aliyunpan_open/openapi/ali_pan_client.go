@@ -15,6 +15,8 @@
 package openapi
 
 import (
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/library-go/requester"
 	"strings"
 	"sync"
@@ -43,8 +45,14 @@ type (
 
 	AliPanClient struct {
 		httpclient *requester.HTTPClient // http 客户端
-		token      ApiToken
-		apiConfig  ApiConfig
+		// 请求失败重试
+		retryTransport *apitransport.RetryTransport
+		// 请求限速
+		governor *apitransport.Governor
+		// 请求观测
+		observer  *apitransport.Observer
+		token     ApiToken
+		apiConfig ApiConfig
 
 		cacheMutex *sync.Mutex
 		useCache   bool
@@ -53,13 +61,23 @@ type (
 	}
 )
 
-func NewAliPanClient(token ApiToken, apiConfig ApiConfig) *AliPanClient {
-	myclient := requester.NewHTTPClient()
+// NewAliPanClient 创建开放接口客户端。opts 可以注入自定义的 http 客户端、传输层、代理等，默认不限制请求速率
+func NewAliPanClient(token ApiToken, apiConfig ApiConfig, opts ...apitransport.ClientOption) *AliPanClient {
+	options := apitransport.NewClientOptions(opts...)
+	governor := options.NewGovernor()
+	myclient := options.NewHTTPClient()
+	apitransport.InstallGovernor(myclient, governor)
+	retryTransport := apitransport.InstallRetry(myclient, aliyunpan.DefaultRetryPolicy())
+	observer := apitransport.NewObserver()
+	apitransport.InstallObserver(myclient, observer)
 
 	return &AliPanClient{
-		httpclient: myclient,
-		token:      token,
-		apiConfig:  apiConfig,
+		httpclient:     myclient,
+		retryTransport: retryTransport,
+		governor:       governor,
+		observer:       observer,
+		token:          token,
+		apiConfig:      apiConfig,
 
 		cacheMutex:       &sync.Mutex{},
 		useCache:         false,
@@ -143,13 +161,38 @@ func (a *AliPanClient) DisableCache() {
 //	return nil
 //}
 
-// SetTimeout 设置 http 请求超时时间
+// SetTimeout 设置 http 请求超时时间，会覆盖创建客户端时 WithTimeout、WithHTTPClient 设置的超时时间
 func (a *AliPanClient) SetTimeout(t time.Duration) {
 	if a.httpclient != nil {
 		a.httpclient.Timeout = t
 	}
 }
 
+// SetRetryPolicy 设置请求失败重试策略，为nil则不重试
+func (a *AliPanClient) SetRetryPolicy(policy *aliyunpan.RetryPolicy) {
+	a.retryTransport.SetPolicy(policy)
+}
+
+// GetRetryPolicy 获取当前的请求失败重试策略
+func (a *AliPanClient) GetRetryPolicy() *aliyunpan.RetryPolicy {
+	return a.retryTransport.Policy()
+}
+
+// GetGovernor 获取请求限速器
+func (a *AliPanClient) GetGovernor() *apitransport.Governor {
+	return a.governor
+}
+
+// GetObserver 获取请求观测器
+func (a *AliPanClient) GetObserver() *apitransport.Observer {
+	return a.observer
+}
+
+// AddRequestHook 添加请求观测钩子
+func (a *AliPanClient) AddRequestHook(hook apitransport.Hook) {
+	a.observer.AddHook(hook)
+}
+
 func formatPathStyle(pathStr string) string {
 	pathStr = strings.ReplaceAll(pathStr, "\\", "/")
 	if pathStr != "/" {
@@ -3,6 +3,7 @@ package openapi
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/library-go/logger"
 	"strings"
 )
@@ -26,7 +27,7 @@ type (
 func (a *AliPanClient) AsyncTaskQueryStatus(param *AsyncTaskQueryStatusParam) (*AsyncTaskQueryStatusResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/async_task/get", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -35,7 +36,7 @@ func (a *AliPanClient) AsyncTaskQueryStatus(param *AsyncTaskQueryStatusParam) (*
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("async task status error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -49,7 +50,7 @@ func (a *AliPanClient) AsyncTaskQueryStatus(param *AsyncTaskQueryStatusParam) (*
 	r := &AsyncTaskQueryStatusResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse async task status result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
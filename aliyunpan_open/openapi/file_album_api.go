@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/library-go/logger"
 	"strings"
 )
@@ -57,6 +58,100 @@ type (
 		NextMarker string `json:"nextMarker"`
 	}
 
+	// AlbumItem 个人相簿项
+	AlbumItem struct {
+		// AlbumId 相簿唯一ID
+		AlbumId string `json:"albumId"`
+		// Name 相簿名称
+		Name string `json:"name"`
+		// Description 相簿简介
+		Description string `json:"description"`
+		// CoverThumbnail 封面图地址
+		CoverThumbnail string `json:"coverThumbnail"`
+		// FileCount 文件数量
+		FileCount int `json:"fileCount"`
+		// ImageCount 图片数量
+		ImageCount int `json:"imageCount"`
+		// VideoCount 视频数量
+		VideoCount int `json:"videoCount"`
+		// CreatedAt 创建时间
+		CreatedAt int64 `json:"createdAt"`
+		// UpdatedAt 更新时间
+		UpdatedAt int64 `json:"updatedAt"`
+	}
+	// AlbumListParam 获取个人相簿列表参数
+	AlbumListParam struct {
+		// OrderBy 排序字段，created_at、updated_at、file_count
+		OrderBy string `json:"order_by"`
+		// OrderDirection 排序方向，ASC 升序，DESC 降序
+		OrderDirection string `json:"order_direction"`
+		// Limit 返回数量
+		Limit int `json:"limit"`
+		// Marker 分页标记
+		Marker string `json:"marker,omitempty"`
+	}
+	// AlbumListResult 获取个人相簿列表返回值
+	AlbumListResult struct {
+		Items []*AlbumItem `json:"items"`
+		// NextMarker 不为空代表还有下一页
+		NextMarker string `json:"nextMarker"`
+	}
+	// AlbumCreateParam 创建个人相簿参数
+	AlbumCreateParam struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	// AlbumUpdateParam 编辑个人相簿参数
+	AlbumUpdateParam struct {
+		AlbumId     string `json:"albumId"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	// AlbumDeleteParam 删除个人相簿参数
+	AlbumDeleteParam struct {
+		AlbumId string `json:"albumId"`
+	}
+	// AlbumGetParam 获取个人相簿参数
+	AlbumGetParam struct {
+		AlbumId string `json:"albumId"`
+	}
+	// AlbumListFileParam 获取个人相簿文件列表参数
+	AlbumListFileParam struct {
+		// AlbumId 相簿唯一ID
+		AlbumId string `json:"albumId"`
+		// OrderBy 排序字段，当前仅支持joined_at
+		OrderBy string `json:"order_by"`
+		// OrderDirection 排序方向，默认 DESC。ASC 升序，DESC 降序。
+		OrderDirection string `json:"order_direction"`
+		// Marker 分页标记
+		Marker string `json:"marker"`
+		// Limit 返回文件数量
+		Limit int `json:"limit"`
+		// ImageThumbnailWidth 生成的图片缩略图宽度
+		ImageThumbnailWidth int `json:"image_thumbnail_width"`
+	}
+	// AlbumListFileResult 获取个人相簿文件列表返回值
+	AlbumListFileResult struct {
+		// Items 文件列表
+		Items []*FileItem `json:"items"`
+		// NextMarker 不为空代表还有下一页
+		NextMarker string `json:"nextMarker"`
+	}
+	// AlbumFileItem 相簿文件项
+	AlbumFileItem struct {
+		DriveId string `json:"drive_id"`
+		FileId  string `json:"file_id"`
+	}
+	// AlbumFileParam 个人相簿增加或者删除文件参数
+	AlbumFileParam struct {
+		AlbumId       string          `json:"albumId"`
+		DriveFileList []AlbumFileItem `json:"drive_file_list"`
+	}
+	// AlbumAddFileResult 个人相簿增加文件返回值
+	AlbumAddFileResult struct {
+		Items []*FileItem `json:"file_list"`
+	}
+
 	// ShareAlbumGetFileUrlParam 获取共享相册下文件下载地址参数
 	ShareAlbumGetFileUrlParam struct {
 		// AlbumId 共享相册唯一ID
@@ -72,7 +167,7 @@ type (
 func (a *AliPanClient) ShareAlbumList(param *ShareAlbumListParam) (*ShareAlbumListResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/sharedAlbum/list", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -81,7 +176,7 @@ func (a *AliPanClient) ShareAlbumList(param *ShareAlbumListParam) (*ShareAlbumLi
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("list share album error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -95,7 +190,7 @@ func (a *AliPanClient) ShareAlbumList(param *ShareAlbumListParam) (*ShareAlbumLi
 	r := &ShareAlbumListResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse list share album result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -104,7 +199,7 @@ func (a *AliPanClient) ShareAlbumList(param *ShareAlbumListParam) (*ShareAlbumLi
 func (a *AliPanClient) ShareAlbumListFile(param *ShareAlbumListFileParam) (*ShareAlbumListFileResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/sharedAlbum/listFile", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -113,7 +208,7 @@ func (a *AliPanClient) ShareAlbumListFile(param *ShareAlbumListFileParam) (*Shar
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("list file of share album error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -127,7 +222,7 @@ func (a *AliPanClient) ShareAlbumListFile(param *ShareAlbumListFileParam) (*Shar
 	r := &ShareAlbumListFileResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse list file of share album result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	// 补全 album ID
 	if r.Items != nil {
@@ -142,7 +237,7 @@ func (a *AliPanClient) ShareAlbumListFile(param *ShareAlbumListFileParam) (*Shar
 func (a *AliPanClient) ShareAlbumGetFileDownloadUrl(param *ShareAlbumGetFileUrlParam) (*aliyunpan.ShareAlbumGetFileUrlResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/sharedAlbum/getDownloadUrl", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -151,7 +246,7 @@ func (a *AliPanClient) ShareAlbumGetFileDownloadUrl(param *ShareAlbumGetFileUrlP
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("get share album file download url error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -165,7 +260,247 @@ func (a *AliPanClient) ShareAlbumGetFileDownloadUrl(param *ShareAlbumGetFileUrlP
 	r := &aliyunpan.ShareAlbumGetFileUrlResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse download url of share album file result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
+	}
+	return r, nil
+}
+
+// AlbumList 获取个人相簿列表
+func (a *AliPanClient) AlbumList(param *AlbumListParam) (*AlbumListResult, *AliApiErrResult) {
+	fullUrl := &strings.Builder{}
+	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/album/list", OPENAPI_URL)
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
+
+	// parameters
+	postData := param
+
+	// request
+	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
+	if err != nil {
+		logger.Verboseln("list album error ", err)
+		return nil, NewAliApiHttpErrorWithCause(err)
+	}
+
+	// handler common error
+	var body []byte
+	var apiErrResult *AliApiErrResult
+	if body, apiErrResult = ParseCommonOpenApiError(resp); apiErrResult != nil {
+		return nil, apiErrResult
+	}
+
+	// parse result
+	r := &AlbumListResult{}
+	if err2 := json.Unmarshal(body, r); err2 != nil {
+		logger.Verboseln("parse list album result json error ", err2)
+		return nil, NewAliApiAppErrorWithCause(err2)
+	}
+	return r, nil
+}
+
+// AlbumCreate 创建个人相簿
+func (a *AliPanClient) AlbumCreate(param *AlbumCreateParam) (*AlbumItem, *AliApiErrResult) {
+	fullUrl := &strings.Builder{}
+	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/album/create", OPENAPI_URL)
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
+
+	// parameters
+	postData := param
+
+	// request
+	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
+	if err != nil {
+		logger.Verboseln("create album error ", err)
+		return nil, NewAliApiHttpErrorWithCause(err)
+	}
+
+	// handler common error
+	var body []byte
+	var apiErrResult *AliApiErrResult
+	if body, apiErrResult = ParseCommonOpenApiError(resp); apiErrResult != nil {
+		return nil, apiErrResult
+	}
+
+	// parse result
+	r := &AlbumItem{}
+	if err2 := json.Unmarshal(body, r); err2 != nil {
+		logger.Verboseln("parse create album result json error ", err2)
+		return nil, NewAliApiAppErrorWithCause(err2)
+	}
+	return r, nil
+}
+
+// AlbumUpdate 编辑个人相簿的名称和简介
+func (a *AliPanClient) AlbumUpdate(param *AlbumUpdateParam) (*AlbumItem, *AliApiErrResult) {
+	fullUrl := &strings.Builder{}
+	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/album/update", OPENAPI_URL)
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
+
+	// parameters
+	postData := param
+
+	// request
+	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
+	if err != nil {
+		logger.Verboseln("update album error ", err)
+		return nil, NewAliApiHttpErrorWithCause(err)
+	}
+
+	// handler common error
+	var body []byte
+	var apiErrResult *AliApiErrResult
+	if body, apiErrResult = ParseCommonOpenApiError(resp); apiErrResult != nil {
+		return nil, apiErrResult
+	}
+
+	// parse result
+	r := &AlbumItem{}
+	if err2 := json.Unmarshal(body, r); err2 != nil {
+		logger.Verboseln("parse update album result json error ", err2)
+		return nil, NewAliApiAppErrorWithCause(err2)
+	}
+	return r, nil
+}
+
+// AlbumDelete 删除个人相簿，相簿中的文件不会被删除
+func (a *AliPanClient) AlbumDelete(param *AlbumDeleteParam) (bool, *AliApiErrResult) {
+	fullUrl := &strings.Builder{}
+	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/album/delete", OPENAPI_URL)
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
+
+	// parameters
+	postData := param
+
+	// request
+	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
+	if err != nil {
+		logger.Verboseln("delete album error ", err)
+		return false, NewAliApiHttpErrorWithCause(err)
+	}
+
+	// handler common error
+	var apiErrResult *AliApiErrResult
+	if _, apiErrResult = ParseCommonOpenApiError(resp); apiErrResult != nil {
+		return false, apiErrResult
+	}
+	return true, nil
+}
+
+// AlbumGet 获取个人相簿信息
+func (a *AliPanClient) AlbumGet(param *AlbumGetParam) (*AlbumItem, *AliApiErrResult) {
+	fullUrl := &strings.Builder{}
+	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/album/get", OPENAPI_URL)
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
+
+	// parameters
+	postData := param
+
+	// request
+	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
+	if err != nil {
+		logger.Verboseln("get album error ", err)
+		return nil, NewAliApiHttpErrorWithCause(err)
+	}
+
+	// handler common error
+	var body []byte
+	var apiErrResult *AliApiErrResult
+	if body, apiErrResult = ParseCommonOpenApiError(resp); apiErrResult != nil {
+		return nil, apiErrResult
+	}
+
+	// parse result
+	r := &AlbumItem{}
+	if err2 := json.Unmarshal(body, r); err2 != nil {
+		logger.Verboseln("parse get album result json error ", err2)
+		return nil, NewAliApiAppErrorWithCause(err2)
+	}
+	return r, nil
+}
+
+// AlbumListFile 获取个人相簿包含的图片视频文件列表
+func (a *AliPanClient) AlbumListFile(param *AlbumListFileParam) (*AlbumListFileResult, *AliApiErrResult) {
+	fullUrl := &strings.Builder{}
+	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/album/listFile", OPENAPI_URL)
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
+
+	// parameters
+	postData := param
+
+	// request
+	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
+	if err != nil {
+		logger.Verboseln("list file of album error ", err)
+		return nil, NewAliApiHttpErrorWithCause(err)
+	}
+
+	// handler common error
+	var body []byte
+	var apiErrResult *AliApiErrResult
+	if body, apiErrResult = ParseCommonOpenApiError(resp); apiErrResult != nil {
+		return nil, apiErrResult
+	}
+
+	// parse result
+	r := &AlbumListFileResult{}
+	if err2 := json.Unmarshal(body, r); err2 != nil {
+		logger.Verboseln("parse list file of album result json error ", err2)
+		return nil, NewAliApiAppErrorWithCause(err2)
+	}
+	return r, nil
+}
+
+// AlbumAddFile 个人相簿增加文件
+func (a *AliPanClient) AlbumAddFile(param *AlbumFileParam) (*AlbumAddFileResult, *AliApiErrResult) {
+	fullUrl := &strings.Builder{}
+	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/album/addFile", OPENAPI_URL)
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
+
+	// parameters
+	postData := param
+
+	// request
+	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
+	if err != nil {
+		logger.Verboseln("add file to album error ", err)
+		return nil, NewAliApiHttpErrorWithCause(err)
+	}
+
+	// handler common error
+	var body []byte
+	var apiErrResult *AliApiErrResult
+	if body, apiErrResult = ParseCommonOpenApiError(resp); apiErrResult != nil {
+		return nil, apiErrResult
+	}
+
+	// parse result
+	r := &AlbumAddFileResult{}
+	if err2 := json.Unmarshal(body, r); err2 != nil {
+		logger.Verboseln("parse add file to album result json error ", err2)
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
+
+// AlbumDeleteFile 个人相簿移除文件，文件本身不会被删除
+func (a *AliPanClient) AlbumDeleteFile(param *AlbumFileParam) (bool, *AliApiErrResult) {
+	fullUrl := &strings.Builder{}
+	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/album/deleteFile", OPENAPI_URL)
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
+
+	// parameters
+	postData := param
+
+	// request
+	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
+	if err != nil {
+		logger.Verboseln("delete file from album error ", err)
+		return false, NewAliApiHttpErrorWithCause(err)
+	}
+
+	// handler common error
+	var apiErrResult *AliApiErrResult
+	if _, apiErrResult = ParseCommonOpenApiError(resp); apiErrResult != nil {
+		return false, apiErrResult
+	}
+	return true, nil
+}
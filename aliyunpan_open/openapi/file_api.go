@@ -3,6 +3,7 @@ package openapi
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/library-go/logger"
 	"strings"
 )
@@ -74,12 +75,32 @@ type (
 		// 102 : 可以忽略，不影响下载播放
 		// 103 ：文件非法，不允许下载
 		PunishFlag int `json:"punish_flag"`
-		// Thumbnail 缩略图地址，只有相册文件才有
+		// Thumbnail 缩略图地址，图片和视频文件才有
 		Thumbnail string `json:"thumbnail"`
+		// ImageMediaMetadata 图片元数据，只有图片文件才有
+		ImageMediaMetadata *ImageMediaMetadata `json:"image_media_metadata,omitempty"`
 		// AlbumId 所属相册ID，只有相册文件才有
 		AlbumId string `json:"albumId"`
 	}
 
+	// ImageMediaMetadata 图片元数据
+	ImageMediaMetadata struct {
+		Width  int `json:"width"`
+		Height int `json:"height"`
+		// Exif EXIF信息，JSON字符串
+		Exif string `json:"exif"`
+	}
+
+	// FileThumbnailOption 批量获取文件详情时生成的缩略图尺寸
+	FileThumbnailOption struct {
+		// ImageThumbnailWidth 生成的图片缩略图宽度
+		ImageThumbnailWidth int `json:"image_thumbnail_width,omitempty"`
+		// VideoThumbnailWidth 生成的视频截图宽度
+		VideoThumbnailWidth int `json:"video_thumbnail_width,omitempty"`
+		// VideoThumbnailTime 视频截图的时间点，单位：毫秒
+		VideoThumbnailTime int `json:"video_thumbnail_time,omitempty"`
+	}
+
 	// FileListResult 获取文件列表返回值
 	FileListResult struct {
 		Items      []*FileItem `json:"items"`
@@ -230,7 +251,7 @@ type (
 func (a *AliPanClient) FileList(param *FileListParam) (*FileListResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/list", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -242,7 +263,7 @@ func (a *AliPanClient) FileList(param *FileListParam) (*FileListResult, *AliApiE
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("get file list info error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -256,7 +277,7 @@ func (a *AliPanClient) FileList(param *FileListParam) (*FileListResult, *AliApiE
 	r := &FileListResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file list info result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -265,7 +286,7 @@ func (a *AliPanClient) FileList(param *FileListParam) (*FileListResult, *AliApiE
 func (a *AliPanClient) FileSearch(param *FileSearchParam) (*FileSearchResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/search", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -277,7 +298,7 @@ func (a *AliPanClient) FileSearch(param *FileSearchParam) (*FileSearchResult, *A
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("get file search error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -291,7 +312,7 @@ func (a *AliPanClient) FileSearch(param *FileSearchParam) (*FileSearchResult, *A
 	r := &FileSearchResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file search result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -300,7 +321,7 @@ func (a *AliPanClient) FileSearch(param *FileSearchParam) (*FileSearchResult, *A
 func (a *AliPanClient) FileStarredList(param *FileStarredListParam) (*FileListResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/starredList", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -312,7 +333,7 @@ func (a *AliPanClient) FileStarredList(param *FileStarredListParam) (*FileListRe
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("get file starred list error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -326,7 +347,7 @@ func (a *AliPanClient) FileStarredList(param *FileStarredListParam) (*FileListRe
 	r := &FileListResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file starred list result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -335,7 +356,7 @@ func (a *AliPanClient) FileStarredList(param *FileStarredListParam) (*FileListRe
 func (a *AliPanClient) FileGetDetailInfo(param *FileIdentityPair) (*FileItem, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/get", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -344,7 +365,7 @@ func (a *AliPanClient) FileGetDetailInfo(param *FileIdentityPair) (*FileItem, *A
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("get file detail info error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -358,7 +379,7 @@ func (a *AliPanClient) FileGetDetailInfo(param *FileIdentityPair) (*FileItem, *A
 	r := &FileItem{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file detail info result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -367,7 +388,7 @@ func (a *AliPanClient) FileGetDetailInfo(param *FileIdentityPair) (*FileItem, *A
 func (a *AliPanClient) FileGetDetailInfoByPath(param *FilePathPair) (*FileItem, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/get_by_path", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -376,7 +397,7 @@ func (a *AliPanClient) FileGetDetailInfoByPath(param *FilePathPair) (*FileItem,
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("get file detail by path error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -390,27 +411,38 @@ func (a *AliPanClient) FileGetDetailInfoByPath(param *FilePathPair) (*FileItem,
 	r := &FileItem{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file detail info by path result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
 
-// FileGetDetailInfoBatch 批量获取文件详情
-func (a *AliPanClient) FileGetDetailInfoBatch(param []*FileIdentityPair) (*FileListResult, *AliApiErrResult) {
+// FileGetDetailInfoBatch 批量获取文件详情，thumbnail 不为空时按照指定尺寸返回缩略图
+func (a *AliPanClient) FileGetDetailInfoBatch(param []*FileIdentityPair, thumbnail ...*FileThumbnailOption) (*FileListResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/batch/get", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := map[string]interface{}{
 		"file_list": param,
 	}
+	if len(thumbnail) > 0 && thumbnail[0] != nil {
+		if thumbnail[0].ImageThumbnailWidth > 0 {
+			postData["image_thumbnail_width"] = thumbnail[0].ImageThumbnailWidth
+		}
+		if thumbnail[0].VideoThumbnailWidth > 0 {
+			postData["video_thumbnail_width"] = thumbnail[0].VideoThumbnailWidth
+		}
+		if thumbnail[0].VideoThumbnailTime > 0 {
+			postData["video_thumbnail_time"] = thumbnail[0].VideoThumbnailTime
+		}
+	}
 
 	// request
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("batch get file detail info error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -424,7 +456,7 @@ func (a *AliPanClient) FileGetDetailInfoBatch(param []*FileIdentityPair) (*FileL
 	r := &FileListResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse batch file detail info result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -433,7 +465,7 @@ func (a *AliPanClient) FileGetDetailInfoBatch(param []*FileIdentityPair) (*FileL
 func (a *AliPanClient) FileGetDownloadUrl(param *FileDownloadUrlParam) (*FileDownloadUrlResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/getDownloadUrl", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -445,7 +477,7 @@ func (a *AliPanClient) FileGetDownloadUrl(param *FileDownloadUrlParam) (*FileDow
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("get file download url error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -459,7 +491,7 @@ func (a *AliPanClient) FileGetDownloadUrl(param *FileDownloadUrlParam) (*FileDow
 	r := &FileDownloadUrlResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse get file download url result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -468,7 +500,7 @@ func (a *AliPanClient) FileGetDownloadUrl(param *FileDownloadUrlParam) (*FileDow
 func (a *AliPanClient) FileUpdate(param *FileUpdateParam) (*FileItem, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/update", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := map[string]interface{}{
@@ -489,7 +521,7 @@ func (a *AliPanClient) FileUpdate(param *FileUpdateParam) (*FileItem, *AliApiErr
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("file update error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -503,7 +535,7 @@ func (a *AliPanClient) FileUpdate(param *FileUpdateParam) (*FileItem, *AliApiErr
 	r := &FileItem{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file update result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -512,7 +544,7 @@ func (a *AliPanClient) FileUpdate(param *FileUpdateParam) (*FileItem, *AliApiErr
 func (a *AliPanClient) FileMove(param *FileMoveParam) (*FileMoveResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/move", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -524,7 +556,7 @@ func (a *AliPanClient) FileMove(param *FileMoveParam) (*FileMoveResult, *AliApiE
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("file move error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -538,7 +570,7 @@ func (a *AliPanClient) FileMove(param *FileMoveParam) (*FileMoveResult, *AliApiE
 	r := &FileMoveResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file move result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -547,7 +579,7 @@ func (a *AliPanClient) FileMove(param *FileMoveParam) (*FileMoveResult, *AliApiE
 func (a *AliPanClient) FileCopy(param *FileCopyParam) (*FileAsyncTaskResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/copy", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -556,7 +588,7 @@ func (a *AliPanClient) FileCopy(param *FileCopyParam) (*FileAsyncTaskResult, *Al
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("file copy error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -570,7 +602,7 @@ func (a *AliPanClient) FileCopy(param *FileCopyParam) (*FileAsyncTaskResult, *Al
 	r := &FileAsyncTaskResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file copy result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -579,7 +611,7 @@ func (a *AliPanClient) FileCopy(param *FileCopyParam) (*FileAsyncTaskResult, *Al
 func (a *AliPanClient) FileTrash(param *FileIdentityPair) (*FileAsyncTaskResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/recyclebin/trash", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -588,7 +620,7 @@ func (a *AliPanClient) FileTrash(param *FileIdentityPair) (*FileAsyncTaskResult,
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("file trash error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -602,7 +634,7 @@ func (a *AliPanClient) FileTrash(param *FileIdentityPair) (*FileAsyncTaskResult,
 	r := &FileAsyncTaskResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file trash result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -611,7 +643,7 @@ func (a *AliPanClient) FileTrash(param *FileIdentityPair) (*FileAsyncTaskResult,
 func (a *AliPanClient) FileDelete(param *FileIdentityPair) (*FileAsyncTaskResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/delete", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -620,7 +652,7 @@ func (a *AliPanClient) FileDelete(param *FileIdentityPair) (*FileAsyncTaskResult
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("file delete error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -634,7 +666,7 @@ func (a *AliPanClient) FileDelete(param *FileIdentityPair) (*FileAsyncTaskResult
 	r := &FileAsyncTaskResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file delete result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
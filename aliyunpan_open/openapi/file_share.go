@@ -3,6 +3,7 @@ package openapi
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/library-go/logger"
 	"strings"
 )
@@ -41,6 +42,68 @@ type (
 		UpdatedAt string `json:"update_at"`
 	}
 
+	// FileShareItem 分享链接详情
+	FileShareItem struct {
+		// 分享ID
+		ShareId string `json:"share_id"`
+		// 分享名称
+		ShareName string `json:"share_name"`
+		// DriveId 网盘id
+		DriveId string `json:"drive_id"`
+		// 分享的文件ID列表
+		FileIdList []string `json:"file_id_list"`
+		// 分享过期时间
+		Expiration string `json:"expiration"`
+		// 分享是否已过期
+		Expired bool `json:"expired"`
+		// 分享提取码
+		SharePwd string `json:"share_pwd"`
+		// 分享链接地址
+		ShareUrl string `json:"share_url"`
+		// 分享创建者ID
+		Creator string `json:"creator"`
+		// 分享当前状态
+		Status string `json:"status"`
+		// 浏览次数
+		PreviewCount int `json:"preview_count"`
+		// 转存次数
+		SaveCount int `json:"save_count"`
+		// 下载次数
+		DownloadCount int `json:"download_count"`
+		// 分享创建时间，格式：2024-09-14T02:11:34.264Z
+		CreatedAt string `json:"created_at"`
+		// 分享更新时间，格式：2024-09-14T02:11:34.264Z
+		UpdatedAt string `json:"updated_at"`
+	}
+	// FileShareListParam 获取分享列表参数
+	FileShareListParam struct {
+		// 每页数量
+		Limit int `json:"limit"`
+		// 下一页的标记
+		Marker string `json:"marker,omitempty"`
+		// 是否包含已取消的分享
+		IncludeCanceled bool `json:"includeCanceled"`
+	}
+	// FileShareListResult 获取分享列表返回值
+	FileShareListResult struct {
+		Items      []*FileShareItem `json:"items"`
+		NextMarker string           `json:"next_marker"`
+	}
+	// FileShareUpdateParam 修改分享参数
+	FileShareUpdateParam struct {
+		// 分享ID
+		ShareId string `json:"shareId"`
+		// 分享提取码，为空代表取消提取码
+		SharePwd string `json:"sharePwd"`
+		// 分享过期时间，为空代表永不过期，格式：2024-09-19T09:32:50.000Z
+		Expiration string `json:"expiration"`
+	}
+	// FileShareCancelParam 取消分享参数
+	FileShareCancelParam struct {
+		// 分享ID
+		ShareId string `json:"shareId"`
+	}
+
 	// FileFastShareFileItem 快传文件项
 	FileFastShareFileItem struct {
 		// DriveId 网盘id
@@ -72,7 +135,7 @@ type (
 func (a *AliPanClient) FileShareCreate(param *FileShareCreateParam) (*FileShareCreateResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/createShare", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -81,7 +144,7 @@ func (a *AliPanClient) FileShareCreate(param *FileShareCreateParam) (*FileShareC
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("create file share error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -95,7 +158,7 @@ func (a *AliPanClient) FileShareCreate(param *FileShareCreateParam) (*FileShareC
 	r := &FileShareCreateResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file share result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -104,7 +167,7 @@ func (a *AliPanClient) FileShareCreate(param *FileShareCreateParam) (*FileShareC
 func (a *AliPanClient) FileFastShareCreate(param *FileFastShareCreateParam) (*FileFastShareCreateResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/createFastTransfer", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -113,7 +176,7 @@ func (a *AliPanClient) FileFastShareCreate(param *FileFastShareCreateParam) (*Fi
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("create file fast share error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -127,7 +190,95 @@ func (a *AliPanClient) FileFastShareCreate(param *FileFastShareCreateParam) (*Fi
 	r := &FileFastShareCreateResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file fast share result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
+	}
+	return r, nil
+}
+
+// FileShareList 获取当前用户创建的分享列表
+func (a *AliPanClient) FileShareList(param *FileShareListParam) (*FileShareListResult, *AliApiErrResult) {
+	fullUrl := &strings.Builder{}
+	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/listShare", OPENAPI_URL)
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
+
+	// parameters
+	postData := param
+
+	// request
+	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
+	if err != nil {
+		logger.Verboseln("list file share error ", err)
+		return nil, NewAliApiHttpErrorWithCause(err)
+	}
+
+	// handler common error
+	var body []byte
+	var apiErrResult *AliApiErrResult
+	if body, apiErrResult = ParseCommonOpenApiError(resp); apiErrResult != nil {
+		return nil, apiErrResult
+	}
+
+	// parse result
+	r := &FileShareListResult{}
+	if err2 := json.Unmarshal(body, r); err2 != nil {
+		logger.Verboseln("parse list file share result json error ", err2)
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
+
+// FileShareUpdate 修改分享的提取码和有效期
+func (a *AliPanClient) FileShareUpdate(param *FileShareUpdateParam) (*FileShareItem, *AliApiErrResult) {
+	fullUrl := &strings.Builder{}
+	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/updateShare", OPENAPI_URL)
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
+
+	// parameters
+	postData := param
+
+	// request
+	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
+	if err != nil {
+		logger.Verboseln("update file share error ", err)
+		return nil, NewAliApiHttpErrorWithCause(err)
+	}
+
+	// handler common error
+	var body []byte
+	var apiErrResult *AliApiErrResult
+	if body, apiErrResult = ParseCommonOpenApiError(resp); apiErrResult != nil {
+		return nil, apiErrResult
+	}
+
+	// parse result
+	r := &FileShareItem{}
+	if err2 := json.Unmarshal(body, r); err2 != nil {
+		logger.Verboseln("parse update file share result json error ", err2)
+		return nil, NewAliApiAppErrorWithCause(err2)
+	}
+	return r, nil
+}
+
+// FileShareCancel 取消分享
+func (a *AliPanClient) FileShareCancel(param *FileShareCancelParam) (bool, *AliApiErrResult) {
+	fullUrl := &strings.Builder{}
+	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/cancelShare", OPENAPI_URL)
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
+
+	// parameters
+	postData := param
+
+	// request
+	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
+	if err != nil {
+		logger.Verboseln("cancel file share error ", err)
+		return false, NewAliApiHttpErrorWithCause(err)
+	}
+
+	// handler common error
+	var apiErrResult *AliApiErrResult
+	if _, apiErrResult = ParseCommonOpenApiError(resp); apiErrResult != nil {
+		return false, apiErrResult
+	}
+	return true, nil
+}
@@ -3,6 +3,7 @@ package openapi
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/library-go/logger"
 	"strings"
 )
@@ -189,7 +190,7 @@ type (
 func (a *AliPanClient) FileUploadCheckPreHash(param *FileUploadCheckPreHashParam) (bool, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/create", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -198,7 +199,7 @@ func (a *AliPanClient) FileUploadCheckPreHash(param *FileUploadCheckPreHashParam
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("file check pre hash error ", err)
-		return false, NewAliApiHttpError(err.Error())
+		return false, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -216,7 +217,7 @@ func (a *AliPanClient) FileUploadCheckPreHash(param *FileUploadCheckPreHashParam
 func (a *AliPanClient) FileUploadCreate(param *FileUploadCreateParam) (*FileUploadCreateResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/create", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := map[string]interface{}{
@@ -278,7 +279,7 @@ func (a *AliPanClient) FileUploadCreate(param *FileUploadCreateParam) (*FileUplo
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("file create error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -292,7 +293,7 @@ func (a *AliPanClient) FileUploadCreate(param *FileUploadCreateParam) (*FileUplo
 	r := &FileUploadCreateResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file create result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -301,7 +302,7 @@ func (a *AliPanClient) FileUploadCreate(param *FileUploadCreateParam) (*FileUplo
 func (a *AliPanClient) FileUploadGetUploadUrl(param *FileUploadGetUploadUrlParam) (*FileUploadGetUploadUrlResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/getUploadUrl", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -310,7 +311,7 @@ func (a *AliPanClient) FileUploadGetUploadUrl(param *FileUploadGetUploadUrlParam
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("file create error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -324,7 +325,7 @@ func (a *AliPanClient) FileUploadGetUploadUrl(param *FileUploadGetUploadUrlParam
 	r := &FileUploadGetUploadUrlResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file create result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -333,7 +334,7 @@ func (a *AliPanClient) FileUploadGetUploadUrl(param *FileUploadGetUploadUrlParam
 func (a *AliPanClient) FileUploadListUploadedParts(param *FileUploadListUploadedPartsParam) (*FileUploadListUploadedPartsResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/listUploadedParts", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := map[string]interface{}{
@@ -349,7 +350,7 @@ func (a *AliPanClient) FileUploadListUploadedParts(param *FileUploadListUploaded
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("file list uploaded parts error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -363,7 +364,7 @@ func (a *AliPanClient) FileUploadListUploadedParts(param *FileUploadListUploaded
 	r := &FileUploadListUploadedPartsResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file create result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -372,7 +373,7 @@ func (a *AliPanClient) FileUploadListUploadedParts(param *FileUploadListUploaded
 func (a *AliPanClient) FileUploadComplete(param *FileUploadCompleteParam) (*FileUploadCompleteResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/openFile/complete", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// parameters
 	postData := param
@@ -381,7 +382,7 @@ func (a *AliPanClient) FileUploadComplete(param *FileUploadCompleteParam) (*File
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), postData, a.Headers())
 	if err != nil {
 		logger.Verboseln("file complete error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -395,7 +396,7 @@ func (a *AliPanClient) FileUploadComplete(param *FileUploadCompleteParam) (*File
 	r := &FileUploadCompleteResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file complete result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
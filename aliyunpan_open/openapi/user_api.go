@@ -3,6 +3,7 @@ package openapi
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/library-go/logger"
 	"strings"
 )
@@ -54,13 +55,13 @@ type (
 func (a *AliPanClient) UserGetDriveInfo() (*DriveInfoResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/user/getDriveInfo", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// request
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), nil, a.Headers())
 	if err != nil {
 		logger.Verboseln("get drive info error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -74,7 +75,7 @@ func (a *AliPanClient) UserGetDriveInfo() (*DriveInfoResult, *AliApiErrResult) {
 	r := &DriveInfoResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse drive info result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -83,13 +84,13 @@ func (a *AliPanClient) UserGetDriveInfo() (*DriveInfoResult, *AliApiErrResult) {
 func (a *AliPanClient) UserGetSpaceInfo() (*PersonalSpaceInfoResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1.0/user/getSpaceInfo", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// request
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), nil, a.Headers())
 	if err != nil {
 		logger.Verboseln("get space info error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -106,7 +107,7 @@ func (a *AliPanClient) UserGetSpaceInfo() (*PersonalSpaceInfoResult, *AliApiErrR
 	r := &personalSpaceInfoData{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse space info result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r.Info, nil
 }
@@ -115,13 +116,13 @@ func (a *AliPanClient) UserGetSpaceInfo() (*PersonalSpaceInfoResult, *AliApiErrR
 func (a *AliPanClient) UserGetVipInfo() (*UserVipInfoResult, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/business/v1.0/user/getVipInfo", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// request
 	resp, err := a.httpclient.Req("POST", fullUrl.String(), nil, a.Headers())
 	if err != nil {
 		logger.Verboseln("get vip info error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -135,7 +136,7 @@ func (a *AliPanClient) UserGetVipInfo() (*UserVipInfoResult, *AliApiErrResult) {
 	r := &UserVipInfoResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse vip info result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -144,13 +145,13 @@ func (a *AliPanClient) UserGetVipInfo() (*UserVipInfoResult, *AliApiErrResult) {
 func (a *AliPanClient) UserScopes() (*UserScopeList, *AliApiErrResult) {
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/oauth/users/scopes", OPENAPI_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// request
 	resp, err := a.httpclient.Req("GET", fullUrl.String(), nil, a.Headers())
 	if err != nil {
 		logger.Verboseln("get user scope info error ", err)
-		return nil, NewAliApiHttpError(err.Error())
+		return nil, NewAliApiHttpErrorWithCause(err)
 	}
 
 	// handler common error
@@ -168,7 +169,7 @@ func (a *AliPanClient) UserScopes() (*UserScopeList, *AliApiErrResult) {
 	r := &userScopeInfoData{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse user scope info result json error ", err2)
-		return nil, NewAliApiAppError(err2.Error())
+		return nil, NewAliApiAppErrorWithCause(err2)
 	}
 	return r.UserScopes, nil
 }
@@ -0,0 +1,81 @@
+package aliyunpan_open
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
+	"github.com/tickstep/aliyunpan-api/aliyunpan_open/openapi"
+)
+
+// newReplayClient 使用 testdata 下录制的响应创建客户端，不会访问网络
+func newReplayClient(t *testing.T, cassetteName string) *OpenPanClient {
+	c, err := apitransport.LoadCassette(filepath.Join("testdata", cassetteName))
+	require.NoError(t, err)
+	p := NewOpenPanClient(openapi.ApiConfig{}, openapi.ApiToken{AccessToken: "test"}, nil,
+		apitransport.WithTransport(apitransport.NewReplayTransport(c)))
+	p.SetRetryPolicy(aliyunpan.NoRetryPolicy())
+	return p
+}
+
+func TestFileListFixture(t *testing.T) {
+	p := newReplayClient(t, "file_list.json")
+
+	r, err := p.FileList(&aliyunpan.FileListParam{
+		DriveId:      "11001",
+		ParentFileId: aliyunpan.DefaultRootParentFileId,
+		Limit:        2,
+	})
+	require.Nil(t, err)
+	require.Len(t, r.FileList, 2)
+	assert.Equal(t, "WyI2MzIwZDFhM2IyN2Q2ZjBhZDE5MzRmZTRhM2M0M2IyY2I0YWMyYzE1Il0", r.NextMarker)
+
+	folder := r.FileList[0]
+	assert.True(t, folder.IsFolder())
+	assert.Equal(t, "我的文档", folder.FileName)
+	assert.Equal(t, apiutil.UtcTime2LocalFormat("2022-09-14T02:10:00.000Z"), folder.UpdatedAt)
+
+	file := r.FileList[1]
+	assert.True(t, file.IsFile())
+	assert.Equal(t, "bj29", file.DomainId)
+	assert.Equal(t, int64(2736451), file.FileSize)
+	assert.Equal(t, "jpg", file.FileExtension)
+	assert.Equal(t, "image", file.Category)
+	assert.Equal(t, "0D4E6A2F8A9B1C3D5E7F90A1B2C3D4E5F6A7B8C9", file.ContentHash)
+	assert.Equal(t, "sha1", file.ContentHashName)
+	assert.Equal(t, 4032, file.ImageMediaMetadata.Width)
+	assert.Equal(t, `{"Orientation":{"value":"1"}}`, file.ImageMediaMetadata.Exif)
+	assert.Contains(t, file.Url, "x-oss-signature=REDACTED")
+
+	_, err = p.FileList(&aliyunpan.FileListParam{
+		DriveId:      "11001",
+		ParentFileId: "6320d19ec3a4b1d3a8c74d6f8d3e5b1b2e2f7a99",
+	})
+	require.NotNil(t, err)
+	assert.True(t, errors.Is(err, apierror.ErrNotFound))
+}
+
+func TestAlbumListFileFixture(t *testing.T) {
+	p := newReplayClient(t, "album_list_file.json")
+
+	r, err := p.AlbumListFile(&aliyunpan.AlbumListFileParam{AlbumId: "a3d0bd7f6b2e4c5a8e9f1d2c3b4a5e6f", Limit: 2})
+	require.Nil(t, err)
+	require.Len(t, r.FileList, 2)
+	assert.Empty(t, r.NextMarker)
+
+	live := r.FileList[0]
+	assert.Equal(t, "IMG_0002.livp", live.FileName)
+	assert.Equal(t, "33003", live.DriveId)
+	assert.Equal(t, "a3d0bd7f6b2e4c5a8e9f1d2c3b4a5e6f", live.AlbumId)
+	assert.True(t, live.IsAlbumLivePhotoFile())
+	assert.Equal(t, int64(3145728), live.FileSize)
+	assert.Contains(t, live.Thumbnail, "x-oss-signature=REDACTED")
+	assert.Equal(t, "video", r.FileList[1].Category)
+	assert.Equal(t, apiutil.UtcTime2LocalFormat("2022-09-14T09:30:00.000Z"), r.FileList[1].CreatedAt)
+}
@@ -0,0 +1,169 @@
+package aliyunpan_open
+
+import (
+	"strings"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+type (
+	// PlanClient 预演模式客户端。FileDelete、FileDeleteCompletely、FileMove、ShareLinkCancel
+	// 不会真正执行，只是连同受影响文件的路径、大小记录到计划中，检查无误后调用 Apply 执行。
+	// 方法签名和 OpenPanClient 一致，返回值中的 Success 代表已经记录到计划中
+	PlanClient struct {
+		client *OpenPanClient
+		plan   *aliyunpan.OperationPlan
+	}
+
+	// planOperator 实现 aliyunpan.PlanOperator
+	planOperator struct {
+		*OpenPanClient
+	}
+)
+
+// NewPlanClient 创建预演模式客户端，查询类的接口请直接使用原客户端
+func NewPlanClient(client *OpenPanClient) *PlanClient {
+	return &PlanClient{
+		client: client,
+		plan:   aliyunpan.NewOperationPlan(),
+	}
+}
+
+// Client 获取原客户端
+func (c *PlanClient) Client() *OpenPanClient {
+	return c.client
+}
+
+// Plan 获取已经记录的计划
+func (c *PlanClient) Plan() *aliyunpan.OperationPlan {
+	return c.plan
+}
+
+// FileDelete 记录删除文件到回收站
+func (c *PlanClient) FileDelete(param *aliyunpan.FileBatchActionParam) (*aliyunpan.FileBatchActionResult, *apierror.ApiError) {
+	return c.recordFileAction(aliyunpan.PlanActionFileDelete, param)
+}
+
+// FileDeleteCompletely 记录彻底删除文件
+func (c *PlanClient) FileDeleteCompletely(param *aliyunpan.FileBatchActionParam) (*aliyunpan.FileBatchActionResult, *apierror.ApiError) {
+	return c.recordFileAction(aliyunpan.PlanActionFileDeleteCompletely, param)
+}
+
+func (c *PlanClient) recordFileAction(action aliyunpan.PlanAction, param *aliyunpan.FileBatchActionParam) (*aliyunpan.FileBatchActionResult, *apierror.ApiError) {
+	if param == nil {
+		return nil, apierror.NewFailedApiError("参数不能为空")
+	}
+	item := &aliyunpan.FileMoveParam{DriveId: param.DriveId, FileId: param.FileId}
+	if err := c.plan.RecordFiles(planOperator{c.client}, action, []*aliyunpan.FileMoveParam{item}); err != nil {
+		return nil, err
+	}
+	return &aliyunpan.FileBatchActionResult{
+		FileId:  param.FileId,
+		Success: true,
+	}, nil
+}
+
+// FileMove 记录移动文件
+func (c *PlanClient) FileMove(param *aliyunpan.FileMoveParam) (*aliyunpan.FileMoveResult, *apierror.ApiError) {
+	if param == nil {
+		return nil, apierror.NewFailedApiError("参数不能为空")
+	}
+	if err := c.plan.RecordFiles(planOperator{c.client}, aliyunpan.PlanActionFileMove, []*aliyunpan.FileMoveParam{param}); err != nil {
+		return nil, err
+	}
+	return &aliyunpan.FileMoveResult{
+		FileId:  param.FileId,
+		Success: true,
+	}, nil
+}
+
+// ShareLinkCancel 记录取消分享链接
+func (c *PlanClient) ShareLinkCancel(shareIdList []string) ([]*aliyunpan.ShareCancelResult, *apierror.ApiError) {
+	if err := c.plan.RecordShareLinkCancel(planOperator{c.client}, shareIdList); err != nil {
+		return nil, err
+	}
+	r := []*aliyunpan.ShareCancelResult{}
+	for _, shareId := range shareIdList {
+		r = append(r, &aliyunpan.ShareCancelResult{
+			Id:      shareId,
+			Success: true,
+		})
+	}
+	return r, nil
+}
+
+// Apply 按记录的顺序执行计划，详见 aliyunpan.OperationPlan.Apply
+func (c *PlanClient) Apply() ([]*aliyunpan.PlanApplyResult, *apierror.ApiError) {
+	return c.plan.Apply(planOperator{c.client})
+}
+
+// FilePathById 逐级获取父文件夹，得到文件的完整路径
+func (o planOperator) FilePathById(driveId, fileId string) (string, *apierror.ApiError) {
+	names := []string{}
+	for fileId != "" && fileId != aliyunpan.DefaultRootParentFileId {
+		f, err := o.FileInfoById(driveId, fileId)
+		if err != nil {
+			return "", err
+		}
+		names = append([]string{f.FileName}, names...)
+		fileId = f.ParentFileId
+	}
+	return "/" + strings.Join(names, "/"), nil
+}
+
+// RecycleBinFiles 开放接口不支持获取回收站文件列表
+func (o planOperator) RecycleBinFiles(driveId string) (aliyunpan.FileList, *apierror.ApiError) {
+	return nil, apierror.NewFailedApiError("不支持回收站操作")
+}
+
+// ShareLinks 获取当前用户的所有分享
+func (o planOperator) ShareLinks() ([]*aliyunpan.ShareEntity, *apierror.ApiError) {
+	return o.ShareLinkListAll(&aliyunpan.ShareLinkListParam{})
+}
+
+// ApplyPlanStep 执行计划中的一个步骤，开放接口没有批量接口，逐个文件执行
+func (o planOperator) ApplyPlanStep(action aliyunpan.PlanAction, driveId string, targets []*aliyunpan.PlanTarget, clearAll bool) ([]*apierror.ApiError, *apierror.ApiError) {
+	errs := make([]*apierror.ApiError, len(targets))
+	if action == aliyunpan.PlanActionShareLinkCancel {
+		shareIdList := []string{}
+		for _, t := range targets {
+			shareIdList = append(shareIdList, t.ShareId)
+		}
+		r, err := o.ShareLinkCancel(shareIdList)
+		if err != nil {
+			return nil, err
+		}
+		for i, item := range r {
+			if !item.Success {
+				errs[i] = item.Err
+				if errs[i] == nil {
+					errs[i] = apierror.NewFailedApiError("操作失败：" + item.Id)
+				}
+			}
+		}
+		return errs, nil
+	}
+
+	for i, t := range targets {
+		param := &aliyunpan.FileBatchActionParam{DriveId: t.DriveId, FileId: t.FileId}
+		var err *apierror.ApiError
+		switch action {
+		case aliyunpan.PlanActionFileDelete:
+			_, err = o.FileDelete(param)
+		case aliyunpan.PlanActionFileDeleteCompletely:
+			_, err = o.FileDeleteCompletely(param)
+		case aliyunpan.PlanActionFileMove:
+			_, err = o.FileMove(&aliyunpan.FileMoveParam{
+				DriveId:        t.DriveId,
+				FileId:         t.FileId,
+				ToDriveId:      t.ToDriveId,
+				ToParentFileId: t.ToParentFileId,
+			})
+		default:
+			return nil, apierror.NewFailedApiError("不支持的操作：" + string(action))
+		}
+		errs[i] = err
+	}
+	return errs, nil
+}
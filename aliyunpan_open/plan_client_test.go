@@ -0,0 +1,53 @@
+package aliyunpan_open
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+func TestPlanClient(t *testing.T) {
+	d := newFakeDrive()
+	docs := d.add("d1", "root", "docs", "folder", 0, "")
+	archive := d.add("d1", "root", "archive", "folder", 0, "")
+	a := d.add("d1", docs, "a.txt", "file", 100, "")
+	b := d.add("d1", docs, "b.txt", "file", 200, "")
+	p := newFakeClient(t, d)
+	c := NewPlanClient(p)
+
+	// 先移动再删除，删除的目标是移动之后的预期状态
+	_, err := c.FileMove(&aliyunpan.FileMoveParam{DriveId: "d1", FileId: a, ToParentFileId: archive})
+	require.Nil(t, err)
+	_, err = c.FileDelete(&aliyunpan.FileBatchActionParam{DriveId: "d1", FileId: a})
+	require.Nil(t, err)
+	_, err = c.FileDeleteCompletely(&aliyunpan.FileBatchActionParam{DriveId: "d1", FileId: b})
+	require.Nil(t, err)
+	// 已经放入回收站的文件不能再移动
+	_, err = c.FileMove(&aliyunpan.FileMoveParam{DriveId: "d1", FileId: a, ToParentFileId: docs})
+	assert.NotNil(t, err)
+
+	plan := c.Plan()
+	require.Equal(t, 3, plan.Len())
+	steps := plan.Steps()
+	assert.Equal(t, "/docs/a.txt", steps[0].Targets[0].Path)
+	assert.Equal(t, "/archive/a.txt", steps[1].Targets[0].Path)
+	assert.Contains(t, plan.String(), "file_move /docs/a.txt -> /archive size=100")
+	assert.Equal(t, 0, d.count("/adrive/v1.0/openFile/move"))
+	assert.Len(t, d.files, 4)
+
+	// 预演之后文件被修改过，不能执行
+	d.get("d1", b).Size = 201
+	results, err := c.Apply()
+	require.Nil(t, err)
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Success)
+	assert.True(t, results[1].Success)
+	assert.True(t, errors.Is(results[2].Err, apierror.ErrPlanTargetChanged))
+	assert.Nil(t, d.get("d1", a))
+	assert.NotNil(t, d.get("d1", b))
+	assert.Equal(t, 0, d.count("/adrive/v1.0/openFile/delete"))
+}
@@ -86,5 +86,6 @@ RetryBegin:
 		}
 	}
 
+	p.driveRegistry.LoadFromUserInfo(returnResult)
 	return returnResult, nil
 }
@@ -0,0 +1,32 @@
+package aliyunpan_open
+
+import (
+	"net/http"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+type (
+	// videoPlayOperator 实现 aliyunpan.VideoPlayOperator
+	videoPlayOperator struct {
+		*OpenPanClient
+	}
+)
+
+// ChooseVideoSource 选择视频的播放源，优先使用高度不超过 maxHeight 的最高清晰度转码，没有可用的转码时使用原文件下载地址
+func (p *OpenPanClient) ChooseVideoSource(driveId, fileId string, maxHeight int) (*aliyunpan.VideoPlaySource, *apierror.ApiError) {
+	return aliyunpan.ChooseVideoSource(&videoPlayOperator{p}, driveId, fileId, maxHeight)
+}
+
+// NewVideoHlsProxy 创建视频的本地 HLS 代理，详见 aliyunpan.VideoHlsProxy
+func (p *OpenPanClient) NewVideoHlsProxy(driveId, fileId string, maxHeight int) *aliyunpan.VideoHlsProxy {
+	return aliyunpan.NewVideoHlsProxy(&videoPlayOperator{p}, driveId, fileId, maxHeight)
+}
+
+// FetchUrl 请求播放列表或者视频分片，使用数据传输的 http 客户端
+func (o *videoPlayOperator) FetchUrl(url string) (*http.Response, error) {
+	return o.transferClient.Req("GET", url, nil, map[string]string{
+		"referer": "https://www.aliyundrive.com/",
+	})
+}
@@ -0,0 +1,99 @@
+package aliyunpan_open
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan_open/openapi"
+)
+
+func fakeTranscodes(prefix string) []*openapi.LiveTranscodingTask {
+	return []*openapi.LiveTranscodingTask{
+		{TemplateId: "LD", TemplateWidth: 640, TemplateHeight: 360, Status: "finished", Url: "https://download.fake/" + prefix + "/LD/index.m3u8"},
+		{TemplateId: "FHD", TemplateWidth: 1920, Status: "finished", Url: "https://download.fake/" + prefix + "/FHD/index.m3u8"},
+		{TemplateId: "HD", TemplateWidth: 1280, TemplateHeight: 720, Status: "finished", Url: "https://download.fake/" + prefix + "/HD/index.m3u8"},
+		{TemplateId: "UHD", TemplateWidth: 3840, TemplateHeight: 2160, Status: "running"},
+	}
+}
+
+func serveProxy(h http.Handler, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	return w
+}
+
+func TestChooseVideoSource(t *testing.T) {
+	d := newFakeDrive()
+	video := d.add("d1", "root", "a.mp4", "file", 100, "")
+	plain := d.add("d1", "root", "b.mkv", "file", 100, "")
+	d.transcodes[fakeKey("d1", video)] = fakeTranscodes("v1")
+	p := newFakeClient(t, d)
+
+	s, err := p.ChooseVideoSource("d1", video, 0)
+	require.Nil(t, err)
+	assert.Equal(t, "FHD", s.TemplateId)
+	assert.Equal(t, 1080, s.Height)
+	assert.False(t, s.IsOriginal)
+
+	s, err = p.ChooseVideoSource("d1", video, 720)
+	require.Nil(t, err)
+	assert.Equal(t, "HD", s.TemplateId)
+	assert.Equal(t, "https://download.fake/v1/HD/index.m3u8", s.Url)
+
+	s, err = p.ChooseVideoSource("d1", video, 240)
+	require.Nil(t, err)
+	assert.True(t, s.IsOriginal)
+	assert.Equal(t, "https://download.fake/d1/"+video, s.Url)
+	assert.Equal(t, 2160, s.Height)
+
+	// 没有转码的视频使用原文件
+	s, err = p.ChooseVideoSource("d1", plain, 0)
+	require.Nil(t, err)
+	assert.True(t, s.IsOriginal)
+	assert.Equal(t, "https://download.fake/d1/"+plain, s.Url)
+}
+
+func TestVideoHlsProxy(t *testing.T) {
+	d := newFakeDrive()
+	video := d.add("d1", "root", "a.mp4", "file", 100, "")
+	d.transcodes[fakeKey("d1", video)] = fakeTranscodes("v1")
+	d.contents["/v1/HD/index.m3u8"] = []byte("#EXTM3U\n#EXT-X-TARGETDURATION:10\n#EXTINF:10,\nseg0.ts\n#EXTINF:10,\n/v1/HD/seg1.ts\n#EXT-X-ENDLIST\n")
+	d.contents["/v1/HD/seg0.ts"] = []byte("v1-seg0")
+	d.contents["/v1/HD/seg1.ts"] = []byte("v1-seg1")
+	d.contents["/v2/HD/index.m3u8"] = []byte("#EXTM3U\n#EXTINF:10,\nseg0.ts\n#EXTINF:10,\nseg1.ts\n#EXT-X-ENDLIST\n")
+	d.contents["/v2/HD/seg1.ts"] = []byte("v2-seg1")
+	proxy := newFakeClient(t, d).NewVideoHlsProxy("d1", video, 720)
+
+	w := serveProxy(proxy, "/master.m3u8")
+	require.Equal(t, 200, w.Code, w.Body.String())
+	master := w.Body.String()
+	assert.Contains(t, master, "RESOLUTION=1280x720")
+	assert.Contains(t, master, "HD/media.m3u8")
+	assert.Contains(t, master, "LD/media.m3u8")
+	assert.NotContains(t, master, "FHD")
+	assert.Less(t, strings.Index(master, "HD/media.m3u8"), strings.Index(master, "LD/media.m3u8"))
+
+	w = serveProxy(proxy, "/HD/media.m3u8")
+	require.Equal(t, 200, w.Code, w.Body.String())
+	assert.Equal(t, "#EXTM3U\n#EXT-X-TARGETDURATION:10\n#EXTINF:10,\n0.ts\n#EXTINF:10,\n1.ts\n#EXT-X-ENDLIST\n", w.Body.String())
+
+	w = serveProxy(proxy, "/HD/0.ts")
+	require.Equal(t, 200, w.Code)
+	assert.Equal(t, "v1-seg0", w.Body.String())
+
+	// 上游地址过期后刷新播放地址
+	d.expired["/v1/HD/seg1.ts"] = true
+	d.transcodes[fakeKey("d1", video)] = fakeTranscodes("v2")
+	w = serveProxy(proxy, "/HD/1.ts")
+	require.Equal(t, 200, w.Code, w.Body.String())
+	assert.Equal(t, "v2-seg1", w.Body.String())
+	assert.Equal(t, 2, d.count("getVideoPreviewPlayInfo"))
+
+	assert.Equal(t, 404, serveProxy(proxy, "/HD/5.ts").Code)
+	assert.Equal(t, 404, serveProxy(proxy, "/FHD/media.m3u8").Code)
+	assert.Equal(t, 404, serveProxy(proxy, "/other").Code)
+}
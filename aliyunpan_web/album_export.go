@@ -0,0 +1,79 @@
+// Copyright (c) 2020 tickstep.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aliyunpan_web
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+type (
+	// albumExportOperator 实现 aliyunpan.AlbumExportOperator
+	albumExportOperator struct {
+		*WebPanClient
+		albumId string
+	}
+)
+
+// AlbumExport 把相簿中的所有文件下载到本地文件夹，支持增量导出，详见 aliyunpan.ExportAlbum
+func (p *WebPanClient) AlbumExport(albumId string, param *aliyunpan.AlbumExportParam, handler aliyunpan.AlbumExportFunc) ([]*aliyunpan.AlbumExportItem, *apierror.ApiError) {
+	return aliyunpan.ExportAlbum(&albumExportOperator{WebPanClient: p, albumId: albumId}, param, handler)
+}
+
+// AlbumFileList 获取相簿中的所有文件
+func (o *albumExportOperator) AlbumFileList() (aliyunpan.FileList, *apierror.ApiError) {
+	fileList, err := o.AlbumListFileGetAll(&AlbumListFileParam{AlbumId: o.albumId})
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fileList {
+		f.AlbumId = o.albumId
+	}
+	return fileList, nil
+}
+
+// AlbumFileUrl 获取相簿文件的下载地址
+func (o *albumExportOperator) AlbumFileUrl(file *aliyunpan.FileEntity) (*aliyunpan.ShareAlbumGetFileUrlResult, *apierror.ApiError) {
+	r, err := o.GetFileDownloadUrl(&aliyunpan.GetFileDownloadUrlParam{
+		DriveId: file.DriveId,
+		FileId:  file.FileId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &aliyunpan.ShareAlbumGetFileUrlResult{
+		Url:        r.Url,
+		Expiration: r.Expiration,
+		Size:       r.Size,
+	}, nil
+}
+
+// OpenUrl 打开下载地址，使用数据传输的 http 客户端，不受接口请求超时时间的限制
+func (o *albumExportOperator) OpenUrl(url string) (io.ReadCloser, error) {
+	resp, err := o.transferClient.Req("GET", url, nil, map[string]string{
+		"referer": "https://www.aliyundrive.com/",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 && resp.StatusCode != 206 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected http status code, %d", resp.StatusCode)
+	}
+	return aliyunpan.NewBandwidthLimitedReadCloser(resp.Body, o.downloadLimiter), nil
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2020 tickstep.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aliyunpan_web
+
+import (
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+type (
+	// albumOrganizeOperator 实现 aliyunpan.AlbumOrganizeOperator
+	albumOrganizeOperator struct {
+		*WebPanClient
+	}
+)
+
+// AlbumOrganize 按照规则把网盘文件夹中的图片和视频归类到相簿中，可以重复执行，详见 aliyunpan.OrganizeAlbums
+func (p *WebPanClient) AlbumOrganize(param *aliyunpan.AlbumOrganizeParam) (*aliyunpan.AlbumOrganizeResult, *apierror.ApiError) {
+	return aliyunpan.OrganizeAlbums(albumOrganizeOperator{p}, param)
+}
+
+// ListAlbums 获取所有相簿
+func (o albumOrganizeOperator) ListAlbums() (aliyunpan.AlbumList, *apierror.ApiError) {
+	return o.AlbumListGetAll(&AlbumListParam{})
+}
+
+// CreateAlbum 创建相簿
+func (o albumOrganizeOperator) CreateAlbum(name string) (*aliyunpan.AlbumEntity, *apierror.ApiError) {
+	return o.AlbumCreate(&AlbumCreateParam{Name: name})
+}
+
+// ListAlbumFiles 获取相簿中的所有文件
+func (o albumOrganizeOperator) ListAlbumFiles(albumId string) (aliyunpan.FileList, *apierror.ApiError) {
+	return o.AlbumListFileGetAll(&AlbumListFileParam{AlbumId: albumId})
+}
+
+// AddAlbumFiles 把文件加入相簿
+func (o albumOrganizeOperator) AddAlbumFiles(albumId string, files aliyunpan.FileList) *apierror.ApiError {
+	param := &AlbumAddFileParam{AlbumId: albumId}
+	for _, f := range files {
+		param.AddFileItem(f.DriveId, f.FileId)
+	}
+	_, err := o.AlbumAddFile(param)
+	return err
+}
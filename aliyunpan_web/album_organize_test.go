@@ -0,0 +1,59 @@
+package aliyunpan_web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+func TestAlbumListGetAllError(t *testing.T) {
+	d := newFakeWebDrive()
+	p := newFakeWebClient(d)
+	var op aliyunpan.AlbumOperator = p
+	for _, name := range []string{"旅行", "家庭", "宠物"} {
+		_, err := op.AlbumCreate(&aliyunpan.AlbumCreateParam{Name: name})
+		require.Nil(t, err)
+	}
+
+	albums, err := op.AlbumListGetAll(&aliyunpan.AlbumListParam{Limit: 2})
+	require.Nil(t, err)
+	assert.Len(t, albums, 3)
+
+	// 任意一页失败时返回错误，而不是返回不完整的列表
+	d.failAlbumMarker = "2"
+	albums, err = op.AlbumListGetAll(&aliyunpan.AlbumListParam{Limit: 2})
+	assert.NotNil(t, err)
+	assert.Nil(t, albums)
+}
+
+func TestAlbumOrganize(t *testing.T) {
+	d := newFakeWebDrive()
+	d.add("camera", "root", "Camera", "folder", 0)
+	for _, id := range []string{"a", "b"} {
+		d.add(id, "camera", id+".jpg", "file", 10)
+		d.files[id].Category = "image"
+	}
+	p := newFakeWebClient(d)
+
+	param := &aliyunpan.AlbumOrganizeParam{
+		DriveId: "11001",
+		Path:    "/Camera",
+		Rules:   []*aliyunpan.AlbumOrganizeRule{{Type: aliyunpan.AlbumRuleByFolder}},
+	}
+	r, err := p.AlbumOrganize(param)
+	require.Nil(t, err)
+	require.Len(t, r.Albums, 1)
+	assert.True(t, r.Albums[0].Created)
+	assert.Equal(t, 2, r.Albums[0].AddedCount)
+
+	// 重复执行不会创建相簿，也不会重复加入文件
+	r, err = p.AlbumOrganize(param)
+	require.Nil(t, err)
+	require.Len(t, r.Albums, 1)
+	assert.False(t, r.Albums[0].Created)
+	assert.Equal(t, 2, r.Albums[0].SkippedCount)
+	assert.Len(t, d.albums, 1)
+	assert.Len(t, d.albumFiles[d.albums[0].AlbumId], 2)
+}
@@ -0,0 +1,35 @@
+package aliyunpan_web
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
+)
+
+type failTransport struct{}
+
+func (failTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+}
+
+func TestNetErrorKeepsFailedCode(t *testing.T) {
+	p := NewWebPanClient(WebLoginToken{}, AppLoginToken{}, AppConfig{}, SessionConfig{},
+		apitransport.WithTransport(failTransport{}))
+	p.SetRetryPolicy(aliyunpan.NoRetryPolicy())
+	p.GetGovernor().SetConfig(apitransport.GovernorConfig{})
+
+	_, err := p.AlbumCreate(&aliyunpan.AlbumCreateParam{Name: "旅行"})
+	require.NotNil(t, err)
+	// 网页端的请求错误保持 ApiCodeFailed，网络错误通过 errors.Is 判断
+	assert.Equal(t, apierror.ApiCodeFailed, err.Code)
+	assert.True(t, errors.Is(err, apierror.ErrNetwork))
+	assert.True(t, errors.Is(err, syscall.ECONNREFUSED))
+}
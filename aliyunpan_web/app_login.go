@@ -15,17 +15,9 @@
 // 电脑手机客户端API，例如MAC客户端
 package aliyunpan_web
 
-import (
-	"github.com/tickstep/library-go/requester"
-)
-
 type (
 	AppLoginToken struct {
 		AccessToken  string `json:"accessToken"`
 		RefreshToken string `json:"refreshToken"`
 	}
 )
-
-var (
-	appClient = requester.NewHTTPClient()
-)
@@ -3,10 +3,13 @@ package aliyunpan_web
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/library-go/logger"
 	"strings"
+	"time"
 )
 
 type (
@@ -36,7 +39,7 @@ func (p *WebPanClient) AsyncTaskQueryStatus(param *AsyncTaskQueryStatusParam) (*
 
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/v2/async_task/get", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	postData := map[string]interface{}{
 		"async_task_id": param.AsyncTaskId,
@@ -45,7 +48,7 @@ func (p *WebPanClient) AsyncTaskQueryStatus(param *AsyncTaskQueryStatusParam) (*
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("async task query status error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -57,7 +60,31 @@ func (p *WebPanClient) AsyncTaskQueryStatus(param *AsyncTaskQueryStatusParam) (*
 	r := &AsyncTaskQueryStatusResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse async task query status result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
+
+// WaitAsyncTask 等待异步任务完成，asyncTaskId 为空说明操作已经同步完成
+func (p *WebPanClient) WaitAsyncTask(asyncTaskId string) *apierror.ApiError {
+	if asyncTaskId == "" {
+		return nil
+	}
+	deadline := time.Now().Add(aliyunpan.AsyncTaskWaitTimeout)
+	for {
+		result, err := p.AsyncTaskQueryStatus(&AsyncTaskQueryStatusParam{AsyncTaskId: asyncTaskId})
+		if err != nil {
+			return err
+		}
+		switch {
+		case result.State == aliyunpan.AsyncTaskStateSucceed || result.Status == aliyunpan.AsyncTaskStateSucceed:
+			return nil
+		case result.State == aliyunpan.AsyncTaskStateFailed || result.Status == aliyunpan.AsyncTaskStateFailed:
+			return apierror.NewFailedApiError("异步任务执行失败: " + asyncTaskId)
+		}
+		if time.Now().After(deadline) {
+			return apierror.NewFailedApiError("等待异步任务超时: " + asyncTaskId)
+		}
+		time.Sleep(aliyunpan.AsyncTaskPollInterval)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/library-go/logger"
 )
@@ -55,7 +56,7 @@ func (p *WebPanClient) BatchTask(url string, param *BatchRequestParam, headers .
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s", url)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	postData := param
@@ -64,7 +65,7 @@ func (p *WebPanClient) BatchTask(url string, param *BatchRequestParam, headers .
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("batch request error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -76,7 +77,20 @@ func (p *WebPanClient) BatchTask(url string, param *BatchRequestParam, headers .
 	r := &BatchResponseResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("batch result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
+
+// batchResponseError 把批量请求中失败的单个响应转换成错误
+func batchResponseError(item *BatchResponse) *apierror.ApiError {
+	code, _ := item.Body["code"].(string)
+	message, _ := item.Body["message"].(string)
+	if message == "" {
+		message = fmt.Sprintf("batch request failed with status %d", item.Status)
+	}
+	if code != "" {
+		message = code + ": " + message
+	}
+	return apierror.NewFailedApiError(message)
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2020 tickstep.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aliyunpan_web
+
+import (
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+type (
+	// diskUsageOperator 实现 aliyunpan.DiskUsageOperator，noCache 为 true 时不使用元数据缓存
+	diskUsageOperator struct {
+		*WebPanClient
+		noCache bool
+	}
+)
+
+// DiskUsage 递归统计目录的占用空间，每个目录统计完成后通过 handler 返回，详见 aliyunpan.DiskUsage
+func (p *WebPanClient) DiskUsage(param *aliyunpan.DiskUsageParam, handler aliyunpan.DiskUsageFunc) (*aliyunpan.DiskUsageResult, *apierror.ApiError) {
+	if param == nil {
+		return nil, apierror.NewFailedApiError("参数不能为空")
+	}
+	return aliyunpan.DiskUsage(diskUsageOperator{p, param.NoCache}, param, handler)
+}
+
+// FileListGetAll 获取目录下的所有文件
+func (o diskUsageOperator) FileListGetAll(param *aliyunpan.FileListParam, delayMilliseconds int) (aliyunpan.FileList, *apierror.ApiError) {
+	if o.noCache {
+		return o.fileListGetAll(param, delayMilliseconds)
+	}
+	return o.WebPanClient.FileListGetAll(param, delayMilliseconds)
+}
@@ -0,0 +1,453 @@
+package aliyunpan_web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
+)
+
+// fakeWebDrive 内存中的网页端网盘和一个公开分享，只实现测试用到的接口
+type fakeWebDrive struct {
+	mutex   sync.Mutex
+	files   map[string]*fileEntityResult
+	trashed map[string]bool
+	// shareFiles 分享中的文件
+	shareFiles []*ListByShareItem
+	// shareTokenExpire 分享Token的过期时间，为空则只返回 expires_in
+	shareTokenExpire time.Time
+	// shareLinks 当前用户创建的分享，取消后从列表中删除
+	shareLinks []*shareEntityResult
+	// albums 个人相簿，albumFiles 为相簿中的文件ID
+	albums     []*aliyunpan.AlbumEntity
+	albumFiles map[string][]string
+	// failAlbumMarker 获取相簿列表时该分页返回错误
+	failAlbumMarker string
+	// partSpeed 获取下载链接时返回的服务器限速，0代表不限速
+	partSpeed int64
+	nextId    int
+	calls     []string
+}
+
+func newFakeWebDrive() *fakeWebDrive {
+	return &fakeWebDrive{
+		files:      map[string]*fileEntityResult{},
+		trashed:    map[string]bool{},
+		albumFiles: map[string][]string{},
+	}
+}
+
+// newFakeWebClient 创建访问 fakeWebDrive 的客户端，不重试、不限速
+func newFakeWebClient(d *fakeWebDrive) *WebPanClient {
+	p := NewWebPanClient(WebLoginToken{}, AppLoginToken{}, AppConfig{}, SessionConfig{},
+		apitransport.WithTransport(d))
+	p.SetRetryPolicy(aliyunpan.NoRetryPolicy())
+	p.GetGovernor().SetConfig(apitransport.GovernorConfig{})
+	return p
+}
+
+func (d *fakeWebDrive) add(id, parent, name, fileType string, size int64) {
+	d.files[id] = &fileEntityResult{
+		DriveId:      "11001",
+		FileId:       id,
+		Name:         name,
+		Type:         fileType,
+		Size:         size,
+		ParentFileId: parent,
+		UpdatedAt:    "2023-01-01T00:00:00.000Z",
+	}
+}
+
+func (d *fakeWebDrive) addShare(id, parent, name, fileType string, size int) {
+	d.shareFiles = append(d.shareFiles, &ListByShareItem{
+		FileID:       id,
+		ParentFileID: parent,
+		Name:         name,
+		Type:         fileType,
+		Size:         size,
+	})
+}
+
+func (d *fakeWebDrive) newId() string {
+	d.nextId++
+	return fmt.Sprintf("n%03d", d.nextId)
+}
+
+// names 文件夹下未删除的文件名，已排序
+func (d *fakeWebDrive) names(parent string) []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	names := []string{}
+	for _, f := range d.children(parent) {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (d *fakeWebDrive) children(parent string) []*fileEntityResult {
+	items := []*fileEntityResult{}
+	for id, f := range d.files {
+		if f.ParentFileId == parent && !d.trashed[id] {
+			items = append(items, f)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].FileId < items[j].FileId })
+	return items
+}
+
+// copyTree 复制文件或者文件夹，返回新文件ID
+func (d *fakeWebDrive) copyTree(f *fileEntityResult, parent, name string) string {
+	id := d.newId()
+	d.add(id, parent, name, f.Type, f.Size)
+	d.files[id].ContentHash = f.ContentHash
+	for _, c := range d.children(f.FileId) {
+		d.copyTree(c, id, c.Name)
+	}
+	return id
+}
+
+func (d *fakeWebDrive) childByName(parent, name string) *fileEntityResult {
+	for _, f := range d.children(parent) {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func (d *fakeWebDrive) RoundTrip(req *http.Request) (*http.Response, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.calls = append(d.calls, req.URL.Path)
+
+	if req.URL.Host == "download.fake" && strings.HasPrefix(req.URL.Path, "/file/") {
+		// 文件内容固定为 12345678，按照 Range 返回
+		content := []byte("12345678")
+		var start, end int
+		if _, err := fmt.Sscanf(req.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil || end >= len(content) {
+			end = len(content) - 1
+		}
+		return &http.Response{
+			StatusCode: 206,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(bytes.NewReader(content[start : end+1])),
+			Request:    req,
+		}, nil
+	}
+
+	param := map[string]interface{}{}
+	if req.Body != nil {
+		data, _ := ioutil.ReadAll(req.Body)
+		_ = json.Unmarshal(data, &param)
+	}
+	str := func(key string) string {
+		s, _ := param[key].(string)
+		return s
+	}
+
+	var result interface{}
+	status := 200
+	switch req.URL.Path {
+	case "/v2/file/get":
+		f, ok := d.files[str("file_id")]
+		if !ok {
+			status = 404
+			result = map[string]string{"code": "NotFound.File", "message": "not found"}
+		} else {
+			result = f
+		}
+	case "/adrive/v1/file/get_path":
+		items := []map[string]string{}
+		for id := str("file_id"); id != "root"; {
+			f := d.files[id]
+			items = append(items, map[string]string{"file_id": f.FileId, "name": f.Name})
+			id = f.ParentFileId
+		}
+		result = map[string]interface{}{"items": items}
+	case "/adrive/v3/file/list":
+		result = map[string]interface{}{"items": d.children(str("parent_file_id"))}
+	case "/adrive/v2/file/createWithFolders":
+		if str("type") == "file" {
+			if f := d.childByName(str("parent_file_id"), str("name")); f != nil {
+				// refuse 模式下返回已存在的文件
+				result = map[string]interface{}{"file_id": f.FileId, "parent_file_id": f.ParentFileId, "file_name": f.Name, "exist": true}
+				break
+			}
+			// 网盘中有相同内容的文件并且带了防伪码才能秒传
+			rapid := false
+			for id, f := range d.files {
+				if !d.trashed[id] && f.ContentHash != "" && f.ContentHash == str("content_hash") && str("proof_code") != "" {
+					rapid = true
+				}
+			}
+			id := d.newId()
+			size, _ := param["size"].(float64)
+			d.add(id, str("parent_file_id"), str("name"), "file", int64(size))
+			d.files[id].ContentHash = str("content_hash")
+			result = map[string]interface{}{"file_id": id, "parent_file_id": str("parent_file_id"), "file_name": str("name"), "rapid_upload": rapid}
+			break
+		}
+		f := d.childByName(str("parent_file_id"), str("name"))
+		if f == nil {
+			id := d.newId()
+			d.add(id, str("parent_file_id"), str("name"), "folder", 0)
+			f = d.files[id]
+		}
+		result = map[string]string{"file_id": f.FileId, "parent_file_id": f.ParentFileId, "file_name": f.Name, "type": f.Type}
+	case "/v2/file/get_download_url":
+		ratelimit := map[string]int64{"part_speed": -1, "part_size": -1}
+		if d.partSpeed > 0 {
+			ratelimit = map[string]int64{"part_speed": d.partSpeed, "part_size": 8}
+		}
+		result = map[string]interface{}{"url": "https://download.fake/file/" + str("file_id"), "method": "GET", "size": 8, "ratelimit": ratelimit}
+	case "/adrive/v3/file/update":
+		f := d.files[str("file_id")]
+		if other := d.childByName(f.ParentFileId, str("name")); other != nil && other != f {
+			status, result = 409, map[string]string{"code": "AlreadyExist.File", "message": "file already exists"}
+			break
+		}
+		f.Name = str("name")
+		result = f
+	case "/adrive/v2/recyclebin/list":
+		items := []*fileEntityResult{}
+		for id := range d.trashed {
+			items = append(items, d.files[id])
+		}
+		result = map[string]interface{}{"items": items}
+	case "/v2/recyclebin/clear":
+		for id := range d.trashed {
+			delete(d.files, id)
+		}
+		d.trashed = map[string]bool{}
+		result = map[string]string{"drive_id": str("drive_id")}
+	case "/v2/share_link/get_share_token":
+		if str("share_pwd") != "8a2b" {
+			status = 400
+			result = map[string]string{"code": "ShareLink.SharePwdInvalid", "message": "invalid pwd"}
+		} else {
+			token := map[string]interface{}{"share_token": "share-token", "expires_in": 7200}
+			if !d.shareTokenExpire.IsZero() {
+				token["expire_time"] = d.shareTokenExpire
+			}
+			result = token
+		}
+	case "/v2/file/get_share_link_download_url":
+		result = map[string]interface{}{
+			"download_url": "https://download.fake/" + str("file_id"),
+			"expiration":   "2023-01-01T00:10:00.000Z",
+		}
+	case "/v2/file/get_video_preview_play_info_by_share":
+		result = map[string]interface{}{
+			"file_id": str("file_id"),
+			"video_preview_play_info": map[string]interface{}{
+				"live_transcoding_task_list": []map[string]string{{"template_id": "FHD", "status": "finished", "url": "https://play.fake/FHD.m3u8"}},
+			},
+		}
+	case "/adrive/v2/file/get_by_share":
+		status, result = 404, map[string]string{"code": "NotFound.File", "message": "not found"}
+		for _, f := range d.shareFiles {
+			if f.FileID == str("file_id") {
+				status, result = 200, f
+			}
+		}
+	case "/adrive/v2/file/list_by_share":
+		items := []*ListByShareItem{}
+		for _, f := range d.shareFiles {
+			if f.ParentFileID == str("parent_file_id") {
+				items = append(items, f)
+			}
+		}
+		// 按照 limit 分页，marker 为下一页的起始位置
+		start, _ := strconv.Atoi(str("marker"))
+		limit := int(param["limit"].(float64))
+		end := start + limit
+		nextMarker := strconv.Itoa(end)
+		if end >= len(items) {
+			end = len(items)
+			nextMarker = ""
+		}
+		result = map[string]interface{}{"items": items[start:end], "next_marker": nextMarker}
+	case "/adrive/v3/share_link/list":
+		// 按照 limit 分页，marker 为下一页的起始位置
+		start, _ := strconv.Atoi(str("marker"))
+		end := start + int(param["limit"].(float64))
+		nextMarker := strconv.Itoa(end)
+		if end >= len(d.shareLinks) {
+			end, nextMarker = len(d.shareLinks), ""
+		}
+		result = map[string]interface{}{"items": d.shareLinks[start:end], "next_marker": nextMarker}
+	case "/adrive/v2/share_link/update":
+		status, result = 404, map[string]string{"code": "NotFound.ShareLink", "message": "share not found"}
+		for _, share := range d.shareLinks {
+			if share.ShareId == str("share_id") {
+				share.SharePwd = str("share_pwd")
+				share.Expiration = str("expiration")
+				share.Expired = false
+				status, result = 200, share
+			}
+		}
+	case "/adrive/v4/batch", "/adrive/v2/batch":
+		responses := []map[string]interface{}{}
+		requests, _ := param["requests"].([]interface{})
+		for _, r := range requests {
+			item := r.(map[string]interface{})
+			body := item["body"].(map[string]interface{})
+			id, _ := item["id"].(string)
+			resp := map[string]interface{}{"id": id, "status": 200}
+			switch item["url"] {
+			case "/recyclebin/trash":
+				d.trashed[id] = true
+			case "/file/delete":
+				delete(d.files, id)
+				delete(d.trashed, id)
+			case "/file/move":
+				f, parent := d.files[id], body["to_parent_file_id"].(string)
+				name := f.Name
+				if newName, _ := body["new_name"].(string); newName != "" {
+					name = newName
+				}
+				if other := d.childByName(parent, name); other != nil && other != f {
+					if body["check_name_mode"] == "refuse" {
+						resp["status"] = 409
+						resp["body"] = map[string]string{"code": "AlreadyExist.File", "message": "file already exists"}
+						break
+					}
+					if body["check_name_mode"] == "auto_rename" {
+						name = aliyunpan.UniqueFileName(name, func(name string) bool {
+							return d.childByName(parent, name) != nil
+						})
+					}
+				}
+				// 移动会更新文件的修改时间
+				f.ParentFileId, f.Name, f.UpdatedAt = parent, name, "2023-01-02T00:00:00.000Z"
+			case "/file/copy":
+				if f, ok := d.files[id]; ok {
+					// 网盘内复制
+					parent := body["to_parent_file_id"].(string)
+					name := aliyunpan.UniqueFileName(f.Name, func(name string) bool {
+						return d.childByName(parent, name) != nil
+					})
+					resp["status"] = 201
+					resp["body"] = map[string]interface{}{"file_id": d.copyTree(f, parent, name), "drive_id": "11001"}
+					break
+				}
+				// 从分享中复制文件，每隔一个文件返回异步任务
+				parent := body["to_parent_file_id"].(string)
+				for _, f := range d.shareFiles {
+					if f.FileID != body["file_id"] {
+						continue
+					}
+					name := aliyunpan.UniqueFileName(f.Name, func(name string) bool {
+						return d.childByName(parent, name) != nil
+					})
+					newId := d.newId()
+					d.add(newId, parent, name, f.Type, int64(f.Size))
+					respBody := map[string]interface{}{"file_id": newId, "drive_id": "11001"}
+					if d.nextId%2 == 0 {
+						respBody["async_task_id"] = "task-" + newId
+					}
+					resp["status"] = 201
+					resp["body"] = respBody
+				}
+			case "/share_link/cancel":
+				resp["status"] = 404
+				for i, share := range d.shareLinks {
+					if share.ShareId == body["share_id"] {
+						d.shareLinks = append(d.shareLinks[:i], d.shareLinks[i+1:]...)
+						resp["status"] = 204
+						break
+					}
+				}
+			case "/file/get":
+				// 图片返回缩略图、预览图和元数据
+				f := d.files[body["file_id"].(string)]
+				if f == nil {
+					resp["status"] = 404
+					break
+				}
+				item := *f
+				if strings.HasSuffix(item.Name, ".jpg") {
+					item.Thumbnail = "https://thumb.fake/" + item.FileId + "?" + body["image_thumbnail_process"].(string)
+					item.Url = "https://thumb.fake/" + item.FileId + "?" + body["image_url_process"].(string)
+					item.ImageMediaMetadata = &struct {
+						Width  int    `json:"width"`
+						Height int    `json:"height"`
+						Exif   string `json:"exif"`
+					}{Width: 4032, Height: 3024, Exif: `{"Model":"fake"}`}
+				}
+				data, _ := json.Marshal(item)
+				respBody := map[string]interface{}{}
+				json.Unmarshal(data, &respBody)
+				resp["body"] = respBody
+			case "/async_task/get":
+				resp["body"] = map[string]interface{}{"state": "Succeed"}
+			}
+			responses = append(responses, resp)
+		}
+		result = map[string]interface{}{"responses": responses}
+	case "/adrive/v1/album/list":
+		// marker 为下一页的起始序号
+		start, _ := strconv.Atoi(str("marker"))
+		if str("marker") != "" && str("marker") == d.failAlbumMarker {
+			status = 500
+			result = map[string]string{"code": "InternalError", "message": "album list failed"}
+			break
+		}
+		end := start + int(param["limit"].(float64))
+		next := strconv.Itoa(end)
+		if end >= len(d.albums) {
+			end, next = len(d.albums), ""
+		}
+		result = map[string]interface{}{"items": d.albums[start:end], "next_marker": next}
+	case "/adrive/v1/album/create":
+		album := &aliyunpan.AlbumEntity{AlbumId: d.newId(), Name: str("name"), Description: str("description")}
+		d.albums = append(d.albums, album)
+		result = album
+	case "/adrive/v1/album/list_files":
+		items := []*fileEntityResult{}
+		for _, id := range d.albumFiles[str("album_id")] {
+			items = append(items, d.files[id])
+		}
+		result = map[string]interface{}{"items": items}
+	case "/adrive/v1/album/add_files":
+		items := []*fileEntityResult{}
+		for _, item := range param["drive_file_list"].([]interface{}) {
+			id := item.(map[string]interface{})["file_id"].(string)
+			d.albumFiles[str("album_id")] = append(d.albumFiles[str("album_id")], id)
+			items = append(items, d.files[id])
+		}
+		result = map[string]interface{}{"file_list": items}
+	default:
+		status = 404
+		result = map[string]string{"code": "NotFound", "message": req.URL.Path}
+	}
+	data, _ := json.Marshal(result)
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(bytes.NewReader(data)),
+		Request:    req,
+	}, nil
+}
+
+func (d *fakeWebDrive) count(apiPath string) int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	n := 0
+	for _, c := range d.calls {
+		if c == apiPath {
+			n++
+		}
+	}
+	return n
+}
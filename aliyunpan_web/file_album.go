@@ -5,54 +5,34 @@ import (
 	"fmt"
 	"github.com/tickstep/aliyunpan-api/aliyunpan"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/library-go/logger"
 	"strings"
 )
 
 type (
-	// AlbumListParam 相册列表参数
-	AlbumListParam struct {
-		OrderBy        AlbumOrderBy        `json:"order_by"`
-		OrderDirection AlbumOrderDirection `json:"order_direction"`
-		Limit          int                 `json:"limit"`
-		// Marker 下一页参数
-		Marker string `json:"marker"`
-	}
+	// AlbumListParam 相册列表参数，与 OpenPanClient 共用 aliyunpan.AlbumListParam
+	AlbumListParam = aliyunpan.AlbumListParam
 
-	AlbumList []*aliyunpan.AlbumEntity
+	AlbumList = aliyunpan.AlbumList
 
-	AlbumListResult struct {
-		Items AlbumList `json:"items"`
-		// NextMarker 不为空，说明还有下一页
-		NextMarker string `json:"next_marker"`
-	}
+	AlbumListResult = aliyunpan.AlbumListResult
 
-	AlbumOrderBy        string
-	AlbumOrderDirection string
+	AlbumOrderBy        = aliyunpan.AlbumOrderBy
+	AlbumOrderDirection = aliyunpan.AlbumOrderDirection
 
 	// AlbumCreateParam 相簿创建参数
-	AlbumCreateParam struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-	}
+	AlbumCreateParam = aliyunpan.AlbumCreateParam
 
 	// AlbumEditParam 相簿编辑参数
-	AlbumEditParam struct {
-		AlbumId     string `json:"albumId"`
-		Description string `json:"description"`
-		Name        string `json:"name"`
-	}
+	AlbumEditParam = aliyunpan.AlbumEditParam
 
 	// AlbumDeleteParam 相簿删除参数
-	AlbumDeleteParam struct {
-		AlbumId string `json:"albumId"`
-	}
+	AlbumDeleteParam = aliyunpan.AlbumDeleteParam
 
 	// AlbumGetParam 相簿查询参数
-	AlbumGetParam struct {
-		AlbumId string `json:"albumId"`
-	}
+	AlbumGetParam = aliyunpan.AlbumGetParam
 
 	// AlbumShareCreateParam 创建相簿分享
 	AlbumShareCreateParam struct {
@@ -93,57 +73,26 @@ type (
 	}
 
 	// AlbumListFileParam 相簿查询包含的文件列表
-	AlbumListFileParam struct {
-		AlbumId string `json:"albumId"`
-		Limit   int    `json:"limit"`
-		// Marker 下一页参数
-		Marker string `json:"marker"`
-	}
+	AlbumListFileParam = aliyunpan.AlbumListFileParam
 
 	// AlbumDeleteFileParam 相簿删除文件参数
-	AlbumDeleteFileParam struct {
-		AlbumId       string                           `json:"album_id"`
-		DriveFileList []aliyunpan.FileBatchActionParam `json:"drive_file_list"`
-	}
+	AlbumDeleteFileParam = aliyunpan.AlbumFileParam
 
 	// AlbumAddFileParam 相簿增加文件参数
-	AlbumAddFileParam struct {
-		AlbumId       string                           `json:"album_id"`
-		DriveFileList []aliyunpan.FileBatchActionParam `json:"drive_file_list"`
-	}
+	AlbumAddFileParam = aliyunpan.AlbumFileParam
 )
 
 const (
-	AlbumOrderByCreatedAt AlbumOrderBy = "created_at"
-	AlbumOrderByUpdatedAt AlbumOrderBy = "updated_at"
-	AlbumOrderByFileCount AlbumOrderBy = "file_count"
+	AlbumOrderByCreatedAt = aliyunpan.AlbumOrderByCreatedAt
+	AlbumOrderByUpdatedAt = aliyunpan.AlbumOrderByUpdatedAt
+	AlbumOrderByFileCount = aliyunpan.AlbumOrderByFileCount
 
 	// AlbumOrderDirectionDesc 降序
-	AlbumOrderDirectionDesc AlbumOrderDirection = "DESC"
+	AlbumOrderDirectionDesc = aliyunpan.AlbumOrderDirectionDesc
 	// AlbumOrderDirectionAsc 升序
-	AlbumOrderDirectionAsc AlbumOrderDirection = "ASC"
+	AlbumOrderDirectionAsc = aliyunpan.AlbumOrderDirectionAsc
 )
 
-func (a *AlbumDeleteFileParam) AddFileItem(driveId, fileId string) {
-	if a.DriveFileList == nil {
-		a.DriveFileList = []aliyunpan.FileBatchActionParam{}
-	}
-	a.DriveFileList = append(a.DriveFileList, aliyunpan.FileBatchActionParam{
-		DriveId: driveId,
-		FileId:  fileId,
-	})
-}
-
-func (a *AlbumAddFileParam) AddFileItem(driveId, fileId string) {
-	if a.DriveFileList == nil {
-		a.DriveFileList = []aliyunpan.FileBatchActionParam{}
-	}
-	a.DriveFileList = append(a.DriveFileList, aliyunpan.FileBatchActionParam{
-		DriveId: driveId,
-		FileId:  fileId,
-	})
-}
-
 // AlbumListGetAll 获取所有相册列表
 func (p *WebPanClient) AlbumListGetAll(param *AlbumListParam) (AlbumList, *apierror.ApiError) {
 	internalParam := &AlbumListParam{
@@ -156,24 +105,18 @@ func (p *WebPanClient) AlbumListGetAll(param *AlbumListParam) (AlbumList, *apier
 		internalParam.Limit = 100
 	}
 
-	fileList := AlbumList{}
-	result, err := p.AlbumList(internalParam)
-	if err != nil || result == nil {
-		return nil, err
-	}
-	fileList = append(fileList, result.Items...)
-
-	// more page?
-	for len(result.NextMarker) > 0 {
-		internalParam.Marker = result.NextMarker
-		result, err = p.AlbumList(internalParam)
-		if err == nil && result != nil {
-			fileList = append(fileList, result.Items...)
-		} else {
-			break
+	albumList := AlbumList{}
+	for {
+		result, err := p.AlbumList(internalParam)
+		if err != nil {
+			return nil, err
+		}
+		albumList = append(albumList, result.Items...)
+		if result.NextMarker == "" {
+			return albumList, nil
 		}
+		internalParam.Marker = result.NextMarker
 	}
-	return fileList, nil
 }
 
 // AlbumList 获取相册列表
@@ -182,15 +125,17 @@ func (p *WebPanClient) AlbumList(param *AlbumListParam) (*AlbumListResult, *apie
 		Items:      AlbumList{},
 		NextMarker: "",
 	}
-	if flr, err := p.albumListReq(param); err == nil {
-		for k := range flr.Items {
-			if flr.Items[k] == nil {
-				continue
-			}
-			result.Items = append(result.Items, flr.Items[k])
+	flr, err := p.albumListReq(param)
+	if err != nil {
+		return nil, err
+	}
+	for k := range flr.Items {
+		if flr.Items[k] == nil {
+			continue
 		}
-		result.NextMarker = flr.NextMarker
+		result.Items = append(result.Items, flr.Items[k])
 	}
+	result.NextMarker = flr.NextMarker
 	return result, nil
 }
 
@@ -201,7 +146,7 @@ func (p *WebPanClient) albumListReq(param *AlbumListParam) (*AlbumListResult, *a
 
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1/album/list", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	limit := param.Limit
 	if limit <= 0 {
@@ -226,7 +171,7 @@ func (p *WebPanClient) albumListReq(param *AlbumListParam) (*AlbumListResult, *a
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("get album list error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -238,7 +183,7 @@ func (p *WebPanClient) albumListReq(param *AlbumListParam) (*AlbumListResult, *a
 	r := &AlbumListResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse album list result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -251,7 +196,7 @@ func (p *WebPanClient) AlbumCreate(param *AlbumCreateParam) (*aliyunpan.AlbumEnt
 
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1/album/create", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	if param.Name == "" {
 		return nil, apierror.NewFailedApiError("album name cannot be empty")
@@ -266,7 +211,7 @@ func (p *WebPanClient) AlbumCreate(param *AlbumCreateParam) (*aliyunpan.AlbumEnt
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("create album error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -278,7 +223,7 @@ func (p *WebPanClient) AlbumCreate(param *AlbumCreateParam) (*aliyunpan.AlbumEnt
 	r := &aliyunpan.AlbumEntity{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse album create result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -291,7 +236,7 @@ func (p *WebPanClient) AlbumEdit(param *AlbumEditParam) (*aliyunpan.AlbumEntity,
 
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1/album/update", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	if param.AlbumId == "" {
 		return nil, apierror.NewFailedApiError("album id cannot be empty")
@@ -310,7 +255,7 @@ func (p *WebPanClient) AlbumEdit(param *AlbumEditParam) (*aliyunpan.AlbumEntity,
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("edit album error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -322,7 +267,7 @@ func (p *WebPanClient) AlbumEdit(param *AlbumEditParam) (*aliyunpan.AlbumEntity,
 	r := &aliyunpan.AlbumEntity{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse album edit result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -335,7 +280,7 @@ func (p *WebPanClient) AlbumDelete(param *AlbumDeleteParam) (bool, *apierror.Api
 
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1/album/delete", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	if param.AlbumId == "" {
 		return false, apierror.NewFailedApiError("album id cannot be empty")
@@ -349,7 +294,7 @@ func (p *WebPanClient) AlbumDelete(param *AlbumDeleteParam) (bool, *apierror.Api
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("delete album error ", err)
-		return false, apierror.NewFailedApiError(err.Error())
+		return false, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -368,7 +313,7 @@ func (p *WebPanClient) AlbumGet(param *AlbumGetParam) (*aliyunpan.AlbumEntity, *
 
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1/album/get", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	if param.AlbumId == "" {
 		return nil, apierror.NewFailedApiError("album id cannot be empty")
@@ -382,7 +327,7 @@ func (p *WebPanClient) AlbumGet(param *AlbumGetParam) (*aliyunpan.AlbumEntity, *
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("get album error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -394,7 +339,7 @@ func (p *WebPanClient) AlbumGet(param *AlbumGetParam) (*aliyunpan.AlbumEntity, *
 	r := &aliyunpan.AlbumEntity{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse album get result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -409,7 +354,7 @@ func (p *WebPanClient) AlbumShareCreate(param *AlbumShareCreateParam) (*AlbumSha
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v2/share_link/create", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	postData := param
@@ -428,9 +373,8 @@ func (p *WebPanClient) AlbumShareCreate(param *AlbumShareCreateParam) (*AlbumSha
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("create album share error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
-	logger.Verboseln("response: ", string(body))
 
 	// handler common error
 	if err1 := apierror.ParseCommonApiError(body); err1 != nil {
@@ -441,7 +385,7 @@ func (p *WebPanClient) AlbumShareCreate(param *AlbumShareCreateParam) (*AlbumSha
 	r := &AlbumShareCreateResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse album share result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -449,32 +393,27 @@ func (p *WebPanClient) AlbumShareCreate(param *AlbumShareCreateParam) (*AlbumSha
 // AlbumListFileGetAll 获取指定相簿下的所有文件列表
 func (p *WebPanClient) AlbumListFileGetAll(param *AlbumListFileParam) (aliyunpan.FileList, *apierror.ApiError) {
 	internalParam := &AlbumListFileParam{
-		AlbumId: param.AlbumId,
-		Limit:   param.Limit,
-		Marker:  param.Marker,
+		AlbumId:             param.AlbumId,
+		Limit:               param.Limit,
+		Marker:              param.Marker,
+		ImageThumbnailWidth: param.ImageThumbnailWidth,
 	}
 	if internalParam.Limit <= 0 {
 		internalParam.Limit = 100
 	}
 
 	fileList := aliyunpan.FileList{}
-	result, err := p.AlbumListFile(internalParam)
-	if err != nil || result == nil {
-		return nil, err
-	}
-	fileList = append(fileList, result.FileList...)
-
-	// more page?
-	for len(result.NextMarker) > 0 {
-		internalParam.Marker = result.NextMarker
-		result, err = p.AlbumListFile(internalParam)
-		if err == nil && result != nil {
-			fileList = append(fileList, result.FileList...)
-		} else {
-			break
+	for {
+		result, err := p.AlbumListFile(internalParam)
+		if err != nil {
+			return nil, err
+		}
+		fileList = append(fileList, result.FileList...)
+		if result.NextMarker == "" {
+			return fileList, nil
 		}
+		internalParam.Marker = result.NextMarker
 	}
-	return fileList, nil
 }
 
 // AlbumListFile 获取相簿下的文件列表
@@ -483,15 +422,17 @@ func (p *WebPanClient) AlbumListFile(param *AlbumListFileParam) (*aliyunpan.File
 		FileList:   aliyunpan.FileList{},
 		NextMarker: "",
 	}
-	if flr, err := p.albumListFileReq(param); err == nil {
-		for k := range flr.Items {
-			if flr.Items[k] == nil {
-				continue
-			}
-			result.FileList = append(result.FileList, createFileEntity(flr.Items[k]))
+	flr, err := p.albumListFileReq(param)
+	if err != nil {
+		return nil, err
+	}
+	for k := range flr.Items {
+		if flr.Items[k] == nil {
+			continue
 		}
-		result.NextMarker = flr.NextMarker
+		result.FileList = append(result.FileList, createFileEntity(flr.Items[k]))
 	}
+	result.NextMarker = flr.NextMarker
 	return result, nil
 }
 
@@ -502,20 +443,24 @@ func (p *WebPanClient) albumListFileReq(param *AlbumListFileParam) (*fileListRes
 
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1/album/list_files", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	limit := param.Limit
 	if limit <= 0 {
 		limit = 100
 	}
+	thumbnailWidth := param.ImageThumbnailWidth
+	if thumbnailWidth <= 0 {
+		thumbnailWidth = 400
+	}
 	postData := map[string]interface{}{
 		"album_id":                param.AlbumId,
-		"image_thumbnail_process": "image/resize,w_400/format,jpeg",
+		"image_thumbnail_process": fmt.Sprintf("image/resize,w_%d/format,jpeg", thumbnailWidth),
 		"video_thumbnail_process": "video/snapshot,t_0,f_jpg,ar_auto,w_1000",
 		"image_url_process":       "image/resize,w_1920/format,jpeg",
 		"filter":                  "",
 		"fields":                  "*",
-		"limit":                   param.Limit,
+		"limit":                   limit,
 		"order_by":                "joined_at",
 		"order_direction":         "DESC",
 	}
@@ -527,7 +472,7 @@ func (p *WebPanClient) albumListFileReq(param *AlbumListFileParam) (*fileListRes
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("get album file list error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -539,7 +484,7 @@ func (p *WebPanClient) albumListFileReq(param *AlbumListFileParam) (*fileListRes
 	r := &fileListResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse album file list result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -552,7 +497,7 @@ func (p *WebPanClient) AlbumDeleteFile(param *AlbumDeleteFileParam) (bool, *apie
 
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1/album/delete_files", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	if param.AlbumId == "" {
 		return false, apierror.NewFailedApiError("album id cannot be empty")
@@ -563,7 +508,7 @@ func (p *WebPanClient) AlbumDeleteFile(param *AlbumDeleteFileParam) (bool, *apie
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("delete album file error ", err)
-		return false, apierror.NewFailedApiError(err.Error())
+		return false, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -582,7 +527,7 @@ func (p *WebPanClient) AlbumAddFile(param *AlbumAddFileParam) (*aliyunpan.FileLi
 
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1/album/add_files", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	if param.AlbumId == "" {
 		return nil, apierror.NewFailedApiError("album id cannot be empty")
@@ -593,7 +538,7 @@ func (p *WebPanClient) AlbumAddFile(param *AlbumAddFileParam) (*aliyunpan.FileLi
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("add album file error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -608,7 +553,7 @@ func (p *WebPanClient) AlbumAddFile(param *AlbumAddFileParam) (*aliyunpan.FileLi
 	r := &fileListResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse add album file result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	fileList := aliyunpan.FileList{}
 	for k := range r.Items {
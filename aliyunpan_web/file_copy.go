@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/library-go/logger"
 	"strings"
@@ -55,7 +56,7 @@ func (p *WebPanClient) FileCrossDriveCopy(param *FileCrossCopyParam) ([]*FileCro
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v2/file/crossDriveCopy", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	postData := param
@@ -67,14 +68,14 @@ func (p *WebPanClient) FileCrossDriveCopy(param *FileCrossCopyParam) ([]*FileCro
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("do cross drive copy error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
-	logger.Verboseln("response: ", string(body))
 
 	// handler common error
 	if err1 := apierror.ParseCommonApiError(body); err1 != nil {
 		return nil, err1
 	}
+	p.removeDirListFromCache(param.ToDriveId, param.ToParentFileId)
 
 	// parse result
 	result := struct {
@@ -82,7 +83,7 @@ func (p *WebPanClient) FileCrossDriveCopy(param *FileCrossCopyParam) ([]*FileCro
 	}{}
 	if err2 := json.Unmarshal(body, &result); err2 != nil {
 		logger.Verboseln("parse cross drive copy result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 
 	// parse result
@@ -103,7 +104,7 @@ func (p *WebPanClient) FileCrossDriveMove(param *FileCrossCopyParam) ([]*FileCro
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v2/file/crossDriveMove", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	postData := param
@@ -115,14 +116,17 @@ func (p *WebPanClient) FileCrossDriveMove(param *FileCrossCopyParam) ([]*FileCro
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("do cross drive copy error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
-	logger.Verboseln("response: ", string(body))
 
 	// handler common error
 	if err1 := apierror.ParseCommonApiError(body); err1 != nil {
 		return nil, err1
 	}
+	for _, fileId := range param.FromFileIds {
+		p.removeFileFromCache(param.FromDriveId, fileId)
+	}
+	p.removeDirListFromCache(param.ToDriveId, param.ToParentFileId)
 
 	// parse result
 	result := struct {
@@ -130,7 +134,7 @@ func (p *WebPanClient) FileCrossDriveMove(param *FileCrossCopyParam) ([]*FileCro
 	}{}
 	if err2 := json.Unmarshal(body, &result); err2 != nil {
 		logger.Verboseln("parse cross drive copy result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 
 	// parse result
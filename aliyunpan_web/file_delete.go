@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"github.com/tickstep/aliyunpan-api/aliyunpan"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/library-go/logger"
 	"strings"
@@ -30,7 +31,7 @@ func (p *WebPanClient) FileDelete(param []*aliyunpan.FileBatchActionParam) ([]*a
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v4/batch", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// process
 	return p.doFileBatchRequest(fullUrl.String(), "/recyclebin/trash", param)
@@ -41,7 +42,7 @@ func (p *WebPanClient) RecycleBinFileDelete(param []*aliyunpan.FileBatchActionPa
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v4/batch", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// process
 	return p.doFileBatchRequest(fullUrl.String(), "/file/delete", param)
@@ -52,7 +53,7 @@ func (p *WebPanClient) RecycleBinFileRestore(param []*aliyunpan.FileBatchActionP
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v4/batch", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// process
 	return p.doFileBatchRequest(fullUrl.String(), "/recyclebin/restore", param)
@@ -72,7 +73,14 @@ func (p *WebPanClient) doFileBatchRequest(url, actionUrl string, param []*aliyun
 	result, err := p.BatchTask(url, &batchParam)
 	if err != nil {
 		logger.Verboseln("file batch error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
+	}
+	for _, item := range param {
+		if actionUrl == "/recyclebin/restore" {
+			p.removeRestoredFileFromCache(item.DriveId, item.FileId)
+		} else {
+			p.removeFileFromCache(item.DriveId, item.FileId)
+		}
 	}
 
 	// parse result
@@ -86,6 +94,16 @@ func (p *WebPanClient) doFileBatchRequest(url, actionUrl string, param []*aliyun
 	return r, nil
 }
 
+// removeRestoredFileFromCache 还原的文件回到原来的目录，需要获取文件信息才能知道目录
+func (p *WebPanClient) removeRestoredFileFromCache(driveId, fileId string) {
+	if p.metaCache == nil {
+		return
+	}
+	if f, err := p.FileInfoById(driveId, fileId); err == nil {
+		p.removeDirListFromCache(driveId, f.ParentFileId)
+	}
+}
+
 func (p *WebPanClient) getFileDeleteBatchRequestList(actionUrl string, param []*aliyunpan.FileBatchActionParam) (BatchRequestList, *apierror.ApiError) {
 	if param == nil {
 		return nil, apierror.NewFailedApiError("参数不能为空")
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"github.com/tickstep/aliyunpan-api/aliyunpan"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/library-go/logger"
 	"path"
@@ -55,6 +56,13 @@ type (
 		PunishFlag      int    `json:"punish_flag"`
 		SyncFlag        bool   `json:"sync_flag"`
 		SyncMeta        string `json:"sync_meta"`
+		Thumbnail       string `json:"thumbnail"`
+		// ImageMediaMetadata 图片元数据，只有图片文件才有
+		ImageMediaMetadata *struct {
+			Width  int    `json:"width"`
+			Height int    `json:"height"`
+			Exif   string `json:"exif"`
+		} `json:"image_media_metadata,omitempty"`
 	}
 
 	fileListResult struct {
@@ -68,7 +76,7 @@ func createFileEntity(f *fileEntityResult) *aliyunpan.FileEntity {
 	if f == nil {
 		return nil
 	}
-	return &aliyunpan.FileEntity{
+	entity := &aliyunpan.FileEntity{
 		DriveId:         f.DriveId,
 		DomainId:        f.DomainId,
 		FileId:          f.FileId,
@@ -87,7 +95,17 @@ func createFileEntity(f *fileEntityResult) *aliyunpan.FileEntity {
 		Category:        f.Category,
 		SyncFlag:        f.SyncFlag,
 		SyncMeta:        f.SyncMeta,
+		Thumbnail:       f.Thumbnail,
+		Url:             f.Url,
+	}
+	if f.ImageMediaMetadata != nil {
+		entity.ImageMediaMetadata = &aliyunpan.ImageMediaMetadata{
+			Width:  f.ImageMediaMetadata.Width,
+			Height: f.ImageMediaMetadata.Height,
+			Exif:   f.ImageMediaMetadata.Exif,
+		}
 	}
+	return entity
 }
 
 // FileList 获取文件列表
@@ -108,14 +126,8 @@ retry:
 		}
 		result.NextMarker = flr.NextMarker
 	} else {
-		if err.Code == apierror.ApiCodeTooManyRequests {
-			if retryCount <= aliyunpan.MaxRequestRetryCount {
-				logger.Verboseln("too many request error, sleep and retry later")
-				time.Sleep(time.Duration(retryCount*2) * time.Second)
-				retryCount++
-				goto retry
-			}
-		} else if err.Code == apierror.ApiCodeDeviceSessionSignatureInvalid {
+		// 限流等错误已经由 RetryPolicy 在传输层统一重试
+		if err.Code == apierror.ApiCodeDeviceSessionSignatureInvalid && retryCount <= 1 {
 			logger.Verboseln("device session signature invalid, updating new session signature")
 			time.Sleep(time.Duration(2 * time.Second))
 			if r, e := p.CreateSession(nil); e != nil {
@@ -123,6 +135,7 @@ retry:
 				logger.Verboseln(r)
 			} else {
 				logger.Verboseln("update session signature success")
+				retryCount++
 				goto retry
 			}
 		}
@@ -138,7 +151,7 @@ func (p *WebPanClient) fileListReq(param *aliyunpan.FileListParam) (*fileListRes
 
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v3/file/list", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	pFileId := param.ParentFileId
 	if pFileId == "" {
@@ -189,7 +202,7 @@ func (p *WebPanClient) fileListReq(param *aliyunpan.FileListParam) (*fileListRes
 	r := &fileListResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file list result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -202,7 +215,7 @@ func (p *WebPanClient) FileInfoById(driveId, fileId string) (*aliyunpan.FileEnti
 
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/v2/file/get", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	pFileId := fileId
 	if pFileId == "" {
@@ -217,7 +230,7 @@ func (p *WebPanClient) FileInfoById(driveId, fileId string) (*aliyunpan.FileEnti
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("get file info error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -229,13 +242,17 @@ func (p *WebPanClient) FileInfoById(driveId, fileId string) (*aliyunpan.FileEnti
 	r := &fileEntityResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file info result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return createFileEntity(r), nil
 }
 
 // FileInfoByPath 通过路径获取文件详情，pathStr是绝对路径
 func (p *WebPanClient) FileInfoByPath(driveId string, pathStr string) (fileInfo *aliyunpan.FileEntity, error *apierror.ApiError) {
+	driveId, pathStr, apiErr := p.ResolveDrivePath(driveId, pathStr)
+	if apiErr != nil {
+		return nil, apiErr
+	}
 	if pathStr == "" {
 		pathStr = "/"
 	}
@@ -398,6 +415,15 @@ func (p *WebPanClient) recurseList(driveId string, folderInfo *aliyunpan.FileEnt
 
 // FileListGetAll 获取指定目录下的所有文件列表
 func (p *WebPanClient) FileListGetAll(param *aliyunpan.FileListParam, delayMilliseconds int) (aliyunpan.FileList, *apierror.ApiError) {
+	if p.metaCache != nil {
+		return p.metaCache.FileListGetAll(param, p.FileInfoById, func() (aliyunpan.FileList, *apierror.ApiError) {
+			return p.fileListGetAll(param, delayMilliseconds)
+		})
+	}
+	return p.fileListGetAll(param, delayMilliseconds)
+}
+
+func (p *WebPanClient) fileListGetAll(param *aliyunpan.FileListParam, delayMilliseconds int) (aliyunpan.FileList, *apierror.ApiError) {
 	internalParam := &aliyunpan.FileListParam{
 		OrderBy:        param.OrderBy,
 		OrderDirection: param.OrderDirection,
@@ -441,7 +467,7 @@ func (p *WebPanClient) FileGetPath(driveId, fileId string) (*aliyunpan.FileGetPa
 
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1/file/get_path", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	postData := map[string]interface{}{
 		"drive_id": driveId,
@@ -450,7 +476,6 @@ func (p *WebPanClient) FileGetPath(driveId, fileId string) (*aliyunpan.FileGetPa
 
 	// request
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
-	logger.Verboseln("get file path response: ", string(body))
 	if err != nil {
 		logger.Verboseln("get file path error ", err)
 		return nil, apierror.NewApiErrorWithError(err)
@@ -465,7 +490,7 @@ func (p *WebPanClient) FileGetPath(driveId, fileId string) (*aliyunpan.FileGetPa
 	r := &aliyunpan.FileGetPathResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file path result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -19,10 +19,10 @@ import (
 	"fmt"
 	"github.com/tickstep/aliyunpan-api/aliyunpan"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/library-go/cachepool"
 	"github.com/tickstep/library-go/logger"
-	"github.com/tickstep/library-go/requester"
 	"io"
 	"net/http"
 	"strconv"
@@ -43,7 +43,7 @@ func (p *WebPanClient) GetFileDownloadUrl(param *aliyunpan.GetFileDownloadUrlPar
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/v2/file/get_download_url", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	sec := param.ExpireSec
@@ -60,7 +60,7 @@ func (p *WebPanClient) GetFileDownloadUrl(param *aliyunpan.GetFileDownloadUrlPar
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("get file download url error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -72,19 +72,45 @@ func (p *WebPanClient) GetFileDownloadUrl(param *aliyunpan.GetFileDownloadUrlPar
 	r := &aliyunpan.GetFileDownloadUrlResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file download url result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	// time format
 	r.Expiration = apiutil.UtcTime2LocalFormat(r.Expiration)
+	p.downloadRatelimits.Record(r)
 	return r, nil
 }
 
-// DownloadFileData 下载文件内容
-func (p *WebPanClient) DownloadFileData(downloadFileUrl string, fileRange aliyunpan.FileDownloadRange, downloadFunc aliyunpan.DownloadFuncCallback) *apierror.ApiError {
+// downloadLimiters 一个下载连接使用的限速器：全局下载限速、服务器对该链接的限速以及单个传输任务的限速
+func (p *WebPanClient) downloadLimiters(downloadFileUrl string, limiters []*aliyunpan.BandwidthLimiter) []*aliyunpan.BandwidthLimiter {
+	result := []*aliyunpan.BandwidthLimiter{p.downloadLimiter}
+	if l := p.downloadRatelimits.NewConnLimiter(downloadFileUrl); l != nil {
+		result = append(result, l)
+	}
+	return append(result, limiters...)
+}
+
+// DownloadFileData 下载文件内容。回调返回的响应体会按照全局下载限速以及 GetFileDownloadUrl 返回的服务器限速读取，
+// limiters 为单个传输任务的限速器，会和全局下载限速同时生效，例如 GetFileDownloadUrlResult.NewTransferLimiter
+func (p *WebPanClient) DownloadFileData(downloadFileUrl string, fileRange aliyunpan.FileDownloadRange, downloadFunc aliyunpan.DownloadFuncCallback, limiters ...*aliyunpan.BandwidthLimiter) *apierror.ApiError {
+	return p.downloadFileData(downloadFileUrl, fileRange, func(httpMethod, fullUrl string, headers map[string]string) (*http.Response, error) {
+		resp, err := p.observer.Do(apitransport.RequestClassTransfer, httpMethod, fullUrl, func() (*http.Response, error) {
+			return p.governor.Do(apitransport.RequestClassTransfer, func() (*http.Response, error) {
+				return downloadFunc(httpMethod, fullUrl, headers)
+			})
+		})
+		if resp != nil && resp.Body != nil {
+			// 响应体按照全局下载限速、服务器限速和单个传输任务的限速读取
+			resp.Body = aliyunpan.NewBandwidthLimitedReadCloser(resp.Body, p.downloadLimiters(downloadFileUrl, limiters)...)
+		}
+		return resp, err
+	})
+}
+
+func (p *WebPanClient) downloadFileData(downloadFileUrl string, fileRange aliyunpan.FileDownloadRange, downloadFunc aliyunpan.DownloadFuncCallback) *apierror.ApiError {
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s", downloadFileUrl)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// header
 	headers := map[string]string{
@@ -100,7 +126,7 @@ func (p *WebPanClient) DownloadFileData(downloadFileUrl string, fileRange aliyun
 		}
 		headers["range"] = rangeStr
 	}
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// request callback
 	_, err := downloadFunc("GET", fullUrl.String(), headers)
@@ -114,19 +140,20 @@ func (p *WebPanClient) DownloadFileData(downloadFileUrl string, fileRange aliyun
 }
 
 // DownloadFileDataAndSave 下载文件并存储到指定IO设备里面。该方法是同步阻塞的
-func (p *WebPanClient) DownloadFileDataAndSave(downloadFileUrl string, fileRange aliyunpan.FileDownloadRange, writerAt io.WriterAt) *apierror.ApiError {
+// limiters 为单个传输任务的限速器，会和全局下载限速同时生效
+func (p *WebPanClient) DownloadFileDataAndSave(downloadFileUrl string, fileRange aliyunpan.FileDownloadRange, writerAt io.WriterAt, limiters ...*aliyunpan.BandwidthLimiter) *apierror.ApiError {
 	var resp *http.Response
 	var err error
-	var client = requester.NewHTTPClient()
-
-	apierr := p.DownloadFileData(
+	// transferClient 已经经过限速器，这里不能再重复获取名额
+	apierr := p.downloadFileData(
 		downloadFileUrl,
 		fileRange,
 		func(httpMethod, fullUrl string, headers map[string]string) (*http.Response, error) {
-			resp, err = client.Req(httpMethod, fullUrl, nil, headers)
+			resp, err = p.transferClient.Req(httpMethod, fullUrl, nil, headers)
 			if err != nil {
 				return nil, err
 			}
+			resp.Body = aliyunpan.NewBandwidthLimitedReadCloser(resp.Body, p.downloadLimiters(downloadFileUrl, limiters)...)
 			return resp, err
 		})
 
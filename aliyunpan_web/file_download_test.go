@@ -0,0 +1,53 @@
+package aliyunpan_web
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+// downloadAll 下载链接的全部数据，返回读取数据的耗时
+func downloadAll(t *testing.T, p *WebPanClient, d *fakeWebDrive, downloadUrl string) (string, time.Duration) {
+	var resp *http.Response
+	err := p.DownloadFileData(downloadUrl, aliyunpan.FileDownloadRange{}, func(httpMethod, fullUrl string, headers map[string]string) (*http.Response, error) {
+		req, _ := http.NewRequest(httpMethod, fullUrl, nil)
+		var e error
+		resp, e = d.RoundTrip(req)
+		return resp, e
+	})
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	start := time.Now()
+	data, e := ioutil.ReadAll(resp.Body)
+	require.Nil(t, e)
+	return string(data), time.Since(start)
+}
+
+func TestDownloadFileDataServerRatelimit(t *testing.T) {
+	d := newFakeWebDrive()
+	d.add("f1", aliyunpan.DefaultRootParentFileId, "a.txt", "file", 8)
+	p := newFakeWebClient(d)
+
+	// 服务器没有限速
+	u, err := p.GetFileDownloadUrl(&aliyunpan.GetFileDownloadUrlParam{DriveId: "d1", FileId: "f1"})
+	require.Nil(t, err)
+	assert.Equal(t, int64(-1), u.Ratelimit.PartSpeed)
+	data, elapsed := downloadAll(t, p, d, u.Url)
+	assert.Equal(t, "12345678", data)
+	assert.True(t, elapsed < 100*time.Millisecond, elapsed)
+
+	// 服务器限速为每个连接40字节/秒，下载时自动应用，8字节需要等待约200毫秒
+	d.partSpeed = 40
+	u, err = p.GetFileDownloadUrl(&aliyunpan.GetFileDownloadUrlParam{DriveId: "d1", FileId: "f1"})
+	require.Nil(t, err)
+	assert.Equal(t, int64(40), u.Ratelimit.PartSpeed)
+	assert.Equal(t, int64(8), u.Ratelimit.PartSize)
+	data, elapsed = downloadAll(t, p, d, u.Url)
+	assert.Equal(t, "12345678", data)
+	assert.True(t, elapsed >= 150*time.Millisecond, elapsed)
+}
@@ -0,0 +1,28 @@
+package aliyunpan_web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+func TestFindDuplicateFilesBatchTrash(t *testing.T) {
+	d := newFakeWebDrive()
+	for _, id := range []string{"f1", "f2", "f3"} {
+		d.add(id, "root", id+".txt", "file", 10)
+		d.files[id].ContentHash = "AAA"
+	}
+	p := newFakeWebClient(d)
+
+	result, err := p.FindDuplicateFiles(&aliyunpan.DuplicateFindParam{
+		Roots:           []*aliyunpan.DuplicateRoot{{DriveId: "11001"}},
+		TrashDuplicates: true,
+	}, nil)
+	require.Nil(t, err)
+	assert.Equal(t, 2, result.TrashedFiles)
+	assert.Equal(t, []string{"f1.txt"}, d.names("root"))
+	// 多余的文件通过一次批量请求放入回收站
+	assert.Equal(t, 1, d.count("/adrive/v4/batch"))
+}
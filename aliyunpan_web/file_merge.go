@@ -0,0 +1,172 @@
+// Copyright (c) 2020 tickstep.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aliyunpan_web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
+	"github.com/tickstep/library-go/logger"
+)
+
+type (
+	// fileMergeOperator 实现 aliyunpan.FileMergeOperator 和 aliyunpan.DuplicateFileOperator
+	fileMergeOperator struct {
+		*WebPanClient
+	}
+)
+
+// FileCopyRecursive 递归复制文件夹并和目标文件夹已有的内容合并，支持跨网盘。
+// 文件优先通过 ContentHash 秒传，不能秒传时使用服务器复制。返回每个文件的处理结果
+func (p *WebPanClient) FileCopyRecursive(param *aliyunpan.FileMergeParam) ([]*aliyunpan.FileMergeItem, *apierror.ApiError) {
+	return aliyunpan.FileMergeCopy(fileMergeOperator{p}, param)
+}
+
+// FindDuplicateFiles 按照文件大小和SHA1查找重复文件，每一组重复文件通过 handler 逐个返回。
+// 可以按照保留策略把多余的文件放入回收站，详见 aliyunpan.FindDuplicateFiles
+func (p *WebPanClient) FindDuplicateFiles(param *aliyunpan.DuplicateFindParam, handler aliyunpan.DuplicateGroupFunc) (*aliyunpan.DuplicateFindResult, *apierror.ApiError) {
+	return aliyunpan.FindDuplicateFiles(fileMergeOperator{p}, param, handler)
+}
+
+// RemoveFile 把文件放入回收站
+func (o fileMergeOperator) RemoveFile(driveId, fileId string) *apierror.ApiError {
+	r, err := o.FileDelete([]*aliyunpan.FileBatchActionParam{{DriveId: driveId, FileId: fileId}})
+	if err != nil {
+		return err
+	}
+	if len(r) == 0 || !r[0].Success {
+		return apierror.NewFailedApiError("删除文件失败：" + fileId)
+	}
+	return nil
+}
+
+// RemoveFiles 通过批量接口把文件放入回收站
+func (o fileMergeOperator) RemoveFiles(driveId string, fileIds []string) []*apierror.ApiError {
+	errs := make([]*apierror.ApiError, len(fileIds))
+	param := []*aliyunpan.FileBatchActionParam{}
+	for _, fileId := range fileIds {
+		param = append(param, &aliyunpan.FileBatchActionParam{DriveId: driveId, FileId: fileId})
+	}
+	r, err := o.FileDelete(param)
+	success := map[string]bool{}
+	for _, item := range r {
+		success[item.FileId] = item.Success
+	}
+	for i, fileId := range fileIds {
+		if err != nil {
+			errs[i] = err
+		} else if !success[fileId] {
+			errs[i] = apierror.NewFailedApiError("删除文件失败：" + fileId)
+		}
+	}
+	return errs
+}
+
+// ServerCopyFile 通过服务器复制文件，跨网盘时使用 FileCrossDriveCopy
+func (o fileMergeOperator) ServerCopyFile(source *aliyunpan.FileEntity, toDriveId, toParentFileId, name string) (string, *apierror.ApiError) {
+	var fileId string
+	if source.DriveId != toDriveId {
+		r, err := o.FileCrossDriveCopy(&FileCrossCopyParam{
+			FromDriveId:    source.DriveId,
+			FromFileIds:    []string{source.FileId},
+			ToDriveId:      toDriveId,
+			ToParentFileId: toParentFileId,
+		})
+		if err != nil {
+			return "", err
+		}
+		if len(r) == 0 || r[0].FileId == "" {
+			return "", apierror.NewFailedApiError("复制文件失败：" + source.FileName)
+		}
+		fileId = r[0].FileId
+	} else {
+		var err *apierror.ApiError
+		if fileId, err = o.fileCopy(source.DriveId, source.FileId, toParentFileId); err != nil {
+			return "", err
+		}
+	}
+
+	if name != source.FileName {
+		// 服务器可能已经自动重命名
+		f, err := o.FileInfoById(toDriveId, fileId)
+		if err != nil {
+			return fileId, err
+		}
+		if f.FileName != name {
+			if _, err := o.FileRename(toDriveId, fileId, name); err != nil {
+				return fileId, err
+			}
+		}
+	}
+	return fileId, nil
+}
+
+// fileCopy 同网盘内复制文件并等待异步任务完成，返回新文件的ID
+func (p *WebPanClient) fileCopy(driveId, fileId, toParentFileId string) (string, *apierror.ApiError) {
+	// url
+	fullUrl := &strings.Builder{}
+	fmt.Fprintf(fullUrl, "%s/adrive/v4/batch", API_URL)
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
+
+	batchParam := BatchRequestParam{
+		Requests: BatchRequestList{
+			{
+				Id:     fileId,
+				Method: "POST",
+				Url:    "/file/copy",
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: map[string]interface{}{
+					"drive_id":          driveId,
+					"file_id":           fileId,
+					"to_drive_id":       driveId,
+					"to_parent_file_id": toParentFileId,
+					"auto_rename":       true,
+				},
+			},
+		},
+		Resource: "file",
+	}
+
+	// request
+	result, err := p.BatchTask(fullUrl.String(), &batchParam)
+	if err != nil {
+		logger.Verboseln("file copy error ", err)
+		return "", err
+	}
+	p.removeDirListFromCache(driveId, toParentFileId)
+	if len(result.Responses) == 0 || result.Responses[0].Status/100 != 2 {
+		return "", apierror.NewFailedApiError("复制文件失败：" + fileId)
+	}
+
+	// parse result
+	body := result.Responses[0].Body
+	newFileId, _ := body["file_id"].(string)
+	asyncTaskId, _ := body["async_task_id"].(string)
+	if err := p.WaitAsyncTask(asyncTaskId); err != nil {
+		return "", err
+	}
+	return newFileId, nil
+}
+
+// TransferRequest 使用数据传输的 http 客户端发送请求，不受接口请求超时时间的限制
+func (o fileMergeOperator) TransferRequest(httpMethod, fullUrl string, headers map[string]string) (*http.Response, error) {
+	return o.transferClient.Req(httpMethod, fullUrl, nil, headers)
+}
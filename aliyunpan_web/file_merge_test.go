@@ -0,0 +1,41 @@
+package aliyunpan_web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+func TestFileCopyRecursiveOverwrite(t *testing.T) {
+	d := newFakeWebDrive()
+	d.add("src", "root", "src", "folder", 0)
+	d.add("a", "src", "a.txt", "file", 8)
+	d.files["a"].ContentHash = "H1"
+	d.add("dst", "root", "dst", "folder", 0)
+	d.add("old", "dst", "a.txt", "file", 3)
+	d.files["old"].ContentHash = "H0"
+	p := newFakeWebClient(d)
+
+	items, err := p.FileCopyRecursive(&aliyunpan.FileMergeParam{
+		FromDriveId:    "11001",
+		FromFileId:     "src",
+		ToDriveId:      "11001",
+		ToParentFileId: "dst",
+		ContentsOnly:   true,
+		ConflictMode:   aliyunpan.FileConflictOverwrite,
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(items))
+	assert.Nil(t, items[0].Err)
+	assert.Equal(t, aliyunpan.FileMergeActionRapidCopied, items[0].Action)
+	assert.True(t, items[0].Overwritten)
+	assert.Equal(t, "a.txt", items[0].TargetPath)
+
+	// the old file is trashed only after the copy succeeded
+	assert.True(t, d.trashed["old"])
+	assert.Equal(t, []string{"a.txt"}, d.names("dst"))
+	assert.Equal(t, "H1", d.childByName("dst", "a.txt").ContentHash)
+	assert.Equal(t, 1, d.count("/v2/file/get_download_url"))
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"github.com/tickstep/aliyunpan-api/aliyunpan"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/library-go/logger"
 	"strings"
@@ -30,7 +31,7 @@ func (p *WebPanClient) FileMove(param []*aliyunpan.FileMoveParam) ([]*aliyunpan.
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v4/batch", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	requests, e := p.getFileMoveBatchRequestList(param)
@@ -46,7 +47,15 @@ func (p *WebPanClient) FileMove(param []*aliyunpan.FileMoveParam) ([]*aliyunpan.
 	result, err := p.BatchTask(fullUrl.String(), &batchParam)
 	if err != nil {
 		logger.Verboseln("file move error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
+	}
+	for _, item := range param {
+		p.removeFileFromCache(item.DriveId, item.FileId)
+		if item.ToDriveId == "" {
+			p.removeDirListFromCache(item.DriveId, item.ToParentFileId)
+		} else {
+			p.removeDirListFromCache(item.ToDriveId, item.ToParentFileId)
+		}
 	}
 
 	// parse result
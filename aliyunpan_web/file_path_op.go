@@ -0,0 +1,77 @@
+// Copyright (c) 2020 tickstep.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aliyunpan_web
+
+import (
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+type (
+	// filePathOperator 实现 aliyunpan.FilePathOperator
+	filePathOperator struct {
+		fileMergeOperator
+	}
+)
+
+// MoveByPath 按路径移动文件，语义和 mv 命令一致：
+// dstPath 是已存在的文件夹或者以 / 结尾时，源文件移动到该文件夹下，否则移动并重命名为 dstPath。
+// srcPath 支持通配符，匹配多个文件时 dstPath 必须是文件夹，不存在会自动创建。
+// 路径支持网盘别名，例如 resource:/Movies，源和目标必须在同一个网盘
+func (p *WebPanClient) MoveByPath(driveId, srcPath, dstPath string, conflictMode aliyunpan.FileConflictMode) ([]*aliyunpan.FilePathOpResult, *apierror.ApiError) {
+	return aliyunpan.FilePathOp(filePathOperator{fileMergeOperator{p}}, aliyunpan.FilePathOpMove, driveId, srcPath, dstPath, conflictMode)
+}
+
+// CopyByPath 按路径复制文件，语义和 cp -r 命令一致，参数规则同 MoveByPath。会等待异步复制任务完成
+func (p *WebPanClient) CopyByPath(driveId, srcPath, dstPath string, conflictMode aliyunpan.FileConflictMode) ([]*aliyunpan.FilePathOpResult, *apierror.ApiError) {
+	return aliyunpan.FilePathOp(filePathOperator{fileMergeOperator{p}}, aliyunpan.FilePathOpCopy, driveId, srcPath, dstPath, conflictMode)
+}
+
+// RenameByPath 按路径重命名文件，newName 只能是文件名
+func (p *WebPanClient) RenameByPath(driveId, pathStr, newName string, conflictMode aliyunpan.FileConflictMode) (*aliyunpan.FilePathOpResult, *apierror.ApiError) {
+	return aliyunpan.FileRenameByPath(filePathOperator{fileMergeOperator{p}}, driveId, pathStr, newName, conflictMode)
+}
+
+// FileListGetAll 不使用缓存，同名冲突检查需要最新的文件列表
+func (o filePathOperator) FileListGetAll(param *aliyunpan.FileListParam, delayMilliseconds int) (aliyunpan.FileList, *apierror.ApiError) {
+	return o.fileListGetAll(param, delayMilliseconds)
+}
+
+// MoveFile 移动文件，改名通过 new_name 在移动时完成
+func (o filePathOperator) MoveFile(source *aliyunpan.FileEntity, toParentFileId, name string) *apierror.ApiError {
+	param := &aliyunpan.FileMoveParam{
+		DriveId:        source.DriveId,
+		FileId:         source.FileId,
+		ToParentFileId: toParentFileId,
+		CheckNameMode:  "refuse",
+	}
+	if name != source.FileName {
+		param.CheckNameMode = "auto_rename"
+		param.NewName = name
+	}
+	r, err := o.FileMove([]*aliyunpan.FileMoveParam{param})
+	if err != nil {
+		return err
+	}
+	if len(r) == 0 || !r[0].Success {
+		return apierror.NewFailedApiError("移动文件失败：" + source.FileName)
+	}
+	return nil
+}
+
+// CopyFile 同网盘复制文件并等待异步任务完成
+func (o filePathOperator) CopyFile(source *aliyunpan.FileEntity, toParentFileId string) (string, *apierror.ApiError) {
+	return o.fileCopy(source.DriveId, source.FileId, toParentFileId)
+}
@@ -0,0 +1,47 @@
+package aliyunpan_web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+func TestMoveCopyRenameByPath(t *testing.T) {
+	d := newFakeWebDrive()
+	d.add("docs", "root", "docs", "folder", 0)
+	d.add("a", "root", "a.txt", "file", 1)
+	d.add("c", "root", "c.log", "file", 3)
+	d.add("old", "docs", "a.txt", "file", 4)
+	p := newFakeWebClient(d)
+
+	// 移动并改名，改名在移动时通过 new_name 完成
+	results, err := p.MoveByPath("11001", "/c.log", "/docs/app.log", "")
+	require.Nil(t, err)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, "/docs/app.log", results[0].TargetPath)
+	assert.Equal(t, 0, d.count("/adrive/v3/file/update"))
+
+	// 覆盖，先移动再把同名文件放入回收站
+	results, err = p.MoveByPath("11001", "/a.txt", "/docs/", aliyunpan.FileConflictOverwrite)
+	require.Nil(t, err)
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, "/docs/a.txt", results[0].TargetPath)
+	assert.True(t, d.trashed["old"])
+	assert.Equal(t, []string{"a.txt", "app.log"}, d.names("docs"))
+	assert.Equal(t, "docs", d.files["a"].ParentFileId)
+
+	// 复制文件夹，自动重命名
+	d.add("docs2", "root", "docs(1)", "folder", 0)
+	results, err = p.CopyByPath("11001", "/docs", "/", "")
+	require.Nil(t, err)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, "/docs(2)", results[0].TargetPath)
+	assert.Equal(t, []string{"a.txt", "app.log"}, d.names(results[0].FileId))
+
+	r, err := p.RenameByPath("11001", "/docs/app.log", "a.txt", "")
+	require.Nil(t, err)
+	assert.True(t, r.Success)
+	assert.Equal(t, "/docs/a(1).txt", r.TargetPath)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/tickstep/aliyunpan-api/aliyunpan"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/library-go/logger"
 	"strings"
@@ -87,7 +88,7 @@ func (p *WebPanClient) recycleBinFileListReq(param *RecycleBinFileListParam) (*f
 
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v2/recyclebin/list", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	limit := param.Limit
 	if limit <= 0 {
@@ -109,7 +110,7 @@ func (p *WebPanClient) recycleBinFileListReq(param *RecycleBinFileListParam) (*f
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("get recycle bin file list error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -121,7 +122,7 @@ func (p *WebPanClient) recycleBinFileListReq(param *RecycleBinFileListParam) (*f
 	r := &fileListResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse recycle bin file list result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -136,7 +137,7 @@ func (p *WebPanClient) RecycleBinFileClear(param *RecycleBinFileClearParam) (*Re
 
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/v2/recyclebin/clear", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	postData := map[string]interface{}{
 		"drive_id": param.DriveId,
@@ -145,7 +146,7 @@ func (p *WebPanClient) RecycleBinFileClear(param *RecycleBinFileClearParam) (*Re
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("clear recycle bin file error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -157,7 +158,7 @@ func (p *WebPanClient) RecycleBinFileClear(param *RecycleBinFileClearParam) (*Re
 	r := &RecycleBinFileClearResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse recycle bin file clear result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"github.com/tickstep/aliyunpan-api/aliyunpan"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/library-go/logger"
 	"strings"
@@ -37,7 +38,7 @@ func (p *WebPanClient) FileRename(driveId, renameFileId, newName string) (bool,
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v3/file/update", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	postData := map[string]interface{}{
@@ -51,19 +52,20 @@ func (p *WebPanClient) FileRename(driveId, renameFileId, newName string) (bool,
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("get rename error ", err)
-		return false, apierror.NewFailedApiError(err.Error())
+		return false, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
 	if err1 := apierror.ParseCommonApiError(body); err1 != nil {
 		return false, err1
 	}
+	p.removeFileFromCache(driveId, renameFileId)
 
 	// parse result
 	r := &aliyunpan.FileEntity{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse rename result json error ", err2)
-		return false, apierror.NewFailedApiError(err2.Error())
+		return false, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return true, nil
 }
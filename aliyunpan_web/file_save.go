@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/library-go/logger"
 )
@@ -42,7 +43,7 @@ func (p *WebPanClient) GetShareInfo(shareID string) (*GetShareByAnonymous, *apie
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v3/share_link/get_share_by_anonymous?share_id=%s", API_URL, shareID)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	postData := map[string]interface{}{
@@ -51,10 +52,9 @@ func (p *WebPanClient) GetShareInfo(shareID string) (*GetShareByAnonymous, *apie
 
 	// request
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
-	logger.Verboseln(string(body))
 	if err != nil {
 		logger.Verboseln("get share by anonymous error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -66,7 +66,7 @@ func (p *WebPanClient) GetShareInfo(shareID string) (*GetShareByAnonymous, *apie
 	r := &GetShareByAnonymous{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse share by anonymous json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -86,7 +86,7 @@ func (p *WebPanClient) GetShareToken(shareID, sharePwd string) (*GetShareTokenRe
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/v2/share_link/get_share_token", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	postData := map[string]interface{}{
@@ -96,10 +96,9 @@ func (p *WebPanClient) GetShareToken(shareID, sharePwd string) (*GetShareTokenRe
 
 	// request
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
-	logger.Verboseln(string(body))
 	if err != nil {
 		logger.Verboseln("get share token error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -111,7 +110,7 @@ func (p *WebPanClient) GetShareToken(shareID, sharePwd string) (*GetShareTokenRe
 	r := &GetShareTokenResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse share token json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -147,6 +146,18 @@ type ListByShareItem struct {
 }
 
 func (p *WebPanClient) GetListByShare(shareToken, shareID, marker string) (*ListByShareResult, *apierror.ApiError) {
+	return p.getListByShare(shareToken, shareID, "root", 20, marker)
+}
+
+// GetListByShareFolder 获取分享中指定文件夹下的文件列表，parentFileId 为 root 代表分享的根目录
+func (p *WebPanClient) GetListByShareFolder(shareToken, shareID, parentFileId, marker string) (*ListByShareResult, *apierror.ApiError) {
+	if parentFileId == "" {
+		parentFileId = "root"
+	}
+	return p.getListByShare(shareToken, shareID, parentFileId, 100, marker)
+}
+
+func (p *WebPanClient) getListByShare(shareToken, shareID, parentFileId string, limit int, marker string) (*ListByShareResult, *apierror.ApiError) {
 	// header
 	header := map[string]string{
 		"authorization": p.webToken.GetAuthorizationStr(),
@@ -156,13 +167,13 @@ func (p *WebPanClient) GetListByShare(shareToken, shareID, marker string) (*List
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v2/file/list_by_share", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	postData := map[string]interface{}{
 		"share_id":                shareID,
-		"parent_file_id":          "root",
-		"limit":                   20,
+		"parent_file_id":          parentFileId,
+		"limit":                   limit,
 		"image_thumbnail_process": "image/resize,w_256/format,jpeg",
 		"image_url_process":       "image/resize,w_1920/format,jpeg/interlace,1",
 		"video_thumbnail_process": "video/snapshot,t_1000,f_jpg,ar_auto,w_256",
@@ -175,10 +186,9 @@ func (p *WebPanClient) GetListByShare(shareToken, shareID, marker string) (*List
 
 	// request
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
-	logger.Verboseln(string(body))
 	if err != nil {
 		logger.Verboseln("get list by share error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -190,7 +200,7 @@ func (p *WebPanClient) GetListByShare(shareToken, shareID, marker string) (*List
 	r := &ListByShareResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse list by share json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -217,7 +227,7 @@ func (p *WebPanClient) FileCopy(shareToken string, param []*FileSaveParam) ([]*F
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v2/batch", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	requests, e := p.getFileCopyBatchRequestList(param)
@@ -233,7 +243,10 @@ func (p *WebPanClient) FileCopy(shareToken string, param []*FileSaveParam) ([]*F
 	result, err := p.BatchTask(fullUrl.String(), &batchParam, [2]string{"x-share-token", shareToken})
 	if err != nil {
 		logger.Verboseln("file copy error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
+	}
+	for _, item := range param {
+		p.removeDirListFromCache(item.ToDriveId, item.ToParentFileId)
 	}
 
 	// parse result
@@ -282,13 +295,15 @@ func (p *WebPanClient) getFileCopyBatchRequestList(param []*FileSaveParam) (Batc
 type AsyncTaskGetResult struct {
 	AsyncTaskId string
 	Success     bool
+	// State 任务状态，例如：Running、Succeed、Failed
+	State string
 }
 
 func (p *WebPanClient) AsyncTaskGet(shareToken string, asyncTaskIds []string) ([]*AsyncTaskGetResult, *apierror.ApiError) {
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v2/batch", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	requests, e := p.getAsyncTaskGetBatchRequestList(asyncTaskIds)
@@ -304,15 +319,20 @@ func (p *WebPanClient) AsyncTaskGet(shareToken string, asyncTaskIds []string) ([
 	result, err := p.BatchTask(fullUrl.String(), &batchParam, [2]string{"x-share-token", shareToken})
 	if err != nil {
 		logger.Verboseln("async task get error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// parse result
 	r := []*AsyncTaskGetResult{}
 	for _, item := range result.Responses {
+		state, _ := item.Body["state"].(string)
+		if state == "" {
+			state, _ = item.Body["status"].(string)
+		}
 		r = append(r, &AsyncTaskGetResult{
 			AsyncTaskId: item.Id,
 			Success:     item.Status == 200,
+			State:       state,
 		})
 	}
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/tickstep/aliyunpan-api/aliyunpan"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/library-go/logger"
 	"strings"
@@ -43,6 +44,8 @@ type (
 		Creator string `json:"creator"`
 		Limit   int64  `json:"limit"`
 		Marker  string `json:"marker"`
+		// IncludeCanceled 是否包含已取消的分享
+		IncludeCanceled bool `json:"include_canceled"`
 	}
 
 	ShareListResult struct {
@@ -50,12 +53,7 @@ type (
 		NextMarker string               `json:"next_marker"`
 	}
 
-	ShareCancelResult struct {
-		// 分享ID
-		Id string
-		// 是否成功
-		Success bool
-	}
+	ShareCancelResult = aliyunpan.ShareCancelResult
 )
 
 func createShareEntity(item *shareEntityResult) *aliyunpan.ShareEntity {
@@ -63,19 +61,22 @@ func createShareEntity(item *shareEntityResult) *aliyunpan.ShareEntity {
 		return nil
 	}
 	return &aliyunpan.ShareEntity{
-		Creator:    item.Creator,
-		DriveId:    item.DriveId,
-		ShareId:    item.ShareId,
-		ShareName:  item.ShareName,
-		SharePwd:   item.SharePwd,
-		ShareUrl:   item.ShareUrl,
-		FileIdList: item.FileIdList,
-		SaveCount:  item.SaveCount,
-		Status:     item.Status,
-		Expiration: apiutil.UtcTime2LocalFormat(item.Expiration),
-		UpdatedAt:  apiutil.UtcTime2LocalFormat(item.UpdatedAt),
-		CreatedAt:  apiutil.UtcTime2LocalFormat(item.CreatedAt),
-		FirstFile:  createFileEntity(item.FirstFile),
+		Creator:       item.Creator,
+		DriveId:       item.DriveId,
+		ShareId:       item.ShareId,
+		ShareName:     item.ShareName,
+		SharePwd:      item.SharePwd,
+		ShareUrl:      item.ShareUrl,
+		FileIdList:    item.FileIdList,
+		SaveCount:     item.SaveCount,
+		PreviewCount:  item.PreviewCount,
+		DownloadCount: item.DownloadCount,
+		Status:        item.Status,
+		Expiration:    apiutil.UtcTime2LocalFormat(item.Expiration),
+		Expired:       item.Expired,
+		UpdatedAt:     apiutil.UtcTime2LocalFormat(item.UpdatedAt),
+		CreatedAt:     apiutil.UtcTime2LocalFormat(item.CreatedAt),
+		FirstFile:     createFileEntity(item.FirstFile),
 	}
 }
 
@@ -107,12 +108,134 @@ func (p *WebPanClient) ShareLinkList(userId string) ([]*aliyunpan.ShareEntity, *
 	return resultList, nil
 }
 
-// ShareLinkCancel 取消分享链接
+// ShareLinkListPage 分页获取分享链接，并按照 param 中的文件、状态、过期条件过滤当前页
+func (p *WebPanClient) ShareLinkListPage(param *aliyunpan.ShareLinkListParam) (*aliyunpan.ShareLinkListResult, *apierror.ApiError) {
+	r, err := p.GetShareLinkListReq(ShareListParam{
+		Creator:         param.Creator,
+		Limit:           int64(param.Limit),
+		Marker:          param.Marker,
+		IncludeCanceled: param.IncludeCanceled,
+	})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	result := &aliyunpan.ShareLinkListResult{
+		Items:      []*aliyunpan.ShareEntity{},
+		NextMarker: r.NextMarker,
+	}
+	for _, item := range r.Items {
+		share := createShareEntity(item)
+		if param.Match(share, now) {
+			result.Items = append(result.Items, share)
+		}
+	}
+	return result, nil
+}
+
+// ShareLinkListAll 获取所有满足过滤条件的分享链接，param.Marker 会被忽略
+func (p *WebPanClient) ShareLinkListAll(param *aliyunpan.ShareLinkListParam) ([]*aliyunpan.ShareEntity, *apierror.ApiError) {
+	resultList := []*aliyunpan.ShareEntity{}
+	pageParam := *param
+	pageParam.Marker = ""
+	for {
+		r, err := p.ShareLinkListPage(&pageParam)
+		if err != nil {
+			return nil, err
+		}
+		resultList = append(resultList, r.Items...)
+		if r.NextMarker == "" {
+			return resultList, nil
+		}
+		pageParam.Marker = r.NextMarker
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// ShareLinkUpdate 修改分享的提取码和有效期，两者都会被更新为 param 中的值
+func (p *WebPanClient) ShareLinkUpdate(param aliyunpan.ShareUpdateParam) (*aliyunpan.ShareEntity, *apierror.ApiError) {
+	// header
+	header := map[string]string{
+		"authorization": p.webToken.GetAuthorizationStr(),
+	}
+
+	// url
+	fullUrl := &strings.Builder{}
+	fmt.Fprintf(fullUrl, "%s/adrive/v2/share_link/update", API_URL)
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
+
+	// check pwd
+	if param.SharePwd != "" && len(param.SharePwd) != 4 {
+		return nil, apierror.NewFailedApiError("密码必须是4个字符")
+	}
+
+	// data
+	postData := map[string]interface{}{
+		"share_id":   param.ShareId,
+		"share_pwd":  param.SharePwd,
+		"expiration": apiutil.LocalTime2UtcFormat(param.Expiration),
+	}
+
+	// request
+	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
+	if err != nil {
+		logger.Verboseln("update share error ", err)
+		return nil, apierror.NewFailedApiErrorWithCause(err)
+	}
+
+	// handler common error
+	if err1 := apierror.ParseCommonApiError(body); err1 != nil {
+		return nil, err1
+	}
+
+	// parse result
+	r := &shareEntityResult{}
+	if err2 := json.Unmarshal(body, r); err2 != nil {
+		logger.Verboseln("parse share update result json error ", err2)
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
+	}
+	return createShareEntity(r), nil
+}
+
+// ShareLinkCancelExpired 取消当前用户所有已过期的分享链接，没有过期的分享时返回空列表
+func (p *WebPanClient) ShareLinkCancelExpired() ([]*ShareCancelResult, *apierror.ApiError) {
+	userId, err := p.currentUserId()
+	if err != nil {
+		return nil, err
+	}
+	shareList, err := p.ShareLinkListAll(&aliyunpan.ShareLinkListParam{
+		Creator: userId,
+		Expired: aliyunpan.ShareExpiredOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 每次最多取消100个
+	results := []*ShareCancelResult{}
+	for start := 0; start < len(shareList); start += 100 {
+		end := start + 100
+		if end > len(shareList) {
+			end = len(shareList)
+		}
+		shareIdList := []string{}
+		for _, share := range shareList[start:end] {
+			shareIdList = append(shareIdList, share.ShareId)
+		}
+		r, err := p.ShareLinkCancel(shareIdList)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r...)
+	}
+	return results, nil
+}
+
+// ShareLinkCancel 取消分享链接，单个分享取消失败不会中断，结果中 Success 为 false，Err 为失败原因
 func (p *WebPanClient) ShareLinkCancel(shareIdList []string) ([]*ShareCancelResult, *apierror.ApiError) {
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v4/batch", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// param
 	pr := BatchRequestList{}
@@ -139,20 +262,36 @@ func (p *WebPanClient) ShareLinkCancel(shareIdList []string) ([]*ShareCancelResu
 	result, err := p.BatchTask(fullUrl.String(), &batchParam)
 	if err != nil {
 		logger.Verboseln("share cancel error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// parse result
 	r := []*ShareCancelResult{}
 	for _, item := range result.Responses {
-		r = append(r, &ShareCancelResult{
+		sr := &ShareCancelResult{
 			Id:      item.Id,
 			Success: item.Status == 204,
-		})
+		}
+		if !sr.Success {
+			sr.Err = batchResponseError(item)
+		}
+		r = append(r, sr)
 	}
 	return r, nil
 }
 
+// currentUserId 获取当前登录用户的ID，没有设置时通过用户信息接口获取
+func (p *WebPanClient) currentUserId() (string, *apierror.ApiError) {
+	if p.appConfig.UserId != "" {
+		return p.appConfig.UserId, nil
+	}
+	userInfo, err := p.GetUserInfo()
+	if err != nil {
+		return "", err
+	}
+	return userInfo.UserId, nil
+}
+
 // ShareLinkCreate 创建分享
 func (p *WebPanClient) ShareLinkCreate(param aliyunpan.ShareCreateParam) (*aliyunpan.ShareEntity, *apierror.ApiError) {
 	// header
@@ -163,7 +302,7 @@ func (p *WebPanClient) ShareLinkCreate(param aliyunpan.ShareCreateParam) (*aliyu
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v2/share_link/create", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	postData := param
@@ -182,9 +321,8 @@ func (p *WebPanClient) ShareLinkCreate(param aliyunpan.ShareCreateParam) (*aliyu
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("create share list error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
-	logger.Verboseln("response: ", string(body))
 
 	// handler common error
 	if err1 := apierror.ParseCommonApiError(body); err1 != nil {
@@ -195,7 +333,7 @@ func (p *WebPanClient) ShareLinkCreate(param aliyunpan.ShareCreateParam) (*aliyu
 	r := &shareEntityResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse share create result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return createShareEntity(r), nil
 }
@@ -209,7 +347,7 @@ func (p *WebPanClient) GetShareLinkListReq(param ShareListParam) (*ShareListResu
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v3/share_link/list", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	if param.Limit <= 0 {
 		param.Limit = 100
@@ -218,7 +356,7 @@ func (p *WebPanClient) GetShareLinkListReq(param ShareListParam) (*ShareListResu
 	postData := map[string]interface{}{
 		"category":         "file,album",
 		"creator":          param.Creator,
-		"include_canceled": false,
+		"include_canceled": param.IncludeCanceled,
 		"order_by":         "created_at",
 		"order_direction":  "DESC",
 		"limit":            param.Limit,
@@ -229,10 +367,9 @@ func (p *WebPanClient) GetShareLinkListReq(param ShareListParam) (*ShareListResu
 
 	// request
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
-	logger.Verboseln(string(body))
 	if err != nil {
 		logger.Verboseln("get share list error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -244,7 +381,7 @@ func (p *WebPanClient) GetShareLinkListReq(param ShareListParam) (*ShareListResu
 	r := &ShareListResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse share list result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -259,7 +396,7 @@ func (p *WebPanClient) FastShareLinkCreate(param aliyunpan.FastShareCreateParam)
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v1/share/create", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	var fileList []aliyunpan.FastShareFileItem
@@ -274,9 +411,8 @@ func (p *WebPanClient) FastShareLinkCreate(param aliyunpan.FastShareCreateParam)
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("create fast share list error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
-	logger.Verboseln("response: ", string(body))
 
 	// handler common error
 	if err1 := apierror.ParseCommonApiError(body); err1 != nil {
@@ -287,7 +423,7 @@ func (p *WebPanClient) FastShareLinkCreate(param aliyunpan.FastShareCreateParam)
 	r := &aliyunpan.FastShareCreateResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse fast share create result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	r.Expiration = apiutil.UtcTime2LocalFormat(r.Expiration)
 	return r, nil
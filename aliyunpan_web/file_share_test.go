@@ -0,0 +1,72 @@
+package aliyunpan_web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+func TestShareLinkManage(t *testing.T) {
+	d := newFakeWebDrive()
+	d.add("f1", "root", "a.txt", "file", 100)
+	d.shareLinks = []*shareEntityResult{
+		{ShareId: "s1", FileIdList: []string{"f1"}, Status: "enabled", PreviewCount: 3, SaveCount: 2, DownloadCount: 1},
+		{ShareId: "s2", FileIdList: []string{"f2"}, Status: "enabled", Expiration: "2020-01-01T00:00:00.000Z"},
+		{ShareId: "s3", FileIdList: []string{"f3"}, Status: "enabled", FirstFile: d.files["f1"]},
+		{ShareId: "s4", FileIdList: []string{"f4"}, Status: "forbidden", Expired: true},
+	}
+	p := newFakeWebClient(d)
+	p.UpdateUserId("u1")
+
+	// 分页并且按文件过滤
+	page, err := p.ShareLinkListPage(&aliyunpan.ShareLinkListParam{Creator: "u1", Limit: 3, FileId: "f1"})
+	require.Nil(t, err)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, "s1", page.Items[0].ShareId)
+	assert.Equal(t, 3, page.Items[0].PreviewCount)
+	assert.Equal(t, 2, page.Items[0].SaveCount)
+	assert.Equal(t, 1, page.Items[0].DownloadCount)
+	assert.Equal(t, "s3", page.Items[1].ShareId)
+	assert.Equal(t, "3", page.NextMarker)
+
+	forbidden, err := p.ShareLinkListAll(&aliyunpan.ShareLinkListParam{Creator: "u1", Status: "forbidden"})
+	require.Nil(t, err)
+	require.Len(t, forbidden, 1)
+	assert.Equal(t, "s4", forbidden[0].ShareId)
+
+	// 修改提取码和有效期
+	_, err = p.ShareLinkUpdate(aliyunpan.ShareUpdateParam{ShareId: "s1", SharePwd: "abcde"})
+	assert.NotNil(t, err)
+	expiration := time.Now().Add(24 * time.Hour).Format("2006-01-02 15:04:05")
+	share, err := p.ShareLinkUpdate(aliyunpan.ShareUpdateParam{ShareId: "s1", SharePwd: "8a2b", Expiration: expiration})
+	require.Nil(t, err)
+	assert.Equal(t, "8a2b", share.SharePwd)
+	assert.Equal(t, expiration, share.Expiration)
+	assert.False(t, share.IsExpired(time.Now()))
+
+	// 只取消已过期的分享
+	results, err := p.ShareLinkCancelExpired()
+	require.Nil(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "s2", results[0].Id)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, "s4", results[1].Id)
+	assert.True(t, results[1].Success)
+	require.Len(t, d.shareLinks, 2)
+
+	results, err = p.ShareLinkCancelExpired()
+	require.Nil(t, err)
+	assert.Empty(t, results)
+
+	// 单个分享取消失败时返回失败原因
+	results, err = p.ShareLinkCancel([]string{"s1", "missing"})
+	require.Nil(t, err)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Success)
+	assert.Nil(t, results[0].Err)
+	assert.False(t, results[1].Success)
+	assert.NotNil(t, results[1].Err)
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2020 tickstep.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aliyunpan_web
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
+	"github.com/tickstep/library-go/logger"
+)
+
+// FileThumbnail 批量获取文件的缩略图、预览地址和图片元数据，图片和视频返回 Thumbnail，
+// 图片返回 ImageUrlWidth 宽度的预览图 Url，小文件（包括文档）返回下载地址 Url，图片返回 ImageMediaMetadata。
+// 获取失败的文件不会出现在结果中
+func (p *WebPanClient) FileThumbnail(param *aliyunpan.FileThumbnailParam) (aliyunpan.FileList, *apierror.ApiError) {
+	if param == nil || param.DriveId == "" {
+		return nil, apierror.NewFailedApiError("参数不能为空")
+	}
+	param = param.WithDefaults()
+	fileList := aliyunpan.FileList{}
+	for start := 0; start < len(param.FileIds); start += aliyunpan.FileThumbnailBatchSize {
+		end := start + aliyunpan.FileThumbnailBatchSize
+		if end > len(param.FileIds) {
+			end = len(param.FileIds)
+		}
+		r, err := p.fileThumbnailBatch(param, param.FileIds[start:end])
+		if err != nil {
+			return nil, err
+		}
+		fileList = append(fileList, r...)
+	}
+	return fileList, nil
+}
+
+func (p *WebPanClient) fileThumbnailBatch(param *aliyunpan.FileThumbnailParam, fileIds []string) (aliyunpan.FileList, *apierror.ApiError) {
+	// url
+	fullUrl := &strings.Builder{}
+	fmt.Fprintf(fullUrl, "%s/adrive/v4/batch", API_URL)
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
+
+	// param
+	pr := BatchRequestList{}
+	for _, fileId := range fileIds {
+		pr = append(pr, &BatchRequest{
+			Id:     fileId,
+			Method: "POST",
+			Url:    "/file/get",
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"drive_id":                param.DriveId,
+				"file_id":                 fileId,
+				"url_expire_sec":          1600,
+				"image_thumbnail_process": param.ImageThumbnailProcess(),
+				"image_url_process":       param.ImageUrlProcess(),
+				"video_thumbnail_process": param.VideoThumbnailProcess(),
+				"fields":                  "*",
+			},
+		})
+	}
+
+	batchParam := BatchRequestParam{
+		Requests: pr,
+		Resource: "file",
+	}
+
+	// request
+	result, err := p.BatchTask(fullUrl.String(), &batchParam)
+	if err != nil {
+		logger.Verboseln("file thumbnail error ", err)
+		return nil, err
+	}
+
+	// parse result
+	fileList := aliyunpan.FileList{}
+	for _, item := range result.Responses {
+		if item.Status != 200 || item.Body == nil {
+			logger.Verboseln("file thumbnail failed ", item.Id, item.Status)
+			continue
+		}
+		data, _ := json.Marshal(item.Body)
+		f := &fileEntityResult{}
+		if err1 := json.Unmarshal(data, f); err1 != nil {
+			logger.Verboseln("parse file thumbnail result json error ", err1)
+			return nil, apierror.NewFailedApiErrorWithCause(err1)
+		}
+		fileList = append(fileList, createFileEntity(f))
+	}
+	return fileList, nil
+}
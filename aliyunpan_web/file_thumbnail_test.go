@@ -0,0 +1,38 @@
+package aliyunpan_web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+func TestFileThumbnail(t *testing.T) {
+	d := newFakeWebDrive()
+	d.add("f1", "root", "a.jpg", "file", 100)
+	d.add("f2", "root", "b.txt", "file", 100)
+	p := newFakeWebClient(d)
+
+	fileList, err := p.FileThumbnail(&aliyunpan.FileThumbnailParam{
+		DriveId:             "11001",
+		FileIds:             []string{"f1", "f2", "missing"},
+		ImageThumbnailWidth: 200,
+	})
+	require.Nil(t, err)
+	require.Len(t, fileList, 2)
+	assert.Equal(t, "https://thumb.fake/f1?image/resize,w_200/format,jpeg", fileList[0].Thumbnail)
+	assert.Equal(t, "https://thumb.fake/f1?image/resize,w_1920/format,jpeg", fileList[0].Url)
+	require.NotNil(t, fileList[0].ImageMediaMetadata)
+	assert.Equal(t, 4032, fileList[0].ImageMediaMetadata.Width)
+	assert.Equal(t, `{"Model":"fake"}`, fileList[0].ImageMediaMetadata.Exif)
+	assert.Equal(t, "f2", fileList[1].FileId)
+	assert.Empty(t, fileList[1].Thumbnail)
+	assert.Nil(t, fileList[1].ImageMediaMetadata)
+}
+
+func TestFileThumbnailProcess(t *testing.T) {
+	param := &aliyunpan.FileThumbnailParam{VideoThumbnailWidth: 320, VideoThumbnailTime: 5000}
+	assert.Equal(t, "image/resize,w_400/format,jpeg", param.ImageThumbnailProcess())
+	assert.Equal(t, "video/snapshot,t_5000,f_jpg,ar_auto,w_320", param.VideoThumbnailProcess())
+}
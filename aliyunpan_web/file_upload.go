@@ -5,9 +5,10 @@ import (
 	"fmt"
 	"github.com/tickstep/aliyunpan-api/aliyunpan"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/library-go/logger"
-	"github.com/tickstep/library-go/requester"
+	"net/http"
 	"strings"
 )
 
@@ -49,7 +50,7 @@ func (p *WebPanClient) CreateUploadFile(param *aliyunpan.CreateFileUploadParam)
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v2/file/createWithFolders", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	postData := param
@@ -79,7 +80,7 @@ func (p *WebPanClient) CreateUploadFile(param *aliyunpan.CreateFileUploadParam)
 	resp, err := p.client.Req("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("create upload file error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -87,12 +88,13 @@ func (p *WebPanClient) CreateUploadFile(param *aliyunpan.CreateFileUploadParam)
 	if err1 != nil {
 		return nil, err1
 	}
+	p.removeDirListFromCache(postData.DriveId, postData.ParentFileId)
 
 	// parse result
 	r := &aliyunpan.CreateFileUploadResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse create upload file result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -109,7 +111,7 @@ func (p *WebPanClient) GetUploadUrl(param *aliyunpan.GetUploadUrlParam) (*aliyun
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/v2/file/get_upload_url", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	postData := param
@@ -118,7 +120,7 @@ func (p *WebPanClient) GetUploadUrl(param *aliyunpan.GetUploadUrlParam) (*aliyun
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("get upload url error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -130,13 +132,14 @@ func (p *WebPanClient) GetUploadUrl(param *aliyunpan.GetUploadUrlParam) (*aliyun
 	r := &aliyunpan.GetUploadUrlResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse get upload url result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	r.CreateAt = apiutil.UtcTime2LocalFormat(r.CreateAt)
 	return r, nil
 }
 
-// UploadFileData 上传文件数据
+// UploadFileData 上传文件数据。上传的数据由回调函数提供，每个分片上传完成后按照请求的 ContentLength 等待全局上传限速，
+// 所以回调中的数据块不需要再使用 LimitUploadChunkData 包装，单个传输任务的限速可以直接设置数据块的 Limiters
 func (p *WebPanClient) UploadFileData(uploadUrl string, uploadFunc aliyunpan.UploadFunc) *apierror.ApiError {
 	// header
 	header := map[string]string{
@@ -146,23 +149,40 @@ func (p *WebPanClient) UploadFileData(uploadUrl string, uploadFunc aliyunpan.Upl
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s", uploadUrl)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// request
 	if uploadFunc != nil {
-		resp, err := uploadFunc("PUT", fullUrl.String(), header)
+		resp, err := p.observer.Do(apitransport.RequestClassTransfer, "PUT", fullUrl.String(), func() (*http.Response, error) {
+			return p.governor.Do(apitransport.RequestClassTransfer, func() (*http.Response, error) {
+				return uploadFunc("PUT", fullUrl.String(), header)
+			})
+		})
 		if err != nil || (resp != nil && resp.StatusCode != 200) {
 			logger.Verboseln("upload file data chunk error ", err)
 			return apierror.NewFailedApiError("update data error")
 		}
+		if resp != nil && resp.Request != nil && resp.Request.ContentLength > 0 {
+			// 数据由回调发送，只能在分片上传完成后按照分片大小等待全局上传限速
+			p.uploadLimiter.WaitN(int(resp.Request.ContentLength))
+		}
 	}
 	return nil
 }
 
-// UploadDataChunk 上传数据。该方法是同步阻塞的
-func (p *WebPanClient) UploadDataChunk(url string, data *aliyunpan.FileUploadChunkData) *apierror.ApiError {
-	var client = requester.NewHTTPClient()
+// LimitUploadChunkData 返回使用全局上传限速的数据块，数据块原有的限速器（单个传输任务的限速）仍然生效。
+// 用于自行发送数据的场景，UploadFileData 已经按照全局上传限速，不需要再包装
+func (p *WebPanClient) LimitUploadChunkData(data *aliyunpan.FileUploadChunkData) *aliyunpan.FileUploadChunkData {
+	if data == nil {
+		return nil
+	}
+	chunk := *data
+	chunk.Limiters = append([]*aliyunpan.BandwidthLimiter{p.uploadLimiter}, data.Limiters...)
+	return &chunk
+}
 
+// UploadDataChunk 上传数据。该方法是同步阻塞的，数据按照全局上传限速以及数据块自身的限速器上传
+func (p *WebPanClient) UploadDataChunk(url string, data *aliyunpan.FileUploadChunkData) *apierror.ApiError {
 	// header
 	header := map[string]string{
 		"referer": "https://www.aliyundrive.com/",
@@ -171,17 +191,22 @@ func (p *WebPanClient) UploadDataChunk(url string, data *aliyunpan.FileUploadChu
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s", url)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	if data == nil || data.Reader == nil || data.Len() == 0 {
 		return apierror.NewFailedApiError("数据块错误")
 	}
 	// request
-	resp, err := client.Req("PUT", fullUrl.String(), data, header)
-	if err != nil || resp.StatusCode != 200 {
+	resp, err := p.transferClient.Req("PUT", fullUrl.String(), p.LimitUploadChunkData(data), header)
+	if err != nil {
 		logger.Verboseln("upload file data chunk error ", err)
-		return apierror.NewFailedApiError(err.Error())
+		return apierror.NewFailedApiErrorWithCause(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		logger.Verboseln("upload file data chunk error, http status ", resp.StatusCode)
+		return apierror.NewFailedApiError("upload data chunk error").WithHttpStatus(resp.StatusCode).WithRequestId(resp.Header.Get("x-request-id"))
 	}
 	return nil
 }
@@ -196,7 +221,7 @@ func (p *WebPanClient) CompleteUploadFile(param *aliyunpan.CompleteUploadFilePar
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/v2/file/complete", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	postData := map[string]interface{}{
@@ -210,7 +235,7 @@ func (p *WebPanClient) CompleteUploadFile(param *aliyunpan.CompleteUploadFilePar
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("complete upload file error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -222,8 +247,9 @@ func (p *WebPanClient) CompleteUploadFile(param *aliyunpan.CompleteUploadFilePar
 	r := &completeUploadFileReqResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse complete upload file result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
+	p.removeDirListFromCache(r.DriveId, r.ParentFileId)
 
 	return &aliyunpan.CompleteUploadFileResult{
 		DriveId:         r.DriveId,
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/tickstep/aliyunpan-api/aliyunpan"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/library-go/logger"
 	"strings"
@@ -20,7 +21,7 @@ func (p *WebPanClient) VideoGetPreviewPlayInfo(param *aliyunpan.VideoGetPreviewP
 
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/v2/file/get_video_preview_play_info", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	postData := map[string]interface{}{
 		"category":    "live_transcoding",
@@ -31,10 +32,9 @@ func (p *WebPanClient) VideoGetPreviewPlayInfo(param *aliyunpan.VideoGetPreviewP
 
 	// request
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
-	logger.Verboseln("response: " + string(body))
 	if err != nil {
 		logger.Verboseln("get video preview play info error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -46,7 +46,7 @@ func (p *WebPanClient) VideoGetPreviewPlayInfo(param *aliyunpan.VideoGetPreviewP
 	r := &aliyunpan.VideoGetPreviewPlayInfoResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse video preview play info json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
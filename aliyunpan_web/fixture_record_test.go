@@ -0,0 +1,250 @@
+package aliyunpan_web
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
+)
+
+var recordFixtures = flag.Bool("record", false, "重新录制 testdata 下的接口响应")
+
+type (
+	// fixtureResponse 没有账号时录制使用的示例响应，敏感字段使用包含 sample-secret 的值，录制后不能出现在文件中
+	fixtureResponse struct {
+		path       string
+		statusCode int
+		requestId  string
+		body       string
+	}
+
+	// fixtureTransport 代替网络按顺序返回示例响应
+	fixtureTransport struct {
+		responses []*fixtureResponse
+	}
+
+	// recordSwitch 客户端的基础传输层，recorder 为nil时不录制，用于跳过创建会话、获取分享Token等准备请求
+	recordSwitch struct {
+		base     http.RoundTripper
+		recorder http.RoundTripper
+	}
+
+	// fixtureIds 录制时使用的ID，使用示例响应时为示例数据中的ID，访问真实账号时从账号信息和环境变量获取
+	fixtureIds struct {
+		driveId    string
+		shareId    string
+		shareToken string
+		albumId    string
+	}
+
+	// fixture 一个录制文件，record 中调用需要录制的接口
+	fixture struct {
+		name      string
+		record    func(p *WebPanClient, ids *fixtureIds)
+		responses []*fixtureResponse
+	}
+)
+
+// sampleFixtureIds 示例响应中的ID
+var sampleFixtureIds = &fixtureIds{
+	driveId:    "11001",
+	shareId:    "GSHjrcPt9pP",
+	shareToken: "sample-secret-share-token",
+	albumId:    "a3d0bd7f6b2e4c5a8e9f1d2c3b4a5e6f",
+}
+
+func (f *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(f.responses) == 0 || f.responses[0].path != req.URL.Path {
+		return nil, fmt.Errorf("no sample response for %s", req.URL.Path)
+	}
+	r := f.responses[0]
+	f.responses = f.responses[1:]
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("x-request-id", r.requestId)
+	return &http.Response{
+		StatusCode: r.statusCode,
+		Header:     header,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(r.body))),
+		Request:    req,
+	}, nil
+}
+
+func (s *recordSwitch) RoundTrip(req *http.Request) (*http.Response, error) {
+	if s.recorder != nil {
+		return s.recorder.RoundTrip(req)
+	}
+	return s.base.RoundTrip(req)
+}
+
+var webFixtures = []*fixture{
+	{
+		name: "file_list.json",
+		record: func(p *WebPanClient, ids *fixtureIds) {
+			p.FileList(&aliyunpan.FileListParam{DriveId: ids.driveId, ParentFileId: aliyunpan.DefaultRootParentFileId, Limit: 2})
+			// 不存在的目录
+			p.FileList(&aliyunpan.FileListParam{DriveId: ids.driveId, ParentFileId: "6320d19ec3a4b1d3a8c74d6f8d3e5b1b2e2f7a99"})
+		},
+		responses: []*fixtureResponse{
+			{
+				path:       "/adrive/v3/file/list",
+				statusCode: 200,
+				requestId:  "0bc13b0116660546641984077e4b01",
+				body: `{"items":[` +
+					`{"drive_id":"11001","domain_id":"bj29","file_id":"6320d19ec3a4b1d3a8c74d6f8d3e5b1b2e2f7a10","name":"我的文档","type":"folder","created_at":"2022-09-13T18:58:38.104Z","updated_at":"2022-09-14T02:10:00.000Z","hidden":false,"starred":false,"status":"available","user_meta":"{\"channel\":\"file_upload\"}","parent_file_id":"root","encrypt_mode":"none","creator_type":"User","creator_id":"sample-secret-user","last_modifier_type":"User","last_modifier_id":"sample-secret-user","sync_flag":false,"sync_meta":""},` +
+					`{"drive_id":"11001","domain_id":"bj29","file_id":"6320d1a3b27d6f0ad1934fe4a3c43b2cb4ac2c15","name":"IMG_0001.JPG","type":"file","content_type":"application/oct-stream","created_at":"2022-09-13T18:58:43.563Z","updated_at":"2022-09-13T18:58:43.563Z","file_extension":"jpg","mime_type":"image/jpeg","mime_extension":"jpg","hidden":false,"size":2736451,"starred":true,"status":"available","upload_id":"D7A3B1C0E6F94A2C8B1F0C3E5D2A4B6C","parent_file_id":"root","crc64_hash":"11838123485123450912","content_hash":"0D4E6A2F8A9B1C3D5E7F90A1B2C3D4E5F6A7B8C9","content_hash_name":"sha1","download_url":"https://bj29.cn-beijing.data.alicloudccp.com/xxx/IMG_0001.JPG?di=bj29&dr=11001&f=6320d1a3&x-oss-signature=sample-secret-sig&security-token=sample-secret-sts","url":"https://bj29.cn-beijing.data.alicloudccp.com/xxx/IMG_0001.JPG?di=bj29&x-oss-signature=sample-secret-sig","thumbnail":"https://bj29.cn-beijing.data.alicloudccp.com/xxx/IMG_0001.JPG?x-oss-process=image%2Fresize%2Cw_400&x-oss-signature=sample-secret-sig","category":"image","encrypt_mode":"none","punish_flag":0,"image_media_metadata":{"width":4032,"height":3024,"exif":"{}"},"creator_id":"sample-secret-user","sync_flag":true,"sync_meta":"{\"device\":\"iPhone\"}"}` +
+					`],"next_marker":"WyI2MzIwZDFhM2IyN2Q2ZjBhZDE5MzRmZTRhM2M0M2IyY2I0YWMyYzE1Il0"}`,
+			},
+			{
+				path:       "/adrive/v3/file/list",
+				statusCode: 404,
+				requestId:  "0bc13b0116660546641984077e4b02",
+				body:       `{"code":"NotFound.File","message":"The resource file cannot be found. file not exist","requestId":"0bc13b0116660546641984077e4b02"}`,
+			},
+		},
+	},
+	{
+		name: "list_by_share.json",
+		record: func(p *WebPanClient, ids *fixtureIds) {
+			p.GetListByShare(ids.shareToken, ids.shareId, "")
+		},
+		responses: []*fixtureResponse{
+			{
+				path:       "/adrive/v2/file/list_by_share",
+				statusCode: 200,
+				requestId:  "0bc13b0116660546641984077e4b03",
+				body: `{"items":[` +
+					`{"drive_id":"22002","domain_id":"bj29","file_id":"63b4f0f2d6a5c1d4e0b84f6a9b2c5e7d1a3f9c20","share_id":"GSHjrcPt9pP","name":"风景.png","type":"file","created_at":"2023-01-04T03:12:18.275Z","updated_at":"2023-01-04T03:12:19.870Z","file_extension":"png","mime_type":"image/png","mime_extension":"png","size":1048576,"parent_file_id":"root","thumbnail":"https://pdsapi.aliyundrive.com/v2/redirect?id=63b4f0f2&signature=sample-secret-sig","category":"image","image_media_metadata":{"width":1920,"height":1080,"exif":"{\"ImageWidth\":{\"value\":\"1920\"}}"},"punish_flag":0,"revision_id":"63b4f0f2aa1e4c22b1f94d7f8cbe0f31"},` +
+					`{"drive_id":"22002","domain_id":"bj29","file_id":"63b4f0e86e0c4f1fb8c94c4a8f0a5e3b9d1c2e10","share_id":"GSHjrcPt9pP","name":"资料","type":"folder","created_at":"2023-01-04T03:12:08.000Z","updated_at":"2023-01-04T03:12:08.000Z","parent_file_id":"root"}` +
+					`],"next_marker":""}`,
+			},
+		},
+	},
+	{
+		name: "album_list.json",
+		record: func(p *WebPanClient, ids *fixtureIds) {
+			p.AlbumList(&AlbumListParam{})
+		},
+		responses: []*fixtureResponse{
+			{
+				path:       "/adrive/v1/album/list",
+				statusCode: 200,
+				requestId:  "0bc13b0116660546641984077e4b04",
+				body: `{"items":[` +
+					`{"owner":"sample-secret-user","name":"旅行","description":"2022 夏天","album_id":"a3d0bd7f6b2e4c5a8e9f1d2c3b4a5e6f","file_count":12,"image_count":10,"video_count":2,"created_at":1662998400000,"updated_at":1663084800000,"is_sharing":false},` +
+					`{"owner":"sample-secret-user","name":"家庭","description":"","album_id":"b4e1ce8a7c3f4d6b9fa02e3d4c5b6f7a","file_count":0,"image_count":0,"video_count":0,"created_at":1663171200000,"updated_at":1663171200000,"is_sharing":true}` +
+					`],"next_marker":""}`,
+			},
+		},
+	},
+	{
+		name: "album_list_file.json",
+		record: func(p *WebPanClient, ids *fixtureIds) {
+			p.AlbumListFile(&AlbumListFileParam{AlbumId: ids.albumId, Limit: 2})
+		},
+		responses: []*fixtureResponse{
+			{
+				path:       "/adrive/v1/album/list_files",
+				statusCode: 200,
+				requestId:  "0bc13b0116660546641984077e4b05",
+				body: `{"items":[` +
+					`{"drive_id":"33003","domain_id":"bj29","file_id":"6321a8f4c9e1b2d3a4f5e6d7c8b9a0f1e2d3c4b5","name":"IMG_0002.livp","type":"file","content_type":"application/oct-stream","created_at":"2022-09-14T08:00:00.000Z","updated_at":"2022-09-14T08:00:01.000Z","file_extension":"livp","mime_type":"application/zip","hidden":false,"size":3145728,"starred":false,"status":"available","parent_file_id":"root","content_hash":"1A2B3C4D5E6F708192A3B4C5D6E7F8091A2B3C4D","content_hash_name":"sha1","thumbnail":"https://bj29.cn-beijing.data.alicloudccp.com/xxx/IMG_0002.livp?x-oss-process=image%2Fresize%2Cw_400&x-oss-signature=sample-secret-sig","category":"image","punish_flag":0,"image_media_metadata":{"width":3024,"height":4032,"exif":"{}"},"creator_id":"sample-secret-user"},` +
+					`{"drive_id":"33003","domain_id":"bj29","file_id":"6321a8f9d0e2c3f4b5a6f7e8d9c0b1a2f3e4d5c6","name":"VID_0003.MOV","type":"file","content_type":"application/oct-stream","created_at":"2022-09-14T09:30:00.000Z","updated_at":"2022-09-14T09:30:05.000Z","file_extension":"mov","mime_type":"video/quicktime","hidden":false,"size":52428800,"starred":false,"status":"available","parent_file_id":"root","content_hash":"2B3C4D5E6F708192A3B4C5D6E7F8091A2B3C4D5E","content_hash_name":"sha1","thumbnail":"https://bj29.cn-beijing.data.alicloudccp.com/xxx/VID_0003.MOV?x-oss-process=video%2Fsnapshot%2Ct_0&x-oss-signature=sample-secret-sig","category":"video","punish_flag":0,"creator_id":"sample-secret-user"}` +
+					`],"next_marker":"WyI2MzIxYThmOWQwZTJjM2Y0YjVhNmY3ZThkOWMwYjFhMmYzZTRkNWM2Il0"}`,
+			},
+		},
+	},
+}
+
+// liveWebClient 使用环境变量中的真实账号创建客户端，创建会话并获取录制需要的ID，这些准备请求不录制。
+// 返回录制文件中不能出现的账号敏感数据
+func liveWebClient(t *testing.T, token string, transport *recordSwitch) (*WebPanClient, *fixtureIds, []string) {
+	deviceId := os.Getenv("ALIYUNPAN_WEB_DEVICE_ID")
+	shareId := os.Getenv("ALIYUNPAN_WEB_SHARE_ID")
+	if deviceId == "" || shareId == "" {
+		t.Fatal("录制真实接口需要设置 ALIYUNPAN_WEB_DEVICE_ID、ALIYUNPAN_WEB_SHARE_ID")
+	}
+	p := NewWebPanClient(WebLoginToken{AccessTokenType: "Bearer", AccessToken: token}, AppLoginToken{},
+		AppConfig{AppId: "25dzX3vbYqktVxyX", DeviceId: deviceId},
+		SessionConfig{DeviceName: "Chrome浏览器", ModelName: "Windows网页版"},
+		apitransport.WithTransport(transport))
+	p.SetRetryPolicy(aliyunpan.NoRetryPolicy())
+
+	ui, err := p.GetUserInfo()
+	require.Nil(t, err)
+	p.UpdateUserId(ui.UserId)
+	_, err = p.CreateSession(nil)
+	require.Nil(t, err)
+	shareToken, err := p.GetShareToken(shareId, os.Getenv("ALIYUNPAN_WEB_SHARE_PWD"))
+	require.Nil(t, err)
+
+	albumId := os.Getenv("ALIYUNPAN_WEB_ALBUM_ID")
+	if albumId == "" {
+		albums, err := p.AlbumList(&AlbumListParam{})
+		require.Nil(t, err)
+		require.NotEmpty(t, albums.Items, "没有相簿，需要设置 ALIYUNPAN_WEB_ALBUM_ID")
+		albumId = albums.Items[0].AlbumId
+	}
+	ids := &fixtureIds{
+		driveId:    ui.FileDriveId,
+		shareId:    shareId,
+		shareToken: shareToken.ShareToken,
+		albumId:    albumId,
+	}
+	secrets := []string{token, deviceId, ui.UserId, shareToken.ShareToken}
+	for _, s := range []string{ui.UserName, ui.Nickname, ui.Phone} {
+		if s != "" {
+			secrets = append(secrets, s)
+		}
+	}
+	return p, ids, secrets
+}
+
+// TestRecordFixtures 使用 go test -run TestRecordFixtures -record 重新录制 testdata 下的接口响应，录制的数据经过录制传输层脱敏后保存。
+// 设置了 ALIYUNPAN_WEB_ACCESS_TOKEN 时使用真实账号录制，还需要设置 ALIYUNPAN_WEB_DEVICE_ID（已登录设备的ID）、
+// ALIYUNPAN_WEB_SHARE_ID（分享ID，有提取码时设置 ALIYUNPAN_WEB_SHARE_PWD），可选设置 ALIYUNPAN_WEB_ALBUM_ID（默认为第一个相簿）；
+// 网盘ID从账号信息获取。没有设置时使用示例响应录制，testdata 中的文件目前是示例响应录制的，
+// 使用真实账号重新录制后需要按照录制的数据更新 parser_test 中的断言
+func TestRecordFixtures(t *testing.T) {
+	if !*recordFixtures {
+		t.Skip("使用 -record 重新录制")
+	}
+	token := os.Getenv("ALIYUNPAN_WEB_ACCESS_TOKEN")
+	transport := &recordSwitch{base: http.DefaultTransport}
+	var p *WebPanClient
+	ids, secrets := sampleFixtureIds, []string{"sample-secret"}
+	if token != "" {
+		p, ids, secrets = liveWebClient(t, token, transport)
+	} else {
+		p = NewWebPanClient(WebLoginToken{AccessTokenType: "Bearer"}, AppLoginToken{}, AppConfig{}, SessionConfig{},
+			apitransport.WithTransport(transport))
+		p.SetRetryPolicy(aliyunpan.NoRetryPolicy())
+	}
+
+	for _, f := range webFixtures {
+		if token == "" {
+			transport.base = &fixtureTransport{responses: f.responses}
+		}
+		cassette := apitransport.NewCassette()
+		transport.recorder = apitransport.NewRecordingTransport(transport.base, cassette)
+		f.record(p, ids)
+		transport.recorder = nil
+
+		fixturePath := filepath.Join("testdata", f.name)
+		require.NoError(t, cassette.Save(fixturePath))
+		data, err := ioutil.ReadFile(fixturePath)
+		require.NoError(t, err)
+		for _, secret := range secrets {
+			assert.NotContains(t, string(data), secret, f.name)
+		}
+	}
+}
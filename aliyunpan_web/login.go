@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/library-go/logger"
 	"github.com/tickstep/library-go/requester"
@@ -66,14 +67,22 @@ func (w *WebLoginToken) IsAccessTokenExpired() bool {
 	return (expireTime.Unix() - now.Unix()) < 60
 }
 
-func GetAccessTokenFromRefreshToken(refreshToken string) (*WebLoginToken, *apierror.ApiError) {
-	myclient := requester.NewHTTPClient()
+// GetAccessTokenFromRefreshToken 使用 RefreshToken 获取新的 AccessToken。opts 和 NewWebPanClient 的配置相同，可以注入传输层、代理等
+func GetAccessTokenFromRefreshToken(refreshToken string, opts ...apitransport.ClientOption) (*WebLoginToken, *apierror.ApiError) {
+	return getAccessTokenFromRefreshToken(apitransport.NewClientOptions(opts...).NewHTTPClient(), refreshToken)
+}
+
+// RefreshAccessToken 使用 RefreshToken 获取新的 AccessToken，请求经过客户端的传输层、代理、重试和限速
+func (p *WebPanClient) RefreshAccessToken(refreshToken string) (*WebLoginToken, *apierror.ApiError) {
+	return getAccessTokenFromRefreshToken(p.client, refreshToken)
+}
 
+func getAccessTokenFromRefreshToken(myclient *requester.HTTPClient, refreshToken string) (*WebLoginToken, *apierror.ApiError) {
 	header := map[string]string{}
 
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/v2/account/token", AUTH_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 	postData := map[string]string{
 		"refresh_token": refreshToken,
 		"api_id":        "pJZInNHN2dZWk8qg",
@@ -83,7 +92,7 @@ func GetAccessTokenFromRefreshToken(refreshToken string) (*WebLoginToken, *apier
 	body, err := myclient.Fetch("POST", fullUrl.String(), postData, apiutil.AddCommonHeader(header))
 	if err != nil {
 		logger.Verboseln("get access token error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -94,7 +103,7 @@ func GetAccessTokenFromRefreshToken(refreshToken string) (*WebLoginToken, *apier
 	r := &refreshTokenResult{}
 	if err1 := json.Unmarshal(body, r); err1 != nil {
 		logger.Verboseln("parse refresh token result json error ", err1)
-		return nil, apierror.NewFailedApiError(err1.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err1)
 	}
 
 	result := &WebLoginToken{
@@ -0,0 +1,56 @@
+package aliyunpan_web
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
+)
+
+// tokenTransport 返回固定的 Token，记录请求地址
+type tokenTransport struct {
+	urls []string
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.urls = append(t.urls, req.URL.String())
+	body := `{"token_type":"Bearer","access_token":"new-access","refresh_token":"new-refresh","expires_in":7200,"expire_time":"2023-01-01T02:00:00Z"}`
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+		Request:    req,
+	}, nil
+}
+
+func TestGetAccessTokenFromRefreshToken(t *testing.T) {
+	tr := &tokenTransport{}
+	token, err := GetAccessTokenFromRefreshToken("old-refresh", apitransport.WithTransport(tr))
+	require.Nil(t, err)
+	assert.Equal(t, "new-access", token.AccessToken)
+	assert.Equal(t, "new-refresh", token.RefreshToken)
+	assert.Equal(t, []string{AUTH_URL + "/v2/account/token"}, tr.urls)
+}
+
+func TestWebPanClientRefreshAccessToken(t *testing.T) {
+	tr := &tokenTransport{}
+	p := NewWebPanClient(WebLoginToken{}, AppLoginToken{}, AppConfig{}, SessionConfig{}, apitransport.WithTransport(tr))
+	p.SetRetryPolicy(aliyunpan.NoRetryPolicy())
+	p.GetGovernor().SetConfig(apitransport.GovernorConfig{})
+	var hooked []string
+	p.AddRequestHook(apitransport.HookFunc(func(ctx context.Context, info *apitransport.RequestInfo) {
+		hooked = append(hooked, info.Endpoint)
+	}))
+
+	token, err := p.RefreshAccessToken("old-refresh")
+	require.Nil(t, err)
+	assert.Equal(t, "Bearer", token.AccessTokenType)
+	assert.Len(t, tr.urls, 1)
+	assert.Equal(t, []string{"/v2/account/token"}, hooked)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/library-go/logger"
 	"strings"
@@ -28,7 +29,7 @@ func (p *WebPanClient) DeviceLogout() (*DeviceLogoutResult, *apierror.ApiError)
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/users/v1/users/device_logout", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	postData := map[string]interface{}{}
@@ -37,9 +38,8 @@ func (p *WebPanClient) DeviceLogout() (*DeviceLogoutResult, *apierror.ApiError)
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("device logout error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
-	logger.Verboseln("device logout response: " + string(body))
 
 	// handler common error
 	if err1 := apierror.ParseCommonApiError(body); err1 != nil {
@@ -50,7 +50,7 @@ func (p *WebPanClient) DeviceLogout() (*DeviceLogoutResult, *apierror.ApiError)
 	r := &DeviceLogoutResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse device logout result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
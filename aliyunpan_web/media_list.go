@@ -0,0 +1,25 @@
+// Copyright (c) 2020 tickstep.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aliyunpan_web
+
+import (
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+// MediaList 获取文件夹中的视频文件和同名字幕文件，可选获取时长和分辨率，详见 aliyunpan.ListMedia
+func (p *WebPanClient) MediaList(param *aliyunpan.MediaListParam) (aliyunpan.MediaList, *apierror.ApiError) {
+	return aliyunpan.ListMedia(p, param)
+}
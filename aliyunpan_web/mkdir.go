@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"github.com/tickstep/aliyunpan-api/aliyunpan"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/library-go/logger"
 	"strings"
@@ -38,7 +39,7 @@ func (p *WebPanClient) Mkdir(driveId, parentFileId, dirName string) (*aliyunpan.
 
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/adrive/v2/file/createWithFolders", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	postData := map[string]interface{}{
 		"drive_id":        driveId,
@@ -52,24 +53,29 @@ func (p *WebPanClient) Mkdir(driveId, parentFileId, dirName string) (*aliyunpan.
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("get file info error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
 	if err1 := apierror.ParseCommonApiError(body); err1 != nil {
 		return nil, err1
 	}
+	p.removeDirListFromCache(driveId, parentFileId)
 
 	// parse result
 	r := &aliyunpan.MkdirResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse file info result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
 
 func (p *WebPanClient) MkdirByFullPath(driveId, fullPath string) (*aliyunpan.MkdirResult, *apierror.ApiError) {
+	driveId, fullPath, apiErr := p.ResolveDrivePath(driveId, fullPath)
+	if apiErr != nil {
+		return nil, apiErr
+	}
 	fullPath = strings.ReplaceAll(fullPath, "//", "/")
 	pathSlice := strings.Split(fullPath, "/")
 	return p.MkdirRecursive(driveId, "", "", 0, pathSlice)
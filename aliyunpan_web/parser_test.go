@@ -0,0 +1,111 @@
+package aliyunpan_web
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
+)
+
+// newReplayClient 使用 testdata 下录制的响应创建客户端，不会访问网络
+func newReplayClient(t *testing.T, cassetteName string) *WebPanClient {
+	c, err := apitransport.LoadCassette(filepath.Join("testdata", cassetteName))
+	require.NoError(t, err)
+	p := NewWebPanClient(WebLoginToken{}, AppLoginToken{}, AppConfig{}, SessionConfig{},
+		apitransport.WithTransport(apitransport.NewReplayTransport(c)))
+	p.SetRetryPolicy(aliyunpan.NoRetryPolicy())
+	return p
+}
+
+func TestFileListFixture(t *testing.T) {
+	p := newReplayClient(t, "file_list.json")
+
+	r, err := p.FileList(&aliyunpan.FileListParam{
+		DriveId:      "11001",
+		ParentFileId: aliyunpan.DefaultRootParentFileId,
+		Limit:        2,
+	})
+	require.Nil(t, err)
+	require.Len(t, r.FileList, 2)
+	assert.Equal(t, "WyI2MzIwZDFhM2IyN2Q2ZjBhZDE5MzRmZTRhM2M0M2IyY2I0YWMyYzE1Il0", r.NextMarker)
+
+	folder := r.FileList[0]
+	assert.True(t, folder.IsFolder())
+	assert.Equal(t, "我的文档", folder.FileName)
+	assert.Equal(t, "我的文档", folder.Path)
+	assert.Equal(t, apiutil.UtcTime2LocalFormat("2022-09-14T02:10:00.000Z"), folder.UpdatedAt)
+
+	file := r.FileList[1]
+	assert.True(t, file.IsFile())
+	assert.Equal(t, int64(2736451), file.FileSize)
+	assert.Equal(t, "jpg", file.FileExtension)
+	assert.Equal(t, "image", file.Category)
+	assert.Equal(t, "0D4E6A2F8A9B1C3D5E7F90A1B2C3D4E5F6A7B8C9", file.ContentHash)
+	assert.Equal(t, "sha1", file.ContentHashName)
+	assert.Equal(t, "11838123485123450912", file.Crc64Hash)
+	assert.True(t, file.SyncFlag)
+
+	_, err = p.FileList(&aliyunpan.FileListParam{
+		DriveId:      "11001",
+		ParentFileId: "6320d19ec3a4b1d3a8c74d6f8d3e5b1b2e2f7a99",
+	})
+	require.NotNil(t, err)
+	assert.True(t, errors.Is(err, apierror.ErrNotFound))
+	assert.Equal(t, 404, err.HttpStatus)
+	assert.Equal(t, "0bc13b0116660546641984077e4b02", err.RequestId)
+}
+
+func TestListByShareFixture(t *testing.T) {
+	p := newReplayClient(t, "list_by_share.json")
+
+	r, err := p.GetListByShare("", "GSHjrcPt9pP", "")
+	require.Nil(t, err)
+	require.Len(t, r.Items, 2)
+	assert.Empty(t, r.NextMarker)
+
+	img := r.Items[0]
+	assert.Equal(t, "风景.png", img.Name)
+	assert.Equal(t, 1048576, img.Size)
+	assert.Equal(t, 1920, img.ImageMediaMetadata.Width)
+	assert.Equal(t, 1080, img.ImageMediaMetadata.Height)
+	assert.Equal(t, 2023, img.CreatedAt.Year())
+	assert.Equal(t, "folder", r.Items[1].Type)
+}
+
+func TestAlbumListFixture(t *testing.T) {
+	p := newReplayClient(t, "album_list.json")
+
+	r, err := p.AlbumList(&AlbumListParam{})
+	require.Nil(t, err)
+	require.Len(t, r.Items, 2)
+	assert.Equal(t, "旅行", r.Items[0].Name)
+	assert.Equal(t, 12, r.Items[0].FileCount)
+	assert.Equal(t, 2, r.Items[0].VideoCount)
+	assert.Equal(t, int64(1662998400000), r.Items[0].CreatedAt)
+	assert.True(t, r.Items[1].IsSharing)
+}
+
+func TestAlbumListFileFixture(t *testing.T) {
+	p := newReplayClient(t, "album_list_file.json")
+
+	r, err := p.AlbumListFile(&AlbumListFileParam{AlbumId: "a3d0bd7f6b2e4c5a8e9f1d2c3b4a5e6f", Limit: 2})
+	require.Nil(t, err)
+	require.Len(t, r.FileList, 2)
+	assert.Equal(t, "WyI2MzIxYThmOWQwZTJjM2Y0YjVhNmY3ZThkOWMwYjFhMmYzZTRkNWM2Il0", r.NextMarker)
+
+	live := r.FileList[0]
+	assert.Equal(t, "IMG_0002.livp", live.FileName)
+	assert.Equal(t, "33003", live.DriveId)
+	assert.Equal(t, int64(3145728), live.FileSize)
+	assert.Equal(t, "image", live.Category)
+	assert.Equal(t, 3024, live.ImageMediaMetadata.Width)
+	assert.Contains(t, live.Thumbnail, "x-oss-signature=REDACTED")
+	assert.Equal(t, "video", r.FileList[1].Category)
+	assert.Equal(t, apiutil.UtcTime2LocalFormat("2022-09-14T09:30:00.000Z"), r.FileList[1].CreatedAt)
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2020 tickstep.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aliyunpan_web
+
+import (
+	"strings"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+type (
+	// PlanClient 预演模式客户端。FileDelete、RecycleBinFileDelete、RecycleBinFileClear、FileMove、ShareLinkCancel
+	// 不会真正执行，只是连同受影响文件的路径、大小记录到计划中，检查无误后调用 Apply 执行。
+	// 方法签名和 WebPanClient 一致，返回值中的 Success 代表已经记录到计划中
+	PlanClient struct {
+		client *WebPanClient
+		plan   *aliyunpan.OperationPlan
+	}
+
+	// planOperator 实现 aliyunpan.PlanOperator
+	planOperator struct {
+		*WebPanClient
+	}
+)
+
+// NewPlanClient 创建预演模式客户端，查询类的接口请直接使用原客户端
+func NewPlanClient(client *WebPanClient) *PlanClient {
+	return &PlanClient{
+		client: client,
+		plan:   aliyunpan.NewOperationPlan(),
+	}
+}
+
+// Client 获取原客户端
+func (c *PlanClient) Client() *WebPanClient {
+	return c.client
+}
+
+// Plan 获取已经记录的计划
+func (c *PlanClient) Plan() *aliyunpan.OperationPlan {
+	return c.plan
+}
+
+// FileDelete 记录删除文件到回收站
+func (c *PlanClient) FileDelete(param []*aliyunpan.FileBatchActionParam) ([]*aliyunpan.FileBatchActionResult, *apierror.ApiError) {
+	return c.recordFileBatchAction(aliyunpan.PlanActionFileDelete, param)
+}
+
+// RecycleBinFileDelete 记录回收站彻底删除文件，可以是计划中之前的步骤放入回收站的文件
+func (c *PlanClient) RecycleBinFileDelete(param []*aliyunpan.FileBatchActionParam) ([]*aliyunpan.FileBatchActionResult, *apierror.ApiError) {
+	return c.recordFileBatchAction(aliyunpan.PlanActionRecycleBinFileDelete, param)
+}
+
+func (c *PlanClient) recordFileBatchAction(action aliyunpan.PlanAction, param []*aliyunpan.FileBatchActionParam) ([]*aliyunpan.FileBatchActionResult, *apierror.ApiError) {
+	if param == nil {
+		return nil, apierror.NewFailedApiError("参数不能为空")
+	}
+	items := []*aliyunpan.FileMoveParam{}
+	r := []*aliyunpan.FileBatchActionResult{}
+	for _, item := range param {
+		items = append(items, &aliyunpan.FileMoveParam{DriveId: item.DriveId, FileId: item.FileId})
+		r = append(r, &aliyunpan.FileBatchActionResult{
+			FileId:  item.FileId,
+			Success: true,
+		})
+	}
+	if err := c.plan.RecordFiles(planOperator{c.client}, action, items); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// RecycleBinFileClear 记录清空回收站，回收站中当前所有的文件和计划中之前的步骤放入回收站的文件都会记录到计划中
+func (c *PlanClient) RecycleBinFileClear(param *RecycleBinFileClearParam) (*RecycleBinFileClearResult, *apierror.ApiError) {
+	if param == nil {
+		return nil, apierror.NewFailedApiError("参数不能为空")
+	}
+	if err := c.plan.RecordRecycleBinClear(planOperator{c.client}, param.DriveId); err != nil {
+		return nil, err
+	}
+	return &RecycleBinFileClearResult{DriveId: param.DriveId}, nil
+}
+
+// FileMove 记录移动文件
+func (c *PlanClient) FileMove(param []*aliyunpan.FileMoveParam) ([]*aliyunpan.FileMoveResult, *apierror.ApiError) {
+	if param == nil {
+		return nil, apierror.NewFailedApiError("参数不能为空")
+	}
+	if err := c.plan.RecordFiles(planOperator{c.client}, aliyunpan.PlanActionFileMove, param); err != nil {
+		return nil, err
+	}
+	r := []*aliyunpan.FileMoveResult{}
+	for _, item := range param {
+		r = append(r, &aliyunpan.FileMoveResult{
+			FileId:  item.FileId,
+			Success: true,
+		})
+	}
+	return r, nil
+}
+
+// ShareLinkCancel 记录取消分享链接
+func (c *PlanClient) ShareLinkCancel(shareIdList []string) ([]*ShareCancelResult, *apierror.ApiError) {
+	if err := c.plan.RecordShareLinkCancel(planOperator{c.client}, shareIdList); err != nil {
+		return nil, err
+	}
+	r := []*ShareCancelResult{}
+	for _, shareId := range shareIdList {
+		r = append(r, &ShareCancelResult{
+			Id:      shareId,
+			Success: true,
+		})
+	}
+	return r, nil
+}
+
+// Apply 按记录的顺序执行计划，详见 aliyunpan.OperationPlan.Apply
+func (c *PlanClient) Apply() ([]*aliyunpan.PlanApplyResult, *apierror.ApiError) {
+	return c.plan.Apply(planOperator{c.client})
+}
+
+// FilePathById 获取文件的完整路径
+func (o planOperator) FilePathById(driveId, fileId string) (string, *apierror.ApiError) {
+	return o.filePathById(driveId, fileId)
+}
+
+// RecycleBinFiles 获取回收站中的所有文件
+func (o planOperator) RecycleBinFiles(driveId string) (aliyunpan.FileList, *apierror.ApiError) {
+	return o.RecycleBinFileListGetAll(&RecycleBinFileListParam{DriveId: driveId})
+}
+
+// ShareLinks 获取当前用户的所有分享
+func (o planOperator) ShareLinks() ([]*aliyunpan.ShareEntity, *apierror.ApiError) {
+	userId, err := o.currentUserId()
+	if err != nil {
+		return nil, err
+	}
+	return o.ShareLinkList(userId)
+}
+
+// ApplyPlanStep 执行计划中的一个步骤，文件操作都使用批量接口
+func (o planOperator) ApplyPlanStep(action aliyunpan.PlanAction, driveId string, targets []*aliyunpan.PlanTarget, clearAll bool) ([]*apierror.ApiError, *apierror.ApiError) {
+	success := map[string]bool{}
+	batchParam := []*aliyunpan.FileBatchActionParam{}
+	for _, t := range targets {
+		batchParam = append(batchParam, &aliyunpan.FileBatchActionParam{DriveId: t.DriveId, FileId: t.FileId})
+	}
+
+	var err *apierror.ApiError
+	var r []*aliyunpan.FileBatchActionResult
+	switch action {
+	case aliyunpan.PlanActionFileDelete:
+		r, err = o.FileDelete(batchParam)
+	case aliyunpan.PlanActionRecycleBinFileDelete:
+		r, err = o.RecycleBinFileDelete(batchParam)
+	case aliyunpan.PlanActionRecycleBinFileClear:
+		if !clearAll {
+			r, err = o.RecycleBinFileDelete(batchParam)
+			break
+		}
+		if _, err = o.RecycleBinFileClear(&RecycleBinFileClearParam{DriveId: driveId}); err == nil {
+			for _, t := range targets {
+				success[t.FileId] = true
+			}
+		}
+	case aliyunpan.PlanActionFileMove:
+		moveParam := []*aliyunpan.FileMoveParam{}
+		for _, t := range targets {
+			moveParam = append(moveParam, &aliyunpan.FileMoveParam{
+				DriveId:        t.DriveId,
+				FileId:         t.FileId,
+				ToDriveId:      t.ToDriveId,
+				ToParentFileId: t.ToParentFileId,
+			})
+		}
+		var mr []*aliyunpan.FileMoveResult
+		if mr, err = o.FileMove(moveParam); err == nil {
+			for _, item := range mr {
+				success[item.FileId] = item.Success
+			}
+		}
+	case aliyunpan.PlanActionShareLinkCancel:
+		shareIdList := []string{}
+		for _, t := range targets {
+			shareIdList = append(shareIdList, t.ShareId)
+		}
+		var sr []*ShareCancelResult
+		if sr, err = o.ShareLinkCancel(shareIdList); err == nil {
+			errs := make([]*apierror.ApiError, len(targets))
+			results := map[string]*ShareCancelResult{}
+			for _, item := range sr {
+				results[item.Id] = item
+			}
+			for i, t := range targets {
+				if item, ok := results[t.ShareId]; !ok || !item.Success {
+					errs[i] = apierror.NewFailedApiError("操作失败：" + t.ShareId)
+					if ok && item.Err != nil {
+						errs[i] = item.Err
+					}
+				}
+			}
+			return errs, nil
+		}
+	default:
+		return nil, apierror.NewFailedApiError("不支持的操作：" + string(action))
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range r {
+		success[item.FileId] = item.Success
+	}
+	errs := make([]*apierror.ApiError, len(targets))
+	for i, t := range targets {
+		if !success[t.FileId] {
+			errs[i] = apierror.NewFailedApiError("操作失败：" + t.FileId)
+		}
+	}
+	return errs, nil
+}
+
+// filePathById 通过fileId获取文件的完整路径
+func (p *WebPanClient) filePathById(driveId, fileId string) (string, *apierror.ApiError) {
+	r, err := p.FileGetPath(driveId, fileId)
+	if err != nil {
+		return "", err
+	}
+	names := []string{}
+	for i := len(r.Items) - 1; i >= 0; i-- {
+		if r.Items[i].FileId == aliyunpan.DefaultRootParentFileId {
+			continue
+		}
+		names = append(names, r.Items[i].Name)
+	}
+	return "/" + strings.Join(names, "/"), nil
+}
@@ -0,0 +1,110 @@
+package aliyunpan_web
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+func TestPlanClient(t *testing.T) {
+	d := newFakeWebDrive()
+	d.add("docs", "root", "docs", "folder", 0)
+	d.add("archive", "root", "archive", "folder", 0)
+	d.add("a", "docs", "a.txt", "file", 100)
+	d.add("b", "docs", "b.txt", "file", 200)
+	d.add("c", "docs", "c.txt", "file", 300)
+	d.add("old", "root", "old.txt", "file", 50)
+	d.trashed["old"] = true
+
+	p := newFakeWebClient(d)
+	c := NewPlanClient(p)
+
+	r, err := c.FileDelete([]*aliyunpan.FileBatchActionParam{
+		{DriveId: "11001", FileId: "a"},
+		{DriveId: "11001", FileId: "b"},
+	})
+	require.Nil(t, err)
+	assert.True(t, r[0].Success)
+	_, err = c.FileMove([]*aliyunpan.FileMoveParam{
+		{DriveId: "11001", FileId: "c", ToParentFileId: "archive"},
+	})
+	require.Nil(t, err)
+	_, err = c.RecycleBinFileClear(&RecycleBinFileClearParam{DriveId: "11001"})
+	require.Nil(t, err)
+	_, err = c.FileDelete([]*aliyunpan.FileBatchActionParam{{DriveId: "11001", FileId: "missing"}})
+	assert.True(t, errors.Is(err, apierror.ErrNotFound))
+
+	// 预演不会修改网盘，清空回收站包括之前的步骤放入回收站的文件
+	plan := c.Plan()
+	assert.Equal(t, 3, plan.Len())
+	assert.Equal(t, 6, plan.TargetCount())
+	assert.Equal(t, int64(950), plan.TotalSize())
+	steps := plan.Steps()
+	assert.Equal(t, "/docs/a.txt", steps[0].Targets[0].Path)
+	assert.Equal(t, "/archive", steps[1].Targets[0].ToPath)
+	assert.Equal(t, "old", steps[2].Targets[0].FileId)
+	assert.Contains(t, plan.String(), "file_move /docs/c.txt -> /archive size=300")
+	assert.Equal(t, 0, d.count("/adrive/v4/batch"))
+	assert.Len(t, d.trashed, 1)
+
+	// 预演之后文件被修改过，不能执行
+	d.files["b"].Size = 201
+	d.add("new", "root", "new.txt", "file", 10)
+	d.trashed["new"] = true
+
+	results, err := c.Apply()
+	require.Nil(t, err)
+	require.Len(t, results, 6)
+	assert.True(t, results[0].Success)
+	assert.False(t, results[1].Success)
+	assert.True(t, errors.Is(results[1].Err, apierror.ErrPlanTargetChanged))
+	assert.True(t, results[2].Success)
+	assert.Equal(t, "archive", d.files["c"].ParentFileId)
+	// 回收站中有新的文件，只彻底删除预演时记录的文件，没有放入回收站的 b 不会被删除
+	assert.True(t, results[3].Success)
+	assert.True(t, results[4].Success)
+	assert.Equal(t, "a", results[4].Target.FileId)
+	assert.True(t, errors.Is(results[5].Err, apierror.ErrPlanTargetChanged))
+	assert.Equal(t, 0, d.count("/v2/recyclebin/clear"))
+	assert.NotContains(t, d.files, "old")
+	assert.NotContains(t, d.files, "a")
+	assert.True(t, d.trashed["new"])
+	assert.False(t, d.trashed["b"])
+	assert.Contains(t, d.files, "b")
+}
+
+func TestPlanClientChainedSteps(t *testing.T) {
+	d := newFakeWebDrive()
+	d.add("docs", "root", "docs", "folder", 0)
+	d.add("archive", "root", "archive", "folder", 0)
+	d.add("a", "docs", "a.txt", "file", 100)
+	p := newFakeWebClient(d)
+	c := NewPlanClient(p)
+
+	// 先移动再删除，删除的目标是移动之后的预期状态
+	_, err := c.FileMove([]*aliyunpan.FileMoveParam{{DriveId: "11001", FileId: "a", ToParentFileId: "archive"}})
+	require.Nil(t, err)
+	_, err = c.FileDelete([]*aliyunpan.FileBatchActionParam{{DriveId: "11001", FileId: "a"}})
+	require.Nil(t, err)
+	steps := c.Plan().Steps()
+	assert.Equal(t, "/archive/a.txt", steps[1].Targets[0].Path)
+	assert.Equal(t, "archive", steps[1].Targets[0].ParentFileId)
+
+	// 已经放入回收站的文件不能再移动
+	_, err = c.FileMove([]*aliyunpan.FileMoveParam{{DriveId: "11001", FileId: "a", ToParentFileId: "docs"}})
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, c.Plan().Len())
+
+	// 移动会更新文件的修改时间，删除时不再比较
+	results, err := c.Apply()
+	require.Nil(t, err)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Success)
+	assert.True(t, results[1].Success)
+	assert.True(t, d.trashed["a"])
+	assert.Equal(t, "archive", d.files["a"].ParentFileId)
+}
@@ -0,0 +1,252 @@
+// Copyright (c) 2020 tickstep.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aliyunpan_web
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
+	"github.com/tickstep/library-go/logger"
+)
+
+const (
+	// shareTokenRefreshAhead 分享Token在过期前多久重新获取
+	shareTokenRefreshAhead = time.Minute
+)
+
+type (
+	// shareTokenCacheItem 分享Token缓存项
+	shareTokenCacheItem struct {
+		sharePwd   string
+		shareToken string
+		expireTime time.Time
+	}
+
+	shareDownloadUrlResult struct {
+		DownloadUrl string `json:"download_url"`
+		Url         string `json:"url"`
+		Expiration  string `json:"expiration"`
+	}
+)
+
+// GetShareTokenCached 获取分享Token，优先使用缓存。缓存的Token会在 GetShareTokenResult.ExpireTime 之前自动刷新，提取码变化也会重新获取
+func (p *WebPanClient) GetShareTokenCached(shareID, sharePwd string) (string, *apierror.ApiError) {
+	if v, ok := p.shareTokenCache.Load(shareID); ok {
+		item := v.(*shareTokenCacheItem)
+		if item.sharePwd == sharePwd && time.Now().Add(shareTokenRefreshAhead).Before(item.expireTime) {
+			return item.shareToken, nil
+		}
+	}
+	r, err := p.GetShareToken(shareID, sharePwd)
+	if err != nil {
+		return "", err
+	}
+	expireTime := r.ExpireTime
+	if expireTime.IsZero() {
+		expireTime = time.Now().Add(time.Duration(r.ExpiresIn) * time.Second)
+	}
+	p.shareTokenCache.Store(shareID, &shareTokenCacheItem{
+		sharePwd:   sharePwd,
+		shareToken: r.ShareToken,
+		expireTime: expireTime,
+	})
+	return r.ShareToken, nil
+}
+
+// InvalidateShareToken 删除缓存的分享Token
+func (p *WebPanClient) InvalidateShareToken(shareID string) {
+	p.shareTokenCache.Delete(shareID)
+}
+
+// ShareFileList 获取分享中指定文件夹下的所有文件，parentFileId 为空或者 root 代表分享的根目录
+func (p *WebPanClient) ShareFileList(shareID, sharePwd, parentFileId string) (aliyunpan.FileList, *apierror.ApiError) {
+	fileList := aliyunpan.FileList{}
+	marker := ""
+	for {
+		token, err := p.GetShareTokenCached(shareID, sharePwd)
+		if err != nil {
+			return nil, err
+		}
+		r, err := p.GetListByShareFolder(token, shareID, parentFileId, marker)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range r.Items {
+			fileList = append(fileList, createShareFileEntity(item))
+		}
+		if r.NextMarker == "" {
+			return fileList, nil
+		}
+		marker = r.NextMarker
+	}
+}
+
+// ShareFileInfoByPath 通过分享中的路径获取文件信息，pathStr 是相对分享根目录的绝对路径，例如：/电影/a.mkv
+func (p *WebPanClient) ShareFileInfoByPath(shareID, sharePwd, pathStr string) (*aliyunpan.FileEntity, *apierror.ApiError) {
+	pathStr = path.Clean("/" + strings.ReplaceAll(pathStr, "\\", "/"))
+	current := aliyunpan.NewFileEntityForRootDir()
+	if pathStr == "/" {
+		return current, nil
+	}
+	for _, name := range strings.Split(strings.TrimPrefix(pathStr, "/"), "/") {
+		if !current.IsFolder() {
+			return nil, apierror.NewApiError(apierror.ApiCodeFileNotFoundCode, "文件不存在："+pathStr)
+		}
+		fileList, err := p.ShareFileList(shareID, sharePwd, current.FileId)
+		if err != nil {
+			return nil, err
+		}
+		var next *aliyunpan.FileEntity
+		for _, f := range fileList {
+			if f.FileName == name {
+				next = f
+				break
+			}
+		}
+		if next == nil {
+			return nil, apierror.NewApiError(apierror.ApiCodeFileNotFoundCode, "文件不存在："+pathStr)
+		}
+		next.Path = path.Join(current.Path, name)
+		current = next
+	}
+	return current, nil
+}
+
+// ShareFileInfoById 通过分享中的文件ID获取文件信息
+func (p *WebPanClient) ShareFileInfoById(shareID, sharePwd, fileId string) (*aliyunpan.FileEntity, *apierror.ApiError) {
+	item, err := p.getShareItem(shareID, sharePwd, fileId)
+	if err != nil {
+		return nil, err
+	}
+	return createShareFileEntity(item), nil
+}
+
+func (p *WebPanClient) getShareItem(shareID, sharePwd, fileId string) (*ListByShareItem, *apierror.ApiError) {
+	postData := map[string]interface{}{
+		"share_id": shareID,
+		"file_id":  fileId,
+	}
+	r := &ListByShareItem{}
+	if err := p.shareRequest(shareID, sharePwd, "/adrive/v2/file/get_by_share", postData, r); err != nil {
+		return nil, err
+	}
+	if r.FileID == "" {
+		return nil, apierror.NewApiError(apierror.ApiCodeFileNotFoundCode, "文件不存在："+fileId)
+	}
+	return r, nil
+}
+
+// ShareGetFileDownloadUrl 通过分享Token获取分享中文件的下载链接，不需要先保存到自己的网盘。expireSec 小于等于0时默认600秒
+func (p *WebPanClient) ShareGetFileDownloadUrl(shareID, sharePwd, fileId string, expireSec int) (*aliyunpan.GetFileDownloadUrlResult, *apierror.ApiError) {
+	if expireSec <= 0 {
+		expireSec = 600
+	}
+	postData := map[string]interface{}{
+		"share_id":   shareID,
+		"file_id":    fileId,
+		"expire_sec": expireSec,
+	}
+	r := &shareDownloadUrlResult{}
+	if err := p.shareRequest(shareID, sharePwd, "/v2/file/get_share_link_download_url", postData, r); err != nil {
+		return nil, err
+	}
+	result := &aliyunpan.GetFileDownloadUrlResult{
+		Method:     "GET",
+		Url:        r.DownloadUrl,
+		Expiration: apiutil.UtcTime2LocalFormat(r.Expiration),
+	}
+	if result.Url == "" {
+		result.Url = r.Url
+	}
+	return result, nil
+}
+
+// ShareVideoGetPreviewPlayInfo 通过分享Token获取分享中视频的转码播放信息
+func (p *WebPanClient) ShareVideoGetPreviewPlayInfo(shareID, sharePwd, fileId string) (*aliyunpan.VideoGetPreviewPlayInfoResult, *apierror.ApiError) {
+	postData := map[string]interface{}{
+		"share_id":        shareID,
+		"file_id":         fileId,
+		"category":        "live_transcoding",
+		"get_preview_url": true,
+		"template_id":     "",
+	}
+	r := &aliyunpan.VideoGetPreviewPlayInfoResult{}
+	if err := p.shareRequest(shareID, sharePwd, "/v2/file/get_video_preview_play_info_by_share", postData, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// shareRequest 带上分享Token请求接口并解析结果
+func (p *WebPanClient) shareRequest(shareID, sharePwd, apiPath string, postData map[string]interface{}, result interface{}) *apierror.ApiError {
+	token, apiErr := p.GetShareTokenCached(shareID, sharePwd)
+	if apiErr != nil {
+		return apiErr
+	}
+	header := map[string]string{
+		"authorization": p.webToken.GetAuthorizationStr(),
+		"x-share-token": token,
+	}
+
+	fullUrl := &strings.Builder{}
+	fmt.Fprintf(fullUrl, "%s%s", API_URL, apiPath)
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
+
+	// request
+	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
+	if err != nil {
+		logger.Verboseln("share request error ", err)
+		return apierror.NewFailedApiErrorWithCause(err)
+	}
+
+	// handler common error
+	if err1 := apierror.ParseCommonApiError(body); err1 != nil {
+		return err1
+	}
+
+	// parse result
+	if err2 := json.Unmarshal(body, result); err2 != nil {
+		logger.Verboseln("parse share response json error ", err2)
+		return apierror.NewFailedApiErrorWithCause(err2)
+	}
+	return nil
+}
+
+func createShareFileEntity(item *ListByShareItem) *aliyunpan.FileEntity {
+	if item == nil {
+		return nil
+	}
+	return &aliyunpan.FileEntity{
+		DriveId:       item.DriveID,
+		DomainId:      item.DomainID,
+		FileId:        item.FileID,
+		FileName:      item.Name,
+		FileSize:      int64(item.Size),
+		FileType:      item.Type,
+		CreatedAt:     apiutil.UnixTime2LocalFormat(item.CreatedAt.UnixNano() / 1e6),
+		UpdatedAt:     apiutil.UnixTime2LocalFormat(item.UpdatedAt.UnixNano() / 1e6),
+		FileExtension: item.FileExtension,
+		ParentFileId:  item.ParentFileID,
+		Category:      item.Category,
+		Thumbnail:     item.Thumbnail,
+	}
+}
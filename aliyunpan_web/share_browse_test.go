@@ -0,0 +1,51 @@
+package aliyunpan_web
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+func TestShareBrowse(t *testing.T) {
+	d := newFakeWebDrive()
+	d.addShare("s1", "root", "movies", "folder", 0)
+	d.addShare("s2", "s1", "a.mkv", "file", 100)
+	d.addShare("s3", "root", "readme.txt", "file", 1)
+	p := newFakeWebClient(d)
+
+	root, err := p.ShareFileList("GSHjrcPt9pP", "8a2b", "")
+	require.Nil(t, err)
+	require.Len(t, root, 2)
+	assert.True(t, root[0].IsFolder())
+	assert.Equal(t, "readme.txt", root[1].FileName)
+
+	f, err := p.ShareFileInfoByPath("GSHjrcPt9pP", "8a2b", "/movies/a.mkv")
+	require.Nil(t, err)
+	assert.Equal(t, "s2", f.FileId)
+	assert.Equal(t, "/movies/a.mkv", f.Path)
+	assert.Equal(t, int64(100), f.FileSize)
+	_, err = p.ShareFileInfoByPath("GSHjrcPt9pP", "8a2b", "/readme.txt/x")
+	assert.True(t, errors.Is(err, apierror.ErrNotFound))
+
+	u, err := p.ShareGetFileDownloadUrl("GSHjrcPt9pP", "8a2b", "s2", 0)
+	require.Nil(t, err)
+	assert.Equal(t, "https://download.fake/s2", u.Url)
+	info, err := p.ShareVideoGetPreviewPlayInfo("GSHjrcPt9pP", "8a2b", "s2")
+	require.Nil(t, err)
+	assert.Equal(t, "https://play.fake/FHD.m3u8", info.VideoPreviewPlayInfo.LiveTranscodingTaskList[0].URL)
+	// 分享Token只获取了一次
+	assert.Equal(t, 1, d.count("/v2/share_link/get_share_token"))
+
+	// Token 快要过期时重新获取
+	d.shareTokenExpire = time.Now().Add(30 * time.Second)
+	p.InvalidateShareToken("GSHjrcPt9pP")
+	_, err = p.ShareFileList("GSHjrcPt9pP", "8a2b", "s1")
+	require.Nil(t, err)
+	_, err = p.ShareFileList("GSHjrcPt9pP", "8a2b", "s1")
+	require.Nil(t, err)
+	assert.Equal(t, 3, d.count("/v2/share_link/get_share_token"))
+}
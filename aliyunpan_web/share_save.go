@@ -0,0 +1,477 @@
+// Copyright (c) 2020 tickstep.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aliyunpan_web
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/library-go/logger"
+)
+
+const (
+	// DefaultShareSaveBatchSize 转存分享时每次批量复制的文件数量
+	DefaultShareSaveBatchSize = 50
+)
+
+type (
+	// ShareSaveOptions 转存分享选项
+	ShareSaveOptions struct {
+		// ToDriveId 保存到的网盘ID，为空则使用备份（文件）网盘。目标路径带网盘别名时以别名为准
+		ToDriveId string `json:"to_drive_id"`
+		// Include 只保存匹配的文件，为空代表所有文件。通配符不包含 / 时匹配文件名，否则匹配分享中的相对路径
+		Include []string `json:"include"`
+		// Exclude 不保存匹配的文件，规则同 Include
+		Exclude []string `json:"exclude"`
+		// MinSize 不保存小于该大小的文件
+		MinSize int64 `json:"min_size"`
+		// MaxSize 不保存大于该大小的文件，小于等于0代表不限制
+		MaxSize int64 `json:"max_size"`
+		// ConflictMode 目标文件夹已有同名文件时的处理方式，默认自动重命名。同名文件夹总是合并。
+		// 覆盖时先复制为自动重命名的文件，复制成功后才把原文件放入回收站并改回原来的文件名
+		ConflictMode aliyunpan.FileConflictMode `json:"conflict_mode"`
+		// BatchSize 每次批量复制的文件数量，默认 DefaultShareSaveBatchSize
+		BatchSize int `json:"batch_size"`
+	}
+
+	// ShareSaveItem 转存单个文件或者文件夹的结果
+	ShareSaveItem struct {
+		// SourcePath 文件在分享中的相对路径
+		SourcePath string `json:"source_path"`
+		// SourceFileId 文件在分享中的ID，新建的文件夹为空
+		SourceFileId string `json:"source_file_id"`
+		// IsFolder 是否是文件夹，文件夹是在网盘中新建的
+		IsFolder bool `json:"is_folder"`
+		// FileSize 文件大小
+		FileSize int64 `json:"file_size"`
+		// TargetPath 保存到网盘中的路径，自动重命名时为重命名前的路径
+		TargetPath string `json:"target_path"`
+		// FileId 保存后的文件ID
+		FileId string `json:"file_id"`
+		// Success 是否成功
+		Success bool `json:"success"`
+		// Skipped 是否因为同名文件冲突而跳过
+		Skipped bool `json:"skipped"`
+		// Err 失败原因
+		Err *apierror.ApiError `json:"-"`
+	}
+
+	shareSaver struct {
+		p        *WebPanClient
+		shareId  string
+		sharePwd string
+		driveId  string
+		rootPath string
+		options  *ShareSaveOptions
+
+		// folders 分享中的相对目录到网盘中文件夹ID的映射
+		folders map[string]string
+		// children 网盘中文件夹下已有的文件
+		children map[string]map[string]*aliyunpan.FileEntity
+		pending  []*shareSavePending
+		items    []*ShareSaveItem
+		// overwrites 复制成功后需要放入回收站的同名文件
+		overwrites map[*ShareSaveItem]*aliyunpan.FileEntity
+	}
+
+	shareSavePending struct {
+		param *FileSaveParam
+		item  *ShareSaveItem
+	}
+)
+
+// SaveShare 把公开分享中的文件递归保存到网盘的 targetPath 文件夹下，目标文件夹不存在会自动创建。
+// shareURL 可以是分享链接、带提取码的分享文本或者分享ID，pwd 为空时使用链接中的提取码。
+// 链接指向分享中的文件夹时保存该文件夹下的内容，指向单个文件（/file/<id>）时只保存该文件。
+// 分享中的文件夹结构会在网盘中重建，文件按照 BatchSize 批量复制并等待异步任务完成，返回每个文件的处理结果
+func (p *WebPanClient) SaveShare(shareURL, pwd, targetPath string, options *ShareSaveOptions) ([]*ShareSaveItem, *apierror.ApiError) {
+	info, err := aliyunpan.ParseShareUrl(shareURL)
+	if err != nil {
+		return nil, err
+	}
+	if pwd == "" {
+		pwd = info.SharePwd
+	}
+	opts := ShareSaveOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.ConflictMode == "" {
+		opts.ConflictMode = aliyunpan.FileConflictAutoRename
+	}
+	if !opts.ConflictMode.IsValid() {
+		return nil, apierror.NewFailedApiError("不支持的冲突处理方式：" + string(opts.ConflictMode))
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultShareSaveBatchSize
+	}
+
+	driveId, targetPath, err := p.ResolveDrivePath(opts.ToDriveId, targetPath)
+	if err != nil {
+		return nil, err
+	}
+	if driveId == "" {
+		userInfo, err := p.GetUserInfo()
+		if err != nil {
+			return nil, err
+		}
+		driveId = userInfo.FileDriveId
+	}
+	targetPath = path.Clean("/" + targetPath)
+	rootFolderId := aliyunpan.DefaultRootParentFileId
+	if targetPath != "/" {
+		r, err := p.MkdirByFullPath(driveId, targetPath)
+		if err != nil {
+			return nil, err
+		}
+		rootFolderId = r.FileId
+	}
+
+	s := &shareSaver{
+		p:        p,
+		shareId:  info.ShareId,
+		sharePwd: pwd,
+		driveId:  driveId,
+		rootPath: targetPath,
+		options:  &opts,
+		folders:  map[string]string{"": rootFolderId},
+		children: map[string]map[string]*aliyunpan.FileEntity{},
+
+		overwrites: map[*ShareSaveItem]*aliyunpan.FileEntity{},
+	}
+	if info.FileId != "" {
+		// 单个文件的分享链接只保存该文件，文件夹连同文件夹本身一起保存
+		item, err := p.getShareItem(info.ShareId, pwd, info.FileId)
+		if err != nil {
+			return nil, err
+		}
+		if item.Type == "folder" {
+			err = s.walk(item.FileID, item.Name)
+		} else if s.options.selected(item.Name, item.Name, int64(item.Size)) {
+			err = s.enqueue(item, "", item.Name)
+		}
+		if err != nil {
+			return s.items, err
+		}
+	} else {
+		folderId := info.FolderId
+		if folderId == "" {
+			folderId = aliyunpan.DefaultRootParentFileId
+		}
+		err = s.walk(folderId, "")
+	}
+	if err != nil {
+		return s.items, err
+	}
+	if err = s.flush(); err != nil {
+		return s.items, err
+	}
+	return s.items, nil
+}
+
+// token 获取分享Token，使用客户端的分享Token缓存，快过期时会自动重新获取
+func (s *shareSaver) token() (string, *apierror.ApiError) {
+	return s.p.GetShareTokenCached(s.shareId, s.sharePwd)
+}
+
+// walk 遍历分享中的文件夹，分页获取直到没有 NextMarker
+func (s *shareSaver) walk(parentFileId, relDir string) *apierror.ApiError {
+	marker := ""
+	for {
+		token, err := s.token()
+		if err != nil {
+			return err
+		}
+		r, err := s.p.GetListByShareFolder(token, s.shareId, parentFileId, marker)
+		if err != nil {
+			return err
+		}
+		for _, item := range r.Items {
+			rel := path.Join(relDir, item.Name)
+			if item.Type == "folder" {
+				if err = s.walk(item.FileID, rel); err != nil {
+					return err
+				}
+				continue
+			}
+			if !s.options.selected(rel, item.Name, int64(item.Size)) {
+				continue
+			}
+			if err = s.enqueue(item, relDir, rel); err != nil {
+				return err
+			}
+		}
+		if r.NextMarker == "" {
+			return nil
+		}
+		marker = r.NextMarker
+	}
+}
+
+// enqueue 处理同名文件冲突并加入待复制队列，队列满了就批量复制
+func (s *shareSaver) enqueue(source *ListByShareItem, relDir, rel string) *apierror.ApiError {
+	// 先创建文件夹，保证结果中文件夹排在里面的文件之前
+	parentId, err := s.ensureFolder(relDir)
+	item := &ShareSaveItem{
+		SourcePath:   rel,
+		SourceFileId: source.FileID,
+		FileSize:     int64(source.Size),
+		TargetPath:   path.Join(s.rootPath, rel),
+	}
+	s.items = append(s.items, item)
+	if err != nil {
+		item.Err = err
+		return nil
+	}
+	children, err := s.listChildren(parentId)
+	if err != nil {
+		item.Err = err
+		return nil
+	}
+	if existing, ok := children[source.Name]; ok {
+		switch s.options.ConflictMode {
+		case aliyunpan.FileConflictSkip:
+			item.Skipped = true
+			item.FileId = existing.FileId
+			return nil
+		case aliyunpan.FileConflictOverwrite:
+			if existing.IsFolder() {
+				item.Err = apierror.NewApiError(apierror.ApiCodeFileAlreadyExisted, "不能覆盖同名文件夹："+item.TargetPath)
+				return nil
+			}
+			// 复制成功后再删除同名文件
+			s.overwrites[item] = existing
+		}
+	}
+
+	s.pending = append(s.pending, &shareSavePending{
+		param: &FileSaveParam{
+			ShareID:        s.shareId,
+			FileId:         source.FileID,
+			AutoRename:     true,
+			ToDriveId:      s.driveId,
+			ToParentFileId: parentId,
+		},
+		item: item,
+	})
+	if len(s.pending) >= s.options.BatchSize {
+		return s.flush()
+	}
+	return nil
+}
+
+// flush 批量复制队列中的文件，并等待异步任务完成
+func (s *shareSaver) flush() *apierror.ApiError {
+	if len(s.pending) == 0 {
+		return nil
+	}
+	pending := s.pending
+	s.pending = nil
+
+	token, err := s.token()
+	if err != nil {
+		return err
+	}
+	params := []*FileSaveParam{}
+	for _, v := range pending {
+		params = append(params, v.param)
+	}
+	results, err := s.p.FileCopy(token, params)
+	if err != nil {
+		for _, v := range pending {
+			v.item.Err = err
+		}
+		return err
+	}
+
+	tasks := map[string]*ShareSaveItem{}
+	for i, v := range pending {
+		if i >= len(results) {
+			v.item.Err = apierror.NewFailedApiError("没有返回复制结果")
+			continue
+		}
+		r := results[i]
+		if r.Status < 200 || r.Status >= 300 {
+			v.item.Err = apierror.NewFailedApiError("复制文件失败：" + v.item.SourcePath)
+			continue
+		}
+		v.item.FileId = r.FileId
+		if r.AsyncTaskId != "" {
+			tasks[r.AsyncTaskId] = v.item
+			continue
+		}
+		s.succeed(v.item)
+	}
+	return s.waitTasks(tasks)
+}
+
+// waitTasks 等待复制的异步任务完成
+func (s *shareSaver) waitTasks(tasks map[string]*ShareSaveItem) *apierror.ApiError {
+	deadline := time.Now().Add(aliyunpan.AsyncTaskWaitTimeout)
+	for len(tasks) > 0 {
+		token, err := s.token()
+		if err != nil {
+			return err
+		}
+		ids := []string{}
+		for id := range tasks {
+			ids = append(ids, id)
+		}
+		results, err := s.p.AsyncTaskGet(token, ids)
+		if err != nil {
+			return err
+		}
+		for _, r := range results {
+			item, ok := tasks[r.AsyncTaskId]
+			if !ok {
+				continue
+			}
+			switch r.State {
+			case aliyunpan.AsyncTaskStateSucceed:
+				s.succeed(item)
+				delete(tasks, r.AsyncTaskId)
+			case aliyunpan.AsyncTaskStateFailed:
+				item.Err = apierror.NewFailedApiError("异步任务执行失败: " + r.AsyncTaskId)
+				delete(tasks, r.AsyncTaskId)
+			}
+		}
+		if len(tasks) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			for id, item := range tasks {
+				item.Err = apierror.NewFailedApiError("等待异步任务超时: " + id)
+			}
+			return nil
+		}
+		time.Sleep(aliyunpan.AsyncTaskPollInterval)
+	}
+	return nil
+}
+
+// succeed 文件复制成功，覆盖模式下把同名文件放入回收站，再把自动重命名的文件改回原来的文件名
+func (s *shareSaver) succeed(item *ShareSaveItem) {
+	existing, ok := s.overwrites[item]
+	if !ok {
+		item.Success = true
+		return
+	}
+	delete(s.overwrites, item)
+	r, err := s.p.FileDelete([]*aliyunpan.FileBatchActionParam{{DriveId: s.driveId, FileId: existing.FileId}})
+	if err == nil && (len(r) == 0 || !r[0].Success) {
+		err = apierror.NewFailedApiError("删除同名文件失败：" + item.TargetPath)
+	}
+	if err != nil {
+		item.Err = err
+		return
+	}
+	f, err := s.p.FileInfoById(s.driveId, item.FileId)
+	if err != nil {
+		item.Err = err
+		return
+	}
+	if f.FileName != existing.FileName {
+		if _, err = s.p.FileRename(s.driveId, item.FileId, existing.FileName); err != nil {
+			item.Err = err
+			return
+		}
+	}
+	item.Success = true
+}
+
+// ensureFolder 获取分享中的相对目录在网盘中对应的文件夹ID，不存在则创建，已有同名文件夹则合并
+func (s *shareSaver) ensureFolder(relDir string) (string, *apierror.ApiError) {
+	if id, ok := s.folders[relDir]; ok {
+		return id, nil
+	}
+	parentRel := path.Dir(relDir)
+	if parentRel == "." {
+		parentRel = ""
+	}
+	parentId, err := s.ensureFolder(parentRel)
+	if err != nil {
+		return "", err
+	}
+	children, err := s.listChildren(parentId)
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(relDir)
+	if existing, ok := children[name]; ok && existing.IsFolder() {
+		s.folders[relDir] = existing.FileId
+		return existing.FileId, nil
+	}
+
+	item := &ShareSaveItem{
+		SourcePath: relDir,
+		IsFolder:   true,
+		TargetPath: path.Join(s.rootPath, relDir),
+	}
+	s.items = append(s.items, item)
+	r, err := s.p.Mkdir(s.driveId, parentId, name)
+	if err != nil {
+		logger.Verboseln("share save mkdir error ", item.TargetPath, err)
+		item.Err = err
+		return "", err
+	}
+	item.FileId = r.FileId
+	item.Success = true
+	s.folders[relDir] = r.FileId
+	s.children[r.FileId] = map[string]*aliyunpan.FileEntity{}
+	return r.FileId, nil
+}
+
+func (s *shareSaver) listChildren(folderId string) (map[string]*aliyunpan.FileEntity, *apierror.ApiError) {
+	if children, ok := s.children[folderId]; ok {
+		return children, nil
+	}
+	fileList, err := s.p.FileListGetAll(&aliyunpan.FileListParam{DriveId: s.driveId, ParentFileId: folderId}, 0)
+	if err != nil {
+		return nil, err
+	}
+	children := map[string]*aliyunpan.FileEntity{}
+	for _, f := range fileList {
+		children[f.FileName] = f
+	}
+	s.children[folderId] = children
+	return children, nil
+}
+
+// selected 文件是否需要保存
+func (o *ShareSaveOptions) selected(rel, name string, size int64) bool {
+	if size < o.MinSize || (o.MaxSize > 0 && size > o.MaxSize) {
+		return false
+	}
+	if len(o.Include) > 0 && !matchSharePattern(o.Include, rel, name) {
+		return false
+	}
+	return !matchSharePattern(o.Exclude, rel, name)
+}
+
+func matchSharePattern(patterns []string, rel, name string) bool {
+	for _, pattern := range patterns {
+		target := name
+		if strings.Contains(pattern, "/") {
+			target = rel
+		}
+		if matched, _ := path.Match(pattern, target); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package aliyunpan_web
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+)
+
+func TestSaveShare(t *testing.T) {
+	d := newFakeWebDrive()
+	d.add("movies", "root", "movies", "folder", 0)
+	d.add("exist", "movies", "a.mkv", "file", 10)
+	d.addShare("s1", "root", "a.mkv", "file", 100)
+	d.addShare("s2", "root", "readme.txt", "file", 1)
+	d.addShare("s3", "root", "season1", "folder", 0)
+	d.addShare("s4", "s3", "extra", "folder", 0)
+	d.addShare("s5", "s4", "notes.txt", "file", 2)
+	for i := 1; i <= 120; i++ {
+		d.addShare(fmt.Sprintf("e%03d", i), "s3", fmt.Sprintf("e%03d.mkv", i), "file", 1000)
+	}
+	p := newFakeWebClient(d)
+
+	items, err := p.SaveShare("https://www.alipan.com/s/GSHjrcPt9pP 提取码: 8a2b", "", "/movies", &ShareSaveOptions{
+		ToDriveId:    "11001",
+		Include:      []string{"*.mkv"},
+		ConflictMode: aliyunpan.FileConflictSkip,
+		BatchSize:    50,
+	})
+	require.Nil(t, err)
+	// 1个已存在的文件 + 120个剧集 + 新建的 season1 文件夹，空的 extra 文件夹不会创建
+	require.Len(t, items, 122)
+	saved := 0
+	for _, item := range items {
+		require.Nil(t, item.Err, item.SourcePath)
+		if item.Success && !item.IsFolder {
+			saved++
+		}
+	}
+	assert.Equal(t, 120, saved)
+	assert.True(t, items[0].Skipped)
+	assert.Equal(t, "exist", items[0].FileId)
+	assert.True(t, items[1].IsFolder)
+	assert.Equal(t, "/movies/season1", items[1].TargetPath)
+	assert.Equal(t, "/movies/season1/e001.mkv", items[2].TargetPath)
+	assert.Equal(t, []string{"a.mkv", "season1"}, d.names("movies"))
+	assert.Len(t, d.names(items[1].FileId), 120)
+	// 复制按照 BatchSize 分为3批，每批都要查询一次异步任务
+	assert.Equal(t, 6, d.count("/adrive/v2/batch"))
+	// 分享列表按照 marker 分页
+	assert.Equal(t, 4, d.count("/adrive/v2/file/list_by_share"))
+
+	// 错误的提取码
+	_, err = p.SaveShare("GSHjrcPt9pP", "0000", "/movies", &ShareSaveOptions{ToDriveId: "11001"})
+	assert.NotNil(t, err)
+}
+
+func TestSaveShareOverwrite(t *testing.T) {
+	d := newFakeWebDrive()
+	d.add("movies", "root", "movies", "folder", 0)
+	d.add("exist", "movies", "a.mkv", "file", 10)
+	d.addShare("s1", "root", "a.mkv", "file", 100)
+	p := newFakeWebClient(d)
+
+	items, err := p.SaveShare("GSHjrcPt9pP", "8a2b", "/movies", &ShareSaveOptions{
+		ToDriveId:    "11001",
+		ConflictMode: aliyunpan.FileConflictOverwrite,
+	})
+	require.Nil(t, err)
+	require.Len(t, items, 1)
+	require.Nil(t, items[0].Err)
+	assert.True(t, items[0].Success)
+	// 复制成功后才删除同名文件，并改回原来的文件名
+	assert.True(t, d.trashed["exist"])
+	assert.Equal(t, []string{"a.mkv"}, d.names("movies"))
+	assert.Equal(t, int64(100), d.childByName("movies", "a.mkv").Size)
+	assert.Equal(t, items[0].FileId, d.childByName("movies", "a.mkv").FileId)
+}
+
+func TestSaveShareSingleFile(t *testing.T) {
+	d := newFakeWebDrive()
+	d.add("movies", "root", "movies", "folder", 0)
+	d.addShare("s1", "root", "a.mkv", "file", 100)
+	d.addShare("s2", "root", "b.mkv", "file", 200)
+	d.addShare("s3", "root", "season1", "folder", 0)
+	d.addShare("s4", "s3", "e01.mkv", "file", 300)
+	p := newFakeWebClient(d)
+
+	// 只保存链接指向的文件
+	items, err := p.SaveShare("https://www.alipan.com/s/GSHjrcPt9pP/file/s2?pwd=8a2b", "", "/movies", &ShareSaveOptions{ToDriveId: "11001"})
+	require.Nil(t, err)
+	require.Len(t, items, 1)
+	assert.True(t, items[0].Success)
+	assert.Equal(t, "/movies/b.mkv", items[0].TargetPath)
+	assert.Equal(t, []string{"b.mkv"}, d.names("movies"))
+
+	// 指向文件夹时连同文件夹一起保存
+	items, err = p.SaveShare("https://www.alipan.com/s/GSHjrcPt9pP/file/s3?pwd=8a2b", "", "/movies", &ShareSaveOptions{ToDriveId: "11001"})
+	require.Nil(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "/movies/season1/e01.mkv", items[1].TargetPath)
+	assert.Equal(t, []string{"b.mkv", "season1"}, d.names("movies"))
+
+	_, err = p.SaveShare("https://www.alipan.com/s/GSHjrcPt9pP/file/nothing?pwd=8a2b", "", "/movies", &ShareSaveOptions{ToDriveId: "11001"})
+	assert.NotNil(t, err)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
 	"github.com/tickstep/aliyunpan-api/aliyunpan/apiutil"
 	"github.com/tickstep/library-go/crypto/secp256k1"
 	"github.com/tickstep/library-go/logger"
@@ -119,7 +120,7 @@ func (p *WebPanClient) CreateSession(param *CreateSessionParam) (*CreateSessionR
 	// url
 	fullUrl := &strings.Builder{}
 	fmt.Fprintf(fullUrl, "%s/users/v1/users/device/create_session", API_URL)
-	logger.Verboseln("do request url: " + fullUrl.String())
+	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 
 	// data
 	postData := map[string]interface{}{
@@ -132,7 +133,7 @@ func (p *WebPanClient) CreateSession(param *CreateSessionParam) (*CreateSessionR
 	body, err := p.client.Fetch("POST", fullUrl.String(), postData, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 	if err != nil {
 		logger.Verboseln("do create session error ", err)
-		return nil, apierror.NewFailedApiError(err.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err)
 	}
 
 	// handler common error
@@ -144,7 +145,7 @@ func (p *WebPanClient) CreateSession(param *CreateSessionParam) (*CreateSessionR
 	r := &CreateSessionResult{}
 	if err2 := json.Unmarshal(body, r); err2 != nil {
 		logger.Verboseln("parse create session result json error ", err2)
-		return nil, apierror.NewFailedApiError(err2.Error())
+		return nil, apierror.NewFailedApiErrorWithCause(err2)
 	}
 	return r, nil
 }
@@ -159,14 +160,14 @@ func (p *WebPanClient) CreateSession(param *CreateSessionParam) (*CreateSessionR
 //	// url
 //	fullUrl := &strings.Builder{}
 //	fmt.Fprintf(fullUrl, "%s/users/v1/users/device/renew_session", API_URL)
-//	logger.Verboseln("do request url: " + fullUrl.String())
+//	logger.Verboseln("do request url: " + apitransport.RedactURLString(fullUrl.String()))
 //
 //	// request
 //	data := map[string]string{}
 //	body, err := p.client.Fetch("POST", fullUrl.String(), data, p.AddSignatureHeader(apiutil.AddCommonHeader(header)))
 //	if err != nil {
 //		logger.Verboseln("do renew session error ", err)
-//		return nil, apierror.NewFailedApiError(err.Error())
+//		return nil, apierror.NewFailedApiErrorWithCause(err)
 //	}
 //
 //	// handler common error
@@ -178,7 +179,7 @@ func (p *WebPanClient) CreateSession(param *CreateSessionParam) (*CreateSessionR
 //	r := &CreateSessionResult{}
 //	if err2 := json.Unmarshal(body, r); err2 != nil {
 //		logger.Verboseln("parse renew session result json error ", err2)
-//		return nil, apierror.NewFailedApiError(err2.Error())
+//		return nil, apierror.NewFailedApiErrorWithCause(err2)
 //	}
 //	return r, nil
 //}
@@ -93,6 +93,10 @@ func (p *WebPanClient) recurseMatchPathByShellPattern(driveId string, index int,
 
 // MatchPathByShellPattern 通配符匹配文件路径, pattern为绝对路径，符合的路径文件存放在resultList中
 func (p *WebPanClient) MatchPathByShellPattern(driveId string, pattern string) (resultList *aliyunpan.FileList, error *apierror.ApiError) {
+	driveId, pattern, apiErr := p.ResolveDrivePath(driveId, pattern)
+	if apiErr != nil {
+		return nil, apiErr
+	}
 	errInfo := apierror.NewApiError(apierror.ApiCodeFailed, "")
 	resultList = &aliyunpan.FileList{}
 
@@ -0,0 +1,46 @@
+// Copyright (c) 2020 tickstep.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aliyunpan_web
+
+import (
+	"net/http"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+)
+
+type (
+	// videoPlayOperator 实现 aliyunpan.VideoPlayOperator
+	videoPlayOperator struct {
+		*WebPanClient
+	}
+)
+
+// ChooseVideoSource 选择视频的播放源，优先使用高度不超过 maxHeight 的最高清晰度转码，没有可用的转码时使用原文件下载地址
+func (p *WebPanClient) ChooseVideoSource(driveId, fileId string, maxHeight int) (*aliyunpan.VideoPlaySource, *apierror.ApiError) {
+	return aliyunpan.ChooseVideoSource(&videoPlayOperator{p}, driveId, fileId, maxHeight)
+}
+
+// NewVideoHlsProxy 创建视频的本地 HLS 代理，详见 aliyunpan.VideoHlsProxy
+func (p *WebPanClient) NewVideoHlsProxy(driveId, fileId string, maxHeight int) *aliyunpan.VideoHlsProxy {
+	return aliyunpan.NewVideoHlsProxy(&videoPlayOperator{p}, driveId, fileId, maxHeight)
+}
+
+// FetchUrl 请求播放列表或者视频分片，使用数据传输的 http 客户端
+func (o *videoPlayOperator) FetchUrl(url string) (*http.Response, error) {
+	return o.transferClient.Req("GET", url, nil, map[string]string{
+		"referer": "https://www.aliyundrive.com/",
+	})
+}
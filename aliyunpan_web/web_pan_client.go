@@ -16,6 +16,9 @@ package aliyunpan_web
 
 import (
 	"github.com/tickstep/aliyunpan-api/aliyunpan"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apierror"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/metacache"
 	"github.com/tickstep/library-go/crypto"
 	"github.com/tickstep/library-go/crypto/secp256k1"
 	"github.com/tickstep/library-go/logger"
@@ -47,32 +50,69 @@ type (
 	}
 
 	WebPanClient struct {
-		client        *requester.HTTPClient // http 客户端
-		webToken      WebLoginToken
-		appToken      AppLoginToken
-		appConfig     AppConfig
-		sessionConfig SessionConfig
+		client *requester.HTTPClient // http 客户端
+		// 请求失败重试
+		retryTransport *apitransport.RetryTransport
+		// 请求限速，所有接口调用和数据传输都经过同一个限速器
+		governor *apitransport.Governor
+		// 数据传输（上传分片、下载文件）使用的 http 客户端
+		transferClient *requester.HTTPClient
+		// 请求观测，用于日志、监控指标、链路追踪
+		observer *apitransport.Observer
+		// 全局上传、下载带宽限速
+		uploadLimiter   *aliyunpan.BandwidthLimiter
+		downloadLimiter *aliyunpan.BandwidthLimiter
+		// 获取下载链接时服务器返回的限速，下载时自动应用
+		downloadRatelimits *aliyunpan.DownloadRatelimits
+		webToken           WebLoginToken
+		appToken           AppLoginToken
+		appConfig          AppConfig
+		sessionConfig      SessionConfig
 
 		cacheMutex *sync.Mutex
 		useCache   bool
 		// 网盘文件绝对路径到网盘文件信息实体映射缓存，避免FileInfoByPath频繁访问服务器触发风控
 		filePathCacheMap sync.Map
+		// 可持久化的元数据缓存，可以跨进程共享
+		metaCache *metacache.Cache
+		// 网盘别名注册表
+		driveRegistry *aliyunpan.DriveRegistry
+		// 分享ID到分享Token的缓存
+		shareTokenCache sync.Map
 	}
 )
 
-// NewWebPanClient 创建WebPanClient
-func NewWebPanClient(webToken WebLoginToken, appToken AppLoginToken, appConfig AppConfig, sessionConfig SessionConfig) *WebPanClient {
-	myclient := requester.NewHTTPClient()
+// NewWebPanClient 创建WebPanClient。opts 可以注入自定义的 http 客户端、传输层、代理等，数据传输也会使用同样的配置。
+// 默认不限制请求速率，批量操作需要限速时使用 apitransport.WithGovernorConfig
+func NewWebPanClient(webToken WebLoginToken, appToken AppLoginToken, appConfig AppConfig, sessionConfig SessionConfig, opts ...apitransport.ClientOption) *WebPanClient {
+	options := apitransport.NewClientOptions(opts...)
+	governor := options.NewGovernor()
+	myclient := options.NewHTTPClient()
+	apitransport.InstallGovernor(myclient, governor)
+	retryTransport := apitransport.InstallRetry(myclient, aliyunpan.DefaultRetryPolicy())
+	observer := apitransport.NewObserver()
+	apitransport.InstallObserver(myclient, observer)
+	transferClient := options.NewTransferHTTPClient()
+	apitransport.InstallTransferGovernor(transferClient, governor)
+	apitransport.InstallTransferObserver(transferClient, observer)
 
 	return &WebPanClient{
-		client:           myclient,
-		webToken:         webToken,
-		appToken:         appToken,
-		appConfig:        appConfig,
-		sessionConfig:    sessionConfig,
-		cacheMutex:       &sync.Mutex{},
-		useCache:         false,
-		filePathCacheMap: sync.Map{},
+		client:             myclient,
+		retryTransport:     retryTransport,
+		governor:           governor,
+		transferClient:     transferClient,
+		observer:           observer,
+		uploadLimiter:      aliyunpan.NewBandwidthLimiter(0),
+		downloadLimiter:    aliyunpan.NewBandwidthLimiter(0),
+		downloadRatelimits: aliyunpan.NewDownloadRatelimits(),
+		webToken:           webToken,
+		appToken:           appToken,
+		appConfig:          appConfig,
+		sessionConfig:      sessionConfig,
+		cacheMutex:         &sync.Mutex{},
+		useCache:           false,
+		filePathCacheMap:   sync.Map{},
+		driveRegistry:      aliyunpan.NewDriveRegistry(),
 	}
 }
 
@@ -92,6 +132,25 @@ func (p *WebPanClient) GetAccessToken() string {
 	return p.webToken.AccessToken
 }
 
+// GetDriveRegistry 获取网盘别名注册表，调用 GetUserInfo 后会自动填充
+func (p *WebPanClient) GetDriveRegistry() *aliyunpan.DriveRegistry {
+	return p.driveRegistry
+}
+
+// ResolveDrivePath 解析带网盘别名的路径，例如 resource:/Movies/x.mkv，返回网盘ID和网盘内的路径。
+// 别名还没有加载时会先获取一次用户信息
+func (p *WebPanClient) ResolveDrivePath(driveId, pathStr string) (string, string, *apierror.ApiError) {
+	if !aliyunpan.IsQualifiedPath(pathStr) {
+		return driveId, pathStr, nil
+	}
+	if !p.driveRegistry.Loaded() {
+		if _, err := p.GetUserInfo(); err != nil {
+			return "", "", err
+		}
+	}
+	return p.driveRegistry.ResolvePath(driveId, pathStr)
+}
+
 // EnableCache 启用缓存
 func (p *WebPanClient) EnableCache() {
 	p.cacheMutex.Lock()
@@ -113,7 +172,22 @@ func (p *WebPanClient) DisableCache() {
 	p.useCache = false
 }
 
+// SetMetaCache 设置可持久化的元数据缓存，为nil则不使用。缓存会被 FileInfoByPath、FileListGetAll、MatchPathByShellPattern 共享
+func (p *WebPanClient) SetMetaCache(cache *metacache.Cache) {
+	p.cacheMutex.Lock()
+	defer p.cacheMutex.Unlock()
+	p.metaCache = cache
+}
+
+// GetMetaCache 获取元数据缓存
+func (p *WebPanClient) GetMetaCache() *metacache.Cache {
+	return p.metaCache
+}
+
 func (p *WebPanClient) storeFilePathToCache(driveId, pathStr string, fileEntity *aliyunpan.FileEntity) {
+	if p.metaCache != nil {
+		p.metaCache.PutPath(driveId, pathStr, fileEntity)
+	}
 	p.cacheMutex.Lock()
 	p.cacheMutex.Unlock()
 	if !p.useCache {
@@ -127,26 +201,123 @@ func (p *WebPanClient) storeFilePathToCache(driveId, pathStr string, fileEntity
 func (p *WebPanClient) loadFilePathFromCache(driveId, pathStr string) *aliyunpan.FileEntity {
 	p.cacheMutex.Lock()
 	p.cacheMutex.Unlock()
-	if !p.useCache {
-		return nil
+	if p.useCache {
+		pathStr = formatPathStyle(pathStr)
+		cache, _ := p.filePathCacheMap.LoadOrStore(driveId, &sync.Map{})
+		s := cache.(*sync.Map)
+		if v, ok := s.Load(pathStr); ok {
+			logger.Verboseln("file path cache hit: ", pathStr)
+			return v.(*aliyunpan.FileEntity)
+		}
 	}
-	pathStr = formatPathStyle(pathStr)
-	cache, _ := p.filePathCacheMap.LoadOrStore(driveId, &sync.Map{})
-	s := cache.(*sync.Map)
-	if v, ok := s.Load(pathStr); ok {
-		logger.Verboseln("file path cache hit: ", pathStr)
-		return v.(*aliyunpan.FileEntity)
+	if p.metaCache != nil {
+		if v := p.metaCache.LookupPath(driveId, pathStr, p.FileInfoById); v != nil {
+			logger.Verboseln("file path meta cache hit: ", pathStr)
+			return v
+		}
 	}
 	return nil
 }
 
-// SetTimeout 设置 http 请求超时时间
+// removeFilePathFromCache 删除路径以及子路径的缓存，文件移动、重命名、删除后调用
+func (p *WebPanClient) removeFilePathFromCache(driveId string, fileEntity *aliyunpan.FileEntity) {
+	p.removeFileFromCache(driveId, fileEntity.FileId)
+	if fileEntity.Path != "" {
+		p.removeMemoryPathCache(driveId, formatPathStyle(fileEntity.Path))
+	}
+}
+
+// removeFileFromCache 删除文件ID对应的所有缓存，包括文件所在目录的文件列表以及文件夹下所有子路径
+func (p *WebPanClient) removeFileFromCache(driveId, fileId string) {
+	if p.metaCache != nil {
+		p.metaCache.Invalidate(driveId, fileId)
+	}
+	cache, ok := p.filePathCacheMap.Load(driveId)
+	if !ok {
+		return
+	}
+	paths := []string{}
+	cache.(*sync.Map).Range(func(key, value interface{}) bool {
+		if f, ok := value.(*aliyunpan.FileEntity); ok && f.FileId == fileId {
+			paths = append(paths, key.(string))
+		}
+		return true
+	})
+	for _, pathStr := range paths {
+		p.removeMemoryPathCache(driveId, pathStr)
+	}
+}
+
+// removeMemoryPathCache 删除内存中路径以及子路径的缓存
+func (p *WebPanClient) removeMemoryPathCache(driveId, pathStr string) {
+	cache, ok := p.filePathCacheMap.Load(driveId)
+	if !ok {
+		return
+	}
+	cache.(*sync.Map).Range(func(key, value interface{}) bool {
+		if k := key.(string); k == pathStr || strings.HasPrefix(k, pathStr+"/") {
+			cache.(*sync.Map).Delete(key)
+		}
+		return true
+	})
+}
+
+// removeDirListFromCache 删除目录文件列表的缓存，目录中新建、复制或者移入文件后调用
+func (p *WebPanClient) removeDirListFromCache(driveId, parentFileId string) {
+	if p.metaCache != nil {
+		p.metaCache.InvalidateDir(driveId, parentFileId)
+	}
+}
+
+// SetTimeout 设置 http 请求超时时间，会覆盖创建客户端时 WithTimeout、WithHTTPClient 设置的超时时间。
+// 只作用于接口请求，数据传输的超时时间由 WithTransferTimeout 设置。
+// 超时时间包含请求失败重试的时间，调小超时时间时需要同时调整重试策略的 MaxElapsed，详见 aliyunpan.RetryPolicy
 func (p *WebPanClient) SetTimeout(t time.Duration) {
 	if p.client != nil {
 		p.client.Timeout = t
 	}
 }
 
+// SetRetryPolicy 设置请求失败重试策略，为nil则不重试
+func (p *WebPanClient) SetRetryPolicy(policy *aliyunpan.RetryPolicy) {
+	p.retryTransport.SetPolicy(policy)
+}
+
+// GetRetryPolicy 获取当前的请求失败重试策略
+func (p *WebPanClient) GetRetryPolicy() *aliyunpan.RetryPolicy {
+	return p.retryTransport.Policy()
+}
+
+// GetGovernor 获取请求限速器，可以用于修改限速配置和查看请求统计
+func (p *WebPanClient) GetGovernor() *apitransport.Governor {
+	return p.governor
+}
+
+// AddRequestHook 添加请求观测钩子，所有的接口请求和数据传输都会经过钩子。URL中的签名、Token等参数已经脱敏
+func (p *WebPanClient) AddRequestHook(hook apitransport.Hook) {
+	p.observer.AddHook(hook)
+}
+
+// SetUploadSpeedLimit 设置全局上传限速（字节/秒），小于等于0代表不限速。传输过程中修改立即生效
+func (p *WebPanClient) SetUploadSpeedLimit(bytesPerSecond int64) {
+	p.uploadLimiter.SetRate(bytesPerSecond)
+}
+
+// SetDownloadSpeedLimit 设置全局下载限速（字节/秒），小于等于0代表不限速。传输过程中修改立即生效
+func (p *WebPanClient) SetDownloadSpeedLimit(bytesPerSecond int64) {
+	p.downloadLimiter.SetRate(bytesPerSecond)
+}
+
+// GetUploadLimiter 获取全局上传限速器
+func (p *WebPanClient) GetUploadLimiter() *aliyunpan.BandwidthLimiter {
+	return p.uploadLimiter
+}
+
+// GetDownloadLimiter 获取全局下载限速器
+func (p *WebPanClient) GetDownloadLimiter() *aliyunpan.BandwidthLimiter {
+	return p.downloadLimiter
+}
+
 // UpdateUserId 更新用户ID
 func (p *WebPanClient) UpdateUserId(userId string) {
 	p.appConfig.UserId = userId
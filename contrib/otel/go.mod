@@ -0,0 +1,20 @@
+module github.com/tickstep/aliyunpan-api/contrib/otel
+
+go 1.20
+
+require (
+	github.com/tickstep/aliyunpan-api v0.0.0-20261019100720-7632fa4cbf14
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/json-iterator/go v1.1.10 // indirect
+	github.com/modern-go/concurrent v0.0.0-20180228061459-e0a39a4cb421 // indirect
+	github.com/modern-go/reflect2 v0.0.0-20180701023420-4b7aa43c6742 // indirect
+	github.com/satori/go.uuid v1.2.0 // indirect
+	github.com/tickstep/library-go v0.1.3 // indirect
+	go.opentelemetry.io/otel/metric v1.24.0 // indirect
+)
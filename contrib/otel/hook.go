@@ -0,0 +1,72 @@
+// Package otelhook 将 aliyunpan-api 的请求导出为 OpenTelemetry 链路追踪的 span
+package otelhook
+
+import (
+	"context"
+
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// instrumentationName 默认的 tracer 名称
+	instrumentationName = "github.com/tickstep/aliyunpan-api"
+)
+
+type (
+	// Hook OpenTelemetry 链路追踪钩子，每个请求对应一个 client span
+	Hook struct {
+		tracer trace.Tracer
+	}
+
+	spanKey struct{}
+)
+
+// NewHook 创建链路追踪钩子，tracer 为nil则使用全局的 TracerProvider
+func NewHook(tracer trace.Tracer) *Hook {
+	if tracer == nil {
+		tracer = otel.Tracer(instrumentationName)
+	}
+	return &Hook{
+		tracer: tracer,
+	}
+}
+
+// OnRequestStart 实现 apitransport.Hook
+func (h *Hook) OnRequestStart(ctx context.Context, info *apitransport.RequestInfo) context.Context {
+	ctx, span := h.tracer.Start(ctx, info.Method+" "+info.Endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(info.StartTime),
+		trace.WithAttributes(
+			attribute.String("http.request.method", info.Method),
+			attribute.String("url.template", info.Endpoint),
+			attribute.String("url.full", info.URL),
+			attribute.String("server.address", info.Host),
+		))
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// OnRequestEnd 实现 apitransport.Hook
+func (h *Hook) OnRequestEnd(ctx context.Context, info *apitransport.RequestInfo) {
+	span, ok := ctx.Value(spanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+	span.SetAttributes(
+		attribute.Int("http.response.status_code", info.StatusCode),
+		attribute.Int("aliyunpan.retries", info.Retries),
+		attribute.Int64("http.request.body.size", info.BytesSent),
+		attribute.Int64("http.response.body.size", info.BytesReceived),
+		attribute.String("aliyunpan.request_id", info.RequestId),
+	)
+	if info.Err != nil {
+		span.RecordError(info.Err)
+		span.SetStatus(codes.Error, info.Err.Error())
+	} else if info.StatusCode >= 400 {
+		span.SetStatus(codes.Error, "")
+	}
+	span.End(trace.WithTimestamp(info.StartTime.Add(info.Latency)))
+}
@@ -0,0 +1,24 @@
+module github.com/tickstep/aliyunpan-api/contrib/prometheus
+
+go 1.20
+
+require (
+	github.com/prometheus/client_golang v1.19.1
+	github.com/tickstep/aliyunpan-api v0.0.0-20261019100720-7632fa4cbf14
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/json-iterator/go v1.1.12 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
+	github.com/modern-go/reflect2 v1.0.2 // indirect
+	github.com/prometheus/client_model v0.5.0 // indirect
+	github.com/prometheus/common v0.48.0 // indirect
+	github.com/prometheus/procfs v0.12.0 // indirect
+	github.com/satori/go.uuid v1.2.0 // indirect
+	github.com/tickstep/library-go v0.1.3 // indirect
+	golang.org/x/sys v0.17.0 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+)
@@ -0,0 +1,88 @@
+// Package promhook 将 aliyunpan-api 的请求观测数据导出为 Prometheus 指标
+package promhook
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tickstep/aliyunpan-api/aliyunpan/apitransport"
+)
+
+type (
+	// Hook Prometheus 指标钩子
+	Hook struct {
+		requests *prometheus.CounterVec
+		latency  *prometheus.HistogramVec
+		retries  *prometheus.CounterVec
+		bytes    *prometheus.CounterVec
+	}
+)
+
+// NewHook 创建 Prometheus 指标钩子并注册到 registerer，registerer 为nil则使用 prometheus.DefaultRegisterer
+func NewHook(registerer prometheus.Registerer, namespace string) (*Hook, error) {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+	h := &Hook{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "aliyunpan",
+			Name:      "requests_total",
+			Help:      "Total number of aliyunpan API requests.",
+		}, []string{"method", "endpoint", "class", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "aliyunpan",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of aliyunpan API requests, including retries.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "endpoint", "class"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "aliyunpan",
+			Name:      "request_retries_total",
+			Help:      "Total number of retried aliyunpan API requests.",
+		}, []string{"method", "endpoint", "class"}),
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "aliyunpan",
+			Name:      "transferred_bytes_total",
+			Help:      "Total number of bytes sent and received.",
+		}, []string{"class", "direction"}),
+	}
+	for _, c := range []prometheus.Collector{h.requests, h.latency, h.retries, h.bytes} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// OnRequestStart 实现 apitransport.Hook
+func (h *Hook) OnRequestStart(ctx context.Context, info *apitransport.RequestInfo) context.Context {
+	return ctx
+}
+
+// OnRequestEnd 实现 apitransport.Hook
+func (h *Hook) OnRequestEnd(ctx context.Context, info *apitransport.RequestInfo) {
+	class := className(info.Class)
+	status := "error"
+	if info.StatusCode != 0 {
+		status = strconv.Itoa(info.StatusCode)
+	}
+	h.requests.WithLabelValues(info.Method, info.Endpoint, class, status).Inc()
+	h.latency.WithLabelValues(info.Method, info.Endpoint, class).Observe(info.Latency.Seconds())
+	if info.Retries > 0 {
+		h.retries.WithLabelValues(info.Method, info.Endpoint, class).Add(float64(info.Retries))
+	}
+	h.bytes.WithLabelValues(class, "sent").Add(float64(info.BytesSent))
+	h.bytes.WithLabelValues(class, "received").Add(float64(info.BytesReceived))
+}
+
+func className(class apitransport.RequestClass) string {
+	if class == apitransport.RequestClassTransfer {
+		return "transfer"
+	}
+	return "metadata"
+}